@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/artofimagination/mysql-user-db-go-interface/initialization"
+)
+
+const backupUsage = `Usage:
+  %[1]s backup [FILE]    write the dump of the DB to FILE, to the standard output if FILE is not set
+  %[1]s restore [FILE]   load the dump from FILE into the empty DB, from the standard input if FILE is not set
+`
+
+// runBackup executes the backup and restore commands on the DB backend selected in cfg.
+// The messages are written to the standard error, so that the dump can be piped.
+func runBackup(cfg *initialization.Config, command string, args []string) (err error) {
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, backupUsage, os.Args[0])
+		return fmt.Errorf("Too many arguments of %s", command)
+	}
+
+	// The bootstrap of the DB prints its progress to the standard output, which may carry the dump.
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	dbController, err := dbcontrollers.NewDBController(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errClose := dbController.DBConnector.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}()
+
+	switch command {
+	case "backup":
+		output := io.WriteCloser(stdout)
+		if len(args) == 1 {
+			if output, err = os.Create(args[0]); err != nil {
+				return err
+			}
+		}
+
+		count, err := dbController.Backup(context.Background(), output)
+		if errClose := output.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Backed up %d rows!\n", count)
+		return nil
+	default:
+		input := io.ReadCloser(os.Stdin)
+		if len(args) == 1 {
+			if input, err = os.Open(args[0]); err != nil {
+				return err
+			}
+		}
+		defer input.Close()
+
+		count, err := dbController.Restore(context.Background(), input)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Restored %d rows!\n", count)
+		return nil
+	}
+}
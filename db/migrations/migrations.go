@@ -0,0 +1,61 @@
+// Package migrations embeds the SQL migrations of the storage backends,
+// so that the binary always ships the schema version its code was written for.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Names of the migration directories.
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Source returns the migrations of the backend. If directory is set, the files found there are used
+// instead of the embedded ones, which lets developers try new migrations without rebuilding the binary.
+func Source(backend string, directory string) (migrate.MigrationSource, error) {
+	if directory != "" {
+		return &migrate.FileMigrationSource{Dir: directory}, nil
+	}
+
+	backendFiles, err := fs.Sub(files, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &migrate.HttpFileSystemMigrationSource{FileSystem: http.FS(backendFiles)}, nil
+}
+
+// Version returns the schema version set by the migration, the timestamp prefix of its ID.
+// The backends name their migrations differently, the schema versions are comparable across them.
+func Version(ID string) string {
+	return strings.SplitN(ID, "_", 2)[0]
+}
+
+// LatestVersion returns the schema version set by the last embedded migration of the backend.
+func LatestVersion(backend string) (string, error) {
+	source, err := Source(backend, "")
+	if err != nil {
+		return "", err
+	}
+
+	found, err := source.FindMigrations()
+	if err != nil {
+		return "", err
+	}
+
+	if len(found) == 0 {
+		return "", fmt.Errorf("No migration is embedded for %s", backend)
+	}
+	return Version(found[len(found)-1].Id), nil
+}
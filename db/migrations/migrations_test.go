@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	for _, backend := range []string{MySQL, Postgres, SQLite} {
+		source, err := Source(backend, "")
+		if err != nil {
+			t.Fatalf("%s: failed to open embedded migrations: %s", backend, err)
+		}
+
+		embedded, err := source.FindMigrations()
+		if err != nil {
+			t.Fatalf("%s: failed to find embedded migrations: %s", backend, err)
+		}
+
+		files, err := filepath.Glob(filepath.Join(backend, "*.sql"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(embedded) == 0 || len(embedded) != len(files) {
+			t.Errorf("%s: %d migrations embedded, %d files in the directory", backend, len(embedded), len(files))
+		}
+
+		for _, migration := range embedded {
+			if len(migration.Up) == 0 || len(migration.Down) == 0 {
+				t.Errorf("%s: migration %s has no Up or Down statements", backend, migration.Id)
+			}
+		}
+	}
+}
+
+func TestSourceDirectoryOverride(t *testing.T) {
+	source, err := Source(SQLite, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := source.FindMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(migrations) != 0 {
+		t.Errorf("Expected the migrations of the empty override directory, got %d", len(migrations))
+	}
+}
+
+// The dumps are restored into any backend with the same schema version, so the backends have to stay in step.
+func TestLatestVersion(t *testing.T) {
+	expected, err := LatestVersion(MySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backend := range []string{Postgres, SQLite} {
+		version, err := LatestVersion(backend)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if version != expected {
+			t.Errorf("%s: schema version is %s, the MySQL one is %s", backend, version, expected)
+		}
+	}
+
+	if version := Version("20210901120000_add_webhooks.sql"); version != "20210901120000" {
+		t.Errorf("Unexpected version %s", version)
+	}
+}
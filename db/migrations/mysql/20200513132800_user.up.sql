@@ -17,3 +17,7 @@ CREATE TABLE IF NOT EXISTS users(
    created_at DATETIME NOT NULL DEFAULT NOW(),
    updated_at DATETIME NOT NULL DEFAULT NOW()
 );
+
+-- +migrate Down
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS user_settings;
@@ -7,4 +7,7 @@ CREATE TABLE IF NOT EXISTS projects(
    config json,
    created_at DATETIME NOT NULL DEFAULT NOW(),
    updated_at DATETIME NOT NULL DEFAULT NOW()
-);
\ No newline at end of file
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS projects;
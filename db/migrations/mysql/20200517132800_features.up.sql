@@ -8,4 +8,7 @@ CREATE TABLE IF NOT EXISTS features(
 );
 
 INSERT INTO features (name, config) values ('Survival', '{"config":{"Food Count": "500", "Creature count": "50"}}');
-INSERT INTO features (name, config) values ('Image Processing', '{"config":{"Food Count": "500", "Creature count": "150"}}');
\ No newline at end of file
+INSERT INTO features (name, config) values ('Image Processing', '{"config":{"Food Count": "500", "Creature count": "150"}}');
+
+-- +migrate Down
+DROP TABLE IF EXISTS features;
@@ -10,3 +10,10 @@ CREATE TABLE IF NOT EXISTS user_assets(
 ALTER TABLE users 
 ADD COLUMN user_assets_id binary(16) AFTER user_settings_id, 
 ADD FOREIGN KEY (user_assets_id)  REFERENCES user_assets(id);
+
+-- +migrate Down
+ALTER TABLE users
+DROP FOREIGN KEY users_ibfk_2,
+DROP COLUMN user_assets_id;
+
+DROP TABLE IF EXISTS user_assets;
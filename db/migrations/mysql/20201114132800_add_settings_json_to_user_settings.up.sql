@@ -1,5 +1,5 @@
--- +migrate Down
-ALTER TABLE user_settings DROP COLUMN two_steps_verif;
-
 -- +migrate Up
-ALTER TABLE user_settings ADD settings json NOT NULL;
\ No newline at end of file
+ALTER TABLE user_settings ADD settings json NOT NULL;
+
+-- +migrate Down
+ALTER TABLE user_settings DROP COLUMN settings;
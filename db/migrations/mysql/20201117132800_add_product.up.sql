@@ -32,3 +32,8 @@ CREATE TABLE IF NOT EXISTS users_products(
    created_at DATETIME NOT NULL DEFAULT NOW(),
    updated_at DATETIME NOT NULL DEFAULT NOW()
 );
+
+-- +migrate Down
+DROP TABLE IF EXISTS users_products;
+DROP TABLE IF EXISTS privileges;
+DROP TABLE IF EXISTS products;
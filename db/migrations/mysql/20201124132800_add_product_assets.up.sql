@@ -13,3 +13,12 @@ ADD FOREIGN KEY (product_assets_id) REFERENCES product_assets(id);
 
 -- +migrate Up
 ALTER TABLE users ADD UNIQUE (name);
+
+-- +migrate Down
+ALTER TABLE users DROP INDEX name_2;
+
+ALTER TABLE products
+DROP FOREIGN KEY products_ibfk_1,
+DROP COLUMN product_assets_id;
+
+DROP TABLE IF EXISTS product_assets;
@@ -16,3 +16,18 @@ ADD FOREIGN KEY (product_details_id) REFERENCES product_details(id);
 -- +migrate Up
 ALTER TABLE products ADD UNIQUE (name);
 ALTER TABLE privileges ADD UNIQUE (name);
+
+-- +migrate Down
+ALTER TABLE privileges DROP INDEX name;
+ALTER TABLE products DROP INDEX name;
+
+ALTER TABLE products
+DROP FOREIGN KEY products_ibfk_2,
+DROP COLUMN product_details_id;
+
+-- The details of the existing products are lost, they are reset to an empty object.
+ALTER TABLE products ADD COLUMN details json AFTER public;
+UPDATE products SET details = JSON_OBJECT();
+ALTER TABLE products MODIFY details json NOT NULL;
+
+DROP TABLE IF EXISTS product_details;
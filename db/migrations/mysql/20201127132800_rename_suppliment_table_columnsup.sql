@@ -3,3 +3,9 @@ ALTER TABLE user_settings RENAME COLUMN settings TO data;
 ALTER TABLE product_details RENAME COLUMN details TO data;
 ALTER TABLE user_assets RENAME COLUMN refs TO data;
 ALTER TABLE product_assets RENAME COLUMN refs TO data;
+
+-- +migrate Down
+ALTER TABLE product_assets RENAME COLUMN data TO refs;
+ALTER TABLE user_assets RENAME COLUMN data TO refs;
+ALTER TABLE product_details RENAME COLUMN data TO details;
+ALTER TABLE user_settings RENAME COLUMN data TO settings;
@@ -1,2 +1,5 @@
 -- +migrate Up
 ALTER TABLE users MODIFY password VARCHAR(1024);
+
+-- +migrate Down
+ALTER TABLE users MODIFY password BINARY(60) NOT NULL;
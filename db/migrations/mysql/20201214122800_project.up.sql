@@ -51,4 +51,21 @@ CREATE TABLE IF NOT EXISTS users_viewers(
    is_owner bool,
    created_at DATETIME NOT NULL DEFAULT NOW(),
    updated_at DATETIME NOT NULL DEFAULT NOW()
-);
\ No newline at end of file
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS users_viewers;
+DROP TABLE IF EXISTS users_projects;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS project_assets;
+DROP TABLE IF EXISTS project_details;
+
+CREATE TABLE IF NOT EXISTS projects(
+   id binary(16) PRIMARY KEY,
+   user_id binary(16) REFERENCES users(id),
+   features_id integer REFERENCES features(id),
+   name varchar(128) UNIQUE NOT NULL,
+   config json,
+   created_at DATETIME NOT NULL DEFAULT NOW(),
+   updated_at DATETIME NOT NULL DEFAULT NOW()
+);
@@ -1,2 +1,5 @@
 -- +migrate Up
-ALTER TABLE products DROP COLUMN public;
\ No newline at end of file
+ALTER TABLE products DROP COLUMN public;
+
+-- +migrate Down
+ALTER TABLE products ADD COLUMN public bool NOT NULL DEFAULT false AFTER name;
@@ -14,3 +14,16 @@ CREATE TABLE IF NOT EXISTS viewers(
 );
 
 ALTER TABLE `users_viewers` ADD CONSTRAINT fk_viewer_id FOREIGN KEY (viewer_id) REFERENCES viewers(id);
+
+-- +migrate Down
+ALTER TABLE users_viewers
+DROP FOREIGN KEY fk_viewer_id,
+DROP INDEX fk_viewer_id;
+
+DROP TABLE IF EXISTS viewers;
+
+ALTER TABLE users_viewers ADD COLUMN is_owner bool AFTER projects_id;
+
+-- The uuid viewer identifiers do not fit the previous bigint column.
+UPDATE users_viewers SET viewer_id = NULL;
+ALTER TABLE users_viewers MODIFY viewer_id bigint;
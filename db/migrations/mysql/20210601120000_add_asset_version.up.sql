@@ -0,0 +1,16 @@
+-- +migrate Up
+-- The version is incremented by every update of the data, writers pass the version they read to detect conflicts.
+ALTER TABLE user_settings ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE user_assets ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE product_details ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE product_assets ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE project_details ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE project_assets ADD COLUMN version bigint NOT NULL DEFAULT 1;
+
+-- +migrate Down
+ALTER TABLE project_assets DROP COLUMN version;
+ALTER TABLE project_details DROP COLUMN version;
+ALTER TABLE product_assets DROP COLUMN version;
+ALTER TABLE product_details DROP COLUMN version;
+ALTER TABLE user_assets DROP COLUMN version;
+ALTER TABLE user_settings DROP COLUMN version;
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- The details most often used by the filters of /get-products and /get-projects are indexed through generated columns.
+-- The columns contain the unquoted JSON value, the filters still compare the JSON values for exact matches.
+ALTER TABLE product_details
+  ADD COLUMN is_free varchar(255) GENERATED ALWAYS AS (LEFT(JSON_UNQUOTE(JSON_EXTRACT(data, '$.is_free')), 255)) VIRTUAL,
+  ADD COLUMN requires_3d varchar(255) GENERATED ALWAYS AS (LEFT(JSON_UNQUOTE(JSON_EXTRACT(data, '$.requires_3d')), 255)) VIRTUAL,
+  ADD COLUMN has_trial varchar(255) GENERATED ALWAYS AS (LEFT(JSON_UNQUOTE(JSON_EXTRACT(data, '$.has_trial')), 255)) VIRTUAL,
+  ADD INDEX is_free (is_free),
+  ADD INDEX requires_3d (requires_3d),
+  ADD INDEX has_trial (has_trial);
+ALTER TABLE project_details
+  ADD COLUMN name varchar(255) GENERATED ALWAYS AS (LEFT(JSON_UNQUOTE(JSON_EXTRACT(data, '$.name')), 255)) VIRTUAL,
+  ADD COLUMN visibility varchar(255) GENERATED ALWAYS AS (LEFT(JSON_UNQUOTE(JSON_EXTRACT(data, '$.visibility')), 255)) VIRTUAL,
+  ADD INDEX name (name),
+  ADD INDEX visibility (visibility);
+
+-- +migrate Down
+ALTER TABLE project_details
+  DROP INDEX visibility,
+  DROP INDEX name,
+  DROP COLUMN visibility,
+  DROP COLUMN name;
+ALTER TABLE product_details
+  DROP INDEX has_trial,
+  DROP INDEX requires_3d,
+  DROP INDEX is_free,
+  DROP COLUMN has_trial,
+  DROP COLUMN requires_3d,
+  DROP COLUMN is_free;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Every update of an asset copies the replaced version here, so it can be listed, compared and restored.
+-- The assets of all types share the table, asset_type is the name of the asset table.
+CREATE TABLE IF NOT EXISTS asset_history(
+   asset_type varchar(32) NOT NULL,
+   asset_id binary(16) NOT NULL,
+   version bigint NOT NULL,
+   data json NOT NULL,
+   changed_by binary(16),
+   changed_at DATETIME NOT NULL DEFAULT NOW(),
+   PRIMARY KEY (asset_type, asset_id, version)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS asset_history;
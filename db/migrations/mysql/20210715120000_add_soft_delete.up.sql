@@ -0,0 +1,10 @@
+-- +migrate Up
+-- Deleted users, products and projects are only marked in soft delete mode and purged after the retention period.
+ALTER TABLE users ADD COLUMN deleted_at DATETIME NULL, ADD INDEX users_deleted_at (deleted_at);
+ALTER TABLE products ADD COLUMN deleted_at DATETIME NULL, ADD INDEX products_deleted_at (deleted_at);
+ALTER TABLE projects ADD COLUMN deleted_at DATETIME NULL, ADD INDEX projects_deleted_at (deleted_at);
+
+-- +migrate Down
+ALTER TABLE projects DROP INDEX projects_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE products DROP INDEX products_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE users DROP INDEX users_deleted_at, DROP COLUMN deleted_at;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Every change made through the controller is recorded here in the transaction of the change.
+-- before_data and after_data are snapshots of the changed entity, actor is NULL for changes not made on behalf of a user.
+CREATE TABLE IF NOT EXISTS audit_events(
+   id bigint NOT NULL AUTO_INCREMENT,
+   occurred_at DATETIME NOT NULL DEFAULT NOW(),
+   actor binary(16),
+   action varchar(32) NOT NULL,
+   entity_type varchar(64) NOT NULL,
+   entity_id binary(16) NOT NULL,
+   before_data json,
+   after_data json,
+   PRIMARY KEY (id),
+   INDEX audit_events_entity (entity_type, entity_id),
+   INDEX audit_events_actor (actor),
+   INDEX audit_events_occurred_at (occurred_at)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_events;
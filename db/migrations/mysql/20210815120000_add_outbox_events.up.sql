@@ -0,0 +1,17 @@
+-- +migrate Up
+-- Domain events written in the transaction of the change, a row is deleted once the event is delivered to every sink.
+-- attempts and last_error describe the failed deliveries of the event.
+CREATE TABLE IF NOT EXISTS outbox_events(
+   id bigint NOT NULL AUTO_INCREMENT,
+   event_type varchar(64) NOT NULL,
+   occurred_at DATETIME NOT NULL DEFAULT NOW(),
+   actor binary(16),
+   aggregate_id binary(16) NOT NULL,
+   payload json NOT NULL,
+   attempts int NOT NULL DEFAULT 0,
+   last_error varchar(1024),
+   PRIMARY KEY (id)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS outbox_events;
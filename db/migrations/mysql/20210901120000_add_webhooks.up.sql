@@ -0,0 +1,35 @@
+-- +migrate Up
+-- Subscriptions of external services to the domain events. event_types is a JSON array, empty for every event.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions(
+   id binary(16) NOT NULL,
+   url varchar(2048) NOT NULL,
+   secret varchar(255) NOT NULL,
+   event_types json NOT NULL,
+   created_at DATETIME NOT NULL DEFAULT NOW(),
+   PRIMARY KEY (id)
+);
+
+-- +migrate Up
+-- Delivery log of the subscriptions. An event is queued once per subscription, payload is the delivered event.
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+   id bigint NOT NULL AUTO_INCREMENT,
+   subscription_id binary(16) NOT NULL,
+   event_id bigint NOT NULL,
+   event_type varchar(64) NOT NULL,
+   payload json NOT NULL,
+   status varchar(16) NOT NULL,
+   attempts int NOT NULL DEFAULT 0,
+   next_attempt_at DATETIME NOT NULL,
+   last_attempt_at DATETIME,
+   last_status_code int NOT NULL DEFAULT 0,
+   last_error varchar(1024),
+   created_at DATETIME NOT NULL DEFAULT NOW(),
+   PRIMARY KEY (id),
+   UNIQUE INDEX webhook_deliveries_event (subscription_id, event_id),
+   INDEX webhook_deliveries_due (status, next_attempt_at),
+   FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,144 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS user_settings(
+   id uuid PRIMARY KEY,
+   data jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS user_assets(
+   id uuid PRIMARY KEY,
+   data jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users(
+   id uuid PRIMARY KEY,
+   name VARCHAR (50) UNIQUE NOT NULL,
+   email VARCHAR (300) UNIQUE NOT NULL,
+   password VARCHAR (1024),
+   user_settings_id uuid REFERENCES user_settings(id),
+   user_assets_id uuid REFERENCES user_assets(id),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS product_details(
+   id uuid PRIMARY KEY,
+   data jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS product_assets(
+   id uuid PRIMARY KEY,
+   data jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS products(
+   id uuid PRIMARY KEY,
+   name VARCHAR (255) UNIQUE NOT NULL,
+   product_details_id uuid REFERENCES product_details(id),
+   product_assets_id uuid REFERENCES product_assets(id),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS privileges(
+   id SMALLSERIAL PRIMARY KEY,
+   name VARCHAR (255) UNIQUE NOT NULL,
+   description VARCHAR (512) NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+INSERT INTO privileges (name, description) VALUES ('Owner', 'The user owns the product, has the right to change or delete it. If there are multiple owners delete/change can happen if all the owners aggree.');
+INSERT INTO privileges (name, description) VALUES ('User', 'The user acquired the product. They can create projects based on the product, but cannot modify or delete the product itself.');
+INSERT INTO privileges (name, description) VALUES ('Partner', 'The user can change the product, but requires the owners approval.');
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users_products(
+   products_id uuid REFERENCES products(id),
+   users_id uuid REFERENCES users(id),
+   privileges_id SMALLINT,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS project_details(
+   id uuid PRIMARY KEY,
+   data jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS project_assets(
+   id uuid PRIMARY KEY,
+   data jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS projects(
+   id uuid PRIMARY KEY,
+   products_id uuid NOT NULL REFERENCES products(id),
+   project_details_id uuid REFERENCES project_details(id),
+   project_assets_id uuid REFERENCES project_assets(id),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users_projects(
+   projects_id uuid REFERENCES projects(id),
+   users_id uuid REFERENCES users(id),
+   privileges_id SMALLINT,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS viewers(
+   id uuid PRIMARY KEY,
+   owner_id uuid REFERENCES users(id),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users_viewers(
+   users_id uuid REFERENCES users(id),
+   viewer_id uuid REFERENCES viewers(id),
+   projects_id uuid REFERENCES projects(id),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS users_viewers;
+DROP TABLE IF EXISTS viewers;
+DROP TABLE IF EXISTS users_projects;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS project_assets;
+DROP TABLE IF EXISTS project_details;
+DROP TABLE IF EXISTS users_products;
+DROP TABLE IF EXISTS privileges;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS product_assets;
+DROP TABLE IF EXISTS product_details;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS user_assets;
+DROP TABLE IF EXISTS user_settings;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- The filters of /get-products and /get-projects select the details by jsonb containment.
+CREATE INDEX product_details_data ON product_details USING gin (data jsonb_path_ops);
+CREATE INDEX project_details_data ON project_details USING gin (data jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX project_details_data;
+DROP INDEX product_details_data;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Every update of an asset copies the replaced version here, so it can be listed, compared and restored.
+-- The assets of all types share the table, asset_type is the name of the asset table.
+CREATE TABLE IF NOT EXISTS asset_history(
+   asset_type VARCHAR (32) NOT NULL,
+   asset_id uuid NOT NULL,
+   version bigint NOT NULL,
+   data jsonb NOT NULL,
+   changed_by uuid,
+   changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   PRIMARY KEY (asset_type, asset_id, version)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS asset_history;
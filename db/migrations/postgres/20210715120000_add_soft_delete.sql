@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Deleted users, products and projects are only marked in soft delete mode and purged after the retention period.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL;
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP NULL;
+ALTER TABLE projects ADD COLUMN deleted_at TIMESTAMP NULL;
+CREATE INDEX users_deleted_at ON users (deleted_at);
+CREATE INDEX products_deleted_at ON products (deleted_at);
+CREATE INDEX projects_deleted_at ON projects (deleted_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS projects_deleted_at;
+DROP INDEX IF EXISTS products_deleted_at;
+DROP INDEX IF EXISTS users_deleted_at;
+ALTER TABLE projects DROP COLUMN deleted_at;
+ALTER TABLE products DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Every change made through the controller is recorded here in the transaction of the change.
+-- before_data and after_data are snapshots of the changed entity, actor is NULL for changes not made on behalf of a user.
+CREATE TABLE IF NOT EXISTS audit_events(
+   id BIGSERIAL PRIMARY KEY,
+   occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   actor uuid,
+   action VARCHAR (32) NOT NULL,
+   entity_type VARCHAR (64) NOT NULL,
+   entity_id uuid NOT NULL,
+   before_data jsonb,
+   after_data jsonb
+);
+CREATE INDEX IF NOT EXISTS audit_events_entity ON audit_events (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_events_actor ON audit_events (actor);
+CREATE INDEX IF NOT EXISTS audit_events_occurred_at ON audit_events (occurred_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Domain events written in the transaction of the change, a row is deleted once the event is delivered to every sink.
+-- attempts and last_error describe the failed deliveries of the event.
+CREATE TABLE IF NOT EXISTS outbox_events(
+   id BIGSERIAL PRIMARY KEY,
+   event_type VARCHAR (64) NOT NULL,
+   occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   actor uuid,
+   aggregate_id uuid NOT NULL,
+   payload jsonb NOT NULL,
+   attempts INTEGER NOT NULL DEFAULT 0,
+   last_error VARCHAR (1024)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,31 @@
+-- +migrate Up
+-- Subscriptions of external services to the domain events. event_types is a JSON array, empty for every event.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions(
+   id uuid PRIMARY KEY,
+   url VARCHAR (2048) NOT NULL,
+   secret VARCHAR (255) NOT NULL,
+   event_types jsonb NOT NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Delivery log of the subscriptions. An event is queued once per subscription, payload is the delivered event.
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+   id BIGSERIAL PRIMARY KEY,
+   subscription_id uuid NOT NULL REFERENCES webhook_subscriptions(id),
+   event_id BIGINT NOT NULL,
+   event_type VARCHAR (64) NOT NULL,
+   payload jsonb NOT NULL,
+   status VARCHAR (16) NOT NULL,
+   attempts INTEGER NOT NULL DEFAULT 0,
+   next_attempt_at TIMESTAMP NOT NULL,
+   last_attempt_at TIMESTAMP,
+   last_status_code INTEGER NOT NULL DEFAULT 0,
+   last_error VARCHAR (1024),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
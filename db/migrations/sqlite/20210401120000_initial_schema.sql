@@ -0,0 +1,144 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS user_settings(
+   id TEXT PRIMARY KEY,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS user_assets(
+   id TEXT PRIMARY KEY,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users(
+   id TEXT PRIMARY KEY,
+   name TEXT UNIQUE NOT NULL CONSTRAINT name_length CHECK (length(name) <= 50),
+   email TEXT UNIQUE NOT NULL CONSTRAINT email_length CHECK (length(email) <= 300),
+   password TEXT CONSTRAINT password_length CHECK (length(password) <= 1024),
+   user_settings_id TEXT REFERENCES user_settings(id),
+   user_assets_id TEXT REFERENCES user_assets(id),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS product_details(
+   id TEXT PRIMARY KEY,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS product_assets(
+   id TEXT PRIMARY KEY,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS products(
+   id TEXT PRIMARY KEY,
+   name TEXT UNIQUE NOT NULL CONSTRAINT name_length CHECK (length(name) <= 255),
+   product_details_id TEXT REFERENCES product_details(id),
+   product_assets_id TEXT REFERENCES product_assets(id),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS privileges(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   name TEXT UNIQUE NOT NULL,
+   description TEXT NOT NULL,
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+INSERT INTO privileges (name, description) VALUES ('Owner', 'The user owns the product, has the right to change or delete it. If there are multiple owners delete/change can happen if all the owners aggree.');
+INSERT INTO privileges (name, description) VALUES ('User', 'The user acquired the product. They can create projects based on the product, but cannot modify or delete the product itself.');
+INSERT INTO privileges (name, description) VALUES ('Partner', 'The user can change the product, but requires the owners approval.');
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users_products(
+   products_id TEXT REFERENCES products(id),
+   users_id TEXT REFERENCES users(id),
+   privileges_id INTEGER,
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS project_details(
+   id TEXT PRIMARY KEY,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS project_assets(
+   id TEXT PRIMARY KEY,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS projects(
+   id TEXT PRIMARY KEY,
+   products_id TEXT NOT NULL REFERENCES products(id),
+   project_details_id TEXT REFERENCES project_details(id),
+   project_assets_id TEXT REFERENCES project_assets(id),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users_projects(
+   projects_id TEXT REFERENCES projects(id),
+   users_id TEXT REFERENCES users(id),
+   privileges_id INTEGER,
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS viewers(
+   id TEXT PRIMARY KEY,
+   owner_id TEXT REFERENCES users(id),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users_viewers(
+   users_id TEXT REFERENCES users(id),
+   viewer_id TEXT REFERENCES viewers(id),
+   projects_id TEXT REFERENCES projects(id),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS users_viewers;
+DROP TABLE IF EXISTS viewers;
+DROP TABLE IF EXISTS users_projects;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS project_assets;
+DROP TABLE IF EXISTS project_details;
+DROP TABLE IF EXISTS users_products;
+DROP TABLE IF EXISTS privileges;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS product_assets;
+DROP TABLE IF EXISTS product_details;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS user_assets;
+DROP TABLE IF EXISTS user_settings;
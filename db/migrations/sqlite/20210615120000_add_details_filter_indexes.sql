@@ -0,0 +1,15 @@
+-- +migrate Up
+-- The details most often used by the filters of /get-products and /get-projects are indexed.
+-- The filters have to use the same json_extract expressions for the indexes to apply.
+CREATE INDEX product_details_is_free ON product_details (json_extract(data, '$.is_free'));
+CREATE INDEX product_details_requires_3d ON product_details (json_extract(data, '$.requires_3d'));
+CREATE INDEX product_details_has_trial ON product_details (json_extract(data, '$.has_trial'));
+CREATE INDEX project_details_name ON project_details (json_extract(data, '$.name'));
+CREATE INDEX project_details_visibility ON project_details (json_extract(data, '$.visibility'));
+
+-- +migrate Down
+DROP INDEX project_details_visibility;
+DROP INDEX project_details_name;
+DROP INDEX product_details_has_trial;
+DROP INDEX product_details_requires_3d;
+DROP INDEX product_details_is_free;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Every update of an asset copies the replaced version here, so it can be listed, compared and restored.
+-- The assets of all types share the table, asset_type is the name of the asset table.
+CREATE TABLE IF NOT EXISTS asset_history(
+   asset_type TEXT NOT NULL,
+   asset_id TEXT NOT NULL,
+   version INTEGER NOT NULL,
+   data TEXT NOT NULL CHECK (json_valid(data)),
+   changed_by TEXT,
+   changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (asset_type, asset_id, version)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS asset_history;
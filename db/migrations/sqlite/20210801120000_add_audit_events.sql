@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Every change made through the controller is recorded here in the transaction of the change.
+-- before_data and after_data are snapshots of the changed entity, actor is NULL for changes not made on behalf of a user.
+CREATE TABLE IF NOT EXISTS audit_events(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   occurred_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   actor TEXT,
+   action TEXT NOT NULL,
+   entity_type TEXT NOT NULL,
+   entity_id TEXT NOT NULL,
+   before_data TEXT CHECK (before_data IS NULL OR json_valid(before_data)),
+   after_data TEXT CHECK (after_data IS NULL OR json_valid(after_data))
+);
+CREATE INDEX IF NOT EXISTS audit_events_entity ON audit_events (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_events_actor ON audit_events (actor);
+CREATE INDEX IF NOT EXISTS audit_events_occurred_at ON audit_events (occurred_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_events;
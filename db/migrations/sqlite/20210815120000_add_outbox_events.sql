@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Domain events written in the transaction of the change, a row is deleted once the event is delivered to every sink.
+-- attempts and last_error describe the failed deliveries of the event.
+CREATE TABLE IF NOT EXISTS outbox_events(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   event_type TEXT NOT NULL,
+   occurred_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   actor TEXT,
+   aggregate_id TEXT NOT NULL,
+   payload TEXT NOT NULL CHECK (json_valid(payload)),
+   attempts INTEGER NOT NULL DEFAULT 0,
+   last_error TEXT
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS outbox_events;
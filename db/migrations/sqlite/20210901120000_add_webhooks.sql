@@ -0,0 +1,31 @@
+-- +migrate Up
+-- Subscriptions of external services to the domain events. event_types is a JSON array, empty for every event.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions(
+   id TEXT PRIMARY KEY,
+   url TEXT NOT NULL CONSTRAINT url_length CHECK (length(url) <= 2048),
+   secret TEXT NOT NULL CONSTRAINT secret_length CHECK (length(secret) <= 255),
+   event_types TEXT NOT NULL CHECK (json_valid(event_types)),
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Delivery log of the subscriptions. An event is queued once per subscription, payload is the delivered event.
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id),
+   event_id INTEGER NOT NULL,
+   event_type TEXT NOT NULL,
+   payload TEXT NOT NULL CHECK (json_valid(payload)),
+   status TEXT NOT NULL,
+   attempts INTEGER NOT NULL DEFAULT 0,
+   next_attempt_at DATETIME NOT NULL,
+   last_attempt_at DATETIME,
+   last_status_code INTEGER NOT NULL DEFAULT 0,
+   last_error TEXT,
+   created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
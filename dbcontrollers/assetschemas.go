@@ -0,0 +1,51 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownAssetType = errors.New("Unknown asset type")
+var ErrAssetSchemaNotFound = errors.New("No schema is registered for the asset type")
+
+// GetAssetSchema returns the JSON schema of assetType. The schemas are loaded from the schema directory on startup,
+// so every instance reading the same directory validates the assets the same way.
+func (c *MYSQLController) GetAssetSchema(assetType string) ([]byte, error) {
+	if !mysqldb.IsAssetType(assetType) {
+		return nil, ErrUnknownAssetType
+	}
+
+	document, ok := c.Schemas.Schema(assetType)
+	if !ok {
+		return nil, ErrAssetSchemaNotFound
+	}
+	return document, nil
+}
+
+// updateAsset stores the asset in a new transaction if it matches the schema of assetType.
+// Only the data set by the caller is validated, the empty assets created with a user, product or project are not.
+func (c *MYSQLController) updateAsset(ctx context.Context, name string, assetType string, asset *models.Asset) error {
+	if err := c.Schemas.Validate(assetType, asset.DataMap); err != nil {
+		return err
+	}
+
+	var version int64
+	err := c.runInTransaction(ctx, name, func(tx *sql.Tx) error {
+		// The update sets the version of the asset it gets, a retried transaction has to start from the original one.
+		updated := *asset
+		if err := c.DBFunctions.UpdateAsset(ctx, assetType, &updated, tx); err != nil {
+			return err
+		}
+		version = updated.Version
+		return c.auditAssetUpdate(ctx, tx, assetType, &updated)
+	})
+	if err != nil {
+		return err
+	}
+	asset.Version = version
+	return nil
+}
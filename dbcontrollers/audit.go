@@ -0,0 +1,122 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+)
+
+// audit records a change in tx, so the event is only kept if the change is committed.
+// The actor is the user set in ctx by models.WithActingUser.
+func (c *MYSQLController) audit(ctx context.Context, tx *sql.Tx, action string, entityType string, entityID uuid.UUID, before models.DataMap, after models.DataMap) error {
+	event := &models.AuditEvent{
+		// The columns store whole seconds.
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+		Actor:      models.ActingUser(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+	}
+	return c.DBFunctions.AddAuditEvent(ctx, event, tx)
+}
+
+// auditAssetUpdate records the update of an asset. The replaced version is read from the asset history.
+func (c *MYSQLController) auditAssetUpdate(ctx context.Context, tx *sql.Tx, assetType string, asset *models.Asset) error {
+	var before models.DataMap
+	revision, err := c.DBFunctions.GetAssetRevision(ctx, assetType, &asset.ID, asset.Version-1, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if revision != nil {
+		before = assetSnapshot(revision.Version, revision.DataMap)
+	}
+	return c.audit(ctx, tx, models.ActionUpdate, assetType, asset.ID, before, assetSnapshot(asset.Version, asset.DataMap))
+}
+
+// The snapshots refer to the related entities by their ID, the passwords of the users are never recorded.
+
+func userSnapshot(user *models.User) models.DataMap {
+	return models.DataMap{
+		"name":        user.Name,
+		"email":       user.Email,
+		"settings_id": user.SettingsID.String(),
+		"assets_id":   user.AssetsID.String(),
+	}
+}
+
+func productSnapshot(product *models.Product) models.DataMap {
+	return models.DataMap{
+		"name":       product.Name,
+		"details_id": product.DetailsID.String(),
+		"assets_id":  product.AssetsID.String(),
+	}
+}
+
+func projectSnapshot(project *models.Project) models.DataMap {
+	return models.DataMap{
+		"product_id": project.ProductID.String(),
+		"details_id": project.DetailsID.String(),
+		"assets_id":  project.AssetsID.String(),
+	}
+}
+
+func assetSnapshot(version int64, data models.DataMap) models.DataMap {
+	return models.DataMap{
+		"version": version,
+		"data":    data,
+	}
+}
+
+// privilegeSnapshot describes the privilege of a user on a product or project, the entity of the event.
+func privilegeSnapshot(userID *uuid.UUID, privilege int) models.DataMap {
+	return models.DataMap{
+		"user_id":   userID.String(),
+		"privilege": privilege,
+	}
+}
+
+func viewerSnapshot(viewer *models.ProjectViewer) models.DataMap {
+	return models.DataMap{
+		"user_id":    viewer.UserID.String(),
+		"project_id": viewer.ProjectID.String(),
+		"is_owner":   viewer.IsOwner,
+	}
+}
+
+func trashSnapshot(deletedAt time.Time) models.DataMap {
+	return models.DataMap{
+		"deleted_at": deletedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// GetAuditEvents lists the audit events matching filter, the latest first. The list is empty if there are none.
+func (c *MYSQLController) GetAuditEvents(ctx context.Context, filter *models.AuditFilter) ([]models.AuditEvent, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	events := make([]models.AuditEvent, 0)
+	err := c.runInTransaction(ctx, "GetAuditEvents", func(tx *sql.Tx) error {
+		found, err := c.DBFunctions.GetAuditEvents(ctx, filter, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+		events = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,289 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/db/migrations"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidBackup = errors.New("The backup is invalid")
+var ErrBackupSchemaMismatch = errors.New("The backup was taken of another schema version")
+var ErrRestoreTargetNotEmpty = errors.New("The DB to restore into is not empty")
+
+// errTableNotEmpty stops the dump of a table at its first row.
+var errTableNotEmpty = errors.New("Table is not empty")
+
+// schemaVersion returns the version of the last migration applied to the DB.
+// The backends without migrations always have the schema of the code.
+func (c *MYSQLController) schemaVersion() (string, error) {
+	connector, ok := c.DBConnector.(mysqldb.MigrationConnector)
+	if !ok {
+		return migrations.LatestVersion(migrations.MySQL)
+	}
+
+	migrator, err := connector.Migrator()
+	if err != nil {
+		return "", err
+	}
+	return migrator.Version()
+}
+
+// Backup writes the rows of mysqldb.BackupTables to w as NDJSON and returns the number of rows written.
+// The tables are read in a single transaction, which is neither retried nor limited by the read timeout,
+// because the dump is streamed while it is read.
+func (c *MYSQLController) Backup(ctx context.Context, w io.Writer) (int64, error) {
+	version, err := c.schemaVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := c.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Nothing is changed, the transaction only provides the snapshot of the tables.
+	defer c.DBConnector.Rollback(tx)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	createdAt := time.Now().UTC()
+	header := models.BackupRecord{
+		Type:          models.BackupHeader,
+		Format:        models.BackupFormat,
+		SchemaVersion: version,
+		CreatedAt:     &createdAt,
+	}
+	if err := encoder.Encode(header); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for i := range mysqldb.BackupTables {
+		table := &mysqldb.BackupTables[i]
+		err := c.DBFunctions.DumpTable(ctx, table, func(r models.BackupRow) error {
+			record := models.BackupRecord{
+				Type:  models.BackupRowRecord,
+				Table: table.Name,
+				Row:   make(map[string]json.RawMessage, len(r)),
+			}
+			for name, value := range r {
+				raw, err := json.Marshal(value)
+				if err != nil {
+					return errors.Wrapf(err, "%s.%s", table.Name, name)
+				}
+				record.Row[name] = raw
+			}
+
+			count++
+			return encoder.Encode(record)
+		}, tx)
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, encoder.Encode(models.BackupRecord{Type: models.BackupEnd, Rows: count})
+}
+
+// Restore loads a dump written by Backup into the DB and returns the number of rows restored.
+// The DB must have the schema version of the dump and must not contain anything but the privileges
+// created by the migrations. The dumped privileges are matched against those by ID and name.
+// The dump is restored in a single transaction, which is committed only if the end record is reached
+// and every reference points to a row restored before it.
+func (c *MYSQLController) Restore(ctx context.Context, r io.Reader) (int64, error) {
+	version, err := c.schemaVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := c.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	restore := &restoreState{
+		controller: c,
+		decoder:    json.NewDecoder(r),
+		keys:       make(map[string]map[interface{}]bool),
+		privileges: make(map[int64]models.BackupRow),
+	}
+	if err := restore.run(ctx, version, tx); err != nil {
+		if errRollback := c.DBConnector.Rollback(tx); errRollback != nil {
+			return 0, fmt.Errorf("Failed to rollback changes: %s: %w", errRollback.Error(), err)
+		}
+		return 0, err
+	}
+	return restore.count, c.DBConnector.Commit(tx)
+}
+
+// restoreState follows a single restore through the records of the dump.
+type restoreState struct {
+	controller *MYSQLController
+	decoder    *json.Decoder
+	line       int
+	count      int64
+	// Keys of the restored rows per table, the references of the later rows are checked against them.
+	keys map[string]map[interface{}]bool
+	// Privileges created by the migrations of the target DB by ID.
+	privileges map[int64]models.BackupRow
+}
+
+// next reads the next record of the dump. io.EOF is returned if the dump ended.
+func (s *restoreState) next() (*models.BackupRecord, error) {
+	record := &models.BackupRecord{}
+	if err := s.decoder.Decode(record); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, errors.Wrapf(ErrInvalidBackup, "record %d: %s", s.line+1, err.Error())
+	}
+	s.line++
+	return record, nil
+}
+
+func (s *restoreState) invalid(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrInvalidBackup, "record %d: %s", s.line, fmt.Sprintf(format, args...))
+}
+
+func (s *restoreState) run(ctx context.Context, version string, tx *sql.Tx) error {
+	header, err := s.next()
+	if err == io.EOF {
+		return errors.Wrap(ErrInvalidBackup, "the backup is empty")
+	}
+	if err != nil {
+		return err
+	}
+
+	if header.Type != models.BackupHeader || header.Format != models.BackupFormat {
+		return s.invalid("the header of format %s is missing", models.BackupFormat)
+	}
+
+	if header.SchemaVersion != version {
+		return errors.Wrapf(ErrBackupSchemaMismatch, "the backup is of %s, the DB is of %s", header.SchemaVersion, version)
+	}
+
+	if err := s.checkTarget(ctx, tx); err != nil {
+		return err
+	}
+
+	for {
+		record, err := s.next()
+		if err == io.EOF {
+			return errors.Wrapf(ErrInvalidBackup, "the end record is missing after %d rows, the backup is truncated", s.count)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch record.Type {
+		case models.BackupRowRecord:
+			if err := s.restoreRow(ctx, record, tx); err != nil {
+				return err
+			}
+		case models.BackupEnd:
+			if record.Rows != s.count {
+				return s.invalid("the backup has %d rows, %d are restored", record.Rows, s.count)
+			}
+
+			if _, err := s.next(); err != io.EOF {
+				return s.invalid("records follow the end record")
+			}
+			return nil
+		default:
+			return s.invalid("unknown record type '%s'", record.Type)
+		}
+	}
+}
+
+// checkTarget fails if any of the backed up tables of the DB has rows, except the privileges.
+// The privileges are kept to be matched against the dumped ones.
+func (s *restoreState) checkTarget(ctx context.Context, tx *sql.Tx) error {
+	for i := range mysqldb.BackupTables {
+		table := &mysqldb.BackupTables[i]
+		err := s.controller.DBFunctions.DumpTable(ctx, table, func(r models.BackupRow) error {
+			if table.Name != "privileges" {
+				return errTableNotEmpty
+			}
+
+			ID := r["id"].(int64)
+			s.privileges[ID] = r
+			s.addKey(table.Name, ID)
+			return nil
+		}, tx)
+		if err == errTableNotEmpty {
+			return errors.WithMessage(ErrRestoreTargetNotEmpty, table.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *restoreState) addKey(tableName string, key interface{}) {
+	if s.keys[tableName] == nil {
+		s.keys[tableName] = make(map[interface{}]bool)
+	}
+	s.keys[tableName][key] = true
+}
+
+// restoreRow validates the row record against the table description and the rows restored so far and inserts it.
+func (s *restoreState) restoreRow(ctx context.Context, record *models.BackupRecord, tx *sql.Tx) error {
+	table, err := mysqldb.GetBackupTable(record.Table)
+	if err != nil {
+		return s.invalid("%s", err.Error())
+	}
+
+	for name := range record.Row {
+		if _, ok := table.Column(name); !ok {
+			return s.invalid("unknown column %s.%s", table.Name, name)
+		}
+	}
+
+	r := make(models.BackupRow, len(table.Columns))
+	for _, column := range table.Columns {
+		value, err := column.Decode(record.Row[column.Name])
+		if err != nil {
+			return s.invalid("%s.%s: %s", table.Name, column.Name, err.Error())
+		}
+		r[column.Name] = value
+
+		if column.References != "" && value != nil && !s.keys[column.References][value] {
+			return s.invalid("%s.%s references %v, which is missing from %s", table.Name, column.Name, value, column.References)
+		}
+	}
+
+	s.count++
+	if table.Key == "" {
+		return s.controller.DBFunctions.RestoreRow(ctx, table, r, tx)
+	}
+
+	key := r[table.Key]
+	if key == nil {
+		return s.invalid("%s.%s is missing", table.Name, table.Key)
+	}
+
+	if table.Name == "privileges" {
+		if existing, ok := s.privileges[key.(int64)]; ok {
+			if existing["name"] != r["name"] {
+				return s.invalid("privilege %d is %v, it is %v in the DB", key, r["name"], existing["name"])
+			}
+			delete(s.privileges, key.(int64))
+			return nil
+		}
+	}
+
+	if s.keys[table.Name][key] {
+		return s.invalid("%s %v is duplicated", table.Name, key)
+	}
+	s.addKey(table.Name, key)
+	return s.controller.DBFunctions.RestoreRow(ctx, table, r, tx)
+}
@@ -0,0 +1,234 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultChangeStreamInterval = time.Second
+	changeBatchSize             = 100
+	defaultChangeGapTimeout     = time.Minute
+	// The largest gap of the audit event IDs waited for to be filled by late events.
+	maxChangeGap = 1000
+)
+
+// LatestChangeID returns the ID of the latest audit event, zero if there is none.
+// A stream started after it only sends the changes made from now on.
+func (c *MYSQLController) LatestChangeID(ctx context.Context) (int64, error) {
+	events, err := c.GetAuditEvents(ctx, &models.AuditFilter{Limit: 1})
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	return events[0].ID, nil
+}
+
+// entityAssets returns the settings, details and assets of the user, product or project ID, nil if there is none.
+func (c *MYSQLController) entityAssets(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) ([]uuid.UUID, error) {
+	user, err := c.DBFunctions.GetUser(ctx, mysqldb.ByID, ID, tx)
+	switch {
+	case err == nil:
+		return []uuid.UUID{user.SettingsID, user.AssetsID}, nil
+	case err != sql.ErrNoRows:
+		return nil, err
+	}
+
+	product, err := c.DBFunctions.GetProductByID(ctx, ID, tx)
+	switch {
+	case err == nil:
+		return []uuid.UUID{product.DetailsID, product.AssetsID}, nil
+	case err != sql.ErrNoRows:
+		return nil, err
+	}
+
+	project, err := c.DBFunctions.GetProjectByID(ctx, ID, tx)
+	switch {
+	case err == nil:
+		return []uuid.UUID{project.DetailsID, project.AssetsID}, nil
+	case err != sql.ErrNoRows:
+		return nil, err
+	}
+	return nil, nil
+}
+
+// resolveChangeFilter sets the assets of the entity and the user selected by filter.
+// The IDs of the entities not existing yet or anymore only match the changes referring to them.
+func (c *MYSQLController) resolveChangeFilter(ctx context.Context, filter *models.ChangeFilter) error {
+	if filter.EntityID == nil && filter.UserID == nil {
+		return nil
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	return c.runInTransaction(ctx, "ResolveChangeFilter", func(tx *sql.Tx) (err error) {
+		if filter.EntityID != nil {
+			if filter.EntityAssets, err = c.entityAssets(ctx, filter.EntityID, tx); err != nil {
+				return err
+			}
+		}
+
+		if filter.UserID != nil {
+			if filter.UserAssets, err = c.entityAssets(ctx, filter.UserID, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ChangeSender receives the changes of a stream, the oldest first, and the ID of the last audit event read.
+// Resuming the stream after lastID skips the changes already received and the events not matching its filter.
+type ChangeSender func(changes []models.AuditEvent, lastID int64) error
+
+// changeCursor is the position of a change stream in the audit log. The events are read in ID order,
+// but the IDs are assigned when the events are recorded, so an event of a long transaction may become visible
+// after a later one was sent. The IDs skipped below the last event read are kept as gaps, and are read again
+// until the event shows up or the gap is older than any transaction can stay open.
+type changeCursor struct {
+	lastID int64
+	// The IDs not seen yet below lastID and the time they were found missing.
+	gaps map[int64]time.Time
+}
+
+// scanFrom returns the ID after which the audit log has to be read, so the late events of the gaps are read again.
+func (cursor *changeCursor) scanFrom() int64 {
+	from := cursor.lastID
+	for ID := range cursor.gaps {
+		if ID-1 < from {
+			from = ID - 1
+		}
+	}
+	return from
+}
+
+// advance records the events read and returns the ones not sent yet: the events after lastID
+// and the late events filling a gap. The gaps between the new events are recorded at now.
+func (cursor *changeCursor) advance(events []models.AuditEvent, now time.Time) []models.AuditEvent {
+	unseen := make([]models.AuditEvent, 0, len(events))
+	for _, event := range events {
+		if event.ID <= cursor.lastID {
+			if _, ok := cursor.gaps[event.ID]; ok {
+				delete(cursor.gaps, event.ID)
+				unseen = append(unseen, event)
+			}
+			continue
+		}
+
+		// A rolled back transaction leaves a gap for good, a gap too large to be concurrent transactions
+		// is not tracked, it comes from an ID jump of the auto increment column.
+		if event.ID-cursor.lastID-1 <= maxChangeGap {
+			for ID := cursor.lastID + 1; ID < event.ID; ID++ {
+				cursor.gaps[ID] = now
+			}
+		}
+		cursor.lastID = event.ID
+		unseen = append(unseen, event)
+	}
+	return unseen
+}
+
+// expireGaps forgets the gaps recorded before deadline, their transactions have been rolled back.
+func (cursor *changeCursor) expireGaps(deadline time.Time) {
+	for ID, missingSince := range cursor.gaps {
+		if missingSince.Before(deadline) {
+			delete(cursor.gaps, ID)
+		}
+	}
+}
+
+// changeGapTimeout returns how long a gap of a change stream is waited for to be filled by a late event.
+func (c *MYSQLController) changeGapTimeout() time.Duration {
+	if c.WriteTimeout <= 0 {
+		return defaultChangeGapTimeout
+	}
+	// The read of the gap may only happen a poll after the transaction committed.
+	return c.WriteTimeout + c.ChangeStreamInterval
+}
+
+// sendChangesAfter calls send with the changes matching filter recorded after the position of cursor, a batch at a time,
+// including the late events filling the gaps of cursor. send is called at least once, with no changes if there are none.
+func (c *MYSQLController) sendChangesAfter(ctx context.Context, filter *models.ChangeFilter, cursor *changeCursor, send ChangeSender) error {
+	cursor.expireGaps(time.Now().Add(-c.changeGapTimeout()))
+	afterID := cursor.scanFrom()
+	for {
+		var events []models.AuditEvent
+		readCtx, cancel := c.readContext(ctx)
+		err := c.runInTransaction(readCtx, "GetAuditEventsAfter", func(tx *sql.Tx) (err error) {
+			events, err = c.DBFunctions.GetAuditEventsAfter(readCtx, afterID, changeBatchSize, tx)
+			return err
+		})
+		cancel()
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return send(nil, cursor.lastID)
+			}
+			return err
+		}
+
+		changes := make([]models.AuditEvent, 0, len(events))
+		for _, event := range cursor.advance(events, time.Now()) {
+			event := event
+			if filter.Matches(&event) {
+				changes = append(changes, event)
+			}
+		}
+
+		afterID = events[len(events)-1].ID
+		if err := send(changes, cursor.lastID); err != nil {
+			return err
+		}
+
+		if len(events) < changeBatchSize {
+			return nil
+		}
+	}
+}
+
+// StreamChanges calls send with the changes of the users, products, projects, their privileges, viewers and assets
+// matching filter and recorded after the audit event afterID, the oldest first. The audit log is polled every
+// ChangeStreamInterval and send is called after every poll, with no changes if there were none, so the caller
+// can keep the connection alive. It returns when ctx is done, the streams are stopped or send fails.
+// An event of a long transaction committed after a later event was sent is sent late, out of ID order. Resuming
+// after lastID only covers the events after it, the late events of the previous stream are not sent again.
+func (c *MYSQLController) StreamChanges(ctx context.Context, filter models.ChangeFilter, afterID int64, send ChangeSender) error {
+	if err := c.resolveChangeFilter(ctx, &filter); err != nil {
+		return err
+	}
+
+	interval := c.ChangeStreamInterval
+	if interval <= 0 {
+		interval = defaultChangeStreamInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	cursor := &changeCursor{lastID: afterID, gaps: make(map[int64]time.Time)}
+	for {
+		if err := c.sendChangesAfter(ctx, &filter, cursor, send); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.changeStreams:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopChangeStreams ends the running and the new change streams, so the server can shut down without waiting for them.
+func (c *MYSQLController) StopChangeStreams() {
+	c.stopChangeStreams.Do(func() {
+		if c.changeStreams != nil {
+			close(c.changeStreams)
+		}
+	})
+}
@@ -1,97 +1,347 @@
 package dbcontrollers
 
 import (
-	"errors"
+	"context"
+	"database/sql"
 	"fmt"
-	"os"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
+	"github.com/artofimagination/mysql-user-db-go-interface/initialization"
+	"github.com/artofimagination/mysql-user-db-go-interface/memorydb"
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/pgdb"
+	"github.com/artofimagination/mysql-user-db-go-interface/sqlitedb"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
+// Names of the supported storage backends.
+const (
+	BackendMySQL    = "mysql"
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// ErrAssetVersionConflict is returned by the asset updates if the asset has been changed since the caller read it.
+var ErrAssetVersionConflict = errors.New("The asset has been modified since it was read, reload it and try again")
+
 type DBControllerCommon interface {
-	CreateProduct(name string, owner *uuid.UUID, generateAssetPath func(assetID *uuid.UUID) (string, error)) (*models.Product, error)
-	DeleteProduct(productID *uuid.UUID) error
-	GetProduct(productID *uuid.UUID) (*models.ProductData, error)
-	UpdateProductDetails(details *models.Asset) error
-	UpdateProductAssets(assets *models.Asset) error
+	CreateProduct(ctx context.Context, name string, owner *uuid.UUID, generateAssetPath func(assetID *uuid.UUID) (string, error)) (*models.Product, error)
+	DeleteProduct(ctx context.Context, productID *uuid.UUID) error
+	GetProduct(ctx context.Context, productID *uuid.UUID) (*models.ProductData, error)
+	UpdateProductDetails(ctx context.Context, details *models.Asset) error
+	UpdateProductAssets(ctx context.Context, assets *models.Asset) error
 
 	CreateUser(
+		ctx context.Context,
 		name string,
 		email string,
 		passwd []byte,
 		generateAssetPath func(assetID *uuid.UUID) string,
 		encryptPassword func(password []byte) ([]byte, error)) (*models.User, error)
-	DeleteUser(ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID) error
-	GetUser(userID *uuid.UUID) (*models.UserData, error)
-	UpdateUserSettings(settings *models.Asset) error
-	UpdateUserAssets(assets *models.Asset) error
-	Authenticate(email string, passwd []byte, authenticate func(string, []byte, *models.User) error) error
+	DeleteUser(ctx context.Context, ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID) error
+	GetUser(ctx context.Context, userID *uuid.UUID) (*models.UserData, error)
+	UpdateUserSettings(ctx context.Context, settings *models.Asset) error
+	UpdateUserAssets(ctx context.Context, assets *models.Asset) error
+	Authenticate(ctx context.Context, email string, passwd []byte, authenticate func(string, []byte, *models.User) error) error
 }
 
 type MYSQLController struct {
+	// Number of re-executed transactions. Accessed atomically, kept first for 64 bit alignment.
+	txRetryCount uint64
+
 	DBFunctions    mysqldb.FunctionsCommon
 	DBConnector    mysqldb.ConnectorCommon
 	ModelFunctions models.ModelFunctionsCommon
+
+	// Deadlines of a single controller operation. Zero means only the caller context applies.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// Number of times a transaction failing on deadlock or lock wait timeout is executed again
+	// and the base delay of the exponential backoff between the attempts.
+	TxMaxRetries int
+	TxRetryDelay time.Duration
+
+	// JSON schemas the assets are validated against before they are stored.
+	Schemas *models.SchemaRegistry
+
+	// Move the deleted users, products and projects to the trash instead of deleting them permanently.
+	// PurgeTrash deletes the entities that have been in the trash longer than TrashRetention.
+	SoftDelete     bool
+	TrashRetention time.Duration
+
+	// Write the domain events to the outbox in the transaction of the change, DispatchOutbox delivers them
+	// to the sinks reading OutboxBatchSize events at once.
+	Outbox          bool
+	OutboxBatchSize int
+
+	// A failed webhook delivery is retried WebhookMaxAttempts times in total, the delay before the retries
+	// starts at WebhookRetryDelay and doubles with every attempt. The requests are made by WebhookClient.
+	WebhookMaxAttempts int
+	WebhookRetryDelay  time.Duration
+	WebhookClient      *http.Client
+
+	// The change streams poll the audit log every ChangeStreamInterval.
+	ChangeStreamInterval time.Duration
+	// Closed by StopChangeStreams, a nil channel never stops the streams.
+	changeStreams     chan struct{}
+	stopChangeStreams sync.Once
 }
 
-func NewDBController() (*MYSQLController, error) {
-	address := os.Getenv("MYSQL_DB_ADDRESS")
-	if address == "" {
-		return nil, errors.New("MYSQL DB address not defined")
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	port := os.Getenv("MYSQL_DB_PORT")
-	if address == "" {
-		return nil, errors.New("MYSQL DB port not defined")
+	return context.WithTimeout(ctx, timeout)
+}
+
+// readContext derives the context of a read only operation.
+func (c *MYSQLController) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.ReadTimeout)
+}
+
+// writeContext derives the context of an operation that modifies the DB.
+func (c *MYSQLController) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.WriteTimeout)
+}
+
+// patchAsset applies patch to the asset of assetType in a single transaction and returns the patched asset.
+// The ID of the asset is resolved by assetID in the same transaction.
+func (c *MYSQLController) patchAsset(
+	ctx context.Context,
+	name string,
+	assetType string,
+	patch *models.AssetPatch,
+	assetID func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error)) (*models.Asset, error) {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	var asset *models.Asset
+	err := c.runInTransaction(ctx, name, func(tx *sql.Tx) error {
+		ID, err := assetID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		asset, err = c.DBFunctions.PatchAsset(ctx, assetType, ID, patch, tx)
+		if err != nil {
+			return err
+		}
+		// The patched asset is validated before the commit, an invalid result is rolled back.
+		if err := c.Schemas.Validate(assetType, asset.DataMap); err != nil {
+			return err
+		}
+		return c.auditAssetUpdate(ctx, tx, assetType, asset)
+	})
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return nil, ErrAssetVersionConflict
+		}
+		return nil, err
 	}
-	username := os.Getenv("MYSQL_DB_USER")
-	if address == "" {
-		return nil, errors.New("MYSQL DB username not defined")
+	return asset, nil
+}
+
+// getAsset reads a single asset of assetType in tx.
+func (c *MYSQLController) getAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) (*models.Asset, error) {
+	assets, err := c.DBFunctions.GetAssets(ctx, assetType, []uuid.UUID{*assetID}, tx)
+	if err != nil {
+		return nil, err
 	}
-	pass := os.Getenv("MYSQL_DB_PASSWORD")
-	if address == "" {
-		return nil, errors.New("MYSQL DB password not defined")
+
+	if len(assets) == 0 {
+		return nil, sql.ErrNoRows
 	}
-	dbName := os.Getenv("MYSQL_DB_NAME")
-	if address == "" {
-		return nil, errors.New("MYSQL DB name not defined")
+	return &assets[0], nil
+}
+
+// indexAssets maps the assets by their ID.
+func indexAssets(assets []models.Asset) map[uuid.UUID]*models.Asset {
+	index := make(map[uuid.UUID]*models.Asset, len(assets))
+	for i := range assets {
+		index[assets[i].ID] = &assets[i]
 	}
+	return index
+}
 
-	migrationDirectory := os.Getenv("MYSQL_DB_MIGRATION_DIR")
-	if migrationDirectory == "" {
-		return nil, errors.New("MYSQL DB migration folder not defined")
+// newBackend creates the connector and the data functions of the storage backend selected in cfg.
+func newBackend(cfg *initialization.Config, uuidImpl models.UUIDCommon) (mysqldb.ConnectorCommon, mysqldb.FunctionsCommon, error) {
+	switch cfg.DBBackend {
+	case "", BackendMySQL:
+		dbConnection := fmt.Sprintf(
+			"%s:%s@tcp(%s:%d)/%s?parseTime=true",
+			cfg.MySQLDBUser,
+			cfg.MySQLDBPassword,
+			cfg.MySQLDBAddress,
+			cfg.MySQLDBPort,
+			cfg.MySQLDBName)
+
+		dbConnector := &mysqldb.MYSQLConnector{
+			DBConnection:         dbConnection,
+			MigrationDirectory:   cfg.MySQLDBMigrationDirectory,
+			SkipMigration:        !cfg.DBAutoMigrate,
+			MigrationLockTimeout: cfg.MySQLDBMigrationLockTimeout,
+			MaxOpenConns:         cfg.DBMaxOpenConns,
+			MaxIdleConns:         cfg.DBMaxIdleConns,
+			ConnMaxLifetime:      cfg.DBConnMaxLifetime,
+		}
+		return dbConnector, &mysqldb.MYSQLFunctions{DBConnector: dbConnector, UUIDImpl: uuidImpl}, nil
+	case BackendPostgres:
+		dbConnection := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.PostgresDBUser, cfg.PostgresDBPassword),
+			Host:     fmt.Sprintf("%s:%d", cfg.PostgresDBAddress, cfg.PostgresDBPort),
+			Path:     cfg.PostgresDBName,
+			RawQuery: url.Values{"sslmode": []string{cfg.PostgresDBSSLMode}}.Encode(),
+		}
+
+		dbConnector := &pgdb.PGConnector{
+			DBConnection:       dbConnection.String(),
+			MigrationDirectory: cfg.PostgresDBMigrationDirectory,
+			SkipMigration:      !cfg.DBAutoMigrate,
+			MaxOpenConns:       cfg.DBMaxOpenConns,
+			MaxIdleConns:       cfg.DBMaxIdleConns,
+			ConnMaxLifetime:    cfg.DBConnMaxLifetime,
+		}
+		return dbConnector, &pgdb.PGFunctions{DBConnector: dbConnector, UUIDImpl: uuidImpl}, nil
+	case BackendSQLite:
+		dbConnector := &sqlitedb.SQLiteConnector{
+			DBConnection:       cfg.SQLiteDBPath,
+			MigrationDirectory: cfg.SQLiteDBMigrationDirectory,
+			SkipMigration:      !cfg.DBAutoMigrate,
+			MaxOpenConns:       cfg.DBMaxOpenConns,
+			MaxIdleConns:       cfg.DBMaxIdleConns,
+			ConnMaxLifetime:    cfg.DBConnMaxLifetime,
+			BusyTimeout:        cfg.SQLiteDBBusyTimeout,
+		}
+		return dbConnector, &sqlitedb.SQLiteFunctions{DBConnector: dbConnector, UUIDImpl: uuidImpl}, nil
+	case BackendMemory:
+		dbConnector := &memorydb.MemoryConnector{}
+		return dbConnector, &memorydb.MemoryFunctions{DBConnector: dbConnector, UUIDImpl: uuidImpl}, nil
+	default:
+		return nil, nil, fmt.Errorf("Unknown DB backend %s", cfg.DBBackend)
 	}
+}
 
-	dbConnection := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		username,
-		pass,
-		address,
-		port,
-		dbName)
+// NewMigrator returns the migration manager of the storage backend selected in cfg and its connector.
+// The connector must be closed by the caller.
+func NewMigrator(cfg *initialization.Config) (*mysqldb.Migrator, mysqldb.ConnectorCommon, error) {
+	dbConnector, _, err := newBackend(cfg, &models.RepoUUID{})
+	if err != nil {
+		return nil, nil, err
+	}
 
-	dbConnector := &mysqldb.MYSQLConnector{
-		DBConnection:       dbConnection,
-		MigrationDirectory: migrationDirectory,
+	migrationConnector, ok := dbConnector.(mysqldb.MigrationConnector)
+	if !ok {
+		return nil, nil, fmt.Errorf("DB backend %s has no migrations", cfg.DBBackend)
+	}
+
+	migrator, err := migrationConnector.Migrator()
+	if err != nil {
+		if errClose := dbConnector.Close(); errClose != nil {
+			return nil, nil, fmt.Errorf("%s\n%s", err.Error(), errClose.Error())
+		}
+		return nil, nil, err
+	}
+	return migrator, dbConnector, nil
+}
+
+func NewDBController(cfg *initialization.Config) (*MYSQLController, error) {
+	schemas := models.NewSchemaRegistry()
+	if cfg.AssetSchemaDirectory != "" {
+		if err := schemas.LoadDirectory(cfg.AssetSchemaDirectory); err != nil {
+			return nil, err
+		}
+		for _, assetType := range schemas.AssetTypes() {
+			if !mysqldb.IsAssetType(assetType) {
+				return nil, fmt.Errorf("%w: schema file of '%s'", ErrUnknownAssetType, assetType)
+			}
+		}
 	}
 
 	uuidImpl := &models.RepoUUID{}
+	dbConnector, dbFunctions, err := newBackend(cfg, uuidImpl)
+	if err != nil {
+		return nil, err
+	}
 
 	controller := &MYSQLController{
-		DBFunctions: &mysqldb.MYSQLFunctions{
-			DBConnector: dbConnector,
-			UUIDImpl:    uuidImpl,
-		},
+		DBFunctions: dbFunctions,
 		DBConnector: dbConnector,
 		ModelFunctions: &models.RepoFunctions{
 			UUIDImpl: uuidImpl,
 		},
+		ReadTimeout:          cfg.DBReadTimeout,
+		WriteTimeout:         cfg.DBWriteTimeout,
+		TxMaxRetries:         cfg.DBTxMaxRetries,
+		TxRetryDelay:         cfg.DBTxRetryDelay,
+		Schemas:              schemas,
+		SoftDelete:           cfg.SoftDelete,
+		TrashRetention:       cfg.TrashRetention,
+		Outbox:               cfg.OutboxEnabled(),
+		OutboxBatchSize:      cfg.OutboxBatchSize,
+		WebhookMaxAttempts:   cfg.WebhookMaxAttempts,
+		WebhookRetryDelay:    cfg.WebhookRetryDelay,
+		WebhookClient:        &http.Client{Timeout: cfg.WebhookTimeout},
+		ChangeStreamInterval: cfg.ChangeStreamInterval,
+		changeStreams:        make(chan struct{}),
 	}
 
 	if err := controller.DBConnector.BootstrapSystem(); err != nil {
+		if errClose := controller.DBConnector.Close(); errClose != nil {
+			return nil, fmt.Errorf("%s\n%s", err.Error(), errClose.Error())
+		}
+		return nil, err
+	}
+
+	if err := checkSchema(controller.DBConnector, cfg.DBSchemaCheck); err != nil {
+		if errClose := controller.DBConnector.Close(); errClose != nil {
+			return nil, fmt.Errorf("%s\n%s", err.Error(), errClose.Error())
+		}
 		return nil, err
 	}
 
 	return controller, nil
 }
+
+// Modes of the schema check executed on startup.
+const (
+	SchemaCheckStrict = "strict"
+	SchemaCheckWarn   = "warn"
+	SchemaCheckOff    = "off"
+)
+
+// checkSchema logs the differences of the live schema from the one expected by the code.
+// In strict mode the tables, columns and indexes have to match, otherwise mysqldb.ErrSchemaDrift is returned.
+// Query constants referencing missing columns are only logged, they fail once they are executed.
+func checkSchema(dbConnector mysqldb.ConnectorCommon, mode string) error {
+	checker, ok := dbConnector.(mysqldb.SchemaChecker)
+	if !ok || mode == SchemaCheckOff {
+		return nil
+	}
+
+	drifts, err := checker.CheckSchema(context.Background())
+	if err != nil {
+		return err
+	}
+
+	schemaDrift := 0
+	for _, drift := range drifts {
+		log.Printf("WARNING: schema drift: %s\n", drift)
+		if drift.Query == "" {
+			schemaDrift++
+		}
+	}
+
+	if schemaDrift > 0 && mode == SchemaCheckStrict {
+		return errors.Wrapf(mysqldb.ErrSchemaDrift, "%d differences found", schemaDrift)
+	}
+	return nil
+}
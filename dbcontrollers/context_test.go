@@ -0,0 +1,103 @@
+package dbcontrollers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/initialization"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+type schemaCheckerMock struct {
+	mysqldb.DBConnectorMock
+	drifts []mysqldb.SchemaDrift
+}
+
+func (c *schemaCheckerMock) CheckSchema(ctx context.Context) ([]mysqldb.SchemaDrift, error) {
+	return c.drifts, nil
+}
+
+func TestCheckSchema(t *testing.T) {
+	queryDrift := mysqldb.SchemaDrift{Table: "users", Column: "email", Query: "GetUserByEmailQuery", Message: "column is missing"}
+	tableDrift := mysqldb.SchemaDrift{Table: "viewers", Message: "table is missing"}
+
+	connector := &schemaCheckerMock{}
+	tests.CheckResult(nil, nil, checkSchema(connector, SchemaCheckStrict), nil, "no_drift", t)
+
+	// Queries are only reported, they do not prevent the start.
+	connector.drifts = []mysqldb.SchemaDrift{queryDrift}
+	tests.CheckResult(nil, nil, checkSchema(connector, SchemaCheckStrict), nil, "query_drift", t)
+
+	connector.drifts = []mysqldb.SchemaDrift{queryDrift, tableDrift}
+	err := checkSchema(connector, SchemaCheckStrict)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrSchemaDrift), true, nil, nil, "strict", t)
+	tests.CheckResult(nil, nil, checkSchema(connector, SchemaCheckWarn), nil, "warn", t)
+	tests.CheckResult(nil, nil, checkSchema(connector, SchemaCheckOff), nil, "off", t)
+
+	// Backends without the check are accepted in every mode.
+	tests.CheckResult(nil, nil, checkSchema(&mysqldb.DBConnectorMock{}, SchemaCheckStrict), nil, "not_supported", t)
+}
+
+func TestSingleConnectionReads(t *testing.T) {
+	uuidImpl := &models.RepoUUID{}
+	cfg := &initialization.Config{
+		DBBackend:      BackendSQLite,
+		SQLiteDBPath:   filepath.Join(t.TempDir(), "test.db"),
+		DBAutoMigrate:  true,
+		DBMaxOpenConns: 1,
+		DBMaxIdleConns: 1,
+	}
+	dbConnector, dbFunctions, err := newBackend(cfg, uuidImpl)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite backend: %s", err)
+	}
+
+	controller := &MYSQLController{
+		DBFunctions:    dbFunctions,
+		DBConnector:    dbConnector,
+		ModelFunctions: &models.RepoFunctions{UUIDImpl: uuidImpl},
+		Schemas:        models.NewSchemaRegistry(),
+	}
+	if err := controller.DBConnector.BootstrapSystem(); err != nil {
+		t.Fatalf("Failed to bootstrap SQLite backend: %s", err)
+	}
+	t.Cleanup(func() {
+		// The SQLite driver interrupts the connection from a goroutine when the context of a finished statement
+		// is cancelled, closing the connection before that goroutine ran crashes the driver.
+		time.Sleep(100 * time.Millisecond)
+		if err := controller.DBConnector.Close(); err != nil {
+			t.Errorf("Failed to close SQLite backend: %s", err)
+		}
+	})
+
+	// With a single pooled connection every read has to stay in the transaction it started,
+	// a second transaction would wait for the first one until the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	project, err := controller.CreateProject(ctx, "testProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+
+	_, err = controller.GetUser(ctx, &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "get_user", t)
+	_, err = controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "get_product", t)
+	_, err = controller.GetProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, nil, "get_project", t)
+
+	users, err := controller.GetUsersByProductID(ctx, &product.ID)
+	tests.CheckResult(len(users), 1, err, nil, "get_users_by_product_id", t)
+	products, err := controller.GetProductsByUserID(ctx, &owner.ID)
+	tests.CheckResult(len(products), 1, err, nil, "get_products_by_user_id", t)
+	projects, err := controller.GetProjectsByUserID(ctx, &owner.ID)
+	tests.CheckResult(len(projects), 1, err, nil, "get_projects_by_user_id", t)
+}
@@ -0,0 +1,166 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+// ExportUser collects everything stored about the user in a single transaction, except the password hash.
+// The memberships of entities in the trash are listed, their data is not.
+func (c *MYSQLController) ExportUser(ctx context.Context, userID *uuid.UUID) (*models.UserExport, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	privileges, err := c.DBFunctions.GetPrivileges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var export *models.UserExport
+	err = c.runInTransaction(ctx, "ExportUser", func(tx *sql.Tx) error {
+		user, err := c.DBFunctions.GetUser(ctx, mysqldb.ByID, userID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		settings, err := c.getAsset(ctx, mysqldb.UserSettings, &user.SettingsID, tx)
+		if err != nil {
+			return err
+		}
+
+		assets, err := c.getAsset(ctx, mysqldb.UserAssets, &user.AssetsID, tx)
+		if err != nil {
+			return err
+		}
+
+		export = &models.UserExport{
+			ExportedAt: time.Now().UTC(),
+			User: models.ExportedUser{
+				ID:    user.ID,
+				Name:  user.Name,
+				Email: user.Email,
+			},
+			Settings:      settings,
+			Assets:        assets,
+			OwnedProducts: make([]models.ProductData, 0),
+			OwnedProjects: make([]models.ProjectData, 0),
+			Viewers:       make([]models.ProjectViewer, 0),
+		}
+
+		userProducts, err := c.DBFunctions.GetUserProductIDs(ctx, userID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		var ownedProductIDs []uuid.UUID
+		if userProducts != nil {
+			export.ProductMemberships, ownedProductIDs = exportMemberships(userProducts.ProductMap, privileges)
+		} else {
+			export.ProductMemberships = make([]models.Membership, 0)
+		}
+
+		if len(ownedProductIDs) > 0 {
+			products, err := c.DBFunctions.GetProductsByIDs(ctx, ownedProductIDs, tx)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+
+			if len(products) > 0 {
+				if export.OwnedProducts, err = c.buildProductData(ctx, products, tx); err != nil {
+					return err
+				}
+			}
+		}
+
+		userProjects, err := c.DBFunctions.GetUserProjectIDs(ctx, userID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		var ownedProjectIDs []uuid.UUID
+		if userProjects != nil {
+			export.ProjectMemberships, ownedProjectIDs = exportMemberships(userProjects.ProjectMap, privileges)
+		} else {
+			export.ProjectMemberships = make([]models.Membership, 0)
+		}
+
+		if len(ownedProjectIDs) > 0 {
+			projects, err := c.DBFunctions.GetProjectsByIDs(ctx, ownedProjectIDs, tx)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+
+			if len(projects) > 0 {
+				if export.OwnedProjects, err = c.buildProjectData(ctx, projects, tx); err != nil {
+					return err
+				}
+			}
+		}
+
+		// The listed products and projects are ordered like the memberships.
+		sort.Slice(export.OwnedProducts, func(i, j int) bool {
+			return export.OwnedProducts[i].ID.String() < export.OwnedProducts[j].ID.String()
+		})
+		sort.Slice(export.OwnedProjects, func(i, j int) bool {
+			return export.OwnedProjects[i].ID.String() < export.OwnedProjects[j].ID.String()
+		})
+
+		viewers, err := c.DBFunctions.GetProjectViewersByUserID(ctx, userID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		ownedViewerIDs, err := c.DBFunctions.GetViewerIDsByOwnerID(ctx, userID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		// users_viewers does not store the ownership.
+		owned := make(map[uuid.UUID]bool)
+		for _, viewerID := range ownedViewerIDs {
+			owned[viewerID] = true
+		}
+		for _, viewer := range viewers {
+			viewer.IsOwner = owned[viewer.ViewerID]
+			export.Viewers = append(export.Viewers, viewer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// exportMemberships lists the memberships of privilegeMap ordered by ID and returns the IDs of the owned ones.
+func exportMemberships(privilegeMap map[uuid.UUID]int, privileges models.Privileges) ([]models.Membership, []uuid.UUID) {
+	memberships := make([]models.Membership, 0, len(privilegeMap))
+	for ID, privilege := range privilegeMap {
+		memberships = append(memberships, models.Membership{
+			ID:          ID,
+			PrivilegeID: privilege,
+			Privilege:   privileges.Name(privilege),
+		})
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].ID.String() < memberships[j].ID.String()
+	})
+
+	ownedIDs := make([]uuid.UUID, 0)
+	for _, membership := range memberships {
+		if privileges.IsOwnerPrivilege(membership.PrivilegeID) {
+			ownedIDs = append(ownedIDs, membership.ID)
+		}
+	}
+	return memberships, ownedIDs
+}
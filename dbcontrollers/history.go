@@ -0,0 +1,148 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrAssetNotFound = errors.New("The selected asset not found")
+var ErrAssetVersionNotFound = errors.New("The selected version of the asset not found")
+
+// getAssetVersion returns the asset as it was at version. The current version is read from the asset itself,
+// the earlier ones from its history.
+func (c *MYSQLController) getAssetVersion(ctx context.Context, assetType string, assetID *uuid.UUID, version int64, tx *sql.Tx) (*models.AssetRevision, error) {
+	assets, err := c.DBFunctions.GetAssets(ctx, assetType, []uuid.UUID{*assetID}, tx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAssetNotFound
+		}
+		return nil, err
+	}
+
+	if assets[0].Version == version {
+		return &models.AssetRevision{AssetID: assets[0].ID, Version: version, DataMap: assets[0].DataMap}, nil
+	}
+
+	revision, err := c.DBFunctions.GetAssetRevision(ctx, assetType, assetID, version, tx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAssetVersionNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+// GetAssetHistory lists the replaced versions of the asset, the latest first. The list is empty
+// if the asset has never been updated.
+func (c *MYSQLController) GetAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID) ([]models.AssetRevision, error) {
+	if !mysqldb.IsAssetType(assetType) {
+		return nil, ErrUnknownAssetType
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var revisions []models.AssetRevision
+	err := c.runInTransaction(ctx, "GetAssetHistory", func(tx *sql.Tx) error {
+		if _, err := c.DBFunctions.GetAssets(ctx, assetType, []uuid.UUID{*assetID}, tx); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrAssetNotFound
+			}
+			return err
+		}
+
+		var err error
+		revisions, err = c.DBFunctions.GetAssetHistory(ctx, assetType, assetID, tx)
+		if err == sql.ErrNoRows {
+			revisions = make([]models.AssetRevision, 0)
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetAssetVersion returns the data of the asset at version, which is either the current or a replaced version.
+func (c *MYSQLController) GetAssetVersion(ctx context.Context, assetType string, assetID *uuid.UUID, version int64) (*models.AssetRevision, error) {
+	if !mysqldb.IsAssetType(assetType) {
+		return nil, ErrUnknownAssetType
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var revision *models.AssetRevision
+	err := c.runInTransaction(ctx, "GetAssetVersion", func(tx *sql.Tx) (err error) {
+		revision, err = c.getAssetVersion(ctx, assetType, assetID, version, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// DiffAssetVersions returns the changes of the asset data from version from to version to.
+func (c *MYSQLController) DiffAssetVersions(ctx context.Context, assetType string, assetID *uuid.UUID, from int64, to int64) ([]models.DataChange, error) {
+	if !mysqldb.IsAssetType(assetType) {
+		return nil, ErrUnknownAssetType
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var changes []models.DataChange
+	err := c.runInTransaction(ctx, "DiffAssetVersions", func(tx *sql.Tx) error {
+		fromRevision, err := c.getAssetVersion(ctx, assetType, assetID, from, tx)
+		if err != nil {
+			return err
+		}
+
+		toRevision, err := c.getAssetVersion(ctx, assetType, assetID, to, tx)
+		if err != nil {
+			return err
+		}
+
+		changes = models.DiffDataMaps(fromRevision.DataMap, toRevision.DataMap)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// RestoreAssetVersion overwrites the asset with the data of a replaced version and returns the updated asset.
+// The restore is an update itself, so the overwritten data is kept in the history and can be restored again.
+// If expectedVersion is set, ErrAssetVersionConflict is returned when the asset has been changed since.
+func (c *MYSQLController) RestoreAssetVersion(ctx context.Context, assetType string, assetID *uuid.UUID, version int64, expectedVersion int64) (*models.Asset, error) {
+	revision, err := c.GetAssetVersion(ctx, assetType, assetID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	asset := &models.Asset{ID: *assetID, DataMap: revision.DataMap, Version: expectedVersion}
+	err = c.updateAsset(ctx, "RestoreAssetVersion", assetType, asset)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
+			return nil, ErrAssetNotFound
+		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return nil, ErrAssetVersionConflict
+		}
+		return nil, err
+	}
+	return asset, nil
+}
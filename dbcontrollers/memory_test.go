@@ -0,0 +1,1056 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/initialization"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/outbox"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+// createMemoryController returns a controller working on the in-memory backend,
+// so the relational behaviour of the controller is tested without a DB server.
+func createMemoryController(t *testing.T) *MYSQLController {
+	uuidImpl := &models.RepoUUID{}
+	dbConnector, dbFunctions, err := newBackend(&initialization.Config{DBBackend: BackendMemory}, uuidImpl)
+	if err != nil {
+		t.Fatalf("Failed to create memory backend: %s", err)
+	}
+
+	controller := &MYSQLController{
+		DBFunctions:    dbFunctions,
+		DBConnector:    dbConnector,
+		ModelFunctions: &models.RepoFunctions{UUIDImpl: uuidImpl},
+		TxMaxRetries:   10,
+		Schemas:        models.NewSchemaRegistry(),
+	}
+	if err := controller.DBConnector.BootstrapSystem(); err != nil {
+		t.Fatalf("Failed to bootstrap memory backend: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := controller.DBConnector.Close(); err != nil {
+			t.Errorf("Failed to close memory backend: %s", err)
+		}
+	})
+	return controller
+}
+
+func TestMemoryBackendProductLifecycle(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+
+	_, err = controller.CreateUser(ctx, "otherName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, ErrDuplicateEmailEntry, "duplicate_email", t)
+
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+
+	// The message names the product like the former error, the REST clients depend on it.
+	_, err = controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(errors.Is(err, ErrProductExists), true, err.Error(), "Product with name testProduct already exists", "duplicate_product", t)
+
+	output, err := controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(output, product, err, nil, "get_product", t)
+
+	// Two clients read the same version, the update of the second one has to fail.
+	first, err := controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "get_product_first_client", t)
+	second, err := controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "get_product_second_client", t)
+
+	first.Details.DataMap["description"] = "first"
+	err = controller.UpdateProductDetails(ctx, first)
+	tests.CheckResult(first.Details.Version, int64(2), err, nil, "update_details_first_client", t)
+
+	second.Details.DataMap["description"] = "second"
+	err = controller.UpdateProductDetails(ctx, second)
+	tests.CheckResult(nil, nil, err, ErrAssetVersionConflict, "update_details_second_client", t)
+
+	output, err = controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(output, first, err, nil, "get_updated_product", t)
+
+	// Patches change single keys and are checked against the version like updates.
+	patch, err := models.NewAssetPatch(models.MergePatchType, []byte(`{"description": "patched"}`), 2)
+	tests.CheckResult(nil, nil, err, nil, "new_patch", t)
+	details, err := controller.PatchProductDetails(ctx, &product.ID, patch)
+	tests.CheckResult(details.DataMap["description"], "patched", details.Version, int64(3), "patch_details", t)
+
+	_, err = controller.PatchProductDetails(ctx, &product.ID, patch)
+	tests.CheckResult(nil, nil, err, ErrAssetVersionConflict, "patch_details_stale_version", t)
+
+	_, err = controller.PatchProductDetails(ctx, &owner.ID, patch)
+	tests.CheckResult(nil, nil, err, ErrProductNotFound, "patch_missing_product", t)
+
+	// Only the products with matching details are listed.
+	other, err := controller.CreateProduct(ctx, "otherProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_other_product", t)
+
+	patch, err = models.NewAssetPatch(models.MergePatchType, []byte(`{"is_free": true}`), 0)
+	tests.CheckResult(nil, nil, err, nil, "new_filter_patch", t)
+	_, err = controller.PatchProductDetails(ctx, &other.ID, patch)
+	tests.CheckResult(nil, nil, err, nil, "patch_other_details", t)
+
+	filtered, err := controller.GetProductsByDetails(ctx, models.DetailsFilter{"is_free": true})
+	tests.CheckResult(len(filtered), 1, err, nil, "filter_products", t)
+	tests.CheckResult(filtered[0].ID, other.ID, nil, nil, "filtered_product", t)
+
+	filtered, err = controller.GetProductsByDetails(ctx, models.DetailsFilter{"is_free": "true"})
+	tests.CheckResult(filtered, []models.ProductData{}, err, nil, "filter_no_products", t)
+
+	err = controller.DeleteProduct(ctx, &other.ID)
+	tests.CheckResult(nil, nil, err, nil, "delete_other_product", t)
+
+	// Deleting the product must not leave its users behind.
+	err = controller.DeleteProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "delete_product", t)
+
+	_, err = controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, ErrProductNotFound, "deleted_product", t)
+
+	var userProducts *models.UserProductIDs
+	err = controller.runInTransaction(ctx, "GetUserProductIDs", func(tx *sql.Tx) (err error) {
+		userProducts, err = controller.DBFunctions.GetUserProductIDs(ctx, &owner.ID, tx)
+		return err
+	})
+	tests.CheckResult(len(userProducts.ProductIDArray), 0, err, nil, "no_product_users_left", t)
+
+	err = controller.DeleteUser(ctx, &owner.ID, nil)
+	tests.CheckResult(nil, nil, err, nil, "delete_owner", t)
+
+	_, err = controller.GetUser(ctx, &owner.ID)
+	tests.CheckResult(nil, nil, err, ErrUserNotFound, "deleted_owner", t)
+}
+
+func TestMemoryBackendFailedProductTransfer(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+
+	project, err := controller.CreateProject(ctx, "testProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+
+	// The nominees are not members, so the transfers fail and nothing is deleted.
+	nominees := map[uuid.UUID]uuid.UUID{product.ID: uuid.New(), project.ID: uuid.New()}
+	err = controller.DeleteUser(ctx, &owner.ID, nominees)
+	expected := &OwnershipTransferError{ProductIDs: []uuid.UUID{product.ID}, ProjectIDs: []uuid.UUID{project.ID}}
+	tests.CheckResult(nil, nil, err, expected, "failed_transfer", t)
+	if !errors.Is(err, ErrOwnershipTransferFailed) {
+		t.Errorf("failed_transfer: expected ErrOwnershipTransferFailed, got %v", err)
+	}
+
+	_, err = controller.GetUser(ctx, &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "owner_kept", t)
+
+	products, err := controller.GetProductsByUserID(ctx, &owner.ID)
+	tests.CheckResult(len(products), 1, err, nil, "product_kept", t)
+
+	_, err = controller.GetProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, nil, "project_kept", t)
+}
+
+func TestMemoryBackendConcurrentCreateUser(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	// Only one of the concurrent requests may create the user, the others see the duplicate.
+	const requests = 8
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := controller.CreateUser(ctx, "testName", fmt.Sprintf("test%d@test.com", i), []byte("testPass"))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch err {
+		case nil:
+			created++
+		case ErrDuplicateNameEntry:
+		default:
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+	tests.CheckResult(created, 1, nil, nil, "single_user_created", t)
+}
+
+func TestMemoryBackendAssetSchema(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	_, err := controller.GetAssetSchema("unknown")
+	tests.CheckResult(nil, nil, err, ErrUnknownAssetType, "unknown_asset_type", t)
+
+	_, err = controller.GetAssetSchema(mysqldb.ProductDetails)
+	tests.CheckResult(nil, nil, err, ErrAssetSchemaNotFound, "missing_schema", t)
+
+	schema := []byte(`{"properties": {"price": {"type": "number", "minimum": 0}}}`)
+	err = controller.Schemas.Register(mysqldb.ProductDetails, schema)
+	tests.CheckResult(nil, nil, err, nil, "register_schema", t)
+
+	document, err := controller.GetAssetSchema(mysqldb.ProductDetails)
+	tests.CheckResult(document, schema, err, nil, "get_schema", t)
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+
+	product.Details.DataMap["price"] = -1
+	err = controller.UpdateProductDetails(ctx, product)
+	if !errors.Is(err, models.ErrSchemaValidation) {
+		t.Errorf("invalid update: expected ErrSchemaValidation, got %v", err)
+	}
+
+	// An invalid patch result is rolled back.
+	patch, err := models.NewAssetPatch(models.MergePatchType, []byte(`{"price": "free"}`), 0)
+	tests.CheckResult(nil, nil, err, nil, "new_patch", t)
+	_, err = controller.PatchProductDetails(ctx, &product.ID, patch)
+	if !errors.Is(err, models.ErrSchemaValidation) {
+		t.Errorf("invalid patch: expected ErrSchemaValidation, got %v", err)
+	}
+
+	output, err := controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(output.Details.Version, int64(1), err, nil, "unchanged_details", t)
+
+}
+
+// The empty assets created with the user are not validated, a schema with required properties applies to the updates.
+func TestMemoryBackendAssetSchemaRequired(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	schema := []byte(`{"required": ["theme"], "properties": {"theme": {"enum": ["dark", "light"]}}}`)
+	err := controller.Schemas.Register(mysqldb.UserSettings, schema)
+	tests.CheckResult(nil, nil, err, nil, "register_schema", t)
+
+	user, err := controller.CreateUser(ctx, "testName", "test@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_user", t)
+
+	err = controller.UpdateUserSettings(ctx, user)
+	if !errors.Is(err, models.ErrSchemaValidation) {
+		t.Errorf("missing required: expected ErrSchemaValidation, got %v", err)
+	}
+
+	user.Settings.DataMap["theme"] = "dark"
+	err = controller.UpdateUserSettings(ctx, user)
+	tests.CheckResult(nil, nil, err, nil, "update_required", t)
+}
+
+func TestMemoryBackendAssetHistory(t *testing.T) {
+	controller := createMemoryController(t)
+	owner, err := controller.CreateUser(context.Background(), "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	ctx := models.WithActingUser(context.Background(), owner.ID)
+
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	project, err := controller.CreateProject(ctx, "testProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+	assetID := &project.Assets.ID
+
+	_, err = controller.GetAssetHistory(ctx, "unknown", assetID)
+	tests.CheckResult(nil, nil, err, ErrUnknownAssetType, "unknown_asset_type", t)
+
+	revisions, err := controller.GetAssetHistory(ctx, mysqldb.ProjectAssets, assetID)
+	tests.CheckResult(revisions, []models.AssetRevision{}, err, nil, "empty_history", t)
+
+	project.Assets.DataMap = models.DataMap{"color": "red"}
+	err = controller.UpdateProjectAssets(ctx, project)
+	tests.CheckResult(nil, nil, err, nil, "update_red", t)
+	project.Assets.DataMap = models.DataMap{"color": "blue", "size": float64(2)}
+	err = controller.UpdateProjectAssets(ctx, project)
+	tests.CheckResult(nil, nil, err, nil, "update_blue", t)
+
+	revisions, err = controller.GetAssetHistory(ctx, mysqldb.ProjectAssets, assetID)
+	tests.CheckResult(len(revisions), 2, err, nil, "history", t)
+	tests.CheckResult(revisions[0].Version, int64(2), revisions[0].ChangedBy, &owner.ID, "latest_revision", t)
+
+	changes, err := controller.DiffAssetVersions(ctx, mysqldb.ProjectAssets, assetID, 2, 3)
+	expected := []models.DataChange{
+		{Path: "/color", Kind: models.ChangeChanged, From: "red", To: "blue"},
+		{Path: "/size", Kind: models.ChangeAdded, To: float64(2)},
+	}
+	tests.CheckResult(changes, expected, err, nil, "diff", t)
+
+	_, err = controller.DiffAssetVersions(ctx, mysqldb.ProjectAssets, assetID, 2, 4)
+	tests.CheckResult(nil, nil, err, ErrAssetVersionNotFound, "diff_missing_version", t)
+
+	// The restore is checked against the current version like any other update.
+	_, err = controller.RestoreAssetVersion(ctx, mysqldb.ProjectAssets, assetID, 2, 2)
+	tests.CheckResult(nil, nil, err, ErrAssetVersionConflict, "restore_stale_version", t)
+
+	restored, err := controller.RestoreAssetVersion(ctx, mysqldb.ProjectAssets, assetID, 2, 3)
+	tests.CheckResult(restored.DataMap, models.DataMap{"color": "red"}, restored.Version, int64(4), "restore", t)
+
+	output, err := controller.GetProject(ctx, &project.ID)
+	tests.CheckResult(output.Assets, restored, err, nil, "restored_project", t)
+
+	// The overwritten version is kept, so the restore can be undone.
+	revision, err := controller.GetAssetVersion(ctx, mysqldb.ProjectAssets, assetID, 3)
+	tests.CheckResult(revision.DataMap, models.DataMap{"color": "blue", "size": float64(2)}, err, nil, "restored_over_version", t)
+}
+
+func TestMemoryBackendTrash(t *testing.T) {
+	controller := createMemoryController(t)
+	controller.SoftDelete = true
+	controller.TrashRetention = time.Hour
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	project, err := controller.CreateProject(ctx, "testProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+
+	_, err = controller.GetTrash(ctx, "user_assets")
+	tests.CheckResult(nil, nil, err, ErrUnknownTrashType, "unknown_trash_type", t)
+
+	err = controller.DeleteProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, nil, "trash_project", t)
+	_, err = controller.GetProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, ErrProjectNotFound, "trashed_project", t)
+	err = controller.DeleteProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, ErrProjectNotFound, "trash_project_twice", t)
+
+	entries, err := controller.GetTrash(ctx, mysqldb.Projects)
+	tests.CheckResult(len(entries), 1, err, nil, "project_trash", t)
+	tests.CheckResult(entries[0].ID, project.ID, nil, nil, "project_trash_entry", t)
+
+	err = controller.RestoreFromTrash(ctx, mysqldb.Projects, &project.ID)
+	tests.CheckResult(nil, nil, err, nil, "restore_project", t)
+	_, err = controller.GetProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, nil, "restored_project", t)
+	err = controller.RestoreFromTrash(ctx, mysqldb.Projects, &project.ID)
+	tests.CheckResult(nil, nil, err, ErrNotInTrash, "restore_project_twice", t)
+
+	// The owned products and their projects go to the trash with the user and come back with it.
+	err = controller.DeleteUser(ctx, &owner.ID, nil)
+	tests.CheckResult(nil, nil, err, nil, "trash_owner", t)
+	_, err = controller.GetUser(ctx, &owner.ID)
+	tests.CheckResult(nil, nil, err, ErrUserNotFound, "trashed_owner", t)
+	_, err = controller.GetProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, ErrProductNotFound, "trashed_owned_product", t)
+
+	entries, err = controller.GetTrash(ctx, "")
+	tests.CheckResult(len(entries), 3, err, nil, "owner_trash", t)
+
+	err = controller.RestoreFromTrash(ctx, mysqldb.Projects, &project.ID)
+	tests.CheckResult(nil, nil, err, ErrProductInTrash, "restore_project_of_trashed_product", t)
+
+	err = controller.RestoreFromTrash(ctx, mysqldb.Users, &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "restore_owner", t)
+	_, err = controller.GetProject(ctx, &project.ID)
+	tests.CheckResult(nil, nil, err, nil, "restored_owned_project", t)
+
+	// The relations of the trashed product are kept, the lists skip it.
+	err = controller.DeleteProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "trash_product", t)
+	products, err := controller.GetProductsByUserID(ctx, &owner.ID)
+	tests.CheckResult(products, []models.UserProduct{}, err, nil, "products_without_trashed", t)
+
+	purged, err := controller.PurgeTrash(ctx)
+	tests.CheckResult(purged, 0, err, nil, "purge_within_retention", t)
+
+	controller.TrashRetention = 0
+	purged, err = controller.PurgeTrash(ctx)
+	tests.CheckResult(purged, 2, err, nil, "purge", t)
+
+	entries, err = controller.GetTrash(ctx, "")
+	tests.CheckResult(entries, []models.TrashEntry{}, err, nil, "purged_trash", t)
+	err = controller.RestoreFromTrash(ctx, mysqldb.Products, &product.ID)
+	tests.CheckResult(nil, nil, err, ErrNotInTrash, "restore_purged_product", t)
+}
+
+func TestMemoryBackendAudit(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+
+	ctx = models.WithActingUser(ctx, owner.ID)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	_, err = controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, &productExistsError{name: "testProduct"}, "create_duplicate_product", t)
+
+	product.Details.DataMap = models.DataMap{"price": float64(10)}
+	err = controller.UpdateProductDetails(ctx, product)
+	tests.CheckResult(nil, nil, err, nil, "update_details", t)
+
+	err = controller.DeleteProduct(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "delete_product", t)
+
+	// The failed create is rolled back with its events.
+	events, err := controller.GetAuditEvents(ctx, &models.AuditFilter{Actor: &owner.ID})
+	actions := make([]string, 0)
+	for _, event := range events {
+		actions = append(actions, event.Action+" "+event.EntityType)
+	}
+	expected := []string{
+		"delete " + mysqldb.Products,
+		"update " + mysqldb.ProductDetails,
+		"create " + mysqldb.ProductUsers,
+		"create " + mysqldb.Products,
+	}
+	tests.CheckResult(actions, expected, err, nil, "actor_events", t)
+
+	tests.CheckResult(events[0].Before["name"], "testProduct", nil, nil, "delete_before", t)
+	tests.CheckResult(events[0].After == nil, true, nil, nil, "delete_after", t)
+	tests.CheckResult(events[1].Before["version"], float64(1), nil, nil, "update_before", t)
+	tests.CheckResult(events[1].After["data"], map[string]interface{}{"price": float64(10)}, nil, nil, "update_after", t)
+
+	events, err = controller.GetAuditEvents(ctx, &models.AuditFilter{EntityType: mysqldb.Users, EntityID: &owner.ID})
+	tests.CheckResult(len(events), 1, err, nil, "user_events", t)
+	tests.CheckResult(events[0].Actor == nil, true, nil, nil, "user_without_actor", t)
+	_, hasPassword := events[0].After["password"]
+	tests.CheckResult(hasPassword, false, nil, nil, "user_without_password", t)
+
+	events, err = controller.GetAuditEvents(ctx, &models.AuditFilter{Limit: 2})
+	tests.CheckResult(len(events), 2, err, nil, "first_page", t)
+	events, err = controller.GetAuditEvents(ctx, &models.AuditFilter{Limit: 2, BeforeID: events[1].ID})
+	tests.CheckResult(len(events), 2, err, nil, "second_page", t)
+	events, err = controller.GetAuditEvents(ctx, &models.AuditFilter{Limit: 2, BeforeID: events[1].ID})
+	tests.CheckResult(len(events), 1, err, nil, "last_page", t)
+
+	_, err = controller.GetAuditEvents(ctx, &models.AuditFilter{Action: "rename"})
+	tests.CheckResult(errors.Is(err, models.ErrInvalidAuditFilter), true, nil, nil, "invalid_filter", t)
+}
+
+// recordingSink keeps the delivered events, every delivery fails with err if it is set.
+type recordingSink struct {
+	events []models.DomainEvent
+	err    error
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Deliver(ctx context.Context, event *models.DomainEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, *event)
+	return nil
+}
+
+func TestMemoryBackendOutbox(t *testing.T) {
+	controller := createMemoryController(t)
+	controller.Outbox = true
+	controller.OutboxBatchSize = 2
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	nominee, err := controller.CreateUser(ctx, "nomineeName", "nominee@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_nominee", t)
+
+	ctx = models.WithActingUser(ctx, owner.ID)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	_, err = controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, &productExistsError{name: "testProduct"}, "create_duplicate_product", t)
+
+	// Nothing is delivered and the events are kept while a sink fails.
+	failing := &recordingSink{err: errors.New("connection refused")}
+	delivered, err := controller.DispatchOutbox(ctx, []outbox.Sink{failing})
+	tests.CheckResult(delivered, 0, errors.Is(err, failing.err), true, "failing_sink", t)
+
+	err = controller.AddProductUser(ctx, &product.ID, &nominee.ID, 2)
+	tests.CheckResult(nil, nil, err, nil, "add_nominee", t)
+	err = controller.DeleteUser(ctx, &owner.ID, map[uuid.UUID]uuid.UUID{product.ID: nominee.ID})
+	tests.CheckResult(nil, nil, err, nil, "delete_owner", t)
+
+	sink := &recordingSink{}
+	delivered, err = controller.DispatchOutbox(ctx, []outbox.Sink{sink})
+	tests.CheckResult(delivered, 6, err, nil, "dispatch", t)
+
+	// The failed create is rolled back with its event.
+	types := make([]string, 0)
+	for _, event := range sink.events {
+		types = append(types, event.Type)
+	}
+	expected := []string{
+		models.EventUserCreated,
+		models.EventUserCreated,
+		models.EventProductCreated,
+		models.EventProductUserAdded,
+		models.EventProductOwnerChanged,
+		models.EventUserDeleted,
+	}
+	tests.CheckResult(types, expected, nil, nil, "event_types", t)
+	tests.CheckResult(sink.events[2].AggregateID, product.ID, nil, nil, "product_id", t)
+	tests.CheckResult(*sink.events[2].Actor, owner.ID, nil, nil, "actor", t)
+	tests.CheckResult(sink.events[4].Payload["new_owner_id"], nominee.ID.String(), nil, nil, "new_owner", t)
+
+	delivered, err = controller.DispatchOutbox(ctx, []outbox.Sink{sink})
+	tests.CheckResult(delivered, 0, err, nil, "dispatch_again", t)
+}
+
+func TestMemoryBackendWebhooks(t *testing.T) {
+	controller := createMemoryController(t)
+	controller.Outbox = true
+	controller.WebhookMaxAttempts = 2
+	// Due again at the next dispatch, the attempts are recorded in whole seconds.
+	controller.WebhookRetryDelay = time.Millisecond
+	ctx := context.Background()
+
+	var secret string
+	status := http.StatusInternalServerError
+	received := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read the body: %s", err)
+		}
+
+		signature := r.Header.Get(outbox.WebhookSignatureHeader)
+		if !outbox.VerifySignature(secret, r.Header.Get(outbox.WebhookTimestampHeader), body, signature) {
+			t.Errorf("Invalid signature %s", signature)
+		}
+		received = append(received, r.Header.Get(outbox.WebhookEventHeader))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	controller.WebhookClient = server.Client()
+
+	_, err := controller.CreateWebhookSubscription(ctx, "ftp://localhost", "", nil)
+	tests.CheckResult(errors.Is(err, models.ErrInvalidWebhookSubscription), true, nil, nil, "invalid_url", t)
+
+	subscription, err := controller.CreateWebhookSubscription(ctx, server.URL, "", []string{models.EventUserCreated})
+	tests.CheckResult(subscription.Secret != "", true, err, nil, "create_subscription", t)
+	secret = subscription.Secret
+
+	// The secret is only returned when the subscription is created.
+	subscriptions, err := controller.GetWebhookSubscriptions(ctx)
+	tests.CheckResult(subscriptions[0].Secret, "", err, nil, "hidden_secret", t)
+
+	user, err := controller.CreateUser(ctx, "userName", "user@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_user", t)
+	_, err = controller.CreateProduct(ctx, "testProduct", &user.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+
+	// Only the events accepted by the subscription are queued.
+	delivered, err := controller.DispatchOutbox(ctx, []outbox.Sink{controller.WebhookSink()})
+	tests.CheckResult(delivered, 2, err, nil, "queue", t)
+
+	getDeliveries := func() []models.WebhookDelivery {
+		deliveries, err := controller.GetWebhookDeliveries(ctx, &subscription.ID, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to get the deliveries: %s", err)
+		}
+		return deliveries
+	}
+
+	deliveries := getDeliveries()
+	tests.CheckResult(len(deliveries), 1, nil, nil, "queued", t)
+	event := deliveries[0].Event
+	tests.CheckResult(event.AggregateID, user.ID, nil, nil, "event", t)
+
+	// An event delivered again by the outbox is queued once.
+	err = controller.WebhookSink().Deliver(ctx, &event)
+	tests.CheckResult(len(getDeliveries()), 1, err, nil, "redelivered", t)
+
+	succeeded, err := controller.DispatchWebhooks(ctx)
+	tests.CheckResult(succeeded, 0, err, nil, "rejected", t)
+	deliveries = getDeliveries()
+	tests.CheckResult(deliveries[0].Status, models.WebhookPending, nil, nil, "retried", t)
+	tests.CheckResult(deliveries[0].LastStatusCode, http.StatusInternalServerError, nil, nil, "status_code", t)
+
+	status = http.StatusNoContent
+	succeeded, err = controller.DispatchWebhooks(ctx)
+	tests.CheckResult(succeeded, 1, err, nil, "delivered", t)
+	deliveries = getDeliveries()
+	tests.CheckResult(deliveries[0].Status, models.WebhookSucceeded, nil, nil, "succeeded", t)
+	tests.CheckResult(deliveries[0].Attempts, 2, nil, nil, "attempts", t)
+	tests.CheckResult(deliveries[0].LastError, "", nil, nil, "no_error", t)
+
+	// A delivery failing every attempt is given up.
+	status = http.StatusServiceUnavailable
+	_, err = controller.CreateUser(ctx, "otherName", "other@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_other", t)
+	_, err = controller.DispatchOutbox(ctx, []outbox.Sink{controller.WebhookSink()})
+	tests.CheckResult(nil, nil, err, nil, "queue_other", t)
+	for i := 0; i < 3; i++ {
+		_, err = controller.DispatchWebhooks(ctx)
+		tests.CheckResult(nil, nil, err, nil, "dispatch_failing", t)
+	}
+	deliveries = getDeliveries()
+	tests.CheckResult(deliveries[0].Status, models.WebhookFailed, nil, nil, "failed", t)
+	tests.CheckResult(deliveries[0].Attempts, 2, nil, nil, "max_attempts", t)
+	tests.CheckResult(len(received), 4, nil, nil, "requests", t)
+
+	subscription.URL = server.URL + "/v2"
+	subscription.Secret = ""
+	err = controller.UpdateWebhookSubscription(ctx, subscription)
+	tests.CheckResult(nil, nil, err, nil, "update", t)
+	found, err := controller.GetWebhookSubscription(ctx, &subscription.ID)
+	tests.CheckResult(found.URL, subscription.URL, err, nil, "updated", t)
+
+	err = controller.DeleteWebhookSubscription(ctx, &subscription.ID)
+	tests.CheckResult(nil, nil, err, nil, "delete", t)
+	_, err = controller.GetWebhookDeliveries(ctx, &subscription.ID, 0, 0)
+	tests.CheckResult(nil, nil, err, ErrWebhookSubscriptionNotFound, "deleted", t)
+	err = controller.UpdateWebhookSubscription(ctx, subscription)
+	tests.CheckResult(nil, nil, err, ErrWebhookSubscriptionNotFound, "update_deleted", t)
+}
+
+func TestMemoryBackendChangeStream(t *testing.T) {
+	controller := createMemoryController(t)
+	controller.ChangeStreamInterval = time.Millisecond
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	started, err := controller.LatestChangeID(ctx)
+	tests.CheckResult(started > 0, true, err, nil, "latest_change", t)
+
+	_, err = controller.CreateProject(ctx, "testProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+	_, err = controller.CreateUser(ctx, "otherName", "other@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_other", t)
+	product.Details.DataMap["is_free"] = true
+	err = controller.UpdateProductDetails(ctx, product)
+	tests.CheckResult(nil, nil, err, nil, "update_details", t)
+
+	// stream returns the entity types of the changes sent by the first poll and the ID to resume after.
+	stream := func(filter models.ChangeFilter, afterID int64) ([]string, int64) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		types := make([]string, 0)
+		var resumeID int64
+		err := controller.StreamChanges(streamCtx, filter, afterID, func(changes []models.AuditEvent, lastID int64) error {
+			for _, change := range changes {
+				types = append(types, change.EntityType)
+			}
+			resumeID = lastID
+			cancel()
+			return nil
+		})
+		tests.CheckResult(nil, nil, err, nil, "stream", t)
+		return types, resumeID
+	}
+
+	// The project of the product and the details of the product, not the other user.
+	types, resumeID := stream(models.ChangeFilter{EntityID: &product.ID}, started)
+	expected := []string{mysqldb.Projects, mysqldb.ProductDetails}
+	tests.CheckResult(types, expected, nil, nil, "by_product", t)
+
+	types, _ = stream(models.ChangeFilter{}, resumeID)
+	tests.CheckResult(types, []string{}, nil, nil, "resumed", t)
+
+	types, _ = stream(models.ChangeFilter{UserID: &owner.ID}, 0)
+	expected = []string{mysqldb.Users, mysqldb.ProductUsers, mysqldb.ProjectUsers}
+	tests.CheckResult(types, expected, nil, nil, "by_user", t)
+
+	// A stopped stream returns without polling again.
+	controller.changeStreams = make(chan struct{})
+	controller.StopChangeStreams()
+	polls := 0
+	err = controller.StreamChanges(ctx, models.ChangeFilter{}, resumeID, func(changes []models.AuditEvent, lastID int64) error {
+		polls++
+		return nil
+	})
+	tests.CheckResult(polls, 1, err, nil, "stopped", t)
+}
+
+func TestMemoryBackendLateChange(t *testing.T) {
+	controller := createMemoryController(t)
+	controller.ChangeStreamInterval = time.Millisecond
+	ctx := context.Background()
+
+	started, err := controller.LatestChangeID(ctx)
+	tests.CheckResult(nil, nil, err, nil, "latest_change", t)
+
+	// The event of the long transaction gets its ID first, but is committed after a later event was sent.
+	tx, err := controller.DBConnector.ConnectSystem(ctx)
+	tests.CheckResult(nil, nil, err, nil, "connect", t)
+	late := uuid.New()
+	err = controller.audit(ctx, tx, models.ActionCreate, mysqldb.Products, late, nil, nil)
+	tests.CheckResult(nil, nil, err, nil, "audit_late", t)
+	user, err := controller.CreateUser(ctx, "userName", "user@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_user", t)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sent := make([]uuid.UUID, 0)
+	polls := 0
+	err = controller.StreamChanges(streamCtx, models.ChangeFilter{}, started, func(changes []models.AuditEvent, lastID int64) error {
+		for _, change := range changes {
+			sent = append(sent, change.EntityID)
+		}
+
+		polls++
+		switch polls {
+		case 1:
+			return controller.DBConnector.Commit(tx)
+		case 3:
+			cancel()
+		}
+		return nil
+	})
+	tests.CheckResult(nil, nil, err, nil, "stream", t)
+
+	// The late event is sent once, after the user.
+	tests.CheckResult(sent, []uuid.UUID{user.ID, late}, nil, nil, "sent", t)
+}
+
+func TestMemoryBackendUserErasure(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	other, err := controller.CreateUser(ctx, "otherName", "other@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_other", t)
+	nominee, err := controller.CreateUser(ctx, "nomineeName", "nominee@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_nominee", t)
+
+	// The product of the owner goes with its project, the owner is a member of the product of the other user.
+	ownedProduct, err := controller.CreateProduct(ctx, "ownedProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_owned_product", t)
+	ownedProductProject, err := controller.CreateProject(ctx, "ownedProductProject", "Public", &owner.ID, &ownedProduct.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_owned_product_project", t)
+	product, err := controller.CreateProduct(ctx, "otherProduct", &other.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	err = controller.AddProductUser(ctx, &product.ID, &owner.ID, 2)
+	tests.CheckResult(nil, nil, err, nil, "add_product_user", t)
+
+	transferred, err := controller.CreateProject(ctx, "transferred", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_transferred", t)
+	deleted, err := controller.CreateProject(ctx, "deleted", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_deleted", t)
+	shared, err := controller.CreateProject(ctx, "shared", "Public", &other.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_shared", t)
+	err = controller.runInTransaction(ctx, "AddProjectUsers", func(tx *sql.Tx) error {
+		if err := controller.DBFunctions.AddProjectUsers(ctx, &transferred.ID, &models.ProjectUserIDs{UserMap: map[uuid.UUID]int{nominee.ID: 2}}, tx); err != nil {
+			return err
+		}
+		return controller.DBFunctions.AddProjectUsers(ctx, &shared.ID, &models.ProjectUserIDs{UserMap: map[uuid.UUID]int{owner.ID: 2}}, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_project_users", t)
+
+	// The viewer of the owner is shared with the nominee, the owner views the viewer of the other user.
+	ownedViewer := &models.ProjectViewer{ViewerID: uuid.New(), UserID: owner.ID, ProjectID: transferred.ID, IsOwner: true}
+	otherViewer := &models.ProjectViewer{ViewerID: uuid.New(), UserID: other.ID, ProjectID: shared.ID, IsOwner: true}
+	for _, viewer := range []*models.ProjectViewer{
+		ownedViewer,
+		{ViewerID: ownedViewer.ViewerID, UserID: nominee.ID, ProjectID: transferred.ID},
+		otherViewer,
+		{ViewerID: otherViewer.ViewerID, UserID: owner.ID, ProjectID: shared.ID},
+		{ViewerID: otherViewer.ViewerID, UserID: other.ID, ProjectID: deleted.ID},
+	} {
+		err = controller.CreateProjectViewer(ctx, viewer)
+		tests.CheckResult(nil, nil, err, nil, "create_project_viewer", t)
+	}
+
+	err = controller.DeleteUser(ctx, &owner.ID, map[uuid.UUID]uuid.UUID{transferred.ID: nominee.ID})
+	tests.CheckResult(nil, nil, err, nil, "delete_owner", t)
+
+	_, err = controller.GetUser(ctx, &owner.ID)
+	tests.CheckResult(nil, nil, err, ErrUserNotFound, "deleted_owner", t)
+	_, err = controller.GetProduct(ctx, &ownedProduct.ID)
+	tests.CheckResult(nil, nil, err, ErrProductNotFound, "deleted_owned_product", t)
+	for _, projectID := range []uuid.UUID{ownedProductProject.ID, deleted.ID} {
+		_, err = controller.GetProject(ctx, &projectID)
+		tests.CheckResult(nil, nil, err, ErrProjectNotFound, "deleted_project", t)
+	}
+	_, err = controller.GetProject(ctx, &shared.ID)
+	tests.CheckResult(nil, nil, err, nil, "kept_shared", t)
+
+	err = controller.runInTransaction(ctx, "CheckErasure", func(tx *sql.Tx) error {
+		productUsers, err := controller.DBFunctions.GetProductUserIDs(ctx, &product.ID, tx)
+		tests.CheckResult(productUsers.UserMap, map[uuid.UUID]int{other.ID: 1}, err, nil, "product_users", t)
+
+		projectUsers, err := controller.DBFunctions.GetUserProjectIDs(ctx, &nominee.ID, tx)
+		tests.CheckResult(projectUsers.ProjectMap, map[uuid.UUID]int{transferred.ID: 1}, err, nil, "new_owner", t)
+
+		projectUsers, err = controller.DBFunctions.GetUserProjectIDs(ctx, &owner.ID, tx)
+		tests.CheckResult(len(projectUsers.ProjectMap), 0, err, nil, "no_project_users_left", t)
+
+		_, err = controller.DBFunctions.GetProjectViewersByViewerID(ctx, &ownedViewer.ViewerID, tx)
+		tests.CheckResult(nil, nil, err, sql.ErrNoRows, "owned_viewer_deleted", t)
+
+		_, err = controller.DBFunctions.GetViewerIDsByOwnerID(ctx, &owner.ID, tx)
+		tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_viewers_left", t)
+
+		// Only the rows of the other user on the kept project remain.
+		viewers, err := controller.DBFunctions.GetProjectViewersByViewerID(ctx, &otherViewer.ViewerID, tx)
+		expected := []models.ProjectViewer{{ViewerID: otherViewer.ViewerID, UserID: other.ID, ProjectID: shared.ID}}
+		tests.CheckResult(viewers, expected, err, nil, "other_viewer_kept", t)
+		return nil
+	})
+	tests.CheckResult(nil, nil, err, nil, "check_erasure", t)
+
+	// A trashed user takes its projects to the trash and back.
+	controller.SoftDelete = true
+	err = controller.DeleteUser(ctx, &nominee.ID, nil)
+	tests.CheckResult(nil, nil, err, nil, "trash_nominee", t)
+	_, err = controller.GetProject(ctx, &transferred.ID)
+	tests.CheckResult(nil, nil, err, ErrProjectNotFound, "trashed_project", t)
+
+	err = controller.RestoreFromTrash(ctx, mysqldb.Users, &nominee.ID)
+	tests.CheckResult(nil, nil, err, nil, "restore_nominee", t)
+	_, err = controller.GetProject(ctx, &transferred.ID)
+	tests.CheckResult(nil, nil, err, nil, "restored_project", t)
+}
+
+// userRows returns the rows of the backed up tables referring to any of IDs.
+func userRows(t *testing.T, controller *MYSQLController, IDs ...uuid.UUID) []string {
+	refersTo := make(map[uuid.UUID]bool, len(IDs))
+	for _, ID := range IDs {
+		refersTo[ID] = true
+	}
+
+	found := make([]string, 0)
+	err := controller.runInTransaction(context.Background(), "DumpTables", func(tx *sql.Tx) error {
+		found = found[:0]
+		for i := range mysqldb.BackupTables {
+			table := &mysqldb.BackupTables[i]
+			err := controller.DBFunctions.DumpTable(context.Background(), table, func(r models.BackupRow) error {
+				for _, value := range r {
+					if ID, ok := value.(uuid.UUID); ok && refersTo[ID] {
+						found = append(found, fmt.Sprintf("%s %v", table.Name, r))
+						break
+					}
+				}
+				return nil
+			}, tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to dump the tables: %s", err)
+	}
+	return found
+}
+
+func TestMemoryBackendEraseUser(t *testing.T) {
+	controller := createMemoryController(t)
+	controller.SoftDelete = true
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	other, err := controller.CreateUser(ctx, "otherName", "other@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_other", t)
+
+	ownedProduct, err := controller.CreateProduct(ctx, "ownedProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_owned_product", t)
+	ownedProductProject, err := controller.CreateProject(ctx, "ownedProductProject", "Public", &owner.ID, &ownedProduct.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_owned_product_project", t)
+	trashedProduct, err := controller.CreateProduct(ctx, "trashedProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_trashed_product", t)
+	product, err := controller.CreateProduct(ctx, "otherProduct", &other.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	err = controller.AddProductUser(ctx, &product.ID, &owner.ID, 2)
+	tests.CheckResult(nil, nil, err, nil, "add_product_user", t)
+	ownedProject, err := controller.CreateProject(ctx, "ownedProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_owned_project", t)
+	shared, err := controller.CreateProject(ctx, "shared", "Public", &other.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_shared", t)
+
+	ownedViewer := &models.ProjectViewer{ViewerID: uuid.New(), UserID: owner.ID, ProjectID: shared.ID, IsOwner: true}
+	otherViewer := &models.ProjectViewer{ViewerID: uuid.New(), UserID: other.ID, ProjectID: shared.ID, IsOwner: true}
+	for _, viewer := range []*models.ProjectViewer{
+		ownedViewer,
+		{ViewerID: ownedViewer.ViewerID, UserID: other.ID, ProjectID: shared.ID},
+		otherViewer,
+		{ViewerID: otherViewer.ViewerID, UserID: owner.ID, ProjectID: shared.ID},
+	} {
+		err = controller.CreateProjectViewer(ctx, viewer)
+		tests.CheckResult(nil, nil, err, nil, "create_project_viewer", t)
+	}
+
+	// A product trashed on its own before the erasure is erased as well.
+	err = controller.DeleteProduct(ctx, &trashedProduct.ID)
+	tests.CheckResult(nil, nil, err, nil, "trash_product", t)
+
+	erased := []uuid.UUID{
+		owner.ID, owner.Settings.ID, owner.Assets.ID, ownedViewer.ViewerID,
+		ownedProduct.ID, ownedProductProject.ID, trashedProduct.ID, ownedProject.ID,
+	}
+	// The nominee is not a member of the project, so the failed transfer keeps the owner and all its data.
+	err = controller.EraseUser(ctx, &owner.ID, map[uuid.UUID]uuid.UUID{ownedProject.ID: uuid.New()})
+	tests.CheckResult(nil, nil, err, &OwnershipTransferError{ProjectIDs: []uuid.UUID{ownedProject.ID}}, "failed_transfer", t)
+	tests.CheckResult(len(userRows(t, controller, erased...)) > 0, true, nil, nil, "failed_transfer_kept", t)
+
+	err = controller.EraseUser(ctx, &owner.ID, nil)
+	tests.CheckResult(userRows(t, controller, erased...), []string{}, err, nil, "erase_owner", t)
+
+	trash, err := controller.GetTrash(ctx, "")
+	tests.CheckResult(trash, []models.TrashEntry{}, err, nil, "trash_bypassed", t)
+
+	_, err = controller.GetProject(ctx, &shared.ID)
+	tests.CheckResult(nil, nil, err, nil, "kept_shared", t)
+
+	// A user in the trash is erased with the entities trashed with it.
+	otherProject, err := controller.CreateProject(ctx, "otherProject", "Public", &other.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_other_project", t)
+	err = controller.DeleteUser(ctx, &other.ID, nil)
+	tests.CheckResult(nil, nil, err, nil, "trash_other", t)
+	tests.CheckResult(len(userRows(t, controller, other.ID)) > 0, true, nil, nil, "trashed_other_kept", t)
+
+	err = controller.EraseUser(ctx, &other.ID, nil)
+	erased = []uuid.UUID{other.ID, product.ID, shared.ID, otherProject.ID, otherViewer.ViewerID}
+	tests.CheckResult(userRows(t, controller, erased...), []string{}, err, nil, "erase_trashed_other", t)
+
+	err = controller.EraseUser(ctx, &other.ID, nil)
+	tests.CheckResult(nil, nil, err, ErrUserNotFound, "erase_missing", t)
+}
+
+func TestMemoryBackendExportUser(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	other, err := controller.CreateUser(ctx, "otherName", "other@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_other", t)
+
+	product, err := controller.CreateProduct(ctx, "ownedProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	project, err := controller.CreateProject(ctx, "ownedProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+	otherProduct, err := controller.CreateProduct(ctx, "otherProduct", &other.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_other_product", t)
+	err = controller.AddProductUser(ctx, &otherProduct.ID, &owner.ID, 2)
+	tests.CheckResult(nil, nil, err, nil, "add_product_user", t)
+	viewer := models.ProjectViewer{ViewerID: uuid.New(), UserID: owner.ID, ProjectID: project.ID, IsOwner: true}
+	err = controller.CreateProjectViewer(ctx, &viewer)
+	tests.CheckResult(nil, nil, err, nil, "create_viewer", t)
+
+	_, err = controller.ExportUser(ctx, &product.ID)
+	tests.CheckResult(nil, nil, err, ErrUserNotFound, "missing_user", t)
+
+	export, err := controller.ExportUser(ctx, &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "export", t)
+	tests.CheckResult(export.User, models.ExportedUser{ID: owner.ID, Name: owner.Name, Email: owner.Email}, nil, nil, "user", t)
+	tests.CheckResult(export.Settings, owner.Settings, nil, nil, "settings", t)
+	tests.CheckResult(export.Assets, owner.Assets, nil, nil, "assets", t)
+
+	productMemberships := []models.Membership{
+		{ID: product.ID, PrivilegeID: 1, Privilege: "Owner"},
+		{ID: otherProduct.ID, PrivilegeID: 2, Privilege: "User"},
+	}
+	if otherProduct.ID.String() < product.ID.String() {
+		productMemberships[0], productMemberships[1] = productMemberships[1], productMemberships[0]
+	}
+	tests.CheckResult(export.ProductMemberships, productMemberships, nil, nil, "product_memberships", t)
+	projectMemberships := []models.Membership{{ID: project.ID, PrivilegeID: 1, Privilege: "Owner"}}
+	tests.CheckResult(export.ProjectMemberships, projectMemberships, nil, nil, "project_memberships", t)
+
+	// Only the owned products are exported with their data.
+	tests.CheckResult(len(export.OwnedProducts), 1, export.OwnedProducts[0].ID, product.ID, "owned_products", t)
+	tests.CheckResult(export.OwnedProducts[0].Details.DataMap, product.Details.DataMap, nil, nil, "owned_product_details", t)
+	tests.CheckResult(len(export.OwnedProjects), 1, export.OwnedProjects[0].ID, project.ID, "owned_projects", t)
+	tests.CheckResult(export.Viewers, []models.ProjectViewer{viewer}, nil, nil, "viewers", t)
+
+	archive, err := json.Marshal(export)
+	tests.CheckResult(strings.Contains(string(archive), "password"), false, err, nil, "no_password", t)
+}
+
+func TestMemoryBackendBackup(t *testing.T) {
+	controller := createMemoryController(t)
+	ctx := context.Background()
+
+	owner, err := controller.CreateUser(ctx, "ownerName", "owner@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_owner", t)
+	other, err := controller.CreateUser(ctx, "otherName", "other@test.com", []byte("testPass"))
+	tests.CheckResult(nil, nil, err, nil, "create_other", t)
+	product, err := controller.CreateProduct(ctx, "testProduct", &owner.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_product", t)
+	err = controller.AddProductUser(ctx, &product.ID, &other.ID, 2)
+	tests.CheckResult(nil, nil, err, nil, "add_product_user", t)
+	project, err := controller.CreateProject(ctx, "testProject", "Public", &owner.ID, &product.ID)
+	tests.CheckResult(nil, nil, err, nil, "create_project", t)
+	viewer := models.ProjectViewer{ViewerID: uuid.New(), UserID: other.ID, ProjectID: project.ID, IsOwner: true}
+	err = controller.CreateProjectViewer(ctx, &viewer)
+	tests.CheckResult(nil, nil, err, nil, "create_viewer", t)
+
+	var dump strings.Builder
+	count, err := controller.Backup(ctx, &dump)
+	tests.CheckResult(nil, nil, err, nil, "backup", t)
+	lines := strings.Split(strings.TrimSuffix(dump.String(), "\n"), "\n")
+	tests.CheckResult(int64(len(lines)-2), count, nil, nil, "rows", t)
+
+	_, err = controller.Restore(ctx, strings.NewReader(dump.String()))
+	tests.CheckResult(errors.Is(err, ErrRestoreTargetNotEmpty), true, nil, nil, "not_empty", t)
+
+	restoreLines := func(lines []string) error {
+		_, err := createMemoryController(t).Restore(ctx, strings.NewReader(strings.Join(lines, "\n")))
+		return err
+	}
+
+	err = restoreLines(lines[:len(lines)-1])
+	tests.CheckResult(errors.Is(err, ErrInvalidBackup), true, nil, nil, "truncated", t)
+
+	withoutUsers := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !strings.Contains(line, `"table":"users"`) {
+			withoutUsers = append(withoutUsers, line)
+		}
+	}
+	err = restoreLines(withoutUsers)
+	tests.CheckResult(errors.Is(err, ErrInvalidBackup), true, nil, nil, "missing_reference", t)
+
+	var header models.BackupRecord
+	err = json.Unmarshal([]byte(lines[0]), &header)
+	tests.CheckResult(nil, nil, err, nil, "header", t)
+	otherVersion := append([]string{strings.Replace(lines[0], header.SchemaVersion, "20200101000000", 1)}, lines[1:]...)
+	err = restoreLines(otherVersion)
+	tests.CheckResult(errors.Is(err, ErrBackupSchemaMismatch), true, nil, nil, "schema_mismatch", t)
+
+	restored := createMemoryController(t)
+	restoredCount, err := restored.Restore(ctx, strings.NewReader(dump.String()))
+	tests.CheckResult(restoredCount, count, err, nil, "restore", t)
+
+	// The restored DB is dumped the same way, apart from the creation time of the header.
+	var restoredDump strings.Builder
+	_, err = restored.Backup(ctx, &restoredDump)
+	restoredLines := strings.Split(strings.TrimSuffix(restoredDump.String(), "\n"), "\n")
+	tests.CheckResult(restoredLines[1:], lines[1:], err, nil, "restored_dump", t)
+
+	export, err := restored.ExportUser(ctx, &other.ID)
+	tests.CheckResult(nil, nil, err, nil, "export_restored", t)
+	productMemberships := []models.Membership{{ID: product.ID, PrivilegeID: 2, Privilege: "User"}}
+	tests.CheckResult(export.ProductMemberships, productMemberships, nil, nil, "restored_memberships", t)
+	tests.CheckResult(export.Viewers, []models.ProjectViewer{viewer}, nil, nil, "restored_viewers", t)
+}
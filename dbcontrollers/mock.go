@@ -1,9 +1,12 @@
 package dbcontrollers
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
 	"github.com/google/uuid"
 )
 
@@ -14,8 +17,10 @@ type ModelMock struct {
 	userID     uuid.UUID
 	productID  uuid.UUID
 	projectID  uuid.UUID
-	project    *models.Project
-	asset      *models.Asset
+	// ID of the created webhook subscriptions.
+	subscriptionID uuid.UUID
+	project        *models.Project
+	asset          *models.Asset
 
 	err error
 }
@@ -52,6 +57,16 @@ func (i *ModelMock) NewProject(productID *uuid.UUID, detailsID *uuid.UUID, asset
 	return i.project, i.err
 }
 
+func (i *ModelMock) NewWebhookSubscription(url string, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	s := &models.WebhookSubscription{
+		ID:         i.subscriptionID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	}
+	return s, i.err
+}
+
 func (i *ModelMock) NewUser(
 	name string,
 	email string,
@@ -82,184 +97,328 @@ type DBFunctionMock struct {
 	projectAdded         bool
 	productDeleted       bool
 	usersProductsUpdated bool
+	transferErr          error
 	privileges           models.Privileges
 	userProducts         *models.UserProductIDs
 	userProjects         *models.UserProjectIDs
 	productUsers         *models.ProductUserIDs
+	auditEvents          []models.AuditEvent
+	outboxEvents         []models.DomainEvent
 	err                  error
 }
 
-func (i *DBFunctionMock) GetPrivileges() (models.Privileges, error) {
+func (i *DBFunctionMock) GetPrivileges(ctx context.Context) (models.Privileges, error) {
 	return i.privileges, i.err
 }
 
-func (i *DBFunctionMock) GetPrivilege(name string) (*models.Privilege, error) {
+func (i *DBFunctionMock) GetPrivilege(ctx context.Context, name string) (*models.Privilege, error) {
 	return i.privileges[0], i.err
 }
 
-func (i *DBFunctionMock) GetUser(queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error) {
+func (i *DBFunctionMock) DumpTable(ctx context.Context, table *mysqldb.BackupTable, fn func(r models.BackupRow) error, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) RestoreRow(ctx context.Context, table *mysqldb.BackupTable, r models.BackupRow, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) GetUser(ctx context.Context, queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error) {
 	return i.user, i.err
 }
 
-func (i *DBFunctionMock) AddUser(user *models.User, tx *sql.Tx) error {
+func (i *DBFunctionMock) AddUser(ctx context.Context, user *models.User, tx *sql.Tx) error {
 	i.userAdded = true
 	return i.err
 }
 
-func (i *DBFunctionMock) GetUsersByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error) {
+func (i *DBFunctionMock) GetUsersByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) GetAssets(assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
+func (i *DBFunctionMock) GetAssets(ctx context.Context, assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) GetProductUserIDs(productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error) {
+func (i *DBFunctionMock) GetProductUserIDs(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error) {
 	return i.productUsers, i.err
 }
 
-func (i *DBFunctionMock) AddAsset(assetType string, asset *models.Asset, tx *sql.Tx) error {
+func (i *DBFunctionMock) AddAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteAsset(assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) GetAsset(assetType string, assetID *uuid.UUID) (*models.Asset, error) {
+func (i *DBFunctionMock) GetAsset(ctx context.Context, assetType string, assetID *uuid.UUID) (*models.Asset, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) UpdateAsset(assetType string, asset *models.Asset) error {
+func (i *DBFunctionMock) UpdateAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) AddProductUsers(productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
+func (i *DBFunctionMock) PatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) ([]models.AssetRevision, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetAssetRevision(ctx context.Context, assetType string, assetID *uuid.UUID, version int64, tx *sql.Tx) (*models.AssetRevision, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) AddProductUsers(ctx context.Context, productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) AddProduct(product *models.Product, tx *sql.Tx) error {
+func (i *DBFunctionMock) AddProduct(ctx context.Context, product *models.Product, tx *sql.Tx) error {
 	i.productAdded = true
 	return i.err
 }
 
-func (i *DBFunctionMock) GetProductsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
+func (i *DBFunctionMock) GetProductsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetProductsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Product, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) GetProductByName(name string, tx *sql.Tx) (*models.Product, error) {
+func (i *DBFunctionMock) GetProductByName(ctx context.Context, name string, tx *sql.Tx) (*models.Product, error) {
 	return i.product, i.err
 }
 
-func (i *DBFunctionMock) GetUserProductIDs(userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
+func (i *DBFunctionMock) GetUserProductIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
 	return i.userProducts, i.err
 }
 
-func (i *DBFunctionMock) DeleteProduct(productID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
 	i.productDeleted = true
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteUser(userID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteUser(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
 	i.userDeleted = true
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteProductUsersByProductID(productID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteProductUsersByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteProductUser(productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) UpdateUsersProducts(userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
+func (i *DBFunctionMock) UpdateUsersProducts(ctx context.Context, userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
 	i.usersProductsUpdated = true
+	if i.transferErr != nil {
+		return i.transferErr
+	}
 	return i.err
 }
 
-func (i *DBFunctionMock) GetProductByID(ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
+func (i *DBFunctionMock) GetProductByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
 	return i.product, i.err
 }
 
-func (i DBFunctionMock) AddProject(project *models.Project, tx *sql.Tx) error {
+func (i DBFunctionMock) AddProject(ctx context.Context, project *models.Project, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) AddProjectUsers(projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error {
+func (i *DBFunctionMock) AddProjectUsers(ctx context.Context, projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) GetProjectByID(ID *uuid.UUID, tx *sql.Tx) (*models.Project, error) {
+func (i *DBFunctionMock) GetProjectByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Project, error) {
 	return i.project, i.err
 }
 
-func (i *DBFunctionMock) UpdateUsersProjects(userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error {
+func (i *DBFunctionMock) UpdateUsersProjects(ctx context.Context, userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) DeleteProjectUsersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteProjectUsersByProjectID(projectID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteProject(projectID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteProject(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) GetUserProjectIDs(userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error) {
+func (i *DBFunctionMock) GetUserProjectIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error) {
 	return i.userProjects, i.err
 }
 
-func (i *DBFunctionMock) GetProductProjects(productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+func (i *DBFunctionMock) GetProductProjects(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
 	return i.projects, i.err
 }
 
-func (i *DBFunctionMock) GetProjectsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+func (i *DBFunctionMock) GetProjectsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetProjectsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Project, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) DeleteProjectsByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) AddProjectViewer(ctx context.Context, projectViewer *models.ProjectViewer, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) DeleteProjectViewerByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) GetProjectViewersByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetProjectViewersByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetProjectViewersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) DeleteProjectViewerByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) DeleteProjectViewerByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) GetViewerIDsByOwnerID(ctx context.Context, ownerID *uuid.UUID, tx *sql.Tx) ([]uuid.UUID, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) DeleteProjectsByProductID(productID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteViewerByOwnerID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) AddProjectViewer(projectViewer *models.ProjectViewer, tx *sql.Tx) error {
+func (i *DBFunctionMock) TrashEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteProjectViewerByUserID(userID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) RestoreEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) GetProjectViewersByUserID(userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+func (i *DBFunctionMock) TrashProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) RestoreProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) GetTrash(ctx context.Context, entityType string, deletedBefore time.Time, tx *sql.Tx) ([]models.TrashEntry, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) GetProjectViewersByViewerID(viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+func (i *DBFunctionMock) GetTrashEntry(ctx context.Context, entityType string, ID *uuid.UUID, tx *sql.Tx) (*models.TrashEntry, error) {
 	return nil, i.err
 }
 
-func (i *DBFunctionMock) DeleteProjectViewerByViewerID(viewerID *uuid.UUID, tx *sql.Tx) error {
+// AddAuditEvent records the event without failing, so the audit does not change the result of the mocked calls.
+func (i *DBFunctionMock) AddAuditEvent(ctx context.Context, event *models.AuditEvent, tx *sql.Tx) error {
+	i.auditEvents = append(i.auditEvents, *event)
+	return nil
+}
+
+func (i *DBFunctionMock) GetAuditEvents(ctx context.Context, filter *models.AuditFilter, tx *sql.Tx) ([]models.AuditEvent, error) {
+	return i.auditEvents, i.err
+}
+
+func (i *DBFunctionMock) GetAuditEventsAfter(ctx context.Context, afterID int64, limit int, tx *sql.Tx) ([]models.AuditEvent, error) {
+	return i.auditEvents, i.err
+}
+
+// AddOutboxEvent records the event without failing, so publishing does not change the result of the mocked calls.
+func (i *DBFunctionMock) AddOutboxEvent(ctx context.Context, event *models.DomainEvent, tx *sql.Tx) error {
+	i.outboxEvents = append(i.outboxEvents, *event)
+	return nil
+}
+
+func (i *DBFunctionMock) GetOutboxEvents(ctx context.Context, limit int, tx *sql.Tx) ([]models.DomainEvent, error) {
+	return i.outboxEvents, i.err
+}
+
+func (i *DBFunctionMock) DeleteOutboxEvent(ctx context.Context, ID int64, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) RecordOutboxFailure(ctx context.Context, ID int64, message string, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) AddWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteProjectViewerByProjectID(projectID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
 	return i.err
 }
 
-func (i *DBFunctionMock) DeleteViewerByOwnerID(userID *uuid.UUID, tx *sql.Tx) error {
+func (i *DBFunctionMock) DeleteWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) GetWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.WebhookSubscription, error) {
+	return nil, i.err
+}
+
+// GetWebhookSubscriptions returns no subscriptions, so the mocked calls are never delivered as webhooks.
+func (i *DBFunctionMock) GetWebhookSubscriptions(ctx context.Context, tx *sql.Tx) ([]models.WebhookSubscription, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (i *DBFunctionMock) AddWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) GetWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, beforeID int64, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) GetDueWebhookDeliveries(ctx context.Context, dueAt time.Time, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	return nil, i.err
+}
+
+func (i *DBFunctionMock) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	return i.err
+}
+
+func (i *DBFunctionMock) DeleteWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, tx *sql.Tx) error {
 	return i.err
 }
 
 // DBConnectorMock overwrites the mysqldb package implementations for DB connectionwith mock code.
 type DBConnectorMock struct {
-	err error
+	rolledBackToSavepoint bool
+	err                   error
 }
 
 func (i *DBConnectorMock) BootstrapSystem() error {
 	return i.err
 }
 
-func (i *DBConnectorMock) ConnectSystem() (*sql.Tx, error) {
+func (i *DBConnectorMock) ConnectSystem(ctx context.Context) (*sql.Tx, error) {
 	return nil, i.err
 }
 
@@ -271,4 +430,21 @@ func (i DBConnectorMock) Rollback(tx *sql.Tx) error {
 	return i.err
 }
 
+func (i *DBConnectorMock) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return i.err
+}
+
+func (i *DBConnectorMock) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	i.rolledBackToSavepoint = true
+	return i.err
+}
+
+func (i *DBConnectorMock) ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return i.err
+}
+
+func (i *DBConnectorMock) Close() error {
+	return i.err
+}
+
 var dbController *MYSQLController
@@ -0,0 +1,153 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/outbox"
+	"github.com/google/uuid"
+)
+
+// Default number of events read from the outbox at once.
+const defaultOutboxBatchSize = 100
+
+// Events published when an entity of the type is moved to or restored from the trash.
+var trashedEvents = map[string]string{
+	mysqldb.Users:    models.EventUserTrashed,
+	mysqldb.Products: models.EventProductTrashed,
+	mysqldb.Projects: models.EventProjectTrashed,
+}
+
+var restoredEvents = map[string]string{
+	mysqldb.Users:    models.EventUserRestored,
+	mysqldb.Products: models.EventProductRestored,
+	mysqldb.Projects: models.EventProjectRestored,
+}
+
+// publish writes the domain event to the outbox in tx, so it is only delivered if the change is committed.
+// Nothing is written if the outbox is disabled.
+func (c *MYSQLController) publish(ctx context.Context, tx *sql.Tx, eventType string, aggregateID uuid.UUID, payload models.DataMap) error {
+	if !c.Outbox {
+		return nil
+	}
+
+	event := &models.DomainEvent{
+		Type: eventType,
+		// The columns store whole seconds.
+		OccurredAt:  time.Now().UTC().Truncate(time.Second),
+		Actor:       models.ActingUser(ctx),
+		AggregateID: aggregateID,
+		Payload:     payload,
+	}
+	return c.DBFunctions.AddOutboxEvent(ctx, event, tx)
+}
+
+// ownerChangePayload describes the transfer of a product from the deleted user to the nominated owner.
+func ownerChangePayload(previousOwner *uuid.UUID, newOwner *uuid.UUID) models.DataMap {
+	return models.DataMap{
+		"previous_owner_id": previousOwner.String(),
+		"new_owner_id":      newOwner.String(),
+	}
+}
+
+// deliveryError cuts the error of a failed delivery to the length of the outbox column.
+func deliveryError(err error) string {
+	message := err.Error()
+	if len(message) <= mysqldb.MaxOutboxErrorLength {
+		return message
+	}
+
+	cut := mysqldb.MaxOutboxErrorLength
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut]
+}
+
+// deliver sends the event to every sink and removes it from the outbox. A failed delivery is recorded
+// on the event and returned, the event is delivered to every sink again in the next run.
+func (c *MYSQLController) deliver(ctx context.Context, event *models.DomainEvent, sinks []outbox.Sink) error {
+	for _, sink := range sinks {
+		if err := sink.Deliver(ctx, event); err != nil {
+			failure := fmt.Errorf("Failed to deliver event %d to %s (attempt %d): %w", event.ID, sink.Name(), event.Attempts+1, err)
+
+			writeCtx, cancel := c.writeContext(ctx)
+			defer cancel()
+			if err := c.runInTransaction(writeCtx, "RecordOutboxFailure", func(tx *sql.Tx) error {
+				return c.DBFunctions.RecordOutboxFailure(writeCtx, event.ID, deliveryError(failure), tx)
+			}); err != nil {
+				return fmt.Errorf("%s, failed to record it: %s", failure.Error(), err.Error())
+			}
+			return failure
+		}
+	}
+
+	writeCtx, cancel := c.writeContext(ctx)
+	defer cancel()
+	return c.runInTransaction(writeCtx, "DeleteOutboxEvent", func(tx *sql.Tx) error {
+		return c.DBFunctions.DeleteOutboxEvent(writeCtx, event.ID, tx)
+	})
+}
+
+// DispatchOutbox delivers the events of the outbox to the sinks in the order they were written
+// and returns the number of delivered events. It stops at the first failed delivery,
+// so the events of an entity are not delivered out of order.
+// An event delivered but not removed from the outbox, for example because the process is stopped, is delivered again.
+func (c *MYSQLController) DispatchOutbox(ctx context.Context, sinks []outbox.Sink) (int, error) {
+	batchSize := c.OutboxBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+
+	delivered := 0
+	for {
+		var events []models.DomainEvent
+		readCtx, cancel := c.readContext(ctx)
+		err := c.runInTransaction(readCtx, "GetOutboxEvents", func(tx *sql.Tx) (err error) {
+			events, err = c.DBFunctions.GetOutboxEvents(readCtx, batchSize, tx)
+			return err
+		})
+		cancel()
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return delivered, nil
+			}
+			return delivered, err
+		}
+
+		for i := range events {
+			if err := c.deliver(ctx, &events[i], sinks); err != nil {
+				return delivered, err
+			}
+			delivered++
+		}
+
+		if len(events) < batchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// RunOutboxDispatcher dispatches the outbox every interval until ctx is cancelled.
+// The failed deliveries are retried in the next run.
+func (c *MYSQLController) RunOutboxDispatcher(ctx context.Context, interval time.Duration, sinks []outbox.Sink) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.DispatchOutbox(ctx, sinks); err != nil {
+				log.Printf("Failed to dispatch the outbox: %s\n", err.Error())
+			}
+		}
+	}
+}
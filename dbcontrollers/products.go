@@ -1,6 +1,7 @@
 package dbcontrollers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -11,6 +12,9 @@ import (
 )
 
 var ErrProductExistsString = "Product with name %s already exists"
+
+// ErrProductExists matches the error of a duplicate product name, whose message names the product.
+var ErrProductExists = mysqldb.ErrDuplicateProductNameEntry
 var ErrEmptyUsersList = errors.New("At least one product user is required")
 var ErrUnknownPrivilegeString = "Unknown privilege %d set for user %s"
 var ErrInvalidOwnerCount = errors.New("Product must have a single owner")
@@ -19,14 +23,26 @@ var ErrNoProductDetailUpdate = errors.New("Details for the selected product not
 var ErrNoProductAssetUpdate = errors.New("Assets for the selected product not found or no change happened")
 var ErrEmptyProductIDList = errors.New("Request does not contain any product identifiers")
 
-var ErrMissingProductUserDBString = "Error 1452: Cannot add or update a child row: a foreign key constraint fails (`user_database`.`users_products`, CONSTRAINT `users_products_ibfk_2` FOREIGN KEY (`users_id`) REFERENCES `users` (`id`))"
+// productExistsError is the error of a duplicate product name. The message names the product like the former
+// formatted error, so the REST responses do not change, and errors.Is matches it with ErrProductExists.
+type productExistsError struct {
+	name string
+}
+
+func (e *productExistsError) Error() string {
+	return fmt.Sprintf(ErrProductExistsString, e.name)
+}
+
+func (e *productExistsError) Unwrap() error {
+	return ErrProductExists
+}
 
-func (c *MYSQLController) validateOwnership(users *models.ProductUserIDs) error {
+func (c *MYSQLController) validateOwnership(ctx context.Context, users *models.ProductUserIDs) error {
 	if users == nil || (users != nil && len(users.UserMap) == 0) {
 		return ErrEmptyUsersList
 	}
 
-	privileges, err := c.DBFunctions.GetPrivileges()
+	privileges, err := c.DBFunctions.GetPrivileges(ctx)
 	if err != nil {
 		return err
 	}
@@ -52,7 +68,10 @@ func (c *MYSQLController) validateOwnership(users *models.ProductUserIDs) error
 	return nil
 }
 
-func (c *MYSQLController) CreateProduct(name string, owner *uuid.UUID) (*models.ProductData, error) {
+func (c *MYSQLController) CreateProduct(ctx context.Context, name string, owner *uuid.UUID) (*models.ProductData, error) {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
 	references := make(models.DataMap)
 	asset, err := c.ModelFunctions.NewAsset(references)
 	if err != nil {
@@ -70,50 +89,62 @@ func (c *MYSQLController) CreateProduct(name string, owner *uuid.UUID) (*models.
 		return nil, err
 	}
 
-	// Start a DB transaction and do all inserts within the same transaction to improve consistency.
-	tx, err := c.DBConnector.ConnectSystem()
+	privilege, err := c.DBFunctions.GetPrivilege(ctx, "Owner")
 	if err != nil {
 		return nil, err
 	}
 
-	existingProduct, err := c.DBFunctions.GetProductByName(name, tx)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, err
-	}
-
-	if existingProduct != nil {
-		if err := c.DBConnector.Rollback(tx); err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf(ErrProductExistsString, product.Name)
-	}
-
 	users := models.ProductUserIDs{
 		UserIDArray: make([]uuid.UUID, 0),
 		UserMap:     make(map[uuid.UUID]int),
 	}
-	privilege, err := c.DBFunctions.GetPrivilege("Owner")
-	if err != nil {
-		return nil, err
-	}
+	users.UserMap[*owner] = privilege.ID
 
-	if err := c.DBFunctions.AddAsset(mysqldb.ProductDetails, productDetails, tx); err != nil {
-		return nil, err
-	}
+	// Do all inserts within the same transaction to improve consistency.
+	err = c.runInTransaction(ctx, "CreateProduct", func(tx *sql.Tx) error {
+		existingProduct, err := c.DBFunctions.GetProductByName(ctx, name, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
 
-	if err := c.DBFunctions.AddAsset(mysqldb.ProductAssets, asset, tx); err != nil {
-		return nil, err
-	}
+		if existingProduct != nil {
+			return &productExistsError{name: name}
+		}
 
-	if err := c.DBFunctions.AddProduct(product, tx); err != nil {
-		return nil, err
-	}
+		if err := c.DBFunctions.AddAsset(ctx, mysqldb.ProductDetails, productDetails, tx); err != nil {
+			return err
+		}
 
-	users.UserMap[*owner] = privilege.ID
-	if err := c.DBFunctions.AddProductUsers(&product.ID, &users, tx); err != nil {
-		if err.Error() == ErrMissingProductUserDBString {
-			return nil, ErrUserNotFound
+		if err := c.DBFunctions.AddAsset(ctx, mysqldb.ProductAssets, asset, tx); err != nil {
+			return err
+		}
+
+		if err := c.DBFunctions.AddProduct(ctx, product, tx); err != nil {
+			if mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "name") {
+				return &productExistsError{name: name}
+			}
+			return err
 		}
+
+		if err := c.DBFunctions.AddProductUsers(ctx, &product.ID, &users, tx); err != nil {
+			if mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "users_id") {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if err := c.audit(ctx, tx, models.ActionCreate, mysqldb.Products, product.ID, nil, productSnapshot(product)); err != nil {
+			return err
+		}
+		if err := c.audit(ctx, tx, models.ActionCreate, mysqldb.ProductUsers, product.ID, nil, privilegeSnapshot(owner, privilege.ID)); err != nil {
+			return err
+		}
+
+		payload := productSnapshot(product)
+		payload["owner_id"] = owner.String()
+		return c.publish(ctx, tx, models.EventProductCreated, product.ID, payload)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -124,173 +155,285 @@ func (c *MYSQLController) CreateProduct(name string, owner *uuid.UUID) (*models.
 		Assets:  asset,
 	}
 
-	return &productData, c.DBConnector.Commit(tx)
+	return &productData, nil
 }
 
-func (c *MYSQLController) DeleteProduct(productID *uuid.UUID) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
-
-	if err := c.deleteProduct(productID, tx); err != nil {
-		return err
-	}
+func (c *MYSQLController) DeleteProduct(ctx context.Context, productID *uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	return c.DBConnector.Commit(tx)
+	return c.runInTransaction(ctx, "DeleteProduct", func(tx *sql.Tx) error {
+		if deletedAt := c.deleteTime(); !deletedAt.IsZero() {
+			return c.trashProduct(ctx, productID, deletedAt, tx)
+		}
+		return c.deleteProduct(ctx, productID, tx)
+	})
 }
 
-func (c *MYSQLController) deleteProduct(productID *uuid.UUID, tx *sql.Tx) error {
-	product, err := c.DBFunctions.GetProductByID(productID, tx)
+func (c *MYSQLController) deleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	product, err := c.DBFunctions.GetProductByID(ctx, productID, tx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return ErrProductNotFound
 		}
+		return err
 	}
 
-	if err := c.DBFunctions.DeleteProductUsersByProductID(productID, tx); err != nil {
+	if err := c.DBFunctions.DeleteProductUsersByProductID(ctx, productID, tx); err != nil {
 		if err == mysqldb.ErrNoProductDeleted {
 			return ErrProductNotFound
 		}
 	}
 
-	if err := c.DBFunctions.DeleteProduct(productID, tx); err != nil {
+	if err := c.DBFunctions.DeleteProduct(ctx, productID, tx); err != nil {
 		if err == mysqldb.ErrNoProductDeleted {
 			return ErrProductNotFound
 		}
 		return err
 	}
 
-	if err := c.DBFunctions.DeleteAsset(mysqldb.ProductAssets, &product.AssetsID, tx); err != nil {
+	if err := c.audit(ctx, tx, models.ActionDelete, mysqldb.Products, product.ID, productSnapshot(product), nil); err != nil {
+		return err
+	}
+
+	if err := c.publish(ctx, tx, models.EventProductDeleted, product.ID, productSnapshot(product)); err != nil {
 		return err
 	}
 
-	if err := c.DBFunctions.DeleteAsset(mysqldb.ProductDetails, &product.DetailsID, tx); err != nil {
+	if err := c.DBFunctions.DeleteAsset(ctx, mysqldb.ProductAssets, &product.AssetsID, tx); err != nil {
+		return err
+	}
+
+	if err := c.DBFunctions.DeleteAsset(ctx, mysqldb.ProductDetails, &product.DetailsID, tx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *MYSQLController) GetProduct(productID *uuid.UUID) (*models.ProductData, error) {
-	tx, err := c.DBConnector.ConnectSystem()
+func (c *MYSQLController) GetProduct(ctx context.Context, productID *uuid.UUID) (*models.ProductData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var productData *models.ProductData
+	err := c.runInTransaction(ctx, "GetProduct", func(tx *sql.Tx) (err error) {
+		productData, err = c.readProductData(ctx, productID, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	product, err := c.DBFunctions.GetProductByID(productID, tx)
+	return productData, nil
+}
+
+// readProductData reads the product with its details and assets in tx.
+func (c *MYSQLController) readProductData(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) (*models.ProductData, error) {
+	product, err := c.DBFunctions.GetProductByID(ctx, productID, tx)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
-			}
 			return nil, ErrProductNotFound
 		}
+		return nil, err
 	}
 
-	details, err := c.DBFunctions.GetAsset(mysqldb.ProductDetails, &product.DetailsID)
+	details, err := c.getAsset(ctx, mysqldb.ProductDetails, &product.DetailsID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	assets, err := c.DBFunctions.GetAsset(mysqldb.ProductAssets, &product.AssetsID)
+	assets, err := c.getAsset(ctx, mysqldb.ProductAssets, &product.AssetsID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	productData := models.ProductData{
+	return &models.ProductData{
 		ID:      product.ID,
 		Name:    product.Name,
 		Details: details,
 		Assets:  assets,
-	}
-
-	return &productData, c.DBConnector.Commit(tx)
+	}, nil
 }
 
-func (c *MYSQLController) UpdateProductDetails(productData *models.ProductData) error {
-	if err := c.DBFunctions.UpdateAsset(mysqldb.ProductDetails, productData.Details); err != nil {
-		if fmt.Errorf(mysqldb.ErrAssetMissing, mysqldb.ProductDetails).Error() == err.Error() {
+func (c *MYSQLController) UpdateProductDetails(ctx context.Context, productData *models.ProductData) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.updateAsset(ctx, "UpdateProductDetails", mysqldb.ProductDetails, productData.Details)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
 			return ErrNoProductDetailUpdate
 		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return ErrAssetVersionConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (c *MYSQLController) UpdateProductAssets(productData *models.ProductData) error {
-	if err := c.DBFunctions.UpdateAsset(mysqldb.ProductAssets, productData.Assets); err != nil {
-		if fmt.Errorf(mysqldb.ErrAssetMissing, mysqldb.ProductAssets).Error() == err.Error() {
+func (c *MYSQLController) UpdateProductAssets(ctx context.Context, productData *models.ProductData) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.updateAsset(ctx, "UpdateProductAssets", mysqldb.ProductAssets, productData.Assets)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
 			return ErrNoProductAssetUpdate
 		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return ErrAssetVersionConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (c *MYSQLController) UpdateProductUser(productID *uuid.UUID, userID *uuid.UUID, privilege int) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
+// PatchProductDetails applies patch to the details of the product and returns the patched details.
+func (c *MYSQLController) PatchProductDetails(ctx context.Context, productID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error) {
+	asset, err := c.patchAsset(ctx, "PatchProductDetails", mysqldb.ProductDetails, patch, func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error) {
+		product, err := c.DBFunctions.GetProductByID(ctx, productID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrProductNotFound
+			}
+			return nil, err
+		}
+		return &product.DetailsID, nil
+	})
+	if errors.Is(err, mysqldb.ErrAssetMissing) {
+		return nil, ErrNoProductDetailUpdate
 	}
-
-	return c.DBConnector.Commit(tx)
+	return asset, err
 }
 
-func (c *MYSQLController) GetProductsByUserID(userID *uuid.UUID) ([]models.UserProduct, error) {
-	products := make([]models.UserProduct, 0)
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
+// PatchProductAssets applies patch to the assets of the product and returns the patched assets.
+func (c *MYSQLController) PatchProductAssets(ctx context.Context, productID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error) {
+	asset, err := c.patchAsset(ctx, "PatchProductAssets", mysqldb.ProductAssets, patch, func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error) {
+		product, err := c.DBFunctions.GetProductByID(ctx, productID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrProductNotFound
+			}
+			return nil, err
+		}
+		return &product.AssetsID, nil
+	})
+	if errors.Is(err, mysqldb.ErrAssetMissing) {
+		return nil, ErrNoProductAssetUpdate
 	}
+	return asset, err
+}
 
-	ownershipMap, err := c.DBFunctions.GetUserProductIDs(userID, tx)
+func (c *MYSQLController) UpdateProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, privilege int) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	tx, err := c.DBConnector.ConnectSystem(ctx)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrNoProductsForUser
-		}
-		return nil, err
+		return err
 	}
 
-	for productID, privilege := range ownershipMap.ProductMap {
-		productID := productID
-		product, err := c.GetProduct(&productID)
+	return c.DBConnector.Commit(tx)
+}
+
+func (c *MYSQLController) GetProductsByUserID(ctx context.Context, userID *uuid.UUID) ([]models.UserProduct, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var products []models.UserProduct
+	err := c.runInTransaction(ctx, "GetProductsByUserID", func(tx *sql.Tx) error {
+		ownershipMap, err := c.DBFunctions.GetUserProductIDs(ctx, userID, tx)
 		if err != nil {
-			return nil, err
+			if err == sql.ErrNoRows {
+				return ErrNoProductsForUser
+			}
+			return err
 		}
 
-		userProduct := models.UserProduct{
-			ProductData: *product,
-			Privilege:   privilege,
-		}
+		products = make([]models.UserProduct, 0)
+		for productID, privilege := range ownershipMap.ProductMap {
+			productID := productID
+			product, err := c.readProductData(ctx, &productID, tx)
+			if err == ErrProductNotFound {
+				// The relation of an entity in the trash is kept until it is purged.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			userProduct := models.UserProduct{
+				ProductData: *product,
+				Privilege:   privilege,
+			}
 
-		products = append(products, userProduct)
+			products = append(products, userProduct)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return products, c.DBConnector.Commit(tx)
+	return products, nil
 }
 
-func (c *MYSQLController) GetProducts(productIDs []uuid.UUID) ([]models.ProductData, error) {
+func (c *MYSQLController) GetProducts(ctx context.Context, productIDs []uuid.UUID) ([]models.ProductData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
 	if len(productIDs) == 0 {
 		return nil, ErrEmptyProductIDList
 	}
 
-	tx, err := c.DBConnector.ConnectSystem()
+	var productDataList []models.ProductData
+	err := c.runInTransaction(ctx, "GetProducts", func(tx *sql.Tx) error {
+		products, err := c.DBFunctions.GetProductsByIDs(ctx, productIDs, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrProductNotFound
+			}
+			return err
+		}
+
+		productDataList, err = c.buildProductData(ctx, products, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	products, err := c.DBFunctions.GetProductsByIDs(productIDs, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
+	return productDataList, nil
+}
+
+// GetProductsByDetails returns the products whose details match filter. The list is empty if there are none.
+func (c *MYSQLController) GetProductsByDetails(ctx context.Context, filter models.DetailsFilter) ([]models.ProductData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	productDataList := make([]models.ProductData, 0)
+	err := c.runInTransaction(ctx, "GetProductsByDetails", func(tx *sql.Tx) error {
+		products, err := c.DBFunctions.GetProductsByDetails(ctx, filter, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
 			}
-			return nil, ErrProductNotFound
+			return err
 		}
+
+		productDataList, err = c.buildProductData(ctx, products, tx)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	return productDataList, nil
+}
+
+func (c *MYSQLController) buildProductData(ctx context.Context, products []models.Product, tx *sql.Tx) ([]models.ProductData, error) {
+	productDataList := make([]models.ProductData, len(products))
 	assetIDs := make([]uuid.UUID, 0)
 	detailsIDs := make([]uuid.UUID, 0)
 	for _, product := range products {
@@ -298,26 +441,27 @@ func (c *MYSQLController) GetProducts(productIDs []uuid.UUID) ([]models.ProductD
 		detailsIDs = append(detailsIDs, product.DetailsID)
 	}
 
-	details, err := c.DBFunctions.GetAssets(mysqldb.ProductDetails, detailsIDs, tx)
+	details, err := c.DBFunctions.GetAssets(ctx, mysqldb.ProductDetails, detailsIDs, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	assets, err := c.DBFunctions.GetAssets(mysqldb.ProductAssets, assetIDs, tx)
+	assets, err := c.DBFunctions.GetAssets(ctx, mysqldb.ProductAssets, assetIDs, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	productDataList := make([]models.ProductData, 0)
+	// The assets are not returned in the order of the IDs.
+	detailsByID := indexAssets(details)
+	assetsByID := indexAssets(assets)
 	for index, product := range products {
 		productData := models.ProductData{
 			ID:      product.ID,
 			Name:    product.Name,
-			Details: &details[index],
-			Assets:  &assets[index],
+			Details: detailsByID[product.DetailsID],
+			Assets:  assetsByID[product.AssetsID],
 		}
-		productDataList = append(productDataList, productData)
+		productDataList[index] = productData
 	}
-
-	return productDataList, c.DBConnector.Commit(tx)
+	return productDataList, nil
 }
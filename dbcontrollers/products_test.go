@@ -1,6 +1,7 @@
 package dbcontrollers
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -124,7 +125,7 @@ func createProductTestData() (*tests.OrderedTests, error) {
 	testCase = "existing_product"
 	expected = ProductExpectedData{
 		productData: nil,
-		err:         fmt.Errorf(ErrProductExistsString, product.Name),
+		err:         &productExistsError{name: product.Name},
 	}
 	input = ProductInputData{
 		productData: productData,
@@ -331,6 +332,7 @@ func TestCreateProduct(t *testing.T) {
 			}
 
 			output, err := dbController.CreateProduct(
+				context.Background(),
 				inputData.productData.Name,
 				&inputData.userID)
 			tests.CheckResult(output, expectedData.productData, err, expectedData.err, testCaseString, t)
@@ -359,7 +361,7 @@ func TestValidateUsers(t *testing.T) {
 				privileges: mockData.privileges,
 			}
 
-			err := dbController.validateOwnership(inputData.productUsers)
+			err := dbController.validateOwnership(context.Background(), inputData.productUsers)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
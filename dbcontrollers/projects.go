@@ -1,17 +1,16 @@
 package dbcontrollers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
-	"strings"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
 	"github.com/google/uuid"
 )
 
-var ErrProjectExistsString = "Project with name %s already exists"
+var ErrProjectExists = errors.New("Project with this name already exists")
 var ErrProjectNotFound = errors.New("The selected project not found")
 var ErrProjectViewerNotFound = errors.New("The selected project viewer not found")
 var ErrUserIsNotConnectedToAnyViewer = errors.New("User is not connected to any viewer")
@@ -21,15 +20,15 @@ var ErrNoProjectDetailsUpdate = errors.New("Details for the selected project not
 var ErrNoProjectAssetsUpdate = errors.New("Assets for the selected project not found or no change happened")
 var ErrEmptyProjectIDList = errors.New("Request does not contain any project identifiers")
 
-var ErrDuplicateEntrySubString = "Duplicate entry"
-var ErrMissingProductDBString = "Error 1452: Cannot add or update a child row: a foreign key constraint fails (`user_database`.`projects`, CONSTRAINT `projects_ibfk_1` FOREIGN KEY (`products_id`) REFERENCES `products` (`id`))"
-var ErrMissingProjectDBString = "Error 1452: Cannot add or update a child row: a foreign key constraint fails (`user_database`.`users_viewers`, CONSTRAINT `users_viewers_ibfk_2` FOREIGN KEY (`projects_id`) REFERENCES `projects` (`id`))"
-
 func (c *MYSQLController) CreateProject(
+	ctx context.Context,
 	name string,
 	visibility string,
 	owner *uuid.UUID,
 	productID *uuid.UUID) (*models.ProjectData, error) {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
 	references := make(models.DataMap)
 	asset, err := c.ModelFunctions.NewAsset(references)
 	if err != nil {
@@ -49,8 +48,7 @@ func (c *MYSQLController) CreateProject(
 		return nil, err
 	}
 
-	// Start a DB transaction and do all inserts within the same transaction to improve consistency.
-	tx, err := c.DBConnector.ConnectSystem()
+	privilege, err := c.DBFunctions.GetPrivilege(ctx, "Owner")
 	if err != nil {
 		return nil, err
 	}
@@ -59,28 +57,41 @@ func (c *MYSQLController) CreateProject(
 		UserIDArray: make([]uuid.UUID, 0),
 		UserMap:     make(map[uuid.UUID]int),
 	}
-	privilege, err := c.DBFunctions.GetPrivilege("Owner")
-	if err != nil {
-		return nil, err
-	}
+	users.UserMap[*owner] = privilege.ID
 
-	if err := c.DBFunctions.AddAsset(mysqldb.ProjectDetails, projectDetails, tx); err != nil {
-		return nil, err
-	}
+	// Do all inserts within the same transaction to improve consistency.
+	err = c.runInTransaction(ctx, "CreateProject", func(tx *sql.Tx) error {
+		if err := c.DBFunctions.AddAsset(ctx, mysqldb.ProjectDetails, projectDetails, tx); err != nil {
+			return err
+		}
 
-	if err := c.DBFunctions.AddAsset(mysqldb.ProjectAssets, asset, tx); err != nil {
-		return nil, err
-	}
+		if err := c.DBFunctions.AddAsset(ctx, mysqldb.ProjectAssets, asset, tx); err != nil {
+			return err
+		}
 
-	if err := c.DBFunctions.AddProject(project, tx); err != nil {
-		if err.Error() == ErrMissingProductDBString {
-			return nil, ErrProductNotFound
+		if err := c.DBFunctions.AddProject(ctx, project, tx); err != nil {
+			if mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "products_id") {
+				return ErrProductNotFound
+			}
+			return err
 		}
-		return nil, err
-	}
 
-	users.UserMap[*owner] = privilege.ID
-	if err := c.DBFunctions.AddProjectUsers(&project.ID, &users, tx); err != nil {
+		if err := c.DBFunctions.AddProjectUsers(ctx, &project.ID, &users, tx); err != nil {
+			return err
+		}
+
+		if err := c.audit(ctx, tx, models.ActionCreate, mysqldb.Projects, project.ID, nil, projectSnapshot(project)); err != nil {
+			return err
+		}
+		if err := c.audit(ctx, tx, models.ActionCreate, mysqldb.ProjectUsers, project.ID, nil, privilegeSnapshot(owner, privilege.ID)); err != nil {
+			return err
+		}
+
+		payload := projectSnapshot(project)
+		payload["owner_id"] = owner.String()
+		return c.publish(ctx, tx, models.EventProjectCreated, project.ID, payload)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -91,153 +102,244 @@ func (c *MYSQLController) CreateProject(
 		Assets:    asset,
 	}
 
-	return &projectData, c.DBConnector.Commit(tx)
+	return &projectData, nil
 }
 
-func (c *MYSQLController) DeleteProject(projectID *uuid.UUID) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
+func (c *MYSQLController) DeleteProject(ctx context.Context, projectID *uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	if err := c.deleteProject(projectID, tx); err != nil {
-		return err
-	}
-
-	return c.DBConnector.Commit(tx)
+	return c.runInTransaction(ctx, "DeleteProject", func(tx *sql.Tx) error {
+		if deletedAt := c.deleteTime(); !deletedAt.IsZero() {
+			return c.trashEntity(ctx, mysqldb.Projects, projectID, deletedAt, tx)
+		}
+		return c.deleteProject(ctx, projectID, tx)
+	})
 }
 
-func (c *MYSQLController) deleteProject(projectID *uuid.UUID, tx *sql.Tx) error {
-	project, err := c.DBFunctions.GetProjectByID(projectID, tx)
+func (c *MYSQLController) deleteProject(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	project, err := c.DBFunctions.GetProjectByID(ctx, projectID, tx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return ErrProjectNotFound
 		}
+		return err
 	}
 
-	if err := c.DBFunctions.DeleteProjectUsersByProjectID(projectID, tx); err != nil {
+	if err := c.DBFunctions.DeleteProjectUsersByProjectID(ctx, projectID, tx); err != nil {
 		if err == mysqldb.ErrNoProductDeleted {
 			return ErrProjectNotFound
 		}
 	}
 
-	if err := c.DBFunctions.DeleteProject(projectID, tx); err != nil {
+	viewers, err := c.DBFunctions.GetProjectViewersByProjectID(ctx, projectID, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err := c.DBFunctions.DeleteProjectViewerByProjectID(ctx, projectID, tx); err != nil && err != mysqldb.ErrNoProjectViewerDeleted {
+		return err
+	}
+
+	if err := c.auditViewerDeletes(ctx, tx, viewers); err != nil {
+		return err
+	}
+
+	if err := c.DBFunctions.DeleteProject(ctx, projectID, tx); err != nil {
 		if err == mysqldb.ErrNoProductDeleted {
 			return ErrProjectNotFound
 		}
 		return err
 	}
 
-	if err := c.DBFunctions.DeleteAsset(mysqldb.ProjectAssets, &project.AssetsID, tx); err != nil {
+	if err := c.audit(ctx, tx, models.ActionDelete, mysqldb.Projects, project.ID, projectSnapshot(project), nil); err != nil {
+		return err
+	}
+
+	if err := c.publish(ctx, tx, models.EventProjectDeleted, project.ID, projectSnapshot(project)); err != nil {
+		return err
+	}
+
+	if err := c.DBFunctions.DeleteAsset(ctx, mysqldb.ProjectAssets, &project.AssetsID, tx); err != nil {
 		return err
 	}
 
-	if err := c.DBFunctions.DeleteAsset(mysqldb.ProjectDetails, &project.DetailsID, tx); err != nil {
+	if err := c.DBFunctions.DeleteAsset(ctx, mysqldb.ProjectDetails, &project.DetailsID, tx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *MYSQLController) GetProject(projectID *uuid.UUID) (*models.ProjectData, error) {
-	tx, err := c.DBConnector.ConnectSystem()
+func (c *MYSQLController) GetProject(ctx context.Context, projectID *uuid.UUID) (*models.ProjectData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var projectData *models.ProjectData
+	err := c.runInTransaction(ctx, "GetProject", func(tx *sql.Tx) (err error) {
+		projectData, err = c.readProjectData(ctx, projectID, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	project, err := c.DBFunctions.GetProjectByID(projectID, tx)
+	return projectData, nil
+}
+
+// readProjectData reads the project with its details and assets in tx.
+func (c *MYSQLController) readProjectData(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) (*models.ProjectData, error) {
+	project, err := c.DBFunctions.GetProjectByID(ctx, projectID, tx)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
-			}
 			return nil, ErrProjectNotFound
 		}
+		return nil, err
 	}
 
-	details, err := c.DBFunctions.GetAsset(mysqldb.ProjectDetails, &project.DetailsID)
+	details, err := c.getAsset(ctx, mysqldb.ProjectDetails, &project.DetailsID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	assets, err := c.DBFunctions.GetAsset(mysqldb.ProjectAssets, &project.AssetsID)
+	assets, err := c.getAsset(ctx, mysqldb.ProjectAssets, &project.AssetsID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	projectData := models.ProjectData{
+	return &models.ProjectData{
 		ID:        project.ID,
 		ProductID: project.ProductID,
 		Details:   details,
 		Assets:    assets,
-	}
-
-	return &projectData, c.DBConnector.Commit(tx)
+	}, nil
 }
 
-func (c *MYSQLController) UpdateProjectDetails(projectData *models.ProjectData) error {
-	if err := c.DBFunctions.UpdateAsset(mysqldb.ProjectDetails, projectData.Details); err != nil {
-		if fmt.Errorf(mysqldb.ErrAssetMissing, mysqldb.ProjectDetails).Error() == err.Error() {
+func (c *MYSQLController) UpdateProjectDetails(ctx context.Context, projectData *models.ProjectData) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.updateAsset(ctx, "UpdateProjectDetails", mysqldb.ProjectDetails, projectData.Details)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
 			return ErrNoProjectDetailsUpdate
 		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return ErrAssetVersionConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (c *MYSQLController) UpdateProjectAssets(projectData *models.ProjectData) error {
-	if err := c.DBFunctions.UpdateAsset(mysqldb.ProjectAssets, projectData.Assets); err != nil {
-		if fmt.Errorf(mysqldb.ErrAssetMissing, mysqldb.ProjectAssets).Error() == err.Error() {
+func (c *MYSQLController) UpdateProjectAssets(ctx context.Context, projectData *models.ProjectData) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.updateAsset(ctx, "UpdateProjectAssets", mysqldb.ProjectAssets, projectData.Assets)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
 			return ErrNoProjectAssetsUpdate
 		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return ErrAssetVersionConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (c *MYSQLController) UpdateProjectUser(projectID *uuid.UUID, userID *uuid.UUID, privilege int) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
+// PatchProjectDetails applies patch to the details of the project and returns the patched details.
+func (c *MYSQLController) PatchProjectDetails(ctx context.Context, projectID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error) {
+	asset, err := c.patchAsset(ctx, "PatchProjectDetails", mysqldb.ProjectDetails, patch, func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error) {
+		project, err := c.DBFunctions.GetProjectByID(ctx, projectID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		return &project.DetailsID, nil
+	})
+	if errors.Is(err, mysqldb.ErrAssetMissing) {
+		return nil, ErrNoProjectDetailsUpdate
 	}
-
-	return c.DBConnector.Commit(tx)
+	return asset, err
 }
 
-func (c *MYSQLController) GetProjectsByUserID(userID *uuid.UUID) ([]models.UserProject, error) {
-	projects := make([]models.UserProject, 0)
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
+// PatchProjectAssets applies patch to the assets of the project and returns the patched assets.
+func (c *MYSQLController) PatchProjectAssets(ctx context.Context, projectID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error) {
+	asset, err := c.patchAsset(ctx, "PatchProjectAssets", mysqldb.ProjectAssets, patch, func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error) {
+		project, err := c.DBFunctions.GetProjectByID(ctx, projectID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		return &project.AssetsID, nil
+	})
+	if errors.Is(err, mysqldb.ErrAssetMissing) {
+		return nil, ErrNoProjectAssetsUpdate
 	}
+	return asset, err
+}
+
+func (c *MYSQLController) UpdateProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, privilege int) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	ownershipMap, err := c.DBFunctions.GetUserProjectIDs(userID, tx)
+	tx, err := c.DBConnector.ConnectSystem(ctx)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrNoProjectsForUser
-		}
-		return nil, err
+		return err
 	}
 
-	for projectID, privilege := range ownershipMap.ProjectMap {
-		projectID := projectID
-		project, err := c.GetProject(&projectID)
+	return c.DBConnector.Commit(tx)
+}
+
+func (c *MYSQLController) GetProjectsByUserID(ctx context.Context, userID *uuid.UUID) ([]models.UserProject, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var projects []models.UserProject
+	err := c.runInTransaction(ctx, "GetProjectsByUserID", func(tx *sql.Tx) error {
+		ownershipMap, err := c.DBFunctions.GetUserProjectIDs(ctx, userID, tx)
 		if err != nil {
-			return nil, err
+			if err == sql.ErrNoRows {
+				return ErrNoProjectsForUser
+			}
+			return err
 		}
 
-		userProject := models.UserProject{
-			ProjectData: project,
-			Privilege:   privilege,
-		}
+		projects = make([]models.UserProject, 0)
+		for projectID, privilege := range ownershipMap.ProjectMap {
+			projectID := projectID
+			project, err := c.readProjectData(ctx, &projectID, tx)
+			if err == ErrProjectNotFound {
+				// The relation of an entity in the trash is kept until it is purged.
+				continue
+			}
+			if err != nil {
+				return err
+			}
 
-		projects = append(projects, userProject)
+			userProject := models.UserProject{
+				ProjectData: project,
+				Privilege:   privilege,
+			}
+
+			projects = append(projects, userProject)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return projects, c.DBConnector.Commit(tx)
+	return projects, nil
 }
 
-func (c *MYSQLController) buildProjectData(projects []models.Project, tx *sql.Tx) ([]models.ProjectData, error) {
+func (c *MYSQLController) buildProjectData(ctx context.Context, projects []models.Project, tx *sql.Tx) ([]models.ProjectData, error) {
 	projectDataList := make([]models.ProjectData, len(projects))
 	assetIDs := make([]uuid.UUID, 0)
 	detailsIDs := make([]uuid.UUID, 0)
@@ -246,156 +348,205 @@ func (c *MYSQLController) buildProjectData(projects []models.Project, tx *sql.Tx
 		detailsIDs = append(detailsIDs, project.DetailsID)
 	}
 
-	details, err := c.DBFunctions.GetAssets(mysqldb.ProjectDetails, detailsIDs, tx)
+	details, err := c.DBFunctions.GetAssets(ctx, mysqldb.ProjectDetails, detailsIDs, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	assets, err := c.DBFunctions.GetAssets(mysqldb.ProjectAssets, assetIDs, tx)
+	assets, err := c.DBFunctions.GetAssets(ctx, mysqldb.ProjectAssets, assetIDs, tx)
 	if err != nil {
 		return nil, err
 	}
 
+	// The assets are not returned in the order of the IDs.
+	detailsByID := indexAssets(details)
+	assetsByID := indexAssets(assets)
 	for index, project := range projects {
 		projectData := models.ProjectData{
 			ID:        project.ID,
 			ProductID: project.ProductID,
-			Details:   &details[index],
-			Assets:    &assets[index],
+			Details:   detailsByID[project.DetailsID],
+			Assets:    assetsByID[project.AssetsID],
 		}
 		projectDataList[index] = projectData
 	}
 	return projectDataList, nil
 }
 
-func (c *MYSQLController) GetProjectsByProductID(productID *uuid.UUID) ([]models.ProjectData, error) {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
-	}
+func (c *MYSQLController) GetProjectsByProductID(ctx context.Context, productID *uuid.UUID) ([]models.ProjectData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
 
-	projects, err := c.DBFunctions.GetProductProjects(productID, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrNoProjectForProduct
+	var projectDataList []models.ProjectData
+	err := c.runInTransaction(ctx, "GetProjectsByProductID", func(tx *sql.Tx) error {
+		projects, err := c.DBFunctions.GetProductProjects(ctx, productID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrNoProjectForProduct
+			}
+			return err
 		}
-		return nil, err
-	}
 
-	projectDataList, err := c.buildProjectData(projects, tx)
+		projectDataList, err = c.buildProjectData(ctx, projects, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return projectDataList, c.DBConnector.Commit(tx)
+	return projectDataList, nil
 }
 
-func (c *MYSQLController) GetProjects(projectIDs []uuid.UUID) ([]models.ProjectData, error) {
+func (c *MYSQLController) GetProjects(ctx context.Context, projectIDs []uuid.UUID) ([]models.ProjectData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
 	if len(projectIDs) == 0 {
 		return nil, ErrEmptyProjectIDList
 	}
 
-	tx, err := c.DBConnector.ConnectSystem()
+	var projectDataList []models.ProjectData
+	err := c.runInTransaction(ctx, "GetProjects", func(tx *sql.Tx) error {
+		projects, err := c.DBFunctions.GetProjectsByIDs(ctx, projectIDs, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+
+		projectDataList, err = c.buildProjectData(ctx, projects, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	projects, err := c.DBFunctions.GetProjectsByIDs(projectIDs, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
+	return projectDataList, nil
+}
+
+// GetProjectsByDetails returns the projects whose details match filter. The list is empty if there are none.
+func (c *MYSQLController) GetProjectsByDetails(ctx context.Context, filter models.DetailsFilter) ([]models.ProjectData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	projectDataList := make([]models.ProjectData, 0)
+	err := c.runInTransaction(ctx, "GetProjectsByDetails", func(tx *sql.Tx) error {
+		projects, err := c.DBFunctions.GetProjectsByDetails(ctx, filter, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
 			}
-			return nil, ErrProjectNotFound
+			return err
 		}
-		return nil, err
-	}
 
-	projectDataList, err := c.buildProjectData(projects, tx)
+		projectDataList, err = c.buildProjectData(ctx, projects, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return projectDataList, c.DBConnector.Commit(tx)
+	return projectDataList, nil
 }
 
-func (c *MYSQLController) CreateProjectViewer(projectViewer *models.ProjectViewer) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
+func (c *MYSQLController) CreateProjectViewer(ctx context.Context, projectViewer *models.ProjectViewer) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	if err := c.DBFunctions.AddProjectViewer(projectViewer, tx); err != nil {
-		if err.Error() == ErrMissingProjectDBString {
-			return ErrProjectNotFound
-		} else if strings.Contains(err.Error(), ErrDuplicateEntrySubString) {
-			return ErrViewerAlreadyExists
+	return c.runInTransaction(ctx, "CreateProjectViewer", func(tx *sql.Tx) error {
+		if err := c.DBFunctions.AddProjectViewer(ctx, projectViewer, tx); err != nil {
+			if mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "projects_id") {
+				return ErrProjectNotFound
+			} else if errors.Is(err, mysqldb.ErrDuplicateEntry) {
+				return ErrViewerAlreadyExists
+			}
+			return err
 		}
-		return err
-	}
-	return c.DBConnector.Commit(tx)
+		return c.audit(ctx, tx, models.ActionCreate, mysqldb.ProjectViewers, projectViewer.ViewerID, nil, viewerSnapshot(projectViewer))
+	})
 }
 
-func (c *MYSQLController) DeleteProjectViewerByUserID(userID *uuid.UUID) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
+func (c *MYSQLController) DeleteProjectViewerByUserID(ctx context.Context, userID *uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	if err := c.DBFunctions.DeleteProjectViewerByUserID(userID, tx); err != nil {
-		return err
-	}
+	return c.runInTransaction(ctx, "DeleteProjectViewerByUserID", func(tx *sql.Tx) error {
+		viewers, err := c.DBFunctions.GetProjectViewersByUserID(ctx, userID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
 
-	return c.DBConnector.Commit(tx)
+		if err := c.DBFunctions.DeleteProjectViewerByUserID(ctx, userID, tx); err != nil {
+			return err
+		}
+		return c.auditViewerDeletes(ctx, tx, viewers)
+	})
 }
 
-func (c *MYSQLController) DeleteProjectViewerByViewerID(viewerID *uuid.UUID) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
+func (c *MYSQLController) DeleteProjectViewerByViewerID(ctx context.Context, viewerID *uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	if err := c.DBFunctions.DeleteProjectViewerByViewerID(viewerID, tx); err != nil {
-		return err
-	}
+	return c.runInTransaction(ctx, "DeleteProjectViewerByViewerID", func(tx *sql.Tx) error {
+		viewers, err := c.DBFunctions.GetProjectViewersByViewerID(ctx, viewerID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
 
-	return c.DBConnector.Commit(tx)
+		if err := c.DBFunctions.DeleteProjectViewerByViewerID(ctx, viewerID, tx); err != nil {
+			return err
+		}
+		return c.auditViewerDeletes(ctx, tx, viewers)
+	})
 }
 
-func (c *MYSQLController) GetProjectViewersByUserID(userID *uuid.UUID) (*[]models.ProjectViewer, error) {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
+// auditViewerDeletes records the deletes of the viewers read before they were deleted.
+func (c *MYSQLController) auditViewerDeletes(ctx context.Context, tx *sql.Tx, viewers []models.ProjectViewer) error {
+	for i := range viewers {
+		if err := c.audit(ctx, tx, models.ActionDelete, mysqldb.ProjectViewers, viewers[i].ViewerID, viewerSnapshot(&viewers[i]), nil); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	projectViewers, err := c.DBFunctions.GetProjectViewersByUserID(userID, tx)
-	if err != nil {
+func (c *MYSQLController) GetProjectViewersByUserID(ctx context.Context, userID *uuid.UUID) (*[]models.ProjectViewer, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var projectViewers []models.ProjectViewer
+	err := c.runInTransaction(ctx, "GetProjectViewersByUserID", func(tx *sql.Tx) error {
+		var err error
+		projectViewers, err = c.DBFunctions.GetProjectViewersByUserID(ctx, userID, tx)
 		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
-			}
-			return nil, ErrUserIsNotConnectedToAnyViewer
+			return ErrUserIsNotConnectedToAnyViewer
 		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &projectViewers, c.DBConnector.Commit(tx)
+	return &projectViewers, nil
 }
 
-func (c *MYSQLController) GetProjectViewersByViewerID(viewerID *uuid.UUID) (*[]models.ProjectViewer, error) {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
-	}
+func (c *MYSQLController) GetProjectViewersByViewerID(ctx context.Context, viewerID *uuid.UUID) (*[]models.ProjectViewer, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
 
-	projectViewers, err := c.DBFunctions.GetProjectViewersByViewerID(viewerID, tx)
-	if err != nil {
+	var projectViewers []models.ProjectViewer
+	err := c.runInTransaction(ctx, "GetProjectViewersByViewerID", func(tx *sql.Tx) error {
+		var err error
+		projectViewers, err = c.DBFunctions.GetProjectViewersByViewerID(ctx, viewerID, tx)
 		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
-			}
-			return nil, ErrProjectViewerNotFound
+			return ErrProjectViewerNotFound
 		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &projectViewers, c.DBConnector.Commit(tx)
+	return &projectViewers, nil
 }
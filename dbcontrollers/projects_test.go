@@ -1,7 +1,7 @@
 package dbcontrollers
 
 import (
-	"fmt"
+	"context"
 	"testing"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
@@ -129,14 +129,14 @@ func createProjectTestData() (*tests.OrderedTests, error) {
 	testCase = "existing_project"
 	expected = ProjectExpectedData{
 		projectData: nil,
-		err:         fmt.Errorf(ErrProjectExistsString, assets.DataMap["name"]),
+		err:         ErrProjectExists,
 	}
 
 	mock = ProjectMockData{
 		projectData: projectData,
 		project:     project,
 		privileges:  privileges,
-		err:         fmt.Errorf(ErrProjectExistsString, assets.DataMap["name"]),
+		err:         ErrProjectExists,
 	}
 
 	dataSet.TestDataSet[testCase] = tests.Data{
@@ -180,6 +180,7 @@ func TestCreateProject(t *testing.T) {
 			}
 
 			output, err := dbController.CreateProject(
+				context.Background(),
 				inputData.projectData.Assets.DataMap["name"].(string),
 				inputData.projectData.Assets.DataMap["visibility"].(string),
 				&inputData.userID,
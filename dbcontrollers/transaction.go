@@ -0,0 +1,124 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+)
+
+// Sequence of the savepoint names, so that nested savepoints never shadow each other.
+var savepointSequence uint64
+
+// Backoff limits of the transaction retries.
+const (
+	defaultTxRetryDelay = 50 * time.Millisecond
+	maxTxRetryDelay     = 2 * time.Second
+)
+
+// isRetryable reports whether a failed transaction may succeed if it is executed again from the start.
+func isRetryable(err error) bool {
+	return errors.Is(err, mysqldb.ErrDeadlock) || errors.Is(err, mysqldb.ErrLockWaitTimeout)
+}
+
+// TxRetryCount returns the number of times a transaction has been re-executed by the controller.
+func (c *MYSQLController) TxRetryCount() uint64 {
+	return atomic.LoadUint64(&c.txRetryCount)
+}
+
+// retryDelay returns the exponential backoff of the attempt with jitter added,
+// so that the transactions involved in the same deadlock do not collide again.
+func (c *MYSQLController) retryDelay(attempt int) time.Duration {
+	delay := c.TxRetryDelay
+	if delay <= 0 {
+		delay = defaultTxRetryDelay
+	}
+
+	for i := 0; i < attempt && delay < maxTxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxTxRetryDelay {
+		delay = maxTxRetryDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retry executes operation and executes it again after a backoff as long as it fails with a retryable error
+// and the configured number of retries is not used up.
+func (c *MYSQLController) retry(ctx context.Context, name string, operation func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := operation()
+		if err == nil || !isRetryable(err) || attempt >= c.TxMaxRetries {
+			return err
+		}
+
+		atomic.AddUint64(&c.txRetryCount, 1)
+		delay := c.retryDelay(attempt)
+		log.Printf("Retrying %s (attempt %d of %d) in %s: %s\n", name, attempt+1, c.TxMaxRetries, delay, err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTransaction executes unitOfWork in a new transaction and commits it if no error occurred.
+// On deadlock or lock wait timeout the whole unit of work is executed again in a fresh transaction,
+// so unitOfWork must not have side effects outside of the transaction it receives.
+func (c *MYSQLController) runInTransaction(ctx context.Context, name string, unitOfWork func(tx *sql.Tx) error) error {
+	return c.retry(ctx, name, func() error {
+		tx, err := c.DBConnector.ConnectSystem(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := unitOfWork(tx); err != nil {
+			if errRollback := c.DBConnector.Rollback(tx); errRollback != nil {
+				return fmt.Errorf("Failed to rollback changes: %s: %w", errRollback.Error(), err)
+			}
+			return err
+		}
+
+		return c.DBConnector.Commit(tx)
+	})
+}
+
+// runInSavepoint executes subOperation on tx inside a savepoint. If subOperation fails, its changes are undone
+// and its error is returned as failure, while the transaction stays usable for the caller.
+// err is returned if the whole transaction has to be rolled back, on deadlock and lock wait timeout it is then
+// executed again by runInTransaction.
+func (c *MYSQLController) runInSavepoint(ctx context.Context, tx *sql.Tx, subOperation func() error) (failure error, err error) {
+	name := fmt.Sprintf("sp_%d", atomic.AddUint64(&savepointSequence, 1))
+	if err := c.DBConnector.Savepoint(ctx, tx, name); err != nil {
+		return nil, err
+	}
+
+	if failure := subOperation(); failure != nil {
+		// A deadlock rolls back the whole MySQL transaction, so the savepoint is gone. A lock wait timeout only
+		// rolls back the statement (innodb_rollback_on_timeout is OFF by default), but it is not handled as
+		// a failure of the sub operation either: it is caused by a concurrent transaction, not by the data,
+		// so the whole transaction is retried instead of taking the fallback of a failed sub operation.
+		if isRetryable(failure) {
+			return nil, failure
+		}
+
+		if err := c.DBConnector.RollbackToSavepoint(ctx, tx, name); err != nil {
+			return nil, fmt.Errorf("Failed to rollback to savepoint: %s: %w", err.Error(), failure)
+		}
+		return failure, nil
+	}
+
+	return nil, c.DBConnector.ReleaseSavepoint(ctx, tx, name)
+}
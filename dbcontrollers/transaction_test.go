@@ -0,0 +1,195 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+type RunInTransactionExpectedData struct {
+	attempts   int
+	retryCount uint64
+	err        error
+}
+
+type RunInTransactionInputData struct {
+	maxRetries int
+	errs       []error
+}
+
+func createRunInTransactionTestData() *tests.OrderedTests {
+	dataSet := &tests.OrderedTests{
+		OrderedList: make(tests.OrderedTestList, 0),
+		TestDataSet: make(tests.DataSet),
+	}
+
+	deadlock := &mysqldb.SQLError{Kind: mysqldb.ErrDeadlock, Number: mysqldb.ErLockDeadlock, Err: errors.New("Deadlock")}
+	lockWaitTimeout := &mysqldb.SQLError{Kind: mysqldb.ErrLockWaitTimeout, Number: mysqldb.ErLockWaitTimeout, Err: errors.New("Lock wait")}
+	failure := errors.New("This is a failure test")
+
+	testCase := "no_error"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: RunInTransactionInputData{
+			maxRetries: 3,
+		},
+		Expected: RunInTransactionExpectedData{
+			attempts: 1,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "retry_until_success"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: RunInTransactionInputData{
+			maxRetries: 3,
+			errs:       []error{deadlock, lockWaitTimeout},
+		},
+		Expected: RunInTransactionExpectedData{
+			attempts:   3,
+			retryCount: 2,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "retries_used_up"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: RunInTransactionInputData{
+			maxRetries: 1,
+			errs:       []error{deadlock, deadlock, deadlock},
+		},
+		Expected: RunInTransactionExpectedData{
+			attempts:   2,
+			retryCount: 1,
+			err:        deadlock,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "not_retryable"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: RunInTransactionInputData{
+			maxRetries: 3,
+			errs:       []error{failure},
+		},
+		Expected: RunInTransactionExpectedData{
+			attempts: 1,
+			err:      failure,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	return dataSet
+}
+
+func TestRunInTransaction(t *testing.T) {
+	dataSet := createRunInTransactionTestData()
+
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			testCase := dataSet.TestDataSet[testCaseString]
+			expectedData := testCase.Expected.(RunInTransactionExpectedData)
+			inputData := testCase.Data.(RunInTransactionInputData)
+
+			controller := &MYSQLController{
+				DBFunctions:  &DBFunctionMock{},
+				DBConnector:  &DBConnectorMock{},
+				TxMaxRetries: inputData.maxRetries,
+				TxRetryDelay: time.Millisecond,
+			}
+
+			attempts := 0
+			err := controller.runInTransaction(context.Background(), testCaseString, func(tx *sql.Tx) error {
+				attempts++
+				if attempts <= len(inputData.errs) {
+					return inputData.errs[attempts-1]
+				}
+				return nil
+			})
+
+			output := RunInTransactionExpectedData{
+				attempts:   attempts,
+				retryCount: controller.TxRetryCount(),
+				err:        err,
+			}
+			tests.CheckResult(output, expectedData, err, expectedData.err, testCaseString, t)
+		})
+	}
+}
+
+func TestRunInTransactionCanceled(t *testing.T) {
+	controller := &MYSQLController{
+		DBFunctions:  &DBFunctionMock{},
+		DBConnector:  &DBConnectorMock{},
+		TxMaxRetries: 3,
+		TxRetryDelay: time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deadlock := &mysqldb.SQLError{Kind: mysqldb.ErrDeadlock, Number: mysqldb.ErLockDeadlock, Err: errors.New("Deadlock")}
+	attempts := 0
+	err := controller.runInTransaction(ctx, "canceled", func(tx *sql.Tx) error {
+		attempts++
+		return deadlock
+	})
+	tests.CheckResult(attempts, 1, err, deadlock, "canceled", t)
+}
+
+func TestRetryDelay(t *testing.T) {
+	controller := &MYSQLController{
+		TxRetryDelay: 100 * time.Millisecond,
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := controller.retryDelay(attempt)
+		upper := controller.TxRetryDelay << uint(attempt)
+		if upper > maxTxRetryDelay {
+			upper = maxTxRetryDelay
+		}
+		if delay < upper/2 || delay > upper {
+			t.Errorf("Delay %s of attempt %d is out of range [%s, %s]", delay, attempt, upper/2, upper)
+		}
+	}
+}
+
+func TestRunInSavepoint(t *testing.T) {
+	deadlock := &mysqldb.SQLError{Kind: mysqldb.ErrDeadlock, Number: mysqldb.ErLockDeadlock, Err: errors.New("Deadlock")}
+	lockWaitTimeout := &mysqldb.SQLError{Kind: mysqldb.ErrLockWaitTimeout, Number: mysqldb.ErLockWaitTimeout, Err: errors.New("Lock wait")}
+	failure := errors.New("This is a failure test")
+
+	for _, testCase := range []struct {
+		name                string
+		subOperationErr     error
+		failure             error
+		err                 error
+		savepointRolledBack bool
+	}{
+		{name: "no_error"},
+		{name: "failure", subOperationErr: failure, failure: failure, savepointRolledBack: true},
+		// The contention of the concurrent transactions is left to the retry of the whole transaction.
+		{name: "deadlock", subOperationErr: deadlock, err: deadlock},
+		{name: "lock_wait_timeout", subOperationErr: lockWaitTimeout, err: lockWaitTimeout},
+	} {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			connector := &DBConnectorMock{}
+			controller := &MYSQLController{
+				DBFunctions: &DBFunctionMock{},
+				DBConnector: connector,
+			}
+
+			failure, err := controller.runInSavepoint(context.Background(), nil, func() error {
+				return testCase.subOperationErr
+			})
+			tests.CheckResult(failure, testCase.failure, err, testCase.err, testCase.name, t)
+			tests.CheckResult(connector.rolledBackToSavepoint, testCase.savepointRolledBack, nil, nil, testCase.name, t)
+		})
+	}
+}
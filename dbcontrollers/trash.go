@@ -0,0 +1,288 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownTrashType = errors.New("Unknown entity type, expected users, products or projects")
+var ErrNotInTrash = errors.New("The selected entity is not in the trash")
+var ErrProductInTrash = errors.New("The product of the project is in the trash, restore the product first")
+
+// notFoundErrors are the errors returned if an entity of the type does not exist or is already in the trash.
+var notFoundErrors = map[string]error{
+	mysqldb.Users:    ErrUserNotFound,
+	mysqldb.Products: ErrProductNotFound,
+	mysqldb.Projects: ErrProjectNotFound,
+}
+
+// deleteTime returns the time the entities deleted now are marked with in the trash.
+// It is zero if soft delete is disabled and the deletes are permanent.
+func (c *MYSQLController) deleteTime() time.Time {
+	if !c.SoftDelete {
+		return time.Time{}
+	}
+	// The entities deleted together share the time, that is how they are restored together.
+	// The columns store whole seconds.
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func (c *MYSQLController) trashEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if err := c.DBFunctions.TrashEntity(ctx, entityType, ID, deletedAt, tx); err != nil {
+		if errors.Is(err, mysqldb.ErrEntityMissing) {
+			return notFoundErrors[entityType]
+		}
+		return err
+	}
+	if err := c.audit(ctx, tx, models.ActionTrash, entityType, *ID, nil, trashSnapshot(deletedAt)); err != nil {
+		return err
+	}
+	return c.publish(ctx, tx, trashedEvents[entityType], *ID, trashSnapshot(deletedAt))
+}
+
+// trashProduct moves the product and its projects to the trash. The projects are recorded in the audit log
+// as part of the product, they are restored or purged together with it.
+func (c *MYSQLController) trashProduct(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if err := c.trashEntity(ctx, mysqldb.Products, productID, deletedAt, tx); err != nil {
+		return err
+	}
+	return c.DBFunctions.TrashProjectsByProductID(ctx, productID, deletedAt, tx)
+}
+
+// restoreEntity takes the entity out of the trash together with the entities deleted with it:
+// the projects of a product and the owned products and projects of a user.
+func (c *MYSQLController) restoreEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if err := c.DBFunctions.RestoreEntity(ctx, entityType, ID, deletedAt, tx); err != nil {
+		return err
+	}
+
+	switch entityType {
+	case mysqldb.Products:
+		return c.DBFunctions.RestoreProjectsByProductID(ctx, ID, deletedAt, tx)
+	case mysqldb.Users:
+		userProducts, err := c.DBFunctions.GetUserProductIDs(ctx, ID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		if userProducts != nil {
+			for _, productID := range userProducts.ProductIDArray {
+				productID := productID
+				// Products not deleted with the user are left as they are.
+				if err := c.restoreEntity(ctx, mysqldb.Products, &productID, deletedAt, tx); err != nil && !errors.Is(err, mysqldb.ErrEntityMissing) {
+					return err
+				}
+			}
+		}
+
+		userProjects, err := c.DBFunctions.GetUserProjectIDs(ctx, ID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		for _, projectID := range userProjects.ProjectIDArray {
+			projectID := projectID
+			// The projects of the restored products are back already.
+			if err := c.DBFunctions.RestoreEntity(ctx, mysqldb.Projects, &projectID, deletedAt, tx); err != nil && !errors.Is(err, mysqldb.ErrEntityMissing) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetTrash lists the entities of entityType in the trash, the oldest first. All types are listed if entityType is empty.
+func (c *MYSQLController) GetTrash(ctx context.Context, entityType string) ([]models.TrashEntry, error) {
+	entityTypes := []string{mysqldb.Users, mysqldb.Products, mysqldb.Projects}
+	if entityType != "" {
+		if !mysqldb.IsTrashType(entityType) {
+			return nil, ErrUnknownTrashType
+		}
+		entityTypes = []string{entityType}
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	entries := make([]models.TrashEntry, 0)
+	err := c.runInTransaction(ctx, "GetTrash", func(tx *sql.Tx) error {
+		entries = entries[:0]
+		now := time.Now().UTC()
+		for _, entityType := range entityTypes {
+			typeEntries, err := c.DBFunctions.GetTrash(ctx, entityType, now, tx)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					continue
+				}
+				return err
+			}
+			entries = append(entries, typeEntries...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RestoreFromTrash takes the entity out of the trash with the entities deleted together with it.
+// A project can only be restored if its product is not in the trash.
+func (c *MYSQLController) RestoreFromTrash(ctx context.Context, entityType string, ID *uuid.UUID) error {
+	if !mysqldb.IsTrashType(entityType) {
+		return ErrUnknownTrashType
+	}
+
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	return c.runInTransaction(ctx, "RestoreFromTrash", func(tx *sql.Tx) error {
+		entry, err := c.DBFunctions.GetTrashEntry(ctx, entityType, ID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrNotInTrash
+			}
+			return err
+		}
+
+		if err := c.restoreEntity(ctx, entityType, ID, entry.DeletedAt, tx); err != nil {
+			return err
+		}
+
+		if err := c.audit(ctx, tx, models.ActionRestore, entityType, *ID, trashSnapshot(entry.DeletedAt), nil); err != nil {
+			return err
+		}
+
+		if err := c.publish(ctx, tx, restoredEvents[entityType], *ID, trashSnapshot(entry.DeletedAt)); err != nil {
+			return err
+		}
+
+		if entityType != mysqldb.Projects {
+			return nil
+		}
+
+		project, err := c.DBFunctions.GetProjectByID(ctx, ID, tx)
+		if err != nil {
+			return err
+		}
+
+		if _, err := c.DBFunctions.GetProductByID(ctx, &project.ProductID, tx); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrProductInTrash
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// restoreTrashEntry takes the entity out of the trash with the entities deleted together with it,
+// if it is in the trash.
+func (c *MYSQLController) restoreTrashEntry(ctx context.Context, entityType string, ID *uuid.UUID, tx *sql.Tx) error {
+	entry, err := c.DBFunctions.GetTrashEntry(ctx, entityType, ID, tx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	return c.restoreEntity(ctx, entityType, ID, entry.DeletedAt, tx)
+}
+
+// purgeEntry deletes the entity of the trash entry permanently. The entity is restored first,
+// so it is removed by the same cascade as a permanent delete.
+func (c *MYSQLController) purgeEntry(ctx context.Context, entry *models.TrashEntry, tx *sql.Tx) error {
+	if err := c.restoreEntity(ctx, entry.Type, &entry.ID, entry.DeletedAt, tx); err != nil {
+		return err
+	}
+
+	switch entry.Type {
+	case mysqldb.Projects:
+		return c.deleteProject(ctx, &entry.ID, tx)
+	case mysqldb.Products:
+		projects, err := c.DBFunctions.GetProductProjects(ctx, &entry.ID, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		for _, project := range projects {
+			project := project
+			if err := c.deleteProject(ctx, &project.ID, tx); err != nil {
+				return err
+			}
+		}
+		return c.deleteProduct(ctx, &entry.ID, tx)
+	default:
+		return c.deleteUser(ctx, &entry.ID, nil, time.Time{}, tx)
+	}
+}
+
+// PurgeTrash deletes the entities that have been in the trash longer than the retention permanently
+// and returns their number. An entity failing to be purged is logged and kept for the next run.
+func (c *MYSQLController) PurgeTrash(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().UTC().Add(-c.TrashRetention)
+	purged := 0
+	// Projects go first and users last, so the trashed children are gone by the time their parent is purged.
+	for _, entityType := range []string{mysqldb.Projects, mysqldb.Products, mysqldb.Users} {
+		var entries []models.TrashEntry
+		readCtx, cancel := c.readContext(ctx)
+		err := c.runInTransaction(readCtx, "PurgeTrash", func(tx *sql.Tx) (err error) {
+			entries, err = c.DBFunctions.GetTrash(readCtx, entityType, deletedBefore, tx)
+			return err
+		})
+		cancel()
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return purged, err
+		}
+
+		for _, entry := range entries {
+			entry := entry
+			writeCtx, cancel := c.writeContext(ctx)
+			err := c.runInTransaction(writeCtx, "PurgeTrash", func(tx *sql.Tx) error {
+				return c.purgeEntry(writeCtx, &entry, tx)
+			})
+			cancel()
+			if err != nil {
+				log.Printf("Failed to purge %s %s from the trash, skipping it: %s\n", entry.Type, entry.ID, err.Error())
+				continue
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// RunTrashPurge purges the trash every interval until ctx is cancelled.
+func (c *MYSQLController) RunTrashPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := c.PurgeTrash(ctx)
+			if err != nil {
+				log.Printf("Failed to purge the trash: %s\n", err.Error())
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d entities from the trash\n", purged)
+			}
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package dbcontrollers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"time"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
@@ -11,7 +15,7 @@ import (
 )
 
 var ErrDuplicateEmailEntry = errors.New("User with this email already exists")
-var ErrDuplicateNameEntry = errors.New("User with this name already exists")
+var ErrDuplicateNameEntry = mysqldb.ErrDuplicateUserNameEntry
 var ErrUserNotFound = errors.New("The selected user not found")
 var ErrInvalidEmailOrPasswd = errors.New("Invalid email or password")
 var ErrNoProductsForUser = errors.New("This user has no products")
@@ -20,13 +24,37 @@ var ErrProductUserNotAssociated = errors.New("Unable to associate the product wi
 var ErrNoUserSetttingsUpdate = errors.New("Settings for the selected user not found or no change happened")
 var ErrNoUserAssetsUpdate = errors.New("Assets for the selected user not found or no change happened")
 var ErrEmptyUserIDList = errors.New("Request does not contain any user identifiers")
+var ErrOwnershipTransferFailed = errors.New("Failed to transfer the ownership to the nominated user")
 
-var ErrMissingUserDBString = "Error 1452: Cannot add or update a child row: a foreign key constraint fails (`user_database`.`users_products`, CONSTRAINT `users_products_ibfk_2` FOREIGN KEY (`users_id`) REFERENCES `users` (`id`))"
+// OwnershipTransferError lists the products and projects of a deleted user that could not be handed over
+// to their nominated owner. The user is not deleted then, errors.Is matches it with ErrOwnershipTransferFailed.
+type OwnershipTransferError struct {
+	ProductIDs []uuid.UUID
+	ProjectIDs []uuid.UUID
+}
+
+func (e *OwnershipTransferError) Error() string {
+	return fmt.Sprintf("%s, products: %v, projects: %v", ErrOwnershipTransferFailed.Error(), e.ProductIDs, e.ProjectIDs)
+}
+
+func (e *OwnershipTransferError) Unwrap() error {
+	return ErrOwnershipTransferFailed
+}
+
+// sortIDs orders the IDs by their text form, so the reported IDs do not depend on the map iteration order.
+func sortIDs(IDs []uuid.UUID) {
+	sort.Slice(IDs, func(i, j int) bool {
+		return IDs[i].String() < IDs[j].String()
+	})
+}
 
 func (c *MYSQLController) CreateUser(
+	ctx context.Context,
 	name string,
 	email string,
 	passwd []byte) (*models.UserData, error) {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
 	references := make(models.DataMap)
 	asset, err := c.ModelFunctions.NewAsset(references)
@@ -45,40 +73,41 @@ func (c *MYSQLController) CreateUser(
 		return nil, err
 	}
 
-	// Start a DB transaction and do all inserts within the same transaction to improve consistency.
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
-	}
-
-	existingUser, err := c.DBFunctions.GetUser(mysqldb.ByEmail, email, tx)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, err
-	}
+	// Do all inserts within the same transaction to improve consistency.
+	err = c.runInTransaction(ctx, "CreateUser", func(tx *sql.Tx) error {
+		existingUser, err := c.DBFunctions.GetUser(ctx, mysqldb.ByEmail, email, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
 
-	if existingUser != nil {
-		if err := c.DBConnector.Rollback(tx); err != nil {
-			return nil, err
+		if existingUser != nil {
+			return ErrDuplicateEmailEntry
 		}
-		return nil, ErrDuplicateEmailEntry
-	}
 
-	if err := c.DBFunctions.AddAsset(mysqldb.UserAssets, asset, tx); err != nil {
-		return nil, err
-	}
+		if err := c.DBFunctions.AddAsset(ctx, mysqldb.UserAssets, asset, tx); err != nil {
+			return err
+		}
 
-	if err := c.DBFunctions.AddAsset(mysqldb.UserSettings, userSettings, tx); err != nil {
-		return nil, err
-	}
+		if err := c.DBFunctions.AddAsset(ctx, mysqldb.UserSettings, userSettings, tx); err != nil {
+			return err
+		}
 
-	if err := c.DBFunctions.AddUser(user, tx); err != nil {
-		errDuplicateName := fmt.Errorf(mysqldb.ErrSQLDuplicateUserNameEntryString, user.Name)
-		if err.Error() == errDuplicateName.Error() {
-			return nil, ErrDuplicateNameEntry
+		if err := c.DBFunctions.AddUser(ctx, user, tx); err != nil {
+			switch {
+			case mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "email"):
+				return ErrDuplicateEmailEntry
+			case mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "name"):
+				return ErrDuplicateNameEntry
+			}
+			return err
 		}
-		if err.Error() == ErrMissingUserDBString {
-			return nil, ErrUserNotFound
+
+		if err := c.audit(ctx, tx, models.ActionCreate, mysqldb.Users, user.ID, nil, userSnapshot(user)); err != nil {
+			return err
 		}
+		return c.publish(ctx, tx, models.EventUserCreated, user.ID, userSnapshot(user))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -91,335 +120,664 @@ func (c *MYSQLController) CreateUser(
 		Assets:   asset,
 	}
 
-	return &userData, c.DBConnector.Commit(tx)
+	return &userData, nil
 }
 
-func (c *MYSQLController) DeleteUser(ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID) error {
-	tx, err := c.DBConnector.ConnectSystem()
+func (c *MYSQLController) DeleteUser(ctx context.Context, ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	return c.runInTransaction(ctx, "DeleteUser", func(tx *sql.Tx) error {
+		return c.deleteUser(ctx, ID, nominatedOwners, c.deleteTime(), tx)
+	})
+}
+
+// EraseUser deletes the user permanently like DeleteUser without soft delete, the trash is bypassed
+// even if soft delete is enabled, so nothing of the user is kept until the purge.
+// A user already in the trash is erased together with the entities trashed with it.
+func (c *MYSQLController) EraseUser(ctx context.Context, ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	return c.runInTransaction(ctx, "EraseUser", func(tx *sql.Tx) error {
+		if err := c.restoreUserTrash(ctx, ID, nominatedOwners, tx); err != nil {
+			return err
+		}
+		return c.deleteUser(ctx, ID, nominatedOwners, time.Time{}, tx)
+	})
+}
+
+// restoreUserTrash takes the user and the products and projects it owns out of the trash,
+// so that the permanent delete reaches them. The ones taken over by a nominated owner stay in the trash.
+func (c *MYSQLController) restoreUserTrash(ctx context.Context, ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID, tx *sql.Tx) error {
+	if err := c.restoreTrashEntry(ctx, mysqldb.Users, ID, tx); err != nil {
+		return err
+	}
+
+	privileges, err := c.DBFunctions.GetPrivileges(ctx)
 	if err != nil {
 		return err
 	}
 
+	userProducts, err := c.DBFunctions.GetUserProductIDs(ctx, ID, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if userProducts != nil {
+		for productID, privilege := range userProducts.ProductMap {
+			productID := productID
+			if _, ok := nominatedOwners[productID]; ok || !privileges.IsOwnerPrivilege(privilege) {
+				continue
+			}
+			if err := c.restoreTrashEntry(ctx, mysqldb.Products, &productID, tx); err != nil {
+				return err
+			}
+		}
+	}
+
+	userProjects, err := c.DBFunctions.GetUserProjectIDs(ctx, ID, tx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	for projectID, privilege := range userProjects.ProjectMap {
+		projectID := projectID
+		if _, ok := nominatedOwners[projectID]; ok || !privileges.IsOwnerPrivilege(privilege) {
+			continue
+		}
+		if err := c.restoreTrashEntry(ctx, mysqldb.Projects, &projectID, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUser hands the owned products and projects over to the nominated owners, nominatedOwners maps
+// the product and project IDs to the new owners. The rest of the owned products and projects are deleted
+// with the user together with the memberships, project viewers and owned viewers of the user.
+// If any ownership transfer fails, nothing is deleted and an OwnershipTransferError lists every product and project
+// whose transfer failed, the user can not be deleted while it still owns them.
+// If deletedAt is set, the user and its products and projects are moved to the trash instead,
+// the memberships and viewers are kept until the user is purged.
+func (c *MYSQLController) deleteUser(ctx context.Context, ID *uuid.UUID, nominatedOwners map[uuid.UUID]uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
 	// Valid user
-	user, err := c.DBFunctions.GetUser(mysqldb.ByID, ID, tx)
+	user, err := c.DBFunctions.GetUser(ctx, mysqldb.ByID, ID, tx)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return err
-			}
 			return ErrUserNotFound
 		}
 		return err
 	}
 
-	// Has products?
-	userProducts, err := c.DBFunctions.GetUserProductIDs(&user.ID, tx)
+	privileges, err := c.DBFunctions.GetPrivileges(ctx)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			return err
-		}
+		return err
 	}
 
-	if userProducts != nil {
-		// Handle all products
-		for productID, privilege := range userProducts.ProductMap {
-			privileges, err := c.DBFunctions.GetPrivileges()
-			if err != nil {
-				return err
-			}
+	failedProducts, err := c.deleteUserProducts(ctx, ID, nominatedOwners, privileges, deletedAt, tx)
+	if err != nil {
+		return err
+	}
 
-			if !privileges.IsOwnerPrivilege(privilege) {
-				continue
-			}
+	// The projects of the deleted products are gone by now.
+	failedProjects, err := c.deleteUserProjects(ctx, ID, nominatedOwners, privileges, deletedAt, tx)
+	if err != nil {
+		return err
+	}
 
-			productID := productID
-			// Check nominated owner
-			nominated, hasNominatedOwner := nominatedOwners[productID]
-			if nominatedOwners == nil || !hasNominatedOwner {
-				if err := c.DBFunctions.DeleteProjectsByProductID(&productID, tx); err != nil && err != mysqldb.ErrNoProjectDeleted {
-					return err
-				}
+	if len(failedProducts) > 0 || len(failedProjects) > 0 {
+		sortIDs(failedProducts)
+		sortIDs(failedProjects)
+		return &OwnershipTransferError{ProductIDs: failedProducts, ProjectIDs: failedProjects}
+	}
+
+	if !deletedAt.IsZero() {
+		return c.trashEntity(ctx, mysqldb.Users, &user.ID, deletedAt, tx)
+	}
+
+	if err := c.deleteUserViewers(ctx, ID, tx); err != nil {
+		return err
+	}
+
+	if err := c.DBFunctions.DeleteUser(ctx, &user.ID, tx); err != nil {
+		return err
+	}
+
+	if err := c.audit(ctx, tx, models.ActionDelete, mysqldb.Users, user.ID, userSnapshot(user), nil); err != nil {
+		return err
+	}
+
+	if err := c.publish(ctx, tx, models.EventUserDeleted, user.ID, userSnapshot(user)); err != nil {
+		return err
+	}
 
-				if err := c.deleteProduct(&productID, tx); err != nil {
+	if err := c.DBFunctions.DeleteAsset(ctx, mysqldb.UserAssets, &user.AssetsID, tx); err != nil {
+		return err
+	}
+
+	return c.DBFunctions.DeleteAsset(ctx, mysqldb.UserSettings, &user.SettingsID, tx)
+}
+
+func (c *MYSQLController) deleteUserProducts(
+	ctx context.Context,
+	ID *uuid.UUID,
+	nominatedOwners map[uuid.UUID]uuid.UUID,
+	privileges models.Privileges,
+	deletedAt time.Time,
+	tx *sql.Tx) ([]uuid.UUID, error) {
+	userProducts, err := c.DBFunctions.GetUserProductIDs(ctx, ID, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if userProducts == nil {
+		return nil, nil
+	}
+
+	var failed []uuid.UUID
+	for productID, privilege := range userProducts.ProductMap {
+		productID := productID
+		nominated, hasNominatedOwner := nominatedOwners[productID]
+		switch {
+		case !privileges.IsOwnerPrivilege(privilege):
+			// The memberships are kept while the user is in the trash.
+			if !deletedAt.IsZero() {
+				continue
+			}
+			if err := c.removeProductUser(ctx, &productID, ID, privilege, tx); err != nil {
+				return nil, err
+			}
+		case hasNominatedOwner:
+			// Transfer ownership of the product. A failing transfer is undone and the product is kept with its owner,
+			// the rest of the products and projects are still tried, so every failing transfer is reported.
+			failure, err := c.runInSavepoint(ctx, tx, func() error {
+				if err := c.DBFunctions.UpdateUsersProducts(ctx, &nominated, &productID, privilege, tx); err != nil {
 					return err
 				}
-			} else {
-				// Transfer ownership of the product
-				if err := c.DBFunctions.UpdateUsersProducts(&nominated, &productID, 0, tx); err != nil {
+				if err := c.DBFunctions.DeleteProductUser(ctx, &productID, ID, tx); err != nil {
 					return err
 				}
-				if err := c.DBFunctions.DeleteProductUser(&productID, ID, tx); err != nil {
-					if err == mysqldb.ErrNoUserWithProduct {
-						return ErrProductUserNotAssociated
-					}
+				if err := c.audit(ctx, tx, models.ActionUpdate, mysqldb.ProductUsers, productID, privilegeSnapshot(ID, privilege), privilegeSnapshot(&nominated, privilege)); err != nil {
 					return err
 				}
+				return c.publish(ctx, tx, models.EventProductOwnerChanged, productID, ownerChangePayload(ID, &nominated))
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if failure != nil {
+				log.Printf("Failed to transfer product %s to user %s: %s\n", productID, nominated, failure.Error())
+				failed = append(failed, productID)
+			}
+		default:
+			if err := c.deleteOwnedProduct(ctx, &productID, deletedAt, tx); err != nil {
+				return nil, err
 			}
 		}
 	}
+	return failed, nil
+}
 
-	if err := c.DBFunctions.DeleteUser(&user.ID, tx); err != nil {
-		return err
+// deleteOwnedProduct deletes the product of a deleted owner together with its projects,
+// or moves it to the trash if deletedAt is set.
+func (c *MYSQLController) deleteOwnedProduct(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if !deletedAt.IsZero() {
+		// Products already in the trash keep their own delete time.
+		if err := c.trashProduct(ctx, productID, deletedAt, tx); err != nil && err != ErrProductNotFound {
+			return err
+		}
+		return nil
 	}
 
-	if err := c.DBFunctions.DeleteAsset(mysqldb.UserAssets, &user.AssetsID, tx); err != nil {
+	projects, err := c.DBFunctions.GetProductProjects(ctx, productID, tx)
+	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
 
-	if err := c.DBFunctions.DeleteAsset(mysqldb.UserSettings, &user.SettingsID, tx); err != nil {
-		return err
+	for _, project := range projects {
+		project := project
+		if err := c.deleteProject(ctx, &project.ID, tx); err != nil {
+			return err
+		}
 	}
 
-	return c.DBConnector.Commit(tx)
+	return c.deleteProduct(ctx, productID, tx)
 }
 
-func (c *MYSQLController) GetUser(userID *uuid.UUID) (*models.UserData, error) {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
+func (c *MYSQLController) deleteUserProjects(
+	ctx context.Context,
+	ID *uuid.UUID,
+	nominatedOwners map[uuid.UUID]uuid.UUID,
+	privileges models.Privileges,
+	deletedAt time.Time,
+	tx *sql.Tx) ([]uuid.UUID, error) {
+	userProjects, err := c.DBFunctions.GetUserProjectIDs(ctx, ID, tx)
+	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 
-	user, err := c.DBFunctions.GetUser(mysqldb.ByID, *userID, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
+	if userProjects == nil {
+		return nil, nil
+	}
+
+	var failed []uuid.UUID
+	for projectID, privilege := range userProjects.ProjectMap {
+		projectID := projectID
+		nominated, hasNominatedOwner := nominatedOwners[projectID]
+		switch {
+		case !privileges.IsOwnerPrivilege(privilege):
+			// The memberships are kept while the user is in the trash.
+			if !deletedAt.IsZero() {
+				continue
+			}
+			if err := c.removeProjectUser(ctx, &projectID, ID, privilege, tx); err != nil {
+				return nil, err
+			}
+		case hasNominatedOwner:
+			// Transfer ownership of the project, a failing transfer is handled as for the products.
+			failure, err := c.runInSavepoint(ctx, tx, func() error {
+				if err := c.DBFunctions.UpdateUsersProjects(ctx, &nominated, &projectID, privilege, tx); err != nil {
+					return err
+				}
+				if err := c.DBFunctions.DeleteProjectUser(ctx, &projectID, ID, tx); err != nil {
+					return err
+				}
+				if err := c.audit(ctx, tx, models.ActionUpdate, mysqldb.ProjectUsers, projectID, privilegeSnapshot(ID, privilege), privilegeSnapshot(&nominated, privilege)); err != nil {
+					return err
+				}
+				return c.publish(ctx, tx, models.EventProjectOwnerChanged, projectID, ownerChangePayload(ID, &nominated))
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if failure != nil {
+				log.Printf("Failed to transfer project %s to user %s: %s\n", projectID, nominated, failure.Error())
+				failed = append(failed, projectID)
+			}
+		default:
+			if err := c.deleteOwnedProject(ctx, &projectID, deletedAt, tx); err != nil {
 				return nil, err
 			}
-			return nil, ErrUserNotFound
 		}
-		return nil, err
 	}
+	return failed, nil
+}
 
-	settings, err := c.DBFunctions.GetAsset(mysqldb.UserSettings, &user.SettingsID)
-	if err != nil {
-		return nil, err
+// deleteOwnedProject deletes the project of a deleted owner, or moves it to the trash if deletedAt is set.
+func (c *MYSQLController) deleteOwnedProject(ctx context.Context, projectID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if deletedAt.IsZero() {
+		return c.deleteProject(ctx, projectID, tx)
 	}
 
-	assets, err := c.DBFunctions.GetAsset(mysqldb.UserAssets, &user.AssetsID)
-	if err != nil {
-		return nil, err
+	// Projects already in the trash keep their own delete time.
+	if err := c.trashEntity(ctx, mysqldb.Projects, projectID, deletedAt, tx); err != nil && err != ErrProjectNotFound {
+		return err
 	}
+	return nil
+}
 
-	userData := models.UserData{
-		ID:       user.ID,
-		Name:     user.Name,
-		Email:    user.Email,
-		Password: user.Password,
-		Settings: settings,
-		Assets:   assets,
+// deleteUserViewers deletes the project viewers of the user and the viewers the user owns
+// together with their project viewers of the other users.
+func (c *MYSQLController) deleteUserViewers(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	viewerIDs, err := c.DBFunctions.GetViewerIDsByOwnerID(ctx, ID, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	for _, viewerID := range viewerIDs {
+		viewerID := viewerID
+		viewers, err := c.DBFunctions.GetProjectViewersByViewerID(ctx, &viewerID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return err
+		}
+
+		if err := c.DBFunctions.DeleteProjectViewerByViewerID(ctx, &viewerID, tx); err != nil {
+			return err
+		}
+		if err := c.auditViewerDeletes(ctx, tx, viewers); err != nil {
+			return err
+		}
+	}
+
+	viewers, err := c.DBFunctions.GetProjectViewersByUserID(ctx, ID, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err := c.DBFunctions.DeleteProjectViewerByUserID(ctx, ID, tx); err != nil &&
+		err != mysqldb.ErrNoProjectViewerDeleted && err != mysqldb.ErrNoViewerDeleted {
+		return err
+	}
+	if err := c.auditViewerDeletes(ctx, tx, viewers); err != nil {
+		return err
 	}
 
-	return &userData, c.DBConnector.Commit(tx)
+	// Owned viewers without any project viewer left are not reached by DeleteProjectViewerByUserID.
+	if err := c.DBFunctions.DeleteViewerByOwnerID(ctx, ID, tx); err != nil && err != mysqldb.ErrNoViewerDeleted {
+		return err
+	}
+	return nil
+}
+
+// removeProductUser removes the membership of the user from the product.
+func (c *MYSQLController) removeProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	if err := c.DBFunctions.DeleteProductUser(ctx, productID, userID, tx); err != nil {
+		if err == mysqldb.ErrNoUserWithProduct {
+			return ErrProductUserNotAssociated
+		}
+		return err
+	}
+	if err := c.audit(ctx, tx, models.ActionDelete, mysqldb.ProductUsers, *productID, privilegeSnapshot(userID, privilege), nil); err != nil {
+		return err
+	}
+	return c.publish(ctx, tx, models.EventProductUserRemoved, *productID, models.DataMap{"user_id": userID.String()})
+}
+
+// removeProjectUser removes the membership of the user from the project.
+func (c *MYSQLController) removeProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	if err := c.DBFunctions.DeleteProjectUser(ctx, projectID, userID, tx); err != nil {
+		if err == mysqldb.ErrNoUserWithProject {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return c.audit(ctx, tx, models.ActionDelete, mysqldb.ProjectUsers, *projectID, privilegeSnapshot(userID, privilege), nil)
 }
 
-func (c *MYSQLController) GetUserByEmail(email string) (*models.UserData, error) {
-	tx, err := c.DBConnector.ConnectSystem()
+func (c *MYSQLController) getUserData(ctx context.Context, queryType int, keyValue interface{}) (*models.UserData, error) {
+	var userData *models.UserData
+	err := c.runInTransaction(ctx, "GetUser", func(tx *sql.Tx) (err error) {
+		userData, err = c.readUserData(ctx, queryType, keyValue, tx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := c.DBFunctions.GetUser(mysqldb.ByEmail, email, tx)
+	return userData, nil
+}
+
+// readUserData reads the user with its settings and assets in tx.
+func (c *MYSQLController) readUserData(ctx context.Context, queryType int, keyValue interface{}, tx *sql.Tx) (*models.UserData, error) {
+	user, err := c.DBFunctions.GetUser(ctx, queryType, keyValue, tx)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
-			}
 			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 
-	settings, err := c.DBFunctions.GetAsset(mysqldb.UserSettings, &user.SettingsID)
+	settings, err := c.getAsset(ctx, mysqldb.UserSettings, &user.SettingsID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	assets, err := c.DBFunctions.GetAsset(mysqldb.UserAssets, &user.AssetsID)
+	assets, err := c.getAsset(ctx, mysqldb.UserAssets, &user.AssetsID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	userData := models.UserData{
+	return &models.UserData{
 		ID:       user.ID,
 		Name:     user.Name,
 		Email:    user.Email,
 		Password: user.Password,
 		Settings: settings,
 		Assets:   assets,
-	}
+	}, nil
+}
+
+func (c *MYSQLController) GetUser(ctx context.Context, userID *uuid.UUID) (*models.UserData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
 
-	return &userData, c.DBConnector.Commit(tx)
+	return c.getUserData(ctx, mysqldb.ByID, *userID)
 }
 
-func (c *MYSQLController) GetUsers(userIDs []uuid.UUID) ([]models.UserData, error) {
+func (c *MYSQLController) GetUserByEmail(ctx context.Context, email string) (*models.UserData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	return c.getUserData(ctx, mysqldb.ByEmail, email)
+}
+
+func (c *MYSQLController) GetUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.UserData, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
 	if len(userIDs) == 0 {
 		return nil, ErrEmptyUserIDList
 	}
 
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
-	}
-
-	users, err := c.DBFunctions.GetUsersByIDs(userIDs, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return nil, err
+	var userDataList []models.UserData
+	err := c.runInTransaction(ctx, "GetUsers", func(tx *sql.Tx) error {
+		users, err := c.DBFunctions.GetUsersByIDs(ctx, userIDs, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
 			}
-			return nil, ErrUserNotFound
+			return err
 		}
-		return nil, err
-	}
 
-	assetIDs := make([]uuid.UUID, 0)
-	settingsIDs := make([]uuid.UUID, 0)
-	for _, user := range users {
-		assetIDs = append(assetIDs, user.AssetsID)
-		settingsIDs = append(settingsIDs, user.SettingsID)
-	}
+		assetIDs := make([]uuid.UUID, 0)
+		settingsIDs := make([]uuid.UUID, 0)
+		for _, user := range users {
+			assetIDs = append(assetIDs, user.AssetsID)
+			settingsIDs = append(settingsIDs, user.SettingsID)
+		}
 
-	settings, err := c.DBFunctions.GetAssets(mysqldb.UserSettings, settingsIDs, tx)
-	if err != nil {
-		return nil, err
-	}
+		settings, err := c.DBFunctions.GetAssets(ctx, mysqldb.UserSettings, settingsIDs, tx)
+		if err != nil {
+			return err
+		}
 
-	assets, err := c.DBFunctions.GetAssets(mysqldb.UserAssets, assetIDs, tx)
-	if err != nil {
-		return nil, err
-	}
+		assets, err := c.DBFunctions.GetAssets(ctx, mysqldb.UserAssets, assetIDs, tx)
+		if err != nil {
+			return err
+		}
 
-	userDataList := make([]models.UserData, 0)
-	for index, user := range users {
-		userData := models.UserData{
-			ID:       user.ID,
-			Name:     user.Name,
-			Email:    user.Email,
-			Password: user.Password,
-			Settings: &settings[index],
-			Assets:   &assets[index],
+		userDataList = make([]models.UserData, 0)
+		for index, user := range users {
+			userData := models.UserData{
+				ID:       user.ID,
+				Name:     user.Name,
+				Email:    user.Email,
+				Password: user.Password,
+				Settings: &settings[index],
+				Assets:   &assets[index],
+			}
+			userDataList = append(userDataList, userData)
 		}
-		userDataList = append(userDataList, userData)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return userDataList, c.DBConnector.Commit(tx)
+	return userDataList, nil
 }
 
-func (c *MYSQLController) UpdateUserSettings(userData *models.UserData) error {
-	if err := c.DBFunctions.UpdateAsset(mysqldb.UserSettings, userData.Settings); err != nil {
-		if fmt.Errorf(mysqldb.ErrAssetMissing, mysqldb.UserSettings).Error() == err.Error() {
+func (c *MYSQLController) UpdateUserSettings(ctx context.Context, userData *models.UserData) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.updateAsset(ctx, "UpdateUserSettings", mysqldb.UserSettings, userData.Settings)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
 			return ErrNoUserSetttingsUpdate
 		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return ErrAssetVersionConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (c *MYSQLController) UpdateUserAssets(userData *models.UserData) error {
-	if err := c.DBFunctions.UpdateAsset(mysqldb.UserAssets, userData.Assets); err != nil {
-		if fmt.Errorf(mysqldb.ErrAssetMissing, mysqldb.UserAssets).Error() == err.Error() {
+func (c *MYSQLController) UpdateUserAssets(ctx context.Context, userData *models.UserData) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.updateAsset(ctx, "UpdateUserAssets", mysqldb.UserAssets, userData.Assets)
+	if err != nil {
+		if errors.Is(err, mysqldb.ErrAssetMissing) {
 			return ErrNoUserAssetsUpdate
 		}
+		if errors.Is(err, mysqldb.ErrVersionConflict) {
+			return ErrAssetVersionConflict
+		}
 		return err
 	}
 	return nil
 }
 
+// PatchUserSettings applies patch to the settings of the user and returns the patched settings.
+func (c *MYSQLController) PatchUserSettings(ctx context.Context, userID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error) {
+	asset, err := c.patchAsset(ctx, "PatchUserSettings", mysqldb.UserSettings, patch, func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error) {
+		user, err := c.DBFunctions.GetUser(ctx, mysqldb.ByID, userID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		return &user.SettingsID, nil
+	})
+	if errors.Is(err, mysqldb.ErrAssetMissing) {
+		return nil, ErrNoUserSetttingsUpdate
+	}
+	return asset, err
+}
+
+// PatchUserAssets applies patch to the assets of the user and returns the patched assets.
+func (c *MYSQLController) PatchUserAssets(ctx context.Context, userID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error) {
+	asset, err := c.patchAsset(ctx, "PatchUserAssets", mysqldb.UserAssets, patch, func(ctx context.Context, tx *sql.Tx) (*uuid.UUID, error) {
+		user, err := c.DBFunctions.GetUser(ctx, mysqldb.ByID, userID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		return &user.AssetsID, nil
+	})
+	if errors.Is(err, mysqldb.ErrAssetMissing) {
+		return nil, ErrNoUserAssetsUpdate
+	}
+	return asset, err
+}
+
 func (c *MYSQLController) Authenticate(
+	ctx context.Context,
 	userID *uuid.UUID,
 	email string,
 	password string,
 	authenticate func(string, string, *models.User) error) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
 
-	user, err := c.DBFunctions.GetUser(mysqldb.ByID, userID, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			if err := c.DBConnector.Rollback(tx); err != nil {
-				return err
+	return c.runInTransaction(ctx, "Authenticate", func(tx *sql.Tx) error {
+		user, err := c.DBFunctions.GetUser(ctx, mysqldb.ByID, userID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
 			}
-			return ErrUserNotFound
+			return err
 		}
-		return err
-	}
 
-	if err := authenticate(email, password, user); err != nil {
-		return err
-	}
-
-	return c.DBConnector.Commit(tx)
+		return authenticate(email, password, user)
+	})
 }
 
-func (c *MYSQLController) GetUsersByProductID(productID *uuid.UUID) ([]models.ProductUser, error) {
-	users := make([]models.ProductUser, 0)
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return nil, err
-	}
-
-	ownershipMap, err := c.DBFunctions.GetProductUserIDs(productID, tx)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrNoProductsForUser
-		}
-		return nil, err
-	}
+func (c *MYSQLController) GetUsersByProductID(ctx context.Context, productID *uuid.UUID) ([]models.ProductUser, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
 
-	for userID, privilege := range ownershipMap.UserMap {
-		userID := userID
-		user, err := c.GetUser(&userID)
+	var users []models.ProductUser
+	err := c.runInTransaction(ctx, "GetUsersByProductID", func(tx *sql.Tx) error {
+		ownershipMap, err := c.DBFunctions.GetProductUserIDs(ctx, productID, tx)
 		if err != nil {
-			return nil, err
+			if err == sql.ErrNoRows {
+				return ErrNoProductsForUser
+			}
+			return err
 		}
 
-		productUser := models.ProductUser{
-			UserData:  *user,
-			Privilege: privilege,
-		}
+		users = make([]models.ProductUser, 0)
+		for userID, privilege := range ownershipMap.UserMap {
+			userID := userID
+			user, err := c.readUserData(ctx, mysqldb.ByID, userID, tx)
+			if err == ErrUserNotFound {
+				// The relation of an entity in the trash is kept until it is purged.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			productUser := models.ProductUser{
+				UserData:  *user,
+				Privilege: privilege,
+			}
 
-		users = append(users, productUser)
+			users = append(users, productUser)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return users, c.DBConnector.Commit(tx)
+	return users, nil
 }
 
-func (c *MYSQLController) AddProductUser(productID *uuid.UUID, userID *uuid.UUID, privilege int) error {
+func (c *MYSQLController) AddProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, privilege int) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
 	productUsers := models.ProductUserIDs{
 		UserIDArray: make([]uuid.UUID, 0),
 		UserMap:     make(map[uuid.UUID]int),
 	}
 	productUsers.UserMap[*userID] = privilege
 
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
-
-	if err := c.DBFunctions.AddProductUsers(productID, &productUsers, tx); err != nil {
-		if err == mysqldb.ErrNoProductUserAdded {
-			return ErrProductUserNotAssociated
+	return c.runInTransaction(ctx, "AddProductUser", func(tx *sql.Tx) error {
+		if err := c.DBFunctions.AddProductUsers(ctx, productID, &productUsers, tx); err != nil {
+			if err == mysqldb.ErrNoProductUserAdded {
+				return ErrProductUserNotAssociated
+			}
+			return err
 		}
-		return err
-	}
-
-	return c.DBConnector.Commit(tx)
+		if err := c.audit(ctx, tx, models.ActionCreate, mysqldb.ProductUsers, *productID, nil, privilegeSnapshot(userID, privilege)); err != nil {
+			return err
+		}
+		return c.publish(ctx, tx, models.EventProductUserAdded, *productID, privilegeSnapshot(userID, privilege))
+	})
 }
 
-func (c *MYSQLController) DeleteProductUser(productID *uuid.UUID, userID *uuid.UUID) error {
-	tx, err := c.DBConnector.ConnectSystem()
-	if err != nil {
-		return err
-	}
+func (c *MYSQLController) DeleteProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 
-	if err := c.DBFunctions.DeleteProductUser(productID, userID, tx); err != nil {
-		if err == mysqldb.ErrNoUserWithProduct {
-			return ErrProductUserNotAssociated
+	return c.runInTransaction(ctx, "DeleteProductUser", func(tx *sql.Tx) error {
+		if err := c.DBFunctions.DeleteProductUser(ctx, productID, userID, tx); err != nil {
+			if err == mysqldb.ErrNoUserWithProduct {
+				return ErrProductUserNotAssociated
+			}
+			return err
 		}
-		return err
-	}
-
-	return c.DBConnector.Commit(tx)
+		if err := c.audit(ctx, tx, models.ActionDelete, mysqldb.ProductUsers, *productID, models.DataMap{"user_id": userID.String()}, nil); err != nil {
+			return err
+		}
+		return c.publish(ctx, tx, models.EventProductUserRemoved, *productID, models.DataMap{"user_id": userID.String()})
+	})
 }
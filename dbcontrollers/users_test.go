@@ -1,10 +1,12 @@
 package dbcontrollers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
 	"github.com/artofimagination/mysql-user-db-go-interface/tests"
 	"github.com/google/uuid"
 	"github.com/kr/pretty"
@@ -20,6 +22,7 @@ type UserExpectedData struct {
 	userDeleted          bool
 	productDeleted       bool
 	usersProductsUpdated bool
+	savepointRolledBack  bool
 	err                  error
 }
 
@@ -28,6 +31,7 @@ type UserMockData struct {
 	product       *models.Product
 	usersProducts *models.UserProductIDs
 	privileges    models.Privileges
+	transferErr   error
 	err           error
 }
 
@@ -190,6 +194,33 @@ func createUserTestData(testID int) (*tests.OrderedTests, error) {
 		}
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 
+		testCase = "valid_data_failed_transfer"
+		usersProducts.ProductMap[productID] = 0
+		expected = UserExpectedData{
+			userDeleted:          false,
+			productDeleted:       false,
+			usersProductsUpdated: true,
+			savepointRolledBack:  true,
+			err:                  &OwnershipTransferError{ProductIDs: []uuid.UUID{productID}},
+		}
+		input = UserInputData{
+			userData: userData,
+			nominees: nominees,
+		}
+		mock = UserMockData{
+			user:          user,
+			product:       product,
+			usersProducts: usersProducts,
+			privileges:    privileges,
+			transferErr:   mysqldb.ErrNoUsersProductUpdate,
+		}
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data:     input,
+			Mock:     mock,
+			Expected: expected,
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
 		testCase = "valid_data_has_no_nominee"
 		usersProducts.ProductMap[productID] = 0
 		expected = UserExpectedData{
@@ -297,6 +328,7 @@ func TestCreateUser(t *testing.T) {
 			}
 
 			output, err := dbController.CreateUser(
+				context.Background(),
 				inputData.userData.Name,
 				inputData.userData.Email,
 				inputData.password)
@@ -339,14 +371,17 @@ func TestDeleteUser(t *testing.T) {
 				userDeleted:          false,
 				productDeleted:       false,
 				usersProductsUpdated: false,
+				transferErr:          mockData.transferErr,
 				privileges:           mockData.privileges,
 			}
+			dbController.DBConnector = &DBConnectorMock{}
 
-			err := dbController.DeleteUser(&inputData.userData.ID, inputData.nominees)
+			err := dbController.DeleteUser(context.Background(), &inputData.userData.ID, inputData.nominees)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 			tests.CheckResult(dbController.DBFunctions.(*DBFunctionMock).userDeleted, expectedData.userDeleted, nil, nil, testCaseString, t)
 			tests.CheckResult(dbController.DBFunctions.(*DBFunctionMock).productDeleted, expectedData.productDeleted, nil, nil, testCaseString, t)
 			tests.CheckResult(dbController.DBFunctions.(*DBFunctionMock).usersProductsUpdated, expectedData.usersProductsUpdated, nil, nil, testCaseString, t)
+			tests.CheckResult(dbController.DBConnector.(*DBConnectorMock).rolledBackToSavepoint, expectedData.savepointRolledBack, nil, nil, testCaseString, t)
 		})
 	}
 }
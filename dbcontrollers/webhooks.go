@@ -0,0 +1,386 @@
+package dbcontrollers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/outbox"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrWebhookSubscriptionNotFound = errors.New("The selected webhook subscription not found")
+var ErrInvalidWebhookDeliveryPage = errors.Errorf("Invalid page, before_id can not be negative and limit has to be between 1 and %d", models.MaxWebhookDeliveryLimit)
+
+// Defaults of the webhook deliveries. The delay before a retry doubles with every failed attempt up to the maximum.
+const (
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookRetryDelay  = 10 * time.Second
+	maxWebhookRetryDelay      = time.Hour
+	defaultWebhookTimeout     = 10 * time.Second
+	webhookBatchSize          = 100
+)
+
+// withoutSecret hides the secret of the subscription, it is only returned when the subscription is created.
+func withoutSecret(subscription *models.WebhookSubscription) *models.WebhookSubscription {
+	subscription.Secret = ""
+	return subscription
+}
+
+// CreateWebhookSubscription subscribes url to the events of eventTypes, to every event if it is empty.
+// A random secret is generated if secret is empty. The returned subscription contains the secret.
+func (c *MYSQLController) CreateWebhookSubscription(ctx context.Context, url string, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	subscription, err := c.ModelFunctions.NewWebhookSubscription(url, secret, eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+
+	err = c.runInTransaction(ctx, "CreateWebhookSubscription", func(tx *sql.Tx) error {
+		return c.DBFunctions.AddWebhookSubscription(ctx, subscription, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetWebhookSubscription returns the subscription without its secret.
+func (c *MYSQLController) GetWebhookSubscription(ctx context.Context, ID *uuid.UUID) (*models.WebhookSubscription, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	var subscription *models.WebhookSubscription
+	err := c.runInTransaction(ctx, "GetWebhookSubscription", func(tx *sql.Tx) (err error) {
+		subscription, err = c.DBFunctions.GetWebhookSubscription(ctx, ID, tx)
+		if err == sql.ErrNoRows {
+			return ErrWebhookSubscriptionNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withoutSecret(subscription), nil
+}
+
+// GetWebhookSubscriptions lists the subscriptions without their secret, the oldest first.
+// The list is empty if there are none.
+func (c *MYSQLController) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	subscriptions := make([]models.WebhookSubscription, 0)
+	err := c.runInTransaction(ctx, "GetWebhookSubscriptions", func(tx *sql.Tx) error {
+		found, err := c.DBFunctions.GetWebhookSubscriptions(ctx, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+		subscriptions = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subscriptions {
+		withoutSecret(&subscriptions[i])
+	}
+	return subscriptions, nil
+}
+
+// UpdateWebhookSubscription replaces the URL and the event types of the subscription.
+// The secret is replaced if it is set, otherwise the subscription keeps its secret.
+func (c *MYSQLController) UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = make([]string, 0)
+	}
+
+	return c.runInTransaction(ctx, "UpdateWebhookSubscription", func(tx *sql.Tx) error {
+		existing, err := c.DBFunctions.GetWebhookSubscription(ctx, &subscription.ID, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrWebhookSubscriptionNotFound
+			}
+			return err
+		}
+
+		updated := *subscription
+		if updated.Secret == "" {
+			updated.Secret = existing.Secret
+		}
+		if err := updated.Validate(); err != nil {
+			return err
+		}
+		return c.DBFunctions.UpdateWebhookSubscription(ctx, &updated, tx)
+	})
+}
+
+// DeleteWebhookSubscription deletes the subscription with its delivery log. The pending deliveries are dropped.
+func (c *MYSQLController) DeleteWebhookSubscription(ctx context.Context, ID *uuid.UUID) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	return c.runInTransaction(ctx, "DeleteWebhookSubscription", func(tx *sql.Tx) error {
+		if err := c.DBFunctions.DeleteWebhookDeliveries(ctx, ID, tx); err != nil {
+			return err
+		}
+
+		if err := c.DBFunctions.DeleteWebhookSubscription(ctx, ID, tx); err != nil {
+			if err == mysqldb.ErrWebhookSubscriptionMissing {
+				return ErrWebhookSubscriptionNotFound
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// GetWebhookDeliveries lists the delivery log of the subscription, the latest first.
+// The next page starts before the ID of the last delivery returned, limit is the page size, a default one if zero.
+func (c *MYSQLController) GetWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, beforeID int64, limit int) ([]models.WebhookDelivery, error) {
+	switch {
+	case beforeID < 0, limit < 0, limit > models.MaxWebhookDeliveryLimit:
+		return nil, ErrInvalidWebhookDeliveryPage
+	case beforeID == 0:
+		beforeID = math.MaxInt64
+	}
+	if limit == 0 {
+		limit = models.DefaultWebhookDeliveryLimit
+	}
+
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	err := c.runInTransaction(ctx, "GetWebhookDeliveries", func(tx *sql.Tx) error {
+		if _, err := c.DBFunctions.GetWebhookSubscription(ctx, subscriptionID, tx); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrWebhookSubscriptionNotFound
+			}
+			return err
+		}
+
+		found, err := c.DBFunctions.GetWebhookDeliveries(ctx, subscriptionID, beforeID, limit, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+		deliveries = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// webhookQueue is the outbox sink queuing the events for the webhook subscriptions accepting them.
+type webhookQueue struct {
+	controller *MYSQLController
+}
+
+func (q *webhookQueue) Name() string {
+	return "webhooks"
+}
+
+func (q *webhookQueue) Deliver(ctx context.Context, event *models.DomainEvent) error {
+	return q.controller.queueWebhookDeliveries(ctx, event)
+}
+
+// WebhookSink returns the outbox sink that queues the events for delivery to the webhook subscriptions.
+// DispatchWebhooks delivers the queued events.
+func (c *MYSQLController) WebhookSink() outbox.Sink {
+	return &webhookQueue{controller: c}
+}
+
+// queueWebhookDeliveries adds a pending delivery of the event for every subscription accepting it.
+// The outbox may deliver an event again, the subscriptions that already have it queued are skipped.
+func (c *MYSQLController) queueWebhookDeliveries(ctx context.Context, event *models.DomainEvent) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	return c.runInTransaction(ctx, "QueueWebhookDeliveries", func(tx *sql.Tx) error {
+		subscriptions, err := c.DBFunctions.GetWebhookSubscriptions(ctx, tx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		// The columns store whole seconds.
+		now := time.Now().UTC().Truncate(time.Second)
+		for _, subscription := range subscriptions {
+			if !subscription.Accepts(event.Type) {
+				continue
+			}
+
+			delivery := &models.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				Event:          *event,
+				Status:         models.WebhookPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+			}
+			failure, err := c.runInSavepoint(ctx, tx, func() error {
+				return c.DBFunctions.AddWebhookDelivery(ctx, delivery, tx)
+			})
+			if err != nil {
+				return err
+			}
+
+			if failure != nil && !errors.Is(failure, mysqldb.ErrDuplicateEntry) {
+				return failure
+			}
+		}
+		return nil
+	})
+}
+
+// webhookRetryDelay returns the delay before the next attempt of a delivery failed attempts times.
+func (c *MYSQLController) webhookRetryDelay(attempts int) time.Duration {
+	delay := c.WebhookRetryDelay
+	if delay <= 0 {
+		delay = defaultWebhookRetryDelay
+	}
+
+	for i := 1; i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxWebhookRetryDelay {
+		delay = maxWebhookRetryDelay
+	}
+	return delay
+}
+
+func (c *MYSQLController) webhookClient() *http.Client {
+	if c.WebhookClient != nil {
+		return c.WebhookClient
+	}
+	return &http.Client{Timeout: defaultWebhookTimeout}
+}
+
+// attemptWebhookDelivery posts the delivery to the subscription and sets the result of the attempt on the delivery.
+// A failed delivery is scheduled for a retry until it runs out of attempts.
+func (c *MYSQLController) attemptWebhookDelivery(ctx context.Context, client *http.Client, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	attemptedAt := time.Now().UTC()
+	statusCode, err := outbox.PostWebhook(ctx, client, subscription, delivery, attemptedAt)
+
+	// The columns store whole seconds.
+	attemptedAt = attemptedAt.Truncate(time.Second)
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	if err == nil {
+		delivery.Status = models.WebhookSucceeded
+		return
+	}
+
+	delivery.LastError = deliveryError(err)
+	maxAttempts := c.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = models.WebhookFailed
+		return
+	}
+	delivery.NextAttemptAt = attemptedAt.Add(c.webhookRetryDelay(delivery.Attempts)).Truncate(time.Second)
+}
+
+// DispatchWebhooks attempts the deliveries due now and returns the number of succeeded ones.
+// The result of every attempt is recorded in the delivery log of the subscription.
+func (c *MYSQLController) DispatchWebhooks(ctx context.Context) (int, error) {
+	var deliveries []models.WebhookDelivery
+	subscriptions := make(map[uuid.UUID]*models.WebhookSubscription)
+	readCtx, cancel := c.readContext(ctx)
+	err := c.runInTransaction(readCtx, "GetDueWebhookDeliveries", func(tx *sql.Tx) (err error) {
+		deliveries, err = c.DBFunctions.GetDueWebhookDeliveries(readCtx, time.Now().UTC(), webhookBatchSize, tx)
+		if err != nil {
+			return err
+		}
+
+		found, err := c.DBFunctions.GetWebhookSubscriptions(readCtx, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		for i := range found {
+			subscriptions[found[i].ID] = &found[i]
+		}
+		return nil
+	})
+	cancel()
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	client := c.webhookClient()
+	succeeded := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			// Deleted with its deliveries since they were read.
+			continue
+		}
+
+		c.attemptWebhookDelivery(ctx, client, subscription, delivery)
+		if delivery.Status == models.WebhookSucceeded {
+			succeeded++
+		}
+
+		writeCtx, cancel := c.writeContext(ctx)
+		err := c.runInTransaction(writeCtx, "UpdateWebhookDelivery", func(tx *sql.Tx) error {
+			return c.DBFunctions.UpdateWebhookDelivery(writeCtx, delivery, tx)
+		})
+		cancel()
+		if err != nil {
+			return succeeded, err
+		}
+	}
+	return succeeded, nil
+}
+
+// RunWebhookDispatcher dispatches the due webhook deliveries every interval until ctx is cancelled.
+func (c *MYSQLController) RunWebhookDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.DispatchWebhooks(ctx); err != nil {
+				log.Printf("Failed to dispatch the webhooks: %s\n", err.Error())
+			}
+		}
+	}
+}
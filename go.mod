@@ -1,20 +1,24 @@
 module github.com/artofimagination/mysql-user-db-go-interface
 
-go 1.15
+go 1.16
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/evanphx/json-patch v4.11.0+incompatible
 	github.com/go-playground/validator/v10 v10.9.0
 	github.com/go-sql-driver/mysql v1.5.0
-	github.com/google/go-cmp v0.4.0
+	github.com/google/go-cmp v0.5.3
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/mux v1.7.3
 	github.com/kr/pretty v0.3.0
+	github.com/lib/pq v1.9.0
 	github.com/pkg/errors v0.9.1
 	github.com/proemergotech/log/v3 v3.0.4
 	github.com/rubenv/sql-migrate v0.0.0-20200616145509-8d140a17f351
+	github.com/santhosh-tekuri/jsonschema/v5 v5.0.0
 	github.com/spf13/viper v1.3.2
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
 	gopkg.in/go-playground/validator.v9 v9.31.0
 	honnef.co/go/tools v0.0.1-2019.2.3
+	modernc.org/sqlite v1.10.8
 )
@@ -3,8 +3,10 @@ package initialization
 import (
 	"context"
 	stdlog "log"
+	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/proemergotech/log/v3"
@@ -19,12 +21,88 @@ type Config struct {
 	Port       int  `mapstructure:"server_port" default:"8080"`
 	DebugPProf bool `mapstructure:"debug_pprof" default:"false"`
 
-	MySQLDBAddress            string `mapstructure:"mysql_db_address" validate:"required"`
-	MySQLDBPort               int    `mapstructure:"mysql_db_port" default:"3306"`
-	MySQLDBUser               string `mapstructure:"mysql_db_user" validate:"required"`
-	MySQLDBPassword           string `mapstructure:"mysql_db_password" validate:"required"`
-	MySQLDBName               string `mapstructure:"mysql_db_name" default:"resource_database"`
-	MySQLDBMigrationDirectory string `mapstructure:"mysql_db_migration_dir" validate:"required"`
+	// Storage backend, either mysql, postgres, sqlite or memory. Only the settings of the selected one are required.
+	// The memory backend keeps the data in the process until it exits, it is meant for tests and demos.
+	DBBackend string `mapstructure:"db_backend" default:"mysql" validate:"oneof=mysql postgres sqlite memory"`
+	// Apply the pending migrations on startup. If disabled, the schema is managed by the migrate command.
+	DBAutoMigrate bool `mapstructure:"db_auto_migrate" default:"true"`
+	// Comparison of the live schema with the one expected by the code on startup. On drift strict refuses to start,
+	// warn only logs the differences. Only the mysql backend is checked.
+	DBSchemaCheck string `mapstructure:"db_schema_check" default:"warn" validate:"oneof=strict warn off"`
+
+	MySQLDBAddress  string `mapstructure:"mysql_db_address" validate:"required_if=DBBackend mysql"`
+	MySQLDBPort     int    `mapstructure:"mysql_db_port" default:"3306"`
+	MySQLDBUser     string `mapstructure:"mysql_db_user" validate:"required_if=DBBackend mysql"`
+	MySQLDBPassword string `mapstructure:"mysql_db_password" validate:"required_if=DBBackend mysql"`
+	MySQLDBName     string `mapstructure:"mysql_db_name" default:"resource_database"`
+	// The migrations are embedded in the binary. The *_DB_MIGRATION_DIR settings are development overrides,
+	// the migration files are read from the given directory instead if set.
+	MySQLDBMigrationDirectory string `mapstructure:"mysql_db_migration_dir"`
+	// Instances starting together migrate one at a time, the others wait this long for the schema to be up to date.
+	MySQLDBMigrationLockTimeout time.Duration `mapstructure:"mysql_db_migration_lock_timeout" default:"2m"`
+
+	PostgresDBAddress  string `mapstructure:"postgres_db_address" validate:"required_if=DBBackend postgres"`
+	PostgresDBPort     int    `mapstructure:"postgres_db_port" default:"5432"`
+	PostgresDBUser     string `mapstructure:"postgres_db_user" validate:"required_if=DBBackend postgres"`
+	PostgresDBPassword string `mapstructure:"postgres_db_password" validate:"required_if=DBBackend postgres"`
+	PostgresDBName     string `mapstructure:"postgres_db_name" default:"resource_database"`
+	PostgresDBSSLMode  string `mapstructure:"postgres_db_ssl_mode" default:"disable"`
+	// Development override of the embedded migrations, like MySQLDBMigrationDirectory.
+	PostgresDBMigrationDirectory string `mapstructure:"postgres_db_migration_dir"`
+
+	// Path of the database file, created on the first start.
+	SQLiteDBPath string `mapstructure:"sqlite_db_path" default:"user_database.db"`
+	// Development override of the embedded migrations, like MySQLDBMigrationDirectory.
+	SQLiteDBMigrationDirectory string        `mapstructure:"sqlite_db_migration_dir"`
+	SQLiteDBBusyTimeout        time.Duration `mapstructure:"sqlite_db_busy_timeout" default:"5s"`
+
+	// Connection pool settings of the shared DB handle, used by every backend like the settings below.
+	// The alias is the former mysql_db_* name, it is still read if the setting itself is not set.
+	DBMaxOpenConns    int           `mapstructure:"db_max_open_conns" alias:"mysql_db_max_open_conns" default:"25"`
+	DBMaxIdleConns    int           `mapstructure:"db_max_idle_conns" alias:"mysql_db_max_idle_conns" default:"25"`
+	DBConnMaxLifetime time.Duration `mapstructure:"db_conn_max_lifetime" alias:"mysql_db_conn_max_lifetime" default:"5m"`
+
+	// Deadlines applied to every controller operation on top of the request context.
+	DBReadTimeout  time.Duration `mapstructure:"db_read_timeout" alias:"mysql_db_read_timeout" default:"5s"`
+	DBWriteTimeout time.Duration `mapstructure:"db_write_timeout" alias:"mysql_db_write_timeout" default:"10s"`
+
+	// Retries of transactions failing on deadlock or lock wait timeout.
+	DBTxMaxRetries int           `mapstructure:"db_tx_max_retries" alias:"mysql_db_tx_max_retries" default:"3"`
+	DBTxRetryDelay time.Duration `mapstructure:"db_tx_retry_delay" alias:"mysql_db_tx_retry_delay" default:"50ms"`
+
+	// Directory of the JSON schemas of the assets, named after the asset type like 'user_settings.json'.
+	// Assets without a schema are not validated. The schemas are read on startup only.
+	AssetSchemaDirectory string `mapstructure:"asset_schema_dir"`
+
+	// Deleted users, products and projects are kept in the trash and can be restored until they are purged.
+	// The entities older than the retention are purged every purge interval. If disabled, the deletes are permanent.
+	SoftDelete         bool          `mapstructure:"soft_delete" default:"false"`
+	TrashRetention     time.Duration `mapstructure:"trash_retention" default:"720h"`
+	TrashPurgeInterval time.Duration `mapstructure:"trash_purge_interval" default:"1h" validate:"gt=0"`
+
+	// Domain events are written to the outbox in the transaction of the change and delivered at least once
+	// to the configured sinks every dispatch interval. The outbox is enabled if at least one sink is set.
+	// The events are posted as JSON to the HTTP URL and appended as JSON lines to the file, '-' is stdout.
+	OutboxHTTPURL          string        `mapstructure:"outbox_http_url" validate:"omitempty,url"`
+	OutboxFile             string        `mapstructure:"outbox_file"`
+	OutboxDispatchInterval time.Duration `mapstructure:"outbox_dispatch_interval" default:"1s" validate:"gt=0"`
+	OutboxBatchSize        int           `mapstructure:"outbox_batch_size" default:"100" validate:"gt=0"`
+
+	// The events of the outbox are queued for the webhook subscriptions and delivered every dispatch interval.
+	// A failed delivery is retried after the retry delay, doubled after every attempt, until it runs out of attempts.
+	Webhooks                bool          `mapstructure:"webhooks" default:"false"`
+	WebhookDispatchInterval time.Duration `mapstructure:"webhook_dispatch_interval" default:"1s" validate:"gt=0"`
+	WebhookMaxAttempts      int           `mapstructure:"webhook_max_attempts" default:"8" validate:"gt=0"`
+	WebhookRetryDelay       time.Duration `mapstructure:"webhook_retry_delay" default:"10s" validate:"gt=0"`
+	WebhookTimeout          time.Duration `mapstructure:"webhook_timeout" default:"10s" validate:"gt=0"`
+
+	// The change streams poll the audit log for new changes every interval.
+	ChangeStreamInterval time.Duration `mapstructure:"change_stream_interval" default:"1s" validate:"gt=0"`
+}
+
+// OutboxEnabled reports whether an outbox sink is configured, the webhooks are one of them.
+func (c *Config) OutboxEnabled() bool {
+	return c.OutboxHTTPURL != "" || c.OutboxFile != "" || c.Webhooks
 }
 
 // InitConfig reads in config file and ENV variables if set.
@@ -49,7 +127,15 @@ func InitConfig(cfg interface{}) {
 			continue
 		}
 
-		if def := fieldType.Tag.Get("default"); def != "" {
+		def := fieldType.Tag.Get("default")
+		if alias := fieldType.Tag.Get("alias"); alias != "" {
+			if value, ok := os.LookupEnv(strings.ToUpper(alias)); ok {
+				stdlog.Printf("Config warning: %s is deprecated, use %s instead", strings.ToUpper(alias), strings.ToUpper(name))
+				def = value
+			}
+		}
+
+		if def != "" {
 			viper.SetDefault(name, def)
 		}
 	}
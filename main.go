@@ -7,20 +7,65 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
 	"github.com/artofimagination/mysql-user-db-go-interface/initialization"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/outbox"
 	"github.com/artofimagination/mysql-user-db-go-interface/restcontrollers"
 )
 
 func main() {
 	cfg := &initialization.Config{}
 	initialization.InitConfig(cfg)
-	r, err := restcontrollers.NewRESTController()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && (os.Args[1] == "backup" || os.Args[1] == "restore") {
+		if err := runBackup(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	dbController, err := dbcontrollers.NewDBController(cfg)
 	if err != nil {
 		panic(err)
 	}
+	r := restcontrollers.NewRESTController(dbController)
+
+	// The background jobs are tracked, so that the DB connection pool is only closed after they returned.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := &sync.WaitGroup{}
+	runJob := func(job func()) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job()
+		}()
+	}
+	if cfg.SoftDelete {
+		runJob(func() { dbController.RunTrashPurge(jobsCtx, cfg.TrashPurgeInterval) })
+	}
+	if cfg.OutboxEnabled() {
+		sinks, err := newOutboxSinks(cfg)
+		if err != nil {
+			panic(err)
+		}
+		if cfg.Webhooks {
+			sinks = append(sinks, dbController.WebhookSink())
+			runJob(func() { dbController.RunWebhookDispatcher(jobsCtx, cfg.WebhookDispatchInterval) })
+		}
+		runJob(func() { dbController.RunOutboxDispatcher(jobsCtx, cfg.OutboxDispatchInterval, sinks) })
+	}
 
 	// Start HTTP server that accepts requests from the offer process to exchange SDP and Candidates
 	port := fmt.Sprintf(":%d", cfg.Port)
@@ -30,6 +75,8 @@ func main() {
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+	// The change streams would keep the shutdown waiting, the clients reconnect to another instance.
+	srv.RegisterOnShutdown(dbController.StopChangeStreams)
 
 	// Start Server
 	go func() {
@@ -40,10 +87,45 @@ func main() {
 	}()
 
 	// Graceful Shutdown
-	waitForShutdown(srv)
+	waitForShutdown(srv, dbController.DBConnector, stopJobs, jobs)
 }
 
-func waitForShutdown(srv *http.Server) {
+// newOutboxSinks creates the sinks of the domain events configured in cfg.
+func newOutboxSinks(cfg *initialization.Config) ([]outbox.Sink, error) {
+	sinks := make([]outbox.Sink, 0)
+	if cfg.OutboxHTTPURL != "" {
+		sinks = append(sinks, outbox.NewHTTPSink(cfg.OutboxHTTPURL))
+	}
+
+	if cfg.OutboxFile != "" {
+		fileSink, err := outbox.NewFileSink(cfg.OutboxFile)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+	return sinks, nil
+}
+
+// waitForJobs waits until the background jobs return or the timeout expires and reports whether they returned.
+func waitForJobs(jobs *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func waitForShutdown(srv *http.Server, dbConnector mysqldb.ConnectorCommon, stopJobs context.CancelFunc, jobs *sync.WaitGroup) {
 	interruptChan := make(chan os.Signal, 1)
 	signal.Notify(interruptChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
@@ -57,6 +139,16 @@ func waitForShutdown(srv *http.Server) {
 		log.Fatal(err)
 	}
 
+	// The in-flight requests are done, stop the background jobs and release the DB connection pool
+	// once they returned. A job stuck in the database is not waited for longer than the timeout.
+	stopJobs()
+	if !waitForJobs(jobs, time.Second*10) {
+		log.Println("Background jobs did not stop in time, closing the DB connection pool anyway")
+	}
+	if err := dbConnector.Close(); err != nil {
+		log.Fatal(err)
+	}
+
 	log.Println("Shutting down")
 	os.Exit(0)
 }
@@ -0,0 +1,226 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// checkAssetType fails if assetType is not one of the asset tables.
+func checkAssetType(assetType string) error {
+	if schema[assetType] != assetSchema {
+		return fmt.Errorf("Table 'memory.%s' doesn't exist", assetType)
+	}
+	return nil
+}
+
+// assetFromRow decodes the asset. The data is stored as JSON, so callers never share the maps of the stored rows.
+func assetFromRow(r row) (models.Asset, error) {
+	asset := models.Asset{ID: r["id"].(uuid.UUID), Version: r["version"].(int64)}
+	if err := json.Unmarshal([]byte(r["data"].(string)), &asset.DataMap); err != nil {
+		return asset, err
+	}
+	return asset, nil
+}
+
+func (*MemoryFunctions) AddAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
+	if err := checkAssetType(assetType); err != nil {
+		return err
+	}
+
+	binary, err := json.Marshal(asset.DataMap)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRow(tx, assetType, row{"id": asset.ID, "data": string(binary), "version": int64(1)}); err != nil {
+		return err
+	}
+
+	asset.Version = 1
+	return nil
+}
+
+// UpdateAsset overwrites the data of the asset and sets asset.Version to the new version.
+// If asset.Version is set, mysqldb.ErrVersionConflict is returned when the stored asset has a different version.
+func (*MemoryFunctions) UpdateAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
+	if err := checkAssetType(assetType); err != nil {
+		return err
+	}
+
+	binary, err := json.Marshal(asset.DataMap)
+	if err != nil {
+		return err
+	}
+
+	rows, err := selectRows(tx, assetType, hasID("id", asset.ID))
+	if err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		return errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	}
+
+	version := rows[0]["version"].(int64)
+	if asset.Version > 0 && asset.Version != version {
+		return errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, version, asset.Version)
+	}
+
+	if err := addAssetHistory(ctx, assetType, rows[0], tx); err != nil {
+		return err
+	}
+
+	err = updateRows(tx, errors.WithMessage(mysqldb.ErrAssetMissing, assetType), assetType, hasID("id", asset.ID), func(r row) row {
+		return row{"id": r["id"], "data": string(binary), "version": version + 1}
+	})
+	if err != nil {
+		return err
+	}
+
+	asset.Version = version + 1
+	return nil
+}
+
+func (f *MemoryFunctions) GetAsset(ctx context.Context, assetType string, assetID *uuid.UUID) (*models.Asset, error) {
+	if err := checkAssetType(assetType); err != nil {
+		return nil, err
+	}
+
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := f.GetAssets(ctx, assetType, []uuid.UUID{*assetID}, tx)
+	if err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	return &assets[0], tx.Commit()
+}
+
+func (*MemoryFunctions) DeleteAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+	if err := checkAssetType(assetType); err != nil {
+		return err
+	}
+
+	if err := deleteRows(tx, errors.WithMessage(mysqldb.ErrAssetMissing, assetType), assetType, hasID("id", *assetID)); err != nil {
+		return err
+	}
+	return deleteAssetHistory(assetType, *assetID, tx)
+}
+
+func (*MemoryFunctions) GetAssets(ctx context.Context, assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
+	if err := checkAssetType(assetType); err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, assetType, inIDs(IDs))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	assets := make([]models.Asset, 0, len(rows))
+	for _, r := range rows {
+		asset, err := assetFromRow(r)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// PatchAsset applies patch to the data of the asset in tx and returns the patched asset.
+// If patch.Version is set, mysqldb.ErrVersionConflict is returned when the stored asset has a different version.
+func (*MemoryFunctions) PatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error) {
+	if err := checkAssetType(assetType); err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, assetType, hasID("id", *assetID))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	}
+
+	asset, err := assetFromRow(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Version > 0 && patch.Version != asset.Version {
+		return nil, errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, asset.Version, patch.Version)
+	}
+
+	patched, err := patch.Apply(asset.DataMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addAssetHistory(ctx, assetType, rows[0], tx); err != nil {
+		return nil, err
+	}
+
+	binary, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateRows(tx, errors.WithMessage(mysqldb.ErrAssetMissing, assetType), assetType, hasID("id", *assetID), func(r row) row {
+		return row{"id": r["id"], "data": string(binary), "version": asset.Version + 1}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	asset.DataMap = patched
+	asset.Version++
+	return &asset, nil
+}
+
+// matchingDetails returns a condition matching the rows whose detailsColumn references details of detailsTable matching filter.
+func matchingDetails(tx *sql.Tx, detailsTable string, detailsColumn string, filter models.DetailsFilter) (func(r row) bool, error) {
+	if len(filter) == 0 {
+		return nil, errors.WithMessage(models.ErrInvalidFilter, "empty filter")
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, detailsTable, func(r row) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	IDs := make(map[uuid.UUID]struct{})
+	for _, r := range rows {
+		details, err := assetFromRow(r)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Matches(details.DataMap) {
+			IDs[details.ID] = struct{}{}
+		}
+	}
+
+	return func(r row) bool {
+		_, ok := IDs[r[detailsColumn].(uuid.UUID)]
+		return ok
+	}, nil
+}
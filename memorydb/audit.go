@@ -0,0 +1,158 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+// marshalSnapshot stores the snapshot as JSON like the SQL backends, so later changes of the map are not recorded.
+func marshalSnapshot(snapshot models.DataMap) (interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	return json.Marshal(snapshot)
+}
+
+func unmarshalSnapshot(value interface{}) (models.DataMap, error) {
+	binary, ok := value.([]byte)
+	if !ok {
+		return nil, nil
+	}
+
+	var snapshot models.DataMap
+	if err := json.Unmarshal(binary, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// AddAuditEvent records the event in tx, so it is only kept if the change is committed.
+func (*MemoryFunctions) AddAuditEvent(ctx context.Context, event *models.AuditEvent, tx *sql.Tx) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+
+	before, err := marshalSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := marshalSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+
+	var actor interface{}
+	if event.Actor != nil {
+		actor = *event.Actor
+	}
+
+	// The row identifiers increase like an auto increment column.
+	return t.insertRow(mysqldb.AuditEvents, row{
+		"id":          t.db.nextRowID(),
+		"occurred_at": event.OccurredAt.UTC(),
+		"actor":       actor,
+		"action":      event.Action,
+		"entity_type": event.EntityType,
+		"entity_id":   event.EntityID,
+		"before_data": before,
+		"after_data":  after,
+	})
+}
+
+// matchingAuditEvents returns the condition selecting the events of filter.
+func matchingAuditEvents(filter *models.AuditFilter) func(r row) bool {
+	return func(r row) bool {
+		occurredAt := r["occurred_at"].(time.Time)
+		switch {
+		case filter.Actor != nil && r["actor"] != *filter.Actor,
+			filter.Action != "" && r["action"] != filter.Action,
+			filter.EntityType != "" && r["entity_type"] != filter.EntityType,
+			filter.EntityID != nil && r["entity_id"] != *filter.EntityID,
+			!filter.From.IsZero() && occurredAt.Before(filter.From),
+			!filter.To.IsZero() && !occurredAt.Before(filter.To),
+			filter.BeforeID > 0 && r["id"].(int64) >= filter.BeforeID:
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+// GetAuditEvents lists the events matching filter, the latest first. sql.ErrNoRows is returned if there are none.
+// The filter has to be validated by the caller.
+func (*MemoryFunctions) GetAuditEvents(ctx context.Context, filter *models.AuditFilter, tx *sql.Tx) ([]models.AuditEvent, error) {
+	rows, err := selectRows(tx, mysqldb.AuditEvents, matchingAuditEvents(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i]["id"].(int64) > rows[j]["id"].(int64)
+	})
+	if len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
+	}
+	return auditEvents(rows)
+}
+
+// GetAuditEventsAfter lists at most limit events recorded after the event afterID, the oldest first.
+// sql.ErrNoRows is returned if there are none.
+func (*MemoryFunctions) GetAuditEventsAfter(ctx context.Context, afterID int64, limit int, tx *sql.Tx) ([]models.AuditEvent, error) {
+	rows, err := selectRows(tx, mysqldb.AuditEvents, func(r row) bool {
+		return r["id"].(int64) > afterID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i]["id"].(int64) < rows[j]["id"].(int64)
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return auditEvents(rows)
+}
+
+func auditEvents(rows []row) ([]models.AuditEvent, error) {
+	var err error
+	events := make([]models.AuditEvent, 0, len(rows))
+	for _, r := range rows {
+		event := models.AuditEvent{
+			ID:         r["id"].(int64),
+			OccurredAt: r["occurred_at"].(time.Time),
+			Action:     r["action"].(string),
+			EntityType: r["entity_type"].(string),
+			EntityID:   r["entity_id"].(uuid.UUID),
+		}
+		if actor, ok := r["actor"].(uuid.UUID); ok {
+			event.Actor = &actor
+		}
+
+		if event.Before, err = unmarshalSnapshot(r["before_data"]); err != nil {
+			return nil, err
+		}
+		if event.After, err = unmarshalSnapshot(r["after_data"]); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
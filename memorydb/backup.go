@@ -0,0 +1,74 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+)
+
+// backupFromRow converts the stored values to the ones of the dump.
+func backupFromRow(table *mysqldb.BackupTable, r row) models.BackupRow {
+	backupRow := make(models.BackupRow, len(table.Columns))
+	for _, column := range table.Columns {
+		switch value := r[column.Name].(type) {
+		case int:
+			backupRow[column.Name] = int64(value)
+		case string:
+			if column.Kind == mysqldb.BackupJSON {
+				backupRow[column.Name] = json.RawMessage(value)
+			} else {
+				backupRow[column.Name] = value
+			}
+		case time.Time:
+			backupRow[column.Name] = value.UTC()
+		default:
+			backupRow[column.Name] = value
+		}
+	}
+	return backupRow
+}
+
+// rowFromBackup converts the values of the dump to the stored ones, NULL columns are left out.
+func rowFromBackup(table *mysqldb.BackupTable, backupRow models.BackupRow) row {
+	r := make(row, len(table.Columns))
+	for _, column := range table.Columns {
+		switch value := backupRow[column.Name].(type) {
+		case nil:
+		case int64:
+			if column.Kind == mysqldb.BackupInt {
+				r[column.Name] = int(value)
+			} else {
+				r[column.Name] = value
+			}
+		case json.RawMessage:
+			r[column.Name] = string(value)
+		default:
+			r[column.Name] = value
+		}
+	}
+	return r
+}
+
+// DumpTable calls fn with every row of the table in insertion order. Iteration stops at the first error returned by fn.
+func (*MemoryFunctions) DumpTable(ctx context.Context, table *mysqldb.BackupTable, fn func(r models.BackupRow) error, tx *sql.Tx) error {
+	rows, err := selectRows(tx, table.Name, func(row) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if err := fn(backupFromRow(table, r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreRow inserts a row of the dump into the table.
+func (*MemoryFunctions) RestoreRow(ctx context.Context, table *mysqldb.BackupTable, r models.BackupRow, tx *sql.Tx) error {
+	return insertRow(tx, table.Name, rowFromBackup(table, r))
+}
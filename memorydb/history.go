@@ -0,0 +1,105 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+// addAssetHistory copies the stored row of the asset into the history before it is overwritten in tx.
+func addAssetHistory(ctx context.Context, assetType string, r row, tx *sql.Tx) error {
+	var changedBy interface{}
+	if userID := models.ActingUser(ctx); userID != nil {
+		changedBy = *userID
+	}
+
+	return insertRow(tx, mysqldb.AssetHistory, row{
+		"asset_type": assetType,
+		"asset_id":   r["id"],
+		"version":    r["version"],
+		"data":       r["data"],
+		"changed_by": changedBy,
+		"changed_at": time.Now().UTC(),
+	})
+}
+
+// isRevisionOf returns a condition matching the history rows of the asset.
+func isRevisionOf(assetType string, assetID uuid.UUID) func(r row) bool {
+	return func(r row) bool {
+		return r["asset_type"] == assetType && r["asset_id"] == assetID
+	}
+}
+
+func revisionFromRow(r row) models.AssetRevision {
+	revision := models.AssetRevision{
+		AssetID:   r["asset_id"].(uuid.UUID),
+		Version:   r["version"].(int64),
+		ChangedAt: r["changed_at"].(time.Time),
+	}
+	if changedBy, ok := r["changed_by"].(uuid.UUID); ok {
+		revision.ChangedBy = &changedBy
+	}
+	return revision
+}
+
+// GetAssetHistory lists the replaced versions of the asset without their data, the latest first.
+// sql.ErrNoRows is returned if the asset has never been updated.
+func (*MemoryFunctions) GetAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) ([]models.AssetRevision, error) {
+	if err := checkAssetType(assetType); err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, mysqldb.AssetHistory, isRevisionOf(assetType, *assetID))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	revisions := make([]models.AssetRevision, 0, len(rows))
+	for _, r := range rows {
+		revisions = append(revisions, revisionFromRow(r))
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Version > revisions[j].Version
+	})
+	return revisions, nil
+}
+
+// GetAssetRevision returns a replaced version of the asset with its data or sql.ErrNoRows.
+func (*MemoryFunctions) GetAssetRevision(ctx context.Context, assetType string, assetID *uuid.UUID, version int64, tx *sql.Tx) (*models.AssetRevision, error) {
+	if err := checkAssetType(assetType); err != nil {
+		return nil, err
+	}
+
+	isRevision := isRevisionOf(assetType, *assetID)
+	rows, err := selectRows(tx, mysqldb.AssetHistory, func(r row) bool {
+		return isRevision(r) && r["version"] == version
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	revision := revisionFromRow(rows[0])
+	if err := json.Unmarshal([]byte(rows[0]["data"].(string)), &revision.DataMap); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// deleteAssetHistory removes the replaced versions of a deleted asset.
+func deleteAssetHistory(assetType string, assetID uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, nil, mysqldb.AssetHistory, isRevisionOf(assetType, assetID))
+}
@@ -0,0 +1,323 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// In-memory database connector implementation
+// The data lives in the memory of the process and is lost when the connector is closed.
+// It enforces the uniqueness and the foreign keys of the SQL schema and isolates the transactions
+// from each other, so the real controller and REST stack can be executed in tests without a DB server.
+//
+// The transactions are real *sql.Tx values handed out by a driver that does not execute SQL,
+// the data functions look up the state of the transaction behind them.
+type MemoryConnector struct {
+	db   *database
+	pool *sql.DB
+}
+
+// MemoryFunctions represents the implementation of the in-memory data manipulation functions.
+// Errors are reported with the sentinel errors of mysqldb, so the callers do not depend on the backend.
+type MemoryFunctions struct {
+	DBConnector mysqldb.ConnectorCommon
+	UUIDImpl    models.UUIDCommon
+}
+
+var errNoSQL = errors.New("The memory backend does not execute SQL statements")
+
+type transactionKey struct{}
+
+// transactions maps the open *sql.Tx values to their state.
+var transactions = struct {
+	sync.Mutex
+	byTx map[*sql.Tx]*transaction
+}{byTx: make(map[*sql.Tx]*transaction)}
+
+// lookup returns the state of tx. sql.ErrTxDone is returned if tx is not an open transaction of the memory backend.
+func lookup(tx *sql.Tx) (*transaction, error) {
+	transactions.Lock()
+	defer transactions.Unlock()
+
+	t, ok := transactions.byTx[tx]
+	if !ok {
+		return nil, sql.ErrTxDone
+	}
+	return t, nil
+}
+
+func forget(tx *sql.Tx) {
+	transactions.Lock()
+	defer transactions.Unlock()
+	delete(transactions.byTx, tx)
+}
+
+// connector opens the connections of the pool. The transaction state is passed to BeginTx in the context.
+type connector struct{}
+
+func (connector) Connect(context.Context) (driver.Conn, error) {
+	return conn{}, nil
+}
+
+func (connector) Driver() driver.Driver {
+	return memoryDriver{}
+}
+
+type memoryDriver struct{}
+
+func (memoryDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("The memory backend can only be opened by MemoryConnector")
+}
+
+type conn struct{}
+
+func (conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errNoSQL
+}
+
+func (conn) Close() error {
+	return nil
+}
+
+func (conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("Transactions of the memory backend must be started by MemoryConnector.ConnectSystem")
+}
+
+func (c conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	t, ok := ctx.Value(transactionKey{}).(*transaction)
+	if !ok {
+		return c.Begin()
+	}
+	return &driverTx{transaction: t}, nil
+}
+
+// driverTx ends the transaction when the *sql.Tx is committed or rolled back,
+// either through the connector or directly.
+type driverTx struct {
+	transaction *transaction
+}
+
+func (tx *driverTx) Commit() error {
+	defer tx.forget()
+	return tx.transaction.commit()
+}
+
+func (tx *driverTx) Rollback() error {
+	defer tx.forget()
+	return tx.transaction.rollback()
+}
+
+func (tx *driverTx) forget() {
+	transactions.Lock()
+	defer transactions.Unlock()
+	if tx.transaction.sqlTx != nil {
+		delete(transactions.byTx, tx.transaction.sqlTx)
+	}
+}
+
+// BootstrapSystem creates the empty database with the privileges seeded like the SQL migrations do.
+// The existing data is kept if the connector is already open.
+func (c *MemoryConnector) BootstrapSystem() error {
+	if c.pool != nil {
+		return nil
+	}
+
+	c.db = newDatabase()
+	c.pool = sql.OpenDB(connector{})
+
+	privileges := []row{
+		{"id": 1, "name": "Owner", "description": "The user owns the product, has the right to change or delete it. If there are multiple owners delete/change can happen if all the owners aggree."},
+		{"id": 2, "name": "User", "description": "The user acquired the product. They can create projects based on the product, but cannot modify or delete the product itself."},
+		{"id": 3, "name": "Partner", "description": "The user can change the product, but requires the owners approval."},
+	}
+
+	t := c.db.begin()
+	for _, privilege := range privileges {
+		if err := t.insertRow("privileges", privilege); err != nil {
+			if errRb := t.rollback(); errRb != nil {
+				return errors.Wrapf(err, "Failed to rollback changes: %s", errRb.Error())
+			}
+			return err
+		}
+	}
+	return t.commit()
+}
+
+// RollbackWithErrorStack rolls back the transaction and returns errorStack.
+// Only functions that start the transaction themselves may use it.
+func RollbackWithErrorStack(tx *sql.Tx, errorStack error) error {
+	if err := tx.Rollback(); err != nil {
+		return errors.Wrapf(errorStack, "Failed to rollback changes: %s", err.Error())
+	}
+	return errorStack
+}
+
+// ConnectSystem starts a new transaction working on a snapshot of the committed data.
+func (c *MemoryConnector) ConnectSystem(ctx context.Context) (*sql.Tx, error) {
+	if c.pool == nil {
+		return nil, mysqldb.ErrConnectorNotOpen
+	}
+
+	t := c.db.begin()
+	tx, err := c.pool.BeginTx(context.WithValue(ctx, transactionKey{}, t), nil)
+	if err != nil {
+		if errRb := t.rollback(); errRb != nil {
+			return nil, errors.Wrapf(err, "Failed to rollback changes: %s", errRb.Error())
+		}
+		return nil, err
+	}
+
+	transactions.Lock()
+	transactions.byTx[tx] = t
+	t.sqlTx = tx
+	transactions.Unlock()
+
+	// The context may have been cancelled and the transaction rolled back before it was registered.
+	if t.isDone() {
+		forget(tx)
+	}
+	return tx, nil
+}
+
+func (*MemoryConnector) Commit(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (*MemoryConnector) Rollback(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+// Savepoint marks the current state of the transaction. Changes made after it can be undone
+// by RollbackToSavepoint without aborting the transaction itself.
+func (*MemoryConnector) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+	return t.savepoint(name)
+}
+
+func (*MemoryConnector) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+	return t.rollbackToSavepoint(name)
+}
+
+func (*MemoryConnector) ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+	return t.releaseSavepoint(name)
+}
+
+// Close drops the data. It is safe to call on a connector that has not been bootstrapped.
+func (c *MemoryConnector) Close() error {
+	if c.pool == nil {
+		return nil
+	}
+
+	err := c.pool.Close()
+	c.pool = nil
+	c.db = nil
+	return err
+}
+
+// toUUID converts the key of a query to uuid.UUID. The SQL backends accept both values and pointers.
+func toUUID(keyValue interface{}) (uuid.UUID, error) {
+	switch value := keyValue.(type) {
+	case uuid.UUID:
+		return value, nil
+	case *uuid.UUID:
+		return *value, nil
+	default:
+		return uuid.UUID{}, fmt.Errorf("Unsupported key type %T", keyValue)
+	}
+}
+
+// hasID returns a condition matching the rows whose column equals ID.
+func hasID(column string, ID uuid.UUID) func(r row) bool {
+	return func(r row) bool {
+		return r[column] == ID
+	}
+}
+
+// inIDs returns a condition matching the rows whose id is in IDs.
+func inIDs(IDs []uuid.UUID) func(r row) bool {
+	set := make(map[uuid.UUID]struct{}, len(IDs))
+	for _, ID := range IDs {
+		set[ID] = struct{}{}
+	}
+
+	return func(r row) bool {
+		ID, ok := r["id"].(uuid.UUID)
+		if !ok {
+			return false
+		}
+		_, ok = set[ID]
+		return ok
+	}
+}
+
+// deleteRows deletes the rows of the table matching where and returns errNoneDeleted if there were none.
+func deleteRows(tx *sql.Tx, errNoneDeleted error, name string, where func(r row) bool) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+
+	affected, err := t.deleteRows(name, where)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errNoneDeleted
+	}
+	return nil
+}
+
+// updateRows updates the rows of the table matching where and returns errNoneUpdated if there were none.
+func updateRows(tx *sql.Tx, errNoneUpdated error, name string, where func(r row) bool, set func(r row) row) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+
+	affected, err := t.updateRows(name, where, set)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errNoneUpdated
+	}
+	return nil
+}
+
+// selectRows returns the rows of the table matching where in insertion order.
+func selectRows(tx *sql.Tx, name string, where func(r row) bool) ([]row, error) {
+	t, err := lookup(tx)
+	if err != nil {
+		return nil, err
+	}
+	return t.selectRows(name, where)
+}
+
+func insertRow(tx *sql.Tx, name string, r row) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+	return t.insertRow(name, r)
+}
@@ -0,0 +1,180 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests/backendtest"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// newTestBackend returns data functions working on a new, empty database.
+func newTestBackend(t *testing.T) (mysqldb.FunctionsCommon, mysqldb.ConnectorCommon) {
+	connector := &MemoryConnector{}
+	if err := connector.BootstrapSystem(); err != nil {
+		t.Fatalf("Failed to bootstrap test DB: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := connector.Close(); err != nil {
+			t.Errorf("Failed to close test DB: %s", err)
+		}
+	})
+
+	return &MemoryFunctions{DBConnector: connector}, connector
+}
+
+func TestConformance(t *testing.T) {
+	backendtest.Run(t, newTestBackend)
+}
+
+// beginTx starts a transaction that is ended by the test.
+func beginTx(t *testing.T, connector mysqldb.ConnectorCommon) *sql.Tx {
+	tx, err := connector.ConnectSystem(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to setup DB transaction %s", err)
+	}
+	return tx
+}
+
+func TestConnectSystem(t *testing.T) {
+	connector := &MemoryConnector{}
+
+	tx, err := connector.ConnectSystem(context.Background())
+	tests.CheckResult(tx == nil, true, err, mysqldb.ErrConnectorNotOpen, "not_bootstrapped", t)
+	tests.CheckResult(nil, nil, connector.Close(), nil, "close_not_bootstrapped", t)
+}
+
+func TestTransactionDone(t *testing.T) {
+	functions, connector := newTestBackend(t)
+	ctx := context.Background()
+	user := backendtest.CreateUser(t, functions, connector, "testName")
+
+	tx := beginTx(t, connector)
+	tests.CheckResult(nil, nil, tx.Commit(), nil, "commit", t)
+
+	output, err := functions.GetUser(ctx, mysqldb.ByID, user.ID, tx)
+	tests.CheckResult(output == nil, true, err, sql.ErrTxDone, "committed", t)
+	tests.CheckResult(nil, nil, connector.Commit(tx), sql.ErrTxDone, "commit_twice", t)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	tx, err = connector.ConnectSystem(cancelled)
+	if err != nil {
+		t.Fatalf("Failed to setup DB transaction %s", err)
+	}
+	cancel()
+
+	// The transaction is rolled back by database/sql in the background once the context is done.
+	for err == nil {
+		_, err = functions.GetUser(ctx, mysqldb.ByID, user.ID, tx)
+	}
+	tests.CheckResult(nil, nil, err, sql.ErrTxDone, "cancelled", t)
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	functions, connector := newTestBackend(t)
+	ctx := context.Background()
+
+	reader := beginTx(t, connector)
+	user := backendtest.CreateUser(t, functions, connector, "testName")
+
+	// A transaction does not see the changes committed after it started.
+	output, err := functions.GetUser(ctx, mysqldb.ByID, user.ID, reader)
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "committed_later", t)
+	tests.CheckResult(nil, nil, connector.Commit(reader), nil, "commit_reader", t)
+
+	writer := beginTx(t, connector)
+	err = functions.DeleteUser(ctx, &user.ID, writer)
+	tests.CheckResult(nil, nil, err, nil, "delete_user", t)
+
+	// Uncommitted changes are not visible to others.
+	err = backendtest.RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetUser(ctx, mysqldb.ByID, user.ID, tx)
+		return err
+	})
+	tests.CheckResult(output, user, err, nil, "uncommitted_delete", t)
+	tests.CheckResult(nil, nil, connector.Rollback(writer), nil, "rollback_writer", t)
+}
+
+func TestWriteConflict(t *testing.T) {
+	functions, connector := newTestBackend(t)
+	ctx := context.Background()
+	owner := backendtest.CreateUser(t, functions, connector, "ownerName")
+	product := backendtest.CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+
+	first := beginTx(t, connector)
+	second := beginTx(t, connector)
+
+	err := functions.UpdateUsersProducts(ctx, &owner.ID, &product.ID, 2, first)
+	tests.CheckResult(nil, nil, err, nil, "update_first", t)
+	err = functions.UpdateUsersProducts(ctx, &owner.ID, &product.ID, 3, second)
+	tests.CheckResult(nil, nil, err, nil, "update_second", t)
+
+	// The first committer wins, the other transaction can be executed again.
+	tests.CheckResult(nil, nil, connector.Commit(first), nil, "commit_first", t)
+	err = connector.Commit(second)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrDeadlock), true, nil, nil, "commit_second", t)
+
+	var userProducts *models.UserProductIDs
+	err = backendtest.RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		userProducts, err = functions.GetUserProductIDs(ctx, &owner.ID, tx)
+		return err
+	})
+	tests.CheckResult(userProducts.ProductMap[product.ID], 2, err, nil, "first_kept", t)
+}
+
+func TestConcurrentConstraints(t *testing.T) {
+	functions, connector := newTestBackend(t)
+	ctx := context.Background()
+	owner := backendtest.CreateUser(t, functions, connector, "ownerName")
+	user := backendtest.CreateUser(t, functions, connector, "userName")
+
+	// Changes of different rows are merged, but the constraints are checked again on commit.
+	first := beginTx(t, connector)
+	second := beginTx(t, connector)
+
+	product := &models.Product{ID: uuid.New(), Name: "testProduct", DetailsID: uuid.New(), AssetsID: uuid.New()}
+	for _, tx := range []*sql.Tx{first, second} {
+		details := &models.Asset{ID: uuid.New(), DataMap: models.DataMap{}}
+		assets := &models.Asset{ID: uuid.New(), DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.ProductDetails, details, tx); err != nil {
+			t.Fatalf("Failed to add details: %s", err)
+		}
+		if err := functions.AddAsset(ctx, mysqldb.ProductAssets, assets, tx); err != nil {
+			t.Fatalf("Failed to add assets: %s", err)
+		}
+
+		duplicate := *product
+		duplicate.ID = uuid.New()
+		duplicate.DetailsID = details.ID
+		duplicate.AssetsID = assets.ID
+		if err := functions.AddProduct(ctx, &duplicate, tx); err != nil {
+			t.Fatalf("Failed to add product: %s", err)
+		}
+	}
+
+	tests.CheckResult(nil, nil, connector.Commit(first), nil, "commit_first", t)
+	err := connector.Commit(second)
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "name"), true, nil, nil, "duplicate_name", t)
+
+	product = backendtest.CreateProduct(t, functions, connector, "otherProduct", &owner.ID)
+	first = beginTx(t, connector)
+	second = beginTx(t, connector)
+
+	productUsers := &models.ProductUserIDs{
+		UserMap:     map[uuid.UUID]int{user.ID: 2},
+		UserIDArray: []uuid.UUID{user.ID},
+	}
+	err = functions.AddProductUsers(ctx, &product.ID, productUsers, first)
+	tests.CheckResult(nil, nil, err, nil, "add_product_user", t)
+	err = functions.DeleteUser(ctx, &user.ID, second)
+	tests.CheckResult(nil, nil, err, nil, "delete_user", t)
+
+	tests.CheckResult(nil, nil, connector.Commit(first), nil, "commit_product_user", t)
+	err = connector.Commit(second)
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrRowReferenced, "users_id"), true, nil, nil, "referenced_user", t)
+}
@@ -0,0 +1,103 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+// AddOutboxEvent writes the event in tx, so it is only published if the change is committed.
+func (*MemoryFunctions) AddOutboxEvent(ctx context.Context, event *models.DomainEvent, tx *sql.Tx) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+
+	// The payload is stored as JSON like the SQL backends, so later changes of the map are not published.
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	var actor interface{}
+	if event.Actor != nil {
+		actor = *event.Actor
+	}
+
+	// The row identifiers increase like an auto increment column.
+	return t.insertRow(mysqldb.OutboxEvents, row{
+		"id":           t.db.nextRowID(),
+		"event_type":   event.Type,
+		"occurred_at":  event.OccurredAt.UTC(),
+		"actor":        actor,
+		"aggregate_id": event.AggregateID,
+		"payload":      payload,
+		"attempts":     0,
+		"last_error":   nil,
+	})
+}
+
+// GetOutboxEvents lists at most limit undelivered events, the oldest first. sql.ErrNoRows is returned if there are none.
+func (*MemoryFunctions) GetOutboxEvents(ctx context.Context, limit int, tx *sql.Tx) ([]models.DomainEvent, error) {
+	rows, err := selectRows(tx, mysqldb.OutboxEvents, func(r row) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	events := make([]models.DomainEvent, 0, len(rows))
+	for _, r := range rows {
+		event := models.DomainEvent{
+			ID:          r["id"].(int64),
+			Type:        r["event_type"].(string),
+			OccurredAt:  r["occurred_at"].(time.Time),
+			AggregateID: r["aggregate_id"].(uuid.UUID),
+			Attempts:    r["attempts"].(int),
+		}
+		if actor, ok := r["actor"].(uuid.UUID); ok {
+			event.Actor = &actor
+		}
+
+		if err := json.Unmarshal(r["payload"].([]byte), &event.Payload); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func hasRowID(ID int64) func(r row) bool {
+	return func(r row) bool {
+		return r["id"] == ID
+	}
+}
+
+// DeleteOutboxEvent removes the delivered event. Deleting an event already removed by another dispatcher is not an error.
+func (*MemoryFunctions) DeleteOutboxEvent(ctx context.Context, ID int64, tx *sql.Tx) error {
+	return deleteRows(tx, nil, mysqldb.OutboxEvents, hasRowID(ID))
+}
+
+// RecordOutboxFailure counts a failed delivery of the event and keeps its error.
+func (*MemoryFunctions) RecordOutboxFailure(ctx context.Context, ID int64, message string, tx *sql.Tx) error {
+	return updateRows(tx, nil, mysqldb.OutboxEvents, hasRowID(ID), func(r row) row {
+		updated := make(row, len(r))
+		for column, v := range r {
+			updated[column] = v
+		}
+		updated["attempts"] = r["attempts"].(int) + 1
+		updated["last_error"] = message
+		return updated
+	})
+}
@@ -0,0 +1,55 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+)
+
+func privilegeFromRow(r row) *models.Privilege {
+	return &models.Privilege{
+		ID:          r["id"].(int),
+		Name:        r["name"].(string),
+		Description: r["description"].(string),
+	}
+}
+
+func (f *MemoryFunctions) GetPrivileges(ctx context.Context) (models.Privileges, error) {
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, "privileges", func(row) bool { return true })
+	if err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	privileges := make(models.Privileges, 0, len(rows))
+	for _, r := range rows {
+		privileges = append(privileges, privilegeFromRow(r))
+	}
+	return privileges, tx.Commit()
+}
+
+func (f *MemoryFunctions) GetPrivilege(ctx context.Context, name string) (*models.Privilege, error) {
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, "privileges", func(r row) bool { return r["name"] == name })
+	if err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return privilegeFromRow(rows[0]), nil
+}
@@ -0,0 +1,142 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+func productFromRow(r row) models.Product {
+	return models.Product{
+		ID:        r["id"].(uuid.UUID),
+		Name:      r["name"].(string),
+		DetailsID: r["product_details_id"].(uuid.UUID),
+		AssetsID:  r["product_assets_id"].(uuid.UUID),
+	}
+}
+
+func (*MemoryFunctions) AddProductUsers(ctx context.Context, productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
+	for userID, privilege := range productUsers.UserMap {
+		err := insertRow(tx, "users_products", row{
+			"users_id":      userID,
+			"products_id":   *productID,
+			"privileges_id": privilege,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*MemoryFunctions) DeleteProductUsersByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoUserWithProduct, "users_products", hasID("products_id", *productID))
+}
+
+func (*MemoryFunctions) UpdateUsersProducts(ctx context.Context, userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	where := func(r row) bool {
+		return r["users_id"] == *userID && r["products_id"] == *productID
+	}
+	return updateRows(tx, mysqldb.ErrNoUsersProductUpdate, "users_products", where, func(r row) row {
+		return row{"users_id": r["users_id"], "products_id": r["products_id"], "privileges_id": privilege}
+	})
+}
+
+func (*MemoryFunctions) AddProduct(ctx context.Context, product *models.Product, tx *sql.Tx) error {
+	return insertRow(tx, "products", row{
+		"id":                 product.ID,
+		"name":               product.Name,
+		"product_details_id": product.DetailsID,
+		"product_assets_id":  product.AssetsID,
+	})
+}
+
+// getProduct returns the first product matching where or sql.ErrNoRows.
+func getProduct(tx *sql.Tx, where func(r row) bool) (*models.Product, error) {
+	rows, err := selectRows(tx, "products", notDeleted(where))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	product := productFromRow(rows[0])
+	return &product, nil
+}
+
+func (*MemoryFunctions) GetProductByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
+	return getProduct(tx, hasID("id", *ID))
+}
+
+func (*MemoryFunctions) GetProductByName(ctx context.Context, name string, tx *sql.Tx) (*models.Product, error) {
+	return getProduct(tx, func(r row) bool {
+		return r["name"] == name
+	})
+}
+
+func (*MemoryFunctions) GetProductsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
+	rows, err := selectRows(tx, "products", notDeleted(inIDs(IDs)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	products := make([]models.Product, 0, len(rows))
+	for _, r := range rows {
+		products = append(products, productFromRow(r))
+	}
+	return products, nil
+}
+
+// GetProductsByDetails returns the products whose details match filter.
+func (*MemoryFunctions) GetProductsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Product, error) {
+	where, err := matchingDetails(tx, mysqldb.ProductDetails, "product_details_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, "products", notDeleted(where))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	products := make([]models.Product, 0, len(rows))
+	for _, r := range rows {
+		products = append(products, productFromRow(r))
+	}
+	return products, nil
+}
+
+func (*MemoryFunctions) GetUserProductIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
+	rows, err := selectRows(tx, "users_products", hasID("users_id", *userID))
+	if err != nil {
+		return nil, err
+	}
+
+	userProducts := models.UserProductIDs{
+		ProductMap:     make(map[uuid.UUID]int),
+		ProductIDArray: make([]uuid.UUID, 0),
+	}
+	for _, r := range rows {
+		productID := r["products_id"].(uuid.UUID)
+		userProducts.ProductMap[productID] = r["privileges_id"].(int)
+		userProducts.ProductIDArray = append(userProducts.ProductIDArray, productID)
+	}
+	return &userProducts, nil
+}
+
+func (*MemoryFunctions) DeleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoProductDeleted, "products", hasID("id", *productID))
+}
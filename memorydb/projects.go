@@ -0,0 +1,213 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+func (*MemoryFunctions) AddProjectUsers(ctx context.Context, projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error {
+	for userID, privilege := range projectUsers.UserMap {
+		err := insertRow(tx, "users_projects", row{
+			"users_id":      userID,
+			"projects_id":   *projectID,
+			"privileges_id": privilege,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*MemoryFunctions) DeleteProjectUsersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoUserWithProject, "users_projects", hasID("projects_id", *projectID))
+}
+
+func (*MemoryFunctions) DeleteProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoUserWithProject, "users_projects", func(r row) bool {
+		return r["projects_id"] == *projectID && r["users_id"] == *userID
+	})
+}
+
+func (*MemoryFunctions) UpdateUsersProjects(ctx context.Context, userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	where := func(r row) bool {
+		return r["users_id"] == *userID && r["projects_id"] == *projectID
+	}
+	return updateRows(tx, mysqldb.ErrNoUsersProjectUpdate, "users_projects", where, func(r row) row {
+		return row{"users_id": r["users_id"], "projects_id": r["projects_id"], "privileges_id": privilege}
+	})
+}
+
+func (*MemoryFunctions) AddProject(ctx context.Context, project *models.Project, tx *sql.Tx) error {
+	return insertRow(tx, "projects", row{
+		"id":                 project.ID,
+		"products_id":        project.ProductID,
+		"project_details_id": project.DetailsID,
+		"project_assets_id":  project.AssetsID,
+	})
+}
+
+func (*MemoryFunctions) GetProjectByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Project, error) {
+	projects, err := getProjects(tx, hasID("id", *ID))
+	if err != nil {
+		return nil, err
+	}
+	return &projects[0], nil
+}
+
+func (*MemoryFunctions) GetProjectsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+	return getProjects(tx, inIDs(IDs))
+}
+
+// GetProjectsByDetails returns the projects whose details match filter.
+func (*MemoryFunctions) GetProjectsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Project, error) {
+	where, err := matchingDetails(tx, mysqldb.ProjectDetails, "project_details_id", filter)
+	if err != nil {
+		return nil, err
+	}
+	return getProjects(tx, where)
+}
+
+func (*MemoryFunctions) GetUserProjectIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error) {
+	rows, err := selectRows(tx, "users_projects", hasID("users_id", *userID))
+	if err != nil {
+		return nil, err
+	}
+
+	userProjects := &models.UserProjectIDs{
+		ProjectMap:     make(map[uuid.UUID]int),
+		ProjectIDArray: make([]uuid.UUID, 0),
+	}
+	for _, r := range rows {
+		projectID := r["projects_id"].(uuid.UUID)
+		userProjects.ProjectMap[projectID] = r["privileges_id"].(int)
+		userProjects.ProjectIDArray = append(userProjects.ProjectIDArray, projectID)
+	}
+	return userProjects, nil
+}
+
+func (*MemoryFunctions) GetProductProjects(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+	return getProjects(tx, hasID("products_id", *productID))
+}
+
+// getProjects returns the projects matching where. sql.ErrNoRows is returned if there are none.
+func getProjects(tx *sql.Tx, where func(r row) bool) ([]models.Project, error) {
+	rows, err := selectRows(tx, "projects", notDeleted(where))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	projects := make([]models.Project, 0, len(rows))
+	for _, r := range rows {
+		projects = append(projects, models.Project{
+			ID:        r["id"].(uuid.UUID),
+			ProductID: r["products_id"].(uuid.UUID),
+			DetailsID: r["project_details_id"].(uuid.UUID),
+			AssetsID:  r["project_assets_id"].(uuid.UUID),
+		})
+	}
+	return projects, nil
+}
+
+func (*MemoryFunctions) DeleteProject(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoProjectDeleted, "projects", hasID("id", *projectID))
+}
+
+func (*MemoryFunctions) DeleteProjectsByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoProjectDeleted, "projects", hasID("products_id", *productID))
+}
+
+func (*MemoryFunctions) AddProjectViewer(ctx context.Context, projectViewer *models.ProjectViewer, tx *sql.Tx) error {
+	if projectViewer.IsOwner {
+		err := insertRow(tx, "viewers", row{"id": projectViewer.ViewerID, "owner_id": projectViewer.UserID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return insertRow(tx, "users_viewers", row{
+		"users_id":    projectViewer.UserID,
+		"viewer_id":   projectViewer.ViewerID,
+		"projects_id": projectViewer.ProjectID,
+	})
+}
+
+func (*MemoryFunctions) GetViewerIDsByOwnerID(ctx context.Context, ownerID *uuid.UUID, tx *sql.Tx) ([]uuid.UUID, error) {
+	rows, err := selectRows(tx, "viewers", hasID("owner_id", *ownerID))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	viewerIDs := make([]uuid.UUID, 0, len(rows))
+	for _, r := range rows {
+		viewerIDs = append(viewerIDs, r["id"].(uuid.UUID))
+	}
+	return viewerIDs, nil
+}
+
+func (*MemoryFunctions) DeleteViewerByOwnerID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoViewerDeleted, "viewers", hasID("owner_id", *userID))
+}
+
+func (f *MemoryFunctions) DeleteProjectViewerByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	if err := deleteRows(tx, mysqldb.ErrNoProjectViewerDeleted, "users_viewers", hasID("users_id", *userID)); err != nil {
+		return err
+	}
+
+	return f.DeleteViewerByOwnerID(ctx, userID, tx)
+}
+
+func (*MemoryFunctions) GetProjectViewersByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	return getProjectViewers(tx, hasID("users_id", *userID))
+}
+
+func (*MemoryFunctions) GetProjectViewersByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	return getProjectViewers(tx, hasID("viewer_id", *viewerID))
+}
+
+func (*MemoryFunctions) GetProjectViewersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	return getProjectViewers(tx, hasID("projects_id", *projectID))
+}
+
+// getProjectViewers returns the project viewers matching where. sql.ErrNoRows is returned if there are none.
+// IsOwner is not stored in users_viewers, it is always false.
+func getProjectViewers(tx *sql.Tx, where func(r row) bool) ([]models.ProjectViewer, error) {
+	rows, err := selectRows(tx, "users_viewers", where)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	projectViewers := make([]models.ProjectViewer, 0, len(rows))
+	for _, r := range rows {
+		projectViewers = append(projectViewers, models.ProjectViewer{
+			UserID:    r["users_id"].(uuid.UUID),
+			ViewerID:  r["viewer_id"].(uuid.UUID),
+			ProjectID: r["projects_id"].(uuid.UUID),
+		})
+	}
+	return projectViewers, nil
+}
+
+func (*MemoryFunctions) DeleteProjectViewerByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoProjectViewerDeleted, "users_viewers", hasID("viewer_id", *viewerID))
+}
+
+func (*MemoryFunctions) DeleteProjectViewerByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoProjectViewerDeleted, "users_viewers", hasID("projects_id", *projectID))
+}
@@ -0,0 +1,741 @@
+package memorydb
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/pkg/errors"
+)
+
+// row is a single table row keyed by the column names. Rows are never modified in place,
+// an update replaces the whole row, so the snapshots of the transactions can share them.
+type row map[string]interface{}
+
+// table maps the row identifiers to the rows. The identifiers are taken from a single
+// increasing sequence, so ordering by them returns the rows in insertion order.
+type table map[int64]row
+
+func (t table) clone() table {
+	copied := make(table, len(t))
+	for id, r := range t {
+		copied[id] = r
+	}
+	return copied
+}
+
+type foreignKey struct {
+	column string
+	table  string
+}
+
+// tableSchema lists the constraints of a table. Foreign keys always reference the id column of the parent.
+type tableSchema struct {
+	primaryKey  string
+	unique      []string
+	notNull     []string
+	lengths     map[string]int
+	foreignKeys []foreignKey
+}
+
+// uniqueColumns returns the primary key and the unique columns of the table.
+func (s *tableSchema) uniqueColumns() []string {
+	if s.primaryKey == "" {
+		return s.unique
+	}
+	return append([]string{s.primaryKey}, s.unique...)
+}
+
+var assetSchema = &tableSchema{
+	primaryKey: "id",
+	notNull:    []string{"data", "version"},
+}
+
+// schema mirrors the constraints of the SQL migrations.
+var schema = map[string]*tableSchema{
+	mysqldb.UserSettings: assetSchema,
+	mysqldb.UserAssets:   assetSchema,
+	"users": {
+		primaryKey: "id",
+		unique:     []string{"name", "email"},
+		notNull:    []string{"name", "email"},
+		lengths:    map[string]int{"name": 50, "email": 300, "password": 1024},
+		foreignKeys: []foreignKey{
+			{column: "user_settings_id", table: mysqldb.UserSettings},
+			{column: "user_assets_id", table: mysqldb.UserAssets},
+		},
+	},
+	mysqldb.ProductDetails: assetSchema,
+	mysqldb.ProductAssets:  assetSchema,
+	"products": {
+		primaryKey: "id",
+		unique:     []string{"name"},
+		notNull:    []string{"name"},
+		lengths:    map[string]int{"name": 255},
+		foreignKeys: []foreignKey{
+			{column: "product_details_id", table: mysqldb.ProductDetails},
+			{column: "product_assets_id", table: mysqldb.ProductAssets},
+		},
+	},
+	"privileges": {
+		primaryKey: "id",
+		unique:     []string{"name"},
+		notNull:    []string{"name", "description"},
+		lengths:    map[string]int{"name": 255, "description": 512},
+	},
+	"users_products": {
+		foreignKeys: []foreignKey{
+			{column: "products_id", table: "products"},
+			{column: "users_id", table: "users"},
+		},
+	},
+	mysqldb.ProjectDetails: assetSchema,
+	mysqldb.ProjectAssets:  assetSchema,
+	"projects": {
+		primaryKey: "id",
+		notNull:    []string{"products_id"},
+		foreignKeys: []foreignKey{
+			{column: "products_id", table: "products"},
+			{column: "project_details_id", table: mysqldb.ProjectDetails},
+			{column: "project_assets_id", table: mysqldb.ProjectAssets},
+		},
+	},
+	"users_projects": {
+		foreignKeys: []foreignKey{
+			{column: "projects_id", table: "projects"},
+			{column: "users_id", table: "users"},
+		},
+	},
+	"viewers": {
+		primaryKey: "id",
+		foreignKeys: []foreignKey{
+			{column: "owner_id", table: "users"},
+		},
+	},
+	"users_viewers": {
+		foreignKeys: []foreignKey{
+			{column: "users_id", table: "users"},
+			{column: "viewer_id", table: "viewers"},
+			{column: "projects_id", table: "projects"},
+		},
+	},
+	mysqldb.AssetHistory: {
+		notNull: []string{"asset_type", "asset_id", "version", "data", "changed_at"},
+	},
+	mysqldb.AuditEvents: {
+		primaryKey: "id",
+		notNull:    []string{"occurred_at", "action", "entity_type", "entity_id"},
+		lengths:    map[string]int{"action": 32, "entity_type": 64},
+	},
+	mysqldb.OutboxEvents: {
+		primaryKey: "id",
+		notNull:    []string{"event_type", "occurred_at", "aggregate_id", "payload", "attempts"},
+		lengths:    map[string]int{"event_type": 64, "last_error": mysqldb.MaxOutboxErrorLength},
+	},
+	mysqldb.WebhookSubscriptions: {
+		primaryKey: "id",
+		notNull:    []string{"url", "secret", "event_types", "created_at"},
+		lengths:    map[string]int{"url": 2048, "secret": 255},
+	},
+	mysqldb.WebhookDeliveries: {
+		primaryKey: "id",
+		notNull:    []string{"subscription_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at", "last_status_code", "created_at"},
+		lengths:    map[string]int{"event_type": 64, "status": 16, "last_error": mysqldb.MaxOutboxErrorLength},
+		foreignKeys: []foreignKey{
+			{column: "subscription_id", table: mysqldb.WebhookSubscriptions},
+		},
+	},
+}
+
+// tableNames returns the names of the schema tables in a fixed order.
+func tableNames() []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeSet holds the identifiers of the rows inserted, updated or deleted by a transaction, per table.
+type writeSet map[string]map[int64]struct{}
+
+func (w writeSet) add(name string, id int64) {
+	if w[name] == nil {
+		w[name] = make(map[int64]struct{})
+	}
+	w[name][id] = struct{}{}
+}
+
+func (w writeSet) clone() writeSet {
+	copied := make(writeSet, len(w))
+	for name, ids := range w {
+		for id := range ids {
+			copied.add(name, id)
+		}
+	}
+	return copied
+}
+
+func (w writeSet) overlaps(other writeSet) bool {
+	for name, ids := range w {
+		for id := range ids {
+			if _, ok := other[name][id]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type commitRecord struct {
+	version uint64
+	written writeSet
+}
+
+// database holds the committed state. Transactions work on a snapshot of it and the first of two
+// concurrent transactions writing the same row wins, the other one fails to commit with ErrDeadlock.
+type database struct {
+	// Row identifier sequence. Accessed atomically, kept first for 64 bit alignment.
+	lastRowID int64
+
+	mu      sync.Mutex
+	tables  map[string]table
+	version uint64
+	// Write sets of the commits that happened after the start of the oldest active transaction.
+	commits []commitRecord
+	active  map[*transaction]struct{}
+}
+
+func newDatabase() *database {
+	db := &database{
+		tables: make(map[string]table),
+		active: make(map[*transaction]struct{}),
+	}
+	for name := range schema {
+		db.tables[name] = make(table)
+	}
+	return db
+}
+
+func (db *database) nextRowID() int64 {
+	return atomic.AddInt64(&db.lastRowID, 1)
+}
+
+// begin takes a snapshot of the committed state.
+func (db *database) begin() *transaction {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t := &transaction{
+		db:      db,
+		version: db.version,
+		tables:  make(map[string]table, len(db.tables)),
+		owned:   make(map[string]bool),
+		written: make(writeSet),
+	}
+	for name, tbl := range db.tables {
+		t.tables[name] = tbl
+	}
+	db.active[t] = struct{}{}
+	return t
+}
+
+// finish forgets the transaction and the commit records no active transaction needs anymore.
+// Must be called with db.mu held.
+func (db *database) finish(t *transaction) {
+	delete(db.active, t)
+
+	oldest := db.version
+	for active := range db.active {
+		if active.version < oldest {
+			oldest = active.version
+		}
+	}
+
+	kept := db.commits[:0]
+	for _, commit := range db.commits {
+		if commit.version > oldest {
+			kept = append(kept, commit)
+		}
+	}
+	db.commits = kept
+}
+
+type savepoint struct {
+	name    string
+	tables  map[string]table
+	written writeSet
+}
+
+// transaction is the state behind a *sql.Tx of the memory backend.
+type transaction struct {
+	mu      sync.Mutex
+	db      *database
+	version uint64
+	tables  map[string]table
+	// Tables already copied by the transaction since the start or the last savepoint.
+	owned      map[string]bool
+	written    writeSet
+	savepoints []savepoint
+	done       bool
+	// The *sql.Tx registered for the transaction, guarded by the lock of transactions.
+	sqlTx *sql.Tx
+}
+
+func (t *transaction) isDone() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.done
+}
+
+// table returns the snapshot of the table. Must be called with t.mu held.
+func (t *transaction) table(name string) (table, error) {
+	if t.done {
+		return nil, sql.ErrTxDone
+	}
+
+	tbl, ok := t.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("Table 'memory.%s' doesn't exist", name)
+	}
+	return tbl, nil
+}
+
+// writable returns a copy of the table owned by the transaction, so that it can be modified
+// without changing the committed state or the savepoints. Must be called with t.mu held.
+func (t *transaction) writable(name string) table {
+	if !t.owned[name] {
+		t.tables[name] = t.tables[name].clone()
+		t.owned[name] = true
+	}
+	return t.tables[name]
+}
+
+// sortedIDs returns the row identifiers of the table in insertion order.
+func sortedIDs(tbl table) []int64 {
+	IDs := make([]int64, 0, len(tbl))
+	for id := range tbl {
+		IDs = append(IDs, id)
+	}
+	sort.Slice(IDs, func(i, j int) bool { return IDs[i] < IDs[j] })
+	return IDs
+}
+
+// selectRows returns the rows of the table matching where in insertion order.
+func (t *transaction) selectRows(name string, where func(r row) bool) ([]row, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, err := t.table(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]row, 0)
+	for _, id := range sortedIDs(tbl) {
+		if where(tbl[id]) {
+			rows = append(rows, tbl[id])
+		}
+	}
+	return rows, nil
+}
+
+// insertRow adds the row to the table after checking the constraints.
+func (t *transaction) insertRow(name string, r row) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, err := t.table(name)
+	if err != nil {
+		return err
+	}
+
+	if err := t.checkRow(name, tbl, 0, r); err != nil {
+		return err
+	}
+
+	id := t.db.nextRowID()
+	t.writable(name)[id] = r
+	t.written.add(name, id)
+	return nil
+}
+
+// updateRows replaces the rows of the table matching where with the result of set and returns the number of changed rows.
+func (t *transaction) updateRows(name string, where func(r row) bool, set func(r row) row) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, err := t.table(name)
+	if err != nil {
+		return 0, err
+	}
+
+	affected := 0
+	for _, id := range sortedIDs(tbl) {
+		if !where(tbl[id]) {
+			continue
+		}
+
+		updated := set(tbl[id])
+		if err := t.checkRow(name, tbl, id, updated); err != nil {
+			return affected, err
+		}
+		if err := t.checkReferences(name, tbl[id], updated); err != nil {
+			return affected, err
+		}
+
+		tbl = t.writable(name)
+		tbl[id] = updated
+		t.written.add(name, id)
+		affected++
+	}
+	return affected, nil
+}
+
+// deleteRows removes the rows of the table matching where and returns the number of deleted rows.
+// Deleting a row still referenced by another table fails with ErrRowReferenced.
+func (t *transaction) deleteRows(name string, where func(r row) bool) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tbl, err := t.table(name)
+	if err != nil {
+		return 0, err
+	}
+
+	affected := 0
+	for _, id := range sortedIDs(tbl) {
+		if !where(tbl[id]) {
+			continue
+		}
+
+		if err := t.checkReferences(name, tbl[id], nil); err != nil {
+			return affected, err
+		}
+
+		tbl = t.writable(name)
+		delete(tbl, id)
+		t.written.add(name, id)
+		affected++
+	}
+	return affected, nil
+}
+
+// checkRow validates the row against the constraints of the table. The row identified by self is skipped
+// by the uniqueness check, so that an updated row does not collide with its previous version.
+func (t *transaction) checkRow(name string, tbl table, self int64, r row) error {
+	tableSchema := schema[name]
+	for _, column := range tableSchema.notNull {
+		if r[column] == nil {
+			return notNullError(column)
+		}
+	}
+
+	for column, length := range tableSchema.lengths {
+		if value, ok := r[column].(string); ok && utf8.RuneCountInString(value) > length {
+			return dataTooLongError(column)
+		}
+	}
+
+	for _, column := range tableSchema.uniqueColumns() {
+		for id, other := range tbl {
+			if id != self && r[column] != nil && other[column] == r[column] {
+				return duplicateEntryError(name, column, r[column])
+			}
+		}
+	}
+
+	for i, key := range tableSchema.foreignKeys {
+		if r[key.column] == nil {
+			continue
+		}
+		if !containsKey(t.tables[key.table], r[key.column]) {
+			return foreignKeyError(name, i, key)
+		}
+	}
+	return nil
+}
+
+// checkReferences fails if the primary key of the parent row is referenced by another table and the row
+// is deleted or its key is changed. updated is nil on delete.
+func (t *transaction) checkReferences(name string, parent row, updated row) error {
+	primaryKey := schema[name].primaryKey
+	if primaryKey == "" || (updated != nil && updated[primaryKey] == parent[primaryKey]) {
+		return nil
+	}
+
+	for _, childName := range tableNames() {
+		for i, key := range schema[childName].foreignKeys {
+			if key.table != name {
+				continue
+			}
+			for _, child := range t.tables[childName] {
+				if child[key.column] == parent[primaryKey] {
+					return rowReferencedError(childName, i, key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func containsKey(tbl table, value interface{}) bool {
+	for _, r := range tbl {
+		if r["id"] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// commit publishes the changes of the transaction. If other transactions committed since the snapshot
+// was taken, the changes are merged into the current state as long as no row was written by both,
+// otherwise the commit fails with ErrDeadlock and the transaction can be executed again.
+func (t *transaction) commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	db := t.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	defer db.finish(t)
+
+	if len(t.written) == 0 {
+		return nil
+	}
+
+	if db.version == t.version {
+		db.tables = t.tables
+	} else {
+		for _, commit := range db.commits {
+			if commit.version > t.version && commit.written.overlaps(t.written) {
+				return deadlockError()
+			}
+		}
+
+		merged := make(map[string]table, len(db.tables))
+		for name, tbl := range db.tables {
+			merged[name] = tbl
+		}
+		for name, IDs := range t.written {
+			tbl := merged[name].clone()
+			for id := range IDs {
+				if r, ok := t.tables[name][id]; ok {
+					tbl[id] = r
+				} else {
+					delete(tbl, id)
+				}
+			}
+			merged[name] = tbl
+		}
+
+		// The constraints were checked against the snapshot only, concurrent commits may have invalidated them.
+		if err := validate(merged, t.written); err != nil {
+			return err
+		}
+		db.tables = merged
+	}
+
+	db.version++
+	db.commits = append(db.commits, commitRecord{version: db.version, written: t.written})
+	return nil
+}
+
+func (t *transaction) rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.finish(t)
+	return nil
+}
+
+func (t *transaction) savepoint(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return sql.ErrTxDone
+	}
+
+	tables := make(map[string]table, len(t.tables))
+	for tableName, tbl := range t.tables {
+		tables[tableName] = tbl
+	}
+	t.savepoints = append(t.savepoints, savepoint{name: name, tables: tables, written: t.written.clone()})
+	// The savepoint shares the tables, the next change must copy them again.
+	t.owned = make(map[string]bool)
+	return nil
+}
+
+// findSavepoint returns the index of the latest savepoint with the name. Must be called with t.mu held.
+func (t *transaction) findSavepoint(name string) (int, error) {
+	if t.done {
+		return 0, sql.ErrTxDone
+	}
+
+	for i := len(t.savepoints) - 1; i >= 0; i-- {
+		if t.savepoints[i].name == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("SAVEPOINT %s does not exist", name)
+}
+
+// rollbackToSavepoint undoes the changes made after the savepoint. The savepoint itself is kept,
+// the ones created after it are removed.
+func (t *transaction) rollbackToSavepoint(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i, err := t.findSavepoint(name)
+	if err != nil {
+		return err
+	}
+
+	t.tables = make(map[string]table, len(t.savepoints[i].tables))
+	for tableName, tbl := range t.savepoints[i].tables {
+		t.tables[tableName] = tbl
+	}
+	t.written = t.savepoints[i].written.clone()
+	t.owned = make(map[string]bool)
+	t.savepoints = t.savepoints[:i+1]
+	return nil
+}
+
+func (t *transaction) releaseSavepoint(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i, err := t.findSavepoint(name)
+	if err != nil {
+		return err
+	}
+
+	t.savepoints = t.savepoints[:i]
+	return nil
+}
+
+// validate checks the uniqueness and the foreign keys of every table. A dangling reference is reported
+// as ErrForeignKeyViolation if the child row was written by the transaction, otherwise the transaction
+// deleted the parent and ErrRowReferenced is returned.
+func validate(tables map[string]table, written writeSet) error {
+	for _, name := range tableNames() {
+		tbl := tables[name]
+		for _, column := range schema[name].uniqueColumns() {
+			values := make(map[interface{}]struct{}, len(tbl))
+			for _, id := range sortedIDs(tbl) {
+				value := tbl[id][column]
+				if value == nil {
+					continue
+				}
+				if _, ok := values[value]; ok {
+					return duplicateEntryError(name, column, value)
+				}
+				values[value] = struct{}{}
+			}
+		}
+
+		for i, key := range schema[name].foreignKeys {
+			for id, r := range tbl {
+				if r[key.column] == nil || containsKey(tables[key.table], r[key.column]) {
+					continue
+				}
+				if _, ok := written[name][id]; ok {
+					return foreignKeyError(name, i, key)
+				}
+				return rowReferencedError(name, i, key)
+			}
+		}
+	}
+	return nil
+}
+
+// The constraint errors carry the MySQL error numbers and messages, so that they read the same as the ones
+// of the MySQL backend.
+
+func constraintName(name string, index int) string {
+	return fmt.Sprintf("%s_ibfk_%d", name, index+1)
+}
+
+func duplicateEntryError(name string, column string, value interface{}) error {
+	return &mysqldb.SQLError{
+		Kind:       mysqldb.ErrDuplicateEntry,
+		Number:     mysqldb.ErDupEntry,
+		Table:      name,
+		Constraint: column,
+		Column:     column,
+		Value:      fmt.Sprint(value),
+		Err:        errors.Errorf("Duplicate entry '%v' for key '%s.%s'", value, name, column),
+	}
+}
+
+func foreignKeyError(name string, index int, key foreignKey) error {
+	return &mysqldb.SQLError{
+		Kind:            mysqldb.ErrForeignKeyViolation,
+		Number:          mysqldb.ErNoReferencedRow2,
+		Table:           name,
+		Constraint:      constraintName(name, index),
+		Column:          key.column,
+		ReferencedTable: key.table,
+		Err: errors.Errorf(
+			"Cannot add or update a child row: a foreign key constraint fails (`memory`.`%s`, CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES `%s` (`id`))",
+			name, constraintName(name, index), key.column, key.table),
+	}
+}
+
+func rowReferencedError(name string, index int, key foreignKey) error {
+	return &mysqldb.SQLError{
+		Kind:            mysqldb.ErrRowReferenced,
+		Number:          mysqldb.ErRowIsReferenced2,
+		Table:           name,
+		Constraint:      constraintName(name, index),
+		Column:          key.column,
+		ReferencedTable: key.table,
+		Err: errors.Errorf(
+			"Cannot delete or update a parent row: a foreign key constraint fails (`memory`.`%s`, CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES `%s` (`id`))",
+			name, constraintName(name, index), key.column, key.table),
+	}
+}
+
+func notNullError(column string) error {
+	return &mysqldb.SQLError{
+		Kind:   mysqldb.ErrNotNullViolation,
+		Number: mysqldb.ErBadNull,
+		Column: column,
+		Err:    errors.Errorf("Column '%s' cannot be null", column),
+	}
+}
+
+func dataTooLongError(column string) error {
+	return &mysqldb.SQLError{
+		Kind:   mysqldb.ErrDataTooLong,
+		Number: mysqldb.ErDataTooLong,
+		Column: column,
+		Err:    errors.Errorf("Data too long for column '%s' at row 1", column),
+	}
+}
+
+func deadlockError() error {
+	return &mysqldb.SQLError{
+		Kind:   mysqldb.ErrDeadlock,
+		Number: mysqldb.ErLockDeadlock,
+		Err:    errors.New("Deadlock found when trying to get lock; try restarting transaction"),
+	}
+}
@@ -0,0 +1,144 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// notDeleted restricts where to the rows that are not in the trash.
+func notDeleted(where func(r row) bool) func(r row) bool {
+	return func(r row) bool {
+		return r["deleted_at"] == nil && where(r)
+	}
+}
+
+// deletedAtTime returns a condition matching the rows deleted exactly at deletedAt.
+func deletedAtTime(deletedAt time.Time) func(r row) bool {
+	return func(r row) bool {
+		t, ok := r["deleted_at"].(time.Time)
+		return ok && t.Equal(deletedAt)
+	}
+}
+
+// withDeletedAt returns a copy of r with the delete mark set to value, nil clears the mark.
+func withDeletedAt(r row, value interface{}) row {
+	updated := make(row, len(r)+1)
+	for column, v := range r {
+		updated[column] = v
+	}
+	updated["deleted_at"] = value
+	return updated
+}
+
+func checkTrashType(entityType string) error {
+	if !mysqldb.IsTrashType(entityType) {
+		return errors.Errorf("Table '%s' doesn't exist", entityType)
+	}
+	return nil
+}
+
+func (*MemoryFunctions) TrashEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if err := checkTrashType(entityType); err != nil {
+		return err
+	}
+
+	return updateRows(tx, errors.WithMessage(mysqldb.ErrEntityMissing, entityType), entityType, notDeleted(hasID("id", *ID)), func(r row) row {
+		return withDeletedAt(r, deletedAt.UTC())
+	})
+}
+
+func (*MemoryFunctions) RestoreEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	if err := checkTrashType(entityType); err != nil {
+		return err
+	}
+
+	isEntity := hasID("id", *ID)
+	isDeleted := deletedAtTime(deletedAt)
+	where := func(r row) bool {
+		return isEntity(r) && isDeleted(r)
+	}
+	return updateRows(tx, errors.WithMessage(mysqldb.ErrEntityMissing, entityType), entityType, where, func(r row) row {
+		return withDeletedAt(r, nil)
+	})
+}
+
+func (*MemoryFunctions) TrashProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	return updateRows(tx, nil, mysqldb.Projects, notDeleted(hasID("products_id", *productID)), func(r row) row {
+		return withDeletedAt(r, deletedAt.UTC())
+	})
+}
+
+func (*MemoryFunctions) RestoreProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	isProductProject := hasID("products_id", *productID)
+	isDeleted := deletedAtTime(deletedAt)
+	where := func(r row) bool {
+		return isProductProject(r) && isDeleted(r)
+	}
+	return updateRows(tx, nil, mysqldb.Projects, where, func(r row) row {
+		return withDeletedAt(r, nil)
+	})
+}
+
+// GetTrash lists the entities of entityType deleted at or before deletedBefore, the oldest first.
+func (*MemoryFunctions) GetTrash(ctx context.Context, entityType string, deletedBefore time.Time, tx *sql.Tx) ([]models.TrashEntry, error) {
+	if err := checkTrashType(entityType); err != nil {
+		return nil, err
+	}
+
+	rows, err := selectRows(tx, entityType, func(r row) bool {
+		t, ok := r["deleted_at"].(time.Time)
+		return ok && !t.After(deletedBefore)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	entries := make([]models.TrashEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, models.TrashEntry{
+			Type:      entityType,
+			ID:        r["id"].(uuid.UUID),
+			DeletedAt: r["deleted_at"].(time.Time),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.Before(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// GetTrashEntry returns the entity if it is in the trash or sql.ErrNoRows.
+func (*MemoryFunctions) GetTrashEntry(ctx context.Context, entityType string, ID *uuid.UUID, tx *sql.Tx) (*models.TrashEntry, error) {
+	if err := checkTrashType(entityType); err != nil {
+		return nil, err
+	}
+
+	isEntity := hasID("id", *ID)
+	rows, err := selectRows(tx, entityType, func(r row) bool {
+		return isEntity(r) && r["deleted_at"] != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &models.TrashEntry{
+		Type:      entityType,
+		ID:        rows[0]["id"].(uuid.UUID),
+		DeletedAt: rows[0]["deleted_at"].(time.Time),
+	}, nil
+}
@@ -0,0 +1,110 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+func userFromRow(r row) models.User {
+	return models.User{
+		ID:         r["id"].(uuid.UUID),
+		Name:       r["name"].(string),
+		Email:      r["email"].(string),
+		Password:   []byte(r["password"].(string)),
+		SettingsID: r["user_settings_id"].(uuid.UUID),
+		AssetsID:   r["user_assets_id"].(uuid.UUID),
+	}
+}
+
+// GetUser returns the user defined by the key name and key value.
+// Key name can be either id or email.
+func (*MemoryFunctions) GetUser(ctx context.Context, queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error) {
+	var where func(r row) bool
+	if queryType == mysqldb.ByEmail {
+		email := strings.ReplaceAll(strings.ToLower(keyValue.(string)), " ", "")
+		where = func(r row) bool {
+			return r["email"] == email
+		}
+	} else {
+		ID, err := toUUID(keyValue)
+		if err != nil {
+			return nil, err
+		}
+		where = hasID("id", ID)
+	}
+
+	rows, err := selectRows(tx, "users", notDeleted(where))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	user := userFromRow(rows[0])
+	return &user, nil
+}
+
+func (*MemoryFunctions) GetUsersByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error) {
+	rows, err := selectRows(tx, "users", notDeleted(inIDs(IDs)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	users := make([]models.User, 0, len(rows))
+	for _, r := range rows {
+		users = append(users, userFromRow(r))
+	}
+	return users, nil
+}
+
+// AddUser creates a new user entry in the DB.
+// Email/Name are unique in DB. Duplicates will return error.
+func (*MemoryFunctions) AddUser(ctx context.Context, user *models.User, tx *sql.Tx) error {
+	return insertRow(tx, "users", row{
+		"id":               user.ID,
+		"name":             user.Name,
+		"email":            user.Email,
+		"password":         string(user.Password),
+		"user_settings_id": user.SettingsID,
+		"user_assets_id":   user.AssetsID,
+	})
+}
+
+func (*MemoryFunctions) DeleteUser(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoUserDeleted, "users", hasID("id", *ID))
+}
+
+func (*MemoryFunctions) GetProductUserIDs(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error) {
+	rows, err := selectRows(tx, "users_products", hasID("products_id", *productID))
+	if err != nil {
+		return nil, err
+	}
+
+	productUsers := models.ProductUserIDs{
+		UserMap:     make(map[uuid.UUID]int),
+		UserIDArray: make([]uuid.UUID, 0),
+	}
+	for _, r := range rows {
+		userID := r["users_id"].(uuid.UUID)
+		productUsers.UserMap[userID] = r["privileges_id"].(int)
+		productUsers.UserIDArray = append(productUsers.UserIDArray, userID)
+	}
+	return &productUsers, nil
+}
+
+func (*MemoryFunctions) DeleteProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrNoUserWithProduct, "users_products", func(r row) bool {
+		return r["products_id"] == *productID && r["users_id"] == *userID
+	})
+}
@@ -0,0 +1,256 @@
+package memorydb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+func webhookSubscriptionRow(subscription *models.WebhookSubscription) (row, error) {
+	// The event types are stored as JSON like the SQL backends, so later changes of the slice are not stored.
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return row{
+		"id":          subscription.ID,
+		"url":         subscription.URL,
+		"secret":      subscription.Secret,
+		"event_types": eventTypes,
+		"created_at":  subscription.CreatedAt.UTC(),
+	}, nil
+}
+
+func (*MemoryFunctions) AddWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
+	r, err := webhookSubscriptionRow(subscription)
+	if err != nil {
+		return err
+	}
+	return insertRow(tx, mysqldb.WebhookSubscriptions, r)
+}
+
+// UpdateWebhookSubscription replaces the URL, the secret and the event types of the subscription.
+// The caller has to check that the subscription exists, like with the MySQL backend.
+func (*MemoryFunctions) UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
+	updated, err := webhookSubscriptionRow(subscription)
+	if err != nil {
+		return err
+	}
+
+	return updateRows(tx, nil, mysqldb.WebhookSubscriptions, hasID("id", subscription.ID), func(r row) row {
+		updated["created_at"] = r["created_at"]
+		return updated
+	})
+}
+
+// DeleteWebhookSubscription returns ErrWebhookSubscriptionMissing if the subscription does not exist.
+// The deliveries of the subscription have to be deleted first.
+func (*MemoryFunctions) DeleteWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, mysqldb.ErrWebhookSubscriptionMissing, mysqldb.WebhookSubscriptions, hasID("id", *ID))
+}
+
+func webhookSubscriptionFromRow(r row) (models.WebhookSubscription, error) {
+	subscription := models.WebhookSubscription{
+		ID:        r["id"].(uuid.UUID),
+		URL:       r["url"].(string),
+		Secret:    r["secret"].(string),
+		CreatedAt: r["created_at"].(time.Time),
+	}
+	err := json.Unmarshal(r["event_types"].([]byte), &subscription.EventTypes)
+	return subscription, err
+}
+
+// GetWebhookSubscription returns sql.ErrNoRows if the subscription does not exist.
+func (*MemoryFunctions) GetWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.WebhookSubscription, error) {
+	rows, err := selectRows(tx, mysqldb.WebhookSubscriptions, hasID("id", *ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	subscription, err := webhookSubscriptionFromRow(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetWebhookSubscriptions lists the subscriptions, the oldest first. sql.ErrNoRows is returned if there are none.
+func (*MemoryFunctions) GetWebhookSubscriptions(ctx context.Context, tx *sql.Tx) ([]models.WebhookSubscription, error) {
+	rows, err := selectRows(tx, mysqldb.WebhookSubscriptions, func(r row) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	subscriptions := make([]models.WebhookSubscription, 0, len(rows))
+	for _, r := range rows {
+		subscription, err := webhookSubscriptionFromRow(r)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+// AddWebhookDelivery queues the delivery of the event to the subscription.
+// An event already queued for the subscription fails with ErrDuplicateEntry.
+func (*MemoryFunctions) AddWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	t, err := lookup(tx)
+	if err != nil {
+		return err
+	}
+
+	// The unique key of the SQL backends spans two columns, the schema only checks single ones.
+	queued, err := t.selectRows(mysqldb.WebhookDeliveries, func(r row) bool {
+		return r["subscription_id"] == delivery.SubscriptionID && r["event_id"] == delivery.Event.ID
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(queued) > 0 {
+		return duplicateEntryError(mysqldb.WebhookDeliveries, "webhook_deliveries_event", fmt.Sprintf("%s-%d", delivery.SubscriptionID, delivery.Event.ID))
+	}
+
+	payload, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	// The row identifiers increase like an auto increment column.
+	return t.insertRow(mysqldb.WebhookDeliveries, row{
+		"id":               t.db.nextRowID(),
+		"subscription_id":  delivery.SubscriptionID,
+		"event_id":         delivery.Event.ID,
+		"event_type":       delivery.Event.Type,
+		"payload":          payload,
+		"status":           delivery.Status,
+		"attempts":         0,
+		"next_attempt_at":  delivery.NextAttemptAt.UTC(),
+		"last_attempt_at":  nil,
+		"last_status_code": 0,
+		"last_error":       nil,
+		"created_at":       delivery.CreatedAt.UTC(),
+	})
+}
+
+func webhookDeliveryFromRow(r row) (models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{
+		ID:             r["id"].(int64),
+		SubscriptionID: r["subscription_id"].(uuid.UUID),
+		Status:         r["status"].(string),
+		Attempts:       r["attempts"].(int),
+		NextAttemptAt:  r["next_attempt_at"].(time.Time),
+		LastStatusCode: r["last_status_code"].(int),
+		CreatedAt:      r["created_at"].(time.Time),
+	}
+	if lastAttemptAt, ok := r["last_attempt_at"].(time.Time); ok {
+		delivery.LastAttemptAt = &lastAttemptAt
+	}
+	if lastError, ok := r["last_error"].(string); ok {
+		delivery.LastError = lastError
+	}
+	err := json.Unmarshal(r["payload"].([]byte), &delivery.Event)
+	return delivery, err
+}
+
+func webhookDeliveriesFromRows(rows []row) ([]models.WebhookDelivery, error) {
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(rows))
+	for _, r := range rows {
+		delivery, err := webhookDeliveryFromRow(r)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDeliveries lists the deliveries of the subscription with an ID below beforeID, the latest first.
+// sql.ErrNoRows is returned if there are none.
+func (*MemoryFunctions) GetWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, beforeID int64, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	rows, err := selectRows(tx, mysqldb.WebhookDeliveries, func(r row) bool {
+		return r["subscription_id"] == *subscriptionID && r["id"].(int64) < beforeID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i]["id"].(int64) > rows[j]["id"].(int64)
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return webhookDeliveriesFromRows(rows)
+}
+
+// GetDueWebhookDeliveries lists at most limit pending deliveries due at dueAt, the oldest first.
+// sql.ErrNoRows is returned if there are none.
+func (*MemoryFunctions) GetDueWebhookDeliveries(ctx context.Context, dueAt time.Time, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	rows, err := selectRows(tx, mysqldb.WebhookDeliveries, func(r row) bool {
+		return r["status"] == models.WebhookPending && !r["next_attempt_at"].(time.Time).After(dueAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return webhookDeliveriesFromRows(rows)
+}
+
+// UpdateWebhookDelivery records the result of a delivery attempt.
+func (*MemoryFunctions) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	var lastAttemptAt, lastError interface{}
+	if delivery.LastAttemptAt != nil {
+		lastAttemptAt = delivery.LastAttemptAt.UTC()
+	}
+	if delivery.LastError != "" {
+		lastError = delivery.LastError
+	}
+
+	return updateRows(tx, nil, mysqldb.WebhookDeliveries, hasRowID(delivery.ID), func(r row) row {
+		updated := make(row, len(r))
+		for column, v := range r {
+			updated[column] = v
+		}
+		updated["status"] = delivery.Status
+		updated["attempts"] = delivery.Attempts
+		updated["next_attempt_at"] = delivery.NextAttemptAt.UTC()
+		updated["last_attempt_at"] = lastAttemptAt
+		updated["last_status_code"] = delivery.LastStatusCode
+		updated["last_error"] = lastError
+		return updated
+	})
+}
+
+// DeleteWebhookDeliveries deletes the delivery log of the subscription.
+func (*MemoryFunctions) DeleteWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, tx *sql.Tx) error {
+	return deleteRows(tx, nil, mysqldb.WebhookDeliveries, hasID("subscription_id", *subscriptionID))
+}
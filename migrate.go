@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/artofimagination/mysql-user-db-go-interface/initialization"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/pkg/errors"
+)
+
+const migrateUsage = `Usage: %s migrate <command>
+
+Commands:
+  status         list the migrations and whether they are applied
+  up [--to ID]   apply the pending migrations, up to and including ID if set
+  down [N]       revert the last N applied migrations, 1 by default
+  redo           revert the last applied migration and apply it again
+  check          compare the DB schema with the one expected by the code
+`
+
+// runMigrate executes the migrate subcommand on the DB backend selected in cfg.
+func runMigrate(cfg *initialization.Config, args []string) (err error) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		return fmt.Errorf("Missing migrate command")
+	}
+
+	migrator, dbConnector, err := dbcontrollers.NewMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errClose := dbConnector.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}()
+
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(migrator)
+	case "up":
+		flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+		to := flags.String("to", "", "ID of the last migration to apply")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		n, err := migrator.Up(*to)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migrations!\n", n)
+		return nil
+	case "down":
+		count := 1
+		if len(args) > 1 {
+			count, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("Invalid number of migrations: %s", args[1])
+			}
+		}
+
+		n, err := migrator.Down(count)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migrations!\n", n)
+		return nil
+	case "redo":
+		if err := migrator.Redo(); err != nil {
+			return err
+		}
+		fmt.Printf("Reapplied the last migration!\n")
+		return nil
+	case "check":
+		return printSchemaDrift(dbConnector)
+	default:
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		return fmt.Errorf("Unknown migrate command %s", args[0])
+	}
+}
+
+func printMigrationStatus(migrator *mysqldb.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "MIGRATION\tAPPLIED")
+	for _, status := range statuses {
+		applied := "no"
+		if status.Applied {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", status.ID, applied)
+	}
+	return writer.Flush()
+}
+
+// printSchemaDrift lists the differences of the DB schema and returns an error if there is any.
+func printSchemaDrift(dbConnector mysqldb.ConnectorCommon) error {
+	checker, ok := dbConnector.(mysqldb.SchemaChecker)
+	if !ok {
+		return fmt.Errorf("The schema check is not supported by the DB backend")
+	}
+
+	drifts, err := checker.CheckSchema(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, drift := range drifts {
+		fmt.Println(drift)
+	}
+
+	if len(drifts) > 0 {
+		return errors.Wrapf(mysqldb.ErrSchemaDrift, "%d differences found", len(drifts))
+	}
+	fmt.Printf("The DB schema is up to date!\n")
+	return nil
+}
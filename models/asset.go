@@ -19,6 +19,9 @@ var ErrAssetRefNotInitialised = errors.New("Asset references not initialised")
 type Asset struct {
 	ID      uuid.UUID `json:"id" validate:"required"`
 	DataMap DataMap   `json:"datamap" validate:"required"`
+	// Version is incremented by every update. If it is set, an update only succeeds
+	// if the stored asset still has the same version.
+	Version int64 `json:"version"`
 }
 
 // Assets structure contains the identification of all user related documents images.
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionTrash   = "trash"
+	ActionRestore = "restore"
+)
+
+// Page sizes of the audit log queries.
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 500
+)
+
+var ErrInvalidAuditFilter = errors.New("Invalid audit filter")
+
+// AuditEvent is a change of a user, product, project, privilege, viewer or asset.
+type AuditEvent struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// The user making the change, nil if it was not made on behalf of a user, like the trash purge.
+	Actor  *uuid.UUID `json:"actor"`
+	Action string     `json:"action"`
+	// Name of the table of the changed entity.
+	EntityType string    `json:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id"`
+	// Snapshots of the entity, Before is nil for a create and After for a delete.
+	Before DataMap `json:"before"`
+	After  DataMap `json:"after"`
+}
+
+// AuditFilter selects the audit events. The zero values do not restrict the result.
+// The events are listed the latest first, the next page starts before the ID of the last event returned.
+type AuditFilter struct {
+	Actor      *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   *uuid.UUID
+	// Time range of the events, From is inclusive, To is exclusive.
+	From     time.Time
+	To       time.Time
+	BeforeID int64
+	Limit    int
+}
+
+// IsAuditAction reports whether action is one of the recorded actions.
+func IsAuditAction(action string) bool {
+	switch action {
+	case ActionCreate, ActionUpdate, ActionDelete, ActionTrash, ActionRestore:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks the filter and sets the default limit if it is not set.
+func (f *AuditFilter) Validate() error {
+	if f.Action != "" && !IsAuditAction(f.Action) {
+		return fmt.Errorf("%w: unknown action '%s'", ErrInvalidAuditFilter, f.Action)
+	}
+
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return fmt.Errorf("%w: from has to be before to", ErrInvalidAuditFilter)
+	}
+
+	if f.BeforeID < 0 {
+		return fmt.Errorf("%w: negative before_id", ErrInvalidAuditFilter)
+	}
+
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultAuditLimit
+	case f.Limit < 0 || f.Limit > MaxAuditLimit:
+		return fmt.Errorf("%w: limit has to be between 1 and %d", ErrInvalidAuditFilter, MaxAuditLimit)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+func TestAuditFilterValidate(t *testing.T) {
+	filter := &AuditFilter{Action: ActionTrash}
+	err := filter.Validate()
+	tests.CheckResult(filter.Limit, DefaultAuditLimit, err, nil, "default_limit", t)
+
+	now := time.Now()
+	invalid := map[string]AuditFilter{
+		"unknown_action":  {Action: "rename"},
+		"empty_range":     {From: now, To: now},
+		"negative_cursor": {BeforeID: -1},
+		"negative_limit":  {Limit: -1},
+		"large_limit":     {Limit: MaxAuditLimit + 1},
+	}
+	for name, filter := range invalid {
+		filter := filter
+		if err := filter.Validate(); !errors.Is(err, ErrInvalidAuditFilter) {
+			t.Errorf("%s: expected an invalid filter error, got %v", name, err)
+		}
+	}
+}
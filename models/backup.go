@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BackupFormat identifies the dump format in the header record, it is changed if the records change incompatibly.
+const BackupFormat = "mysql-user-db-backup/1"
+
+// Types of the dump records.
+const (
+	BackupHeader    = "header"
+	BackupRowRecord = "row"
+	BackupEnd       = "end"
+)
+
+// BackupRow is a table row of the dump keyed by the column names.
+// The values are uuid.UUID, string, int64, json.RawMessage or time.Time, NULL columns are nil.
+type BackupRow map[string]interface{}
+
+// BackupRecord is a single line of the dump. The dump starts with a header, continues with the rows
+// of the tables in dependency order and finishes with an end record counting the rows,
+// so that a truncated dump is detected before it is committed.
+type BackupRecord struct {
+	Type string `json:"type"`
+	// Set in the header.
+	Format        string     `json:"format,omitempty"`
+	SchemaVersion string     `json:"schema_version,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	// Set in the row records.
+	Table string                     `json:"table,omitempty"`
+	Row   map[string]json.RawMessage `json:"row,omitempty"`
+	// Set in the end record.
+	Rows int64 `json:"rows,omitempty"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Keys of the snapshots referring to the related users, products and projects.
+var referenceKeys = []string{"user_id", "product_id", "project_id"}
+
+// ChangeFilter selects the audit events of a change stream. The zero value selects every change,
+// with both IDs set a change has to match both.
+type ChangeFilter struct {
+	// EntityID selects the changes of a user, product or project and of the privileges, viewers and projects referring to it.
+	EntityID *uuid.UUID
+	// UserID selects the changes made by a user and the changes of the user and of its privileges and viewers.
+	UserID *uuid.UUID
+	// IDs of the settings, details and assets of the selected entity and user, their updates are selected too.
+	EntityAssets []uuid.UUID
+	UserAssets   []uuid.UUID
+}
+
+// refersTo reports whether the snapshots of the event refer to ID.
+func (e *AuditEvent) refersTo(ID uuid.UUID) bool {
+	for _, snapshot := range []DataMap{e.Before, e.After} {
+		for _, key := range referenceKeys {
+			if reference, ok := snapshot[key].(string); ok && reference == ID.String() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// concerns reports whether the event is a change of the entity ID, one of its assets or a relation referring to it.
+func (e *AuditEvent) concerns(ID uuid.UUID, assets []uuid.UUID) bool {
+	if e.EntityID == ID || e.refersTo(ID) {
+		return true
+	}
+
+	for _, asset := range assets {
+		if e.EntityID == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the event is selected by the filter.
+func (f *ChangeFilter) Matches(event *AuditEvent) bool {
+	if f.EntityID != nil && !event.concerns(*f.EntityID, f.EntityAssets) {
+		return false
+	}
+
+	if f.UserID != nil {
+		madeByUser := event.Actor != nil && *event.Actor == *f.UserID
+		if !madeByUser && !event.concerns(*f.UserID, f.UserAssets) {
+			return false
+		}
+	}
+	return true
+}
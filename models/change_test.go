@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestChangeFilterMatches(t *testing.T) {
+	userID := uuid.New()
+	productID := uuid.New()
+	detailsID := uuid.New()
+	otherID := uuid.New()
+
+	product := &AuditEvent{Action: ActionCreate, EntityType: "products", EntityID: productID, Actor: &userID}
+	privilege := &AuditEvent{Action: ActionCreate, EntityType: "users_products", EntityID: productID, After: DataMap{"user_id": userID.String()}}
+	project := &AuditEvent{Action: ActionCreate, EntityType: "projects", EntityID: otherID, After: DataMap{"product_id": productID.String()}}
+	details := &AuditEvent{Action: ActionUpdate, EntityType: "product_details", EntityID: detailsID}
+	unrelated := &AuditEvent{Action: ActionDelete, EntityType: "users", EntityID: otherID, Before: DataMap{"name": "otherUser"}}
+
+	all := &ChangeFilter{}
+	tests.CheckResult(all.Matches(unrelated), true, nil, nil, "no_filter", t)
+
+	byEntity := &ChangeFilter{EntityID: &productID, EntityAssets: []uuid.UUID{detailsID}}
+	tests.CheckResult(byEntity.Matches(product), true, nil, nil, "entity", t)
+	tests.CheckResult(byEntity.Matches(privilege), true, nil, nil, "entity_privilege", t)
+	tests.CheckResult(byEntity.Matches(project), true, nil, nil, "entity_project", t)
+	tests.CheckResult(byEntity.Matches(details), true, nil, nil, "entity_details", t)
+	tests.CheckResult(byEntity.Matches(unrelated), false, nil, nil, "entity_unrelated", t)
+
+	byUser := &ChangeFilter{UserID: &userID}
+	tests.CheckResult(byUser.Matches(product), true, nil, nil, "user_actor", t)
+	tests.CheckResult(byUser.Matches(privilege), true, nil, nil, "user_privilege", t)
+	tests.CheckResult(byUser.Matches(project), false, nil, nil, "user_unrelated", t)
+
+	both := &ChangeFilter{EntityID: &productID, UserID: &userID}
+	tests.CheckResult(both.Matches(privilege), true, nil, nil, "both", t)
+	tests.CheckResult(both.Matches(project), false, nil, nil, "both_user_missing", t)
+}
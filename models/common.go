@@ -21,6 +21,7 @@ type ModelFunctionsCommon interface {
 		assetsID uuid.UUID) (*User, error)
 	NewProduct(name string, detailsID *uuid.UUID, assetsID *uuid.UUID) (*Product, error)
 	NewProject(productID *uuid.UUID, detailsID *uuid.UUID, assetsID *uuid.UUID) (*Project, error)
+	NewWebhookSubscription(url string, secret string, eventTypes []string) (*WebhookSubscription, error)
 	GetFilePath(asset *Asset, typeString string, defaultPath string) string
 	SetFilePath(asset *Asset, typeString string, extension string) error
 	GetField(asset *Asset, typeString string, defaultURL string) interface{}
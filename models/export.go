@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserExport is everything stored about a user, the archive handed over to the user on request.
+type UserExport struct {
+	ExportedAt time.Time    `json:"exported_at"`
+	User       ExportedUser `json:"user"`
+	Settings   *Asset       `json:"settings"`
+	Assets     *Asset       `json:"assets"`
+	// The products and projects the user is a member of. The owned ones are listed with their data as well.
+	ProductMemberships []Membership  `json:"product_memberships"`
+	ProjectMemberships []Membership  `json:"project_memberships"`
+	OwnedProducts      []ProductData `json:"owned_products"`
+	OwnedProjects      []ProjectData `json:"owned_projects"`
+	// The project viewers of the user, IsOwner is set for the viewers owned by the user.
+	Viewers []ProjectViewer `json:"viewers"`
+}
+
+// ExportedUser is the user record without the password hash.
+type ExportedUser struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"username"`
+	Email string    `json:"email"`
+}
+
+// Membership is the privilege of the user on a product or project.
+type Membership struct {
+	ID          uuid.UUID `json:"id"`
+	PrivilegeID int       `json:"privilege_id"`
+	Privilege   string    `json:"privilege"`
+}
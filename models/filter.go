@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DetailsFilterPrefix is the prefix of the URL parameters selecting products and projects by their details.
+const DetailsFilterPrefix = "details."
+
+var ErrInvalidFilter = errors.New("Invalid details filter")
+
+// Keys of the filters. They are used as JSON paths, so only simple names are accepted.
+var filterKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// DetailsFilter selects the products or projects whose details contain every key with the same value.
+// The values are JSON scalars: string, float64, bool or nil.
+type DetailsFilter map[string]interface{}
+
+// ParseDetailsFilter returns the filter of the 'details.<key>=<value>' parameters of query.
+// A value is decoded as JSON if possible, so 'true', '12' and 'null' select booleans, numbers and nulls,
+// while '"12"' and any other text select strings.
+func ParseDetailsFilter(query url.Values) (DetailsFilter, error) {
+	filter := make(DetailsFilter)
+	for parameter, values := range query {
+		if !strings.HasPrefix(parameter, DetailsFilterPrefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(parameter, DetailsFilterPrefix)
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%w: '%s' has multiple values", ErrInvalidFilter, parameter)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(values[0]), &value); err != nil {
+			value = values[0]
+		}
+
+		filter[key] = value
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Validate fails if the filter is not usable in a query.
+func (f DetailsFilter) Validate() error {
+	for key, value := range f {
+		if !filterKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: invalid key '%s'", ErrInvalidFilter, key)
+		}
+
+		switch value.(type) {
+		case string, float64, bool, nil:
+		default:
+			return fmt.Errorf("%w: '%s' is not a string, number, boolean or null", ErrInvalidFilter, key)
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys of the filter in order, so the queries built from it are deterministic.
+func (f DetailsFilter) Keys() []string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Matches reports whether dataMap contains every key of the filter with the same value.
+func (f DetailsFilter) Matches(dataMap DataMap) bool {
+	for key, value := range f {
+		stored, ok := dataMap[key]
+		if !ok || stored != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+func TestParseDetailsFilter(t *testing.T) {
+	query, err := url.ParseQuery(`ids=1&details.is_free=true&details.price=12&details.name=test&details.code="12"&details.removed=null`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := ParseDetailsFilter(query)
+	expected := DetailsFilter{
+		"is_free": true,
+		"price":   float64(12),
+		"name":    "test",
+		"code":    "12",
+		"removed": nil,
+	}
+	tests.CheckResult(filter, expected, err, nil, "valid", t)
+	tests.CheckResult(filter.Keys(), []string{"code", "is_free", "name", "price", "removed"}, nil, nil, "keys", t)
+
+	invalid := []string{
+		`details.a.b=1`,
+		`details.$.a=1`,
+		`details.a=1&details.a=2`,
+		`details.a={"b": 1}`,
+		`details.a=[1]`,
+	}
+	for _, rawQuery := range invalid {
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseDetailsFilter(query); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", rawQuery, err)
+		}
+	}
+}
+
+func TestDetailsFilterMatches(t *testing.T) {
+	dataMap := DataMap{"is_free": true, "price": float64(12), "name": "test", "tags": []interface{}{"a"}}
+
+	tests.CheckResult(DetailsFilter{"is_free": true, "price": float64(12)}.Matches(dataMap), true, nil, nil, "match", t)
+	tests.CheckResult(DetailsFilter{"is_free": "true"}.Matches(dataMap), false, nil, nil, "different_type", t)
+	tests.CheckResult(DetailsFilter{"tags": "a"}.Matches(dataMap), false, nil, nil, "not_scalar", t)
+	tests.CheckResult(DetailsFilter{"missing": nil}.Matches(dataMap), false, nil, nil, "missing_key", t)
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of the changes between two versions of an asset.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// AssetRevision is a version of an asset that has been overwritten by an update.
+type AssetRevision struct {
+	AssetID uuid.UUID `json:"asset_id"`
+	Version int64     `json:"version"`
+	// Only set if a single revision is read, the history lists the versions without their data.
+	DataMap DataMap `json:"datamap,omitempty"`
+	// Time and user of the update replacing this version. The user is nil if the update was not made on behalf of a user.
+	ChangedAt time.Time  `json:"changed_at"`
+	ChangedBy *uuid.UUID `json:"changed_by"`
+}
+
+// DataChange is a single difference between two versions of a DataMap.
+type DataChange struct {
+	// JSON pointer of the changed value.
+	Path string      `json:"path"`
+	Kind string      `json:"kind"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// DiffDataMaps returns the changes turning from into to, ordered by their path.
+// Nested objects are compared key by key, any other values including arrays as a whole.
+func DiffDataMaps(from DataMap, to DataMap) []DataChange {
+	changes := make([]DataChange, 0)
+	diffObjects("", from, to, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func diffObjects(path string, from map[string]interface{}, to map[string]interface{}, changes *[]DataChange) {
+	for key, fromValue := range from {
+		keyPath := path + "/" + escapePointer(key)
+		toValue, ok := to[key]
+		if !ok {
+			*changes = append(*changes, DataChange{Path: keyPath, Kind: ChangeRemoved, From: fromValue})
+			continue
+		}
+
+		fromObject, fromIsObject := asObject(fromValue)
+		toObject, toIsObject := asObject(toValue)
+		switch {
+		case fromIsObject && toIsObject:
+			diffObjects(keyPath, fromObject, toObject, changes)
+		case !reflect.DeepEqual(fromValue, toValue):
+			*changes = append(*changes, DataChange{Path: keyPath, Kind: ChangeChanged, From: fromValue, To: toValue})
+		}
+	}
+
+	for key, toValue := range to {
+		if _, ok := from[key]; !ok {
+			*changes = append(*changes, DataChange{Path: path + "/" + escapePointer(key), Kind: ChangeAdded, To: toValue})
+		}
+	}
+}
+
+func asObject(value interface{}) (map[string]interface{}, bool) {
+	switch object := value.(type) {
+	case map[string]interface{}:
+		return object, true
+	case DataMap:
+		return object, true
+	default:
+		return nil, false
+	}
+}
+
+// escapePointer escapes a key as a JSON pointer token (RFC 6901).
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+type actingUserKey struct{}
+
+// WithActingUser returns a context recording userID as the user making the changes,
+// so the asset history can tell who replaced a version.
+func WithActingUser(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actingUserKey{}, userID)
+}
+
+// ActingUser returns the user set by WithActingUser or nil.
+func ActingUser(ctx context.Context) *uuid.UUID {
+	userID, ok := ctx.Value(actingUserKey{}).(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return &userID
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestDiffDataMaps(t *testing.T) {
+	from := DataMap{
+		"name":  "Product",
+		"tags":  []interface{}{"a", "b"},
+		"price": float64(10),
+		"theme": map[string]interface{}{"color": "red", "font": "mono"},
+		"a/b":   true,
+	}
+	to := DataMap{
+		"name":  "Product",
+		"tags":  []interface{}{"a"},
+		"theme": map[string]interface{}{"color": "blue", "font": "mono", "size": float64(12)},
+		"a/b":   "true",
+		"new":   nil,
+	}
+
+	expected := []DataChange{
+		{Path: "/a~1b", Kind: ChangeChanged, From: true, To: "true"},
+		{Path: "/new", Kind: ChangeAdded, To: nil},
+		{Path: "/price", Kind: ChangeRemoved, From: float64(10)},
+		{Path: "/tags", Kind: ChangeChanged, From: []interface{}{"a", "b"}, To: []interface{}{"a"}},
+		{Path: "/theme/color", Kind: ChangeChanged, From: "red", To: "blue"},
+		{Path: "/theme/size", Kind: ChangeAdded, To: float64(12)},
+	}
+	tests.CheckResult(DiffDataMaps(from, to), expected, nil, nil, "diff", t)
+	tests.CheckResult(DiffDataMaps(from, from), []DataChange{}, nil, nil, "no_changes", t)
+	tests.CheckResult(len(DiffDataMaps(nil, DataMap{"a": 1})), 1, nil, nil, "from_nil", t)
+}
+
+func TestActingUser(t *testing.T) {
+	ctx := context.Background()
+	tests.CheckResult(ActingUser(ctx), (*uuid.UUID)(nil), nil, nil, "no_user", t)
+
+	userID := uuid.New()
+	tests.CheckResult(ActingUser(WithActingUser(ctx, userID)), &userID, nil, nil, "user", t)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the domain events published through the outbox.
+const (
+	EventUserCreated         = "user.created"
+	EventUserDeleted         = "user.deleted"
+	EventUserTrashed         = "user.trashed"
+	EventUserRestored        = "user.restored"
+	EventProductCreated      = "product.created"
+	EventProductDeleted      = "product.deleted"
+	EventProductTrashed      = "product.trashed"
+	EventProductRestored     = "product.restored"
+	EventProductOwnerChanged = "product.owner_changed"
+	EventProductUserAdded    = "product.user_added"
+	EventProductUserRemoved  = "product.user_removed"
+	EventProjectCreated      = "project.created"
+	EventProjectDeleted      = "project.deleted"
+	EventProjectTrashed      = "project.trashed"
+	EventProjectRestored     = "project.restored"
+	EventProjectOwnerChanged = "project.owner_changed"
+)
+
+// DomainEvent is a change other services may react to. The events are delivered at least once,
+// the consumers can recognize the redelivered ones by their ID.
+type DomainEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// The user making the change, nil if it was not made on behalf of a user.
+	Actor *uuid.UUID `json:"actor"`
+	// ID of the user, product or project the event is about.
+	AggregateID uuid.UUID `json:"aggregate_id"`
+	Payload     DataMap   `json:"payload"`
+	// Number of failed deliveries, not sent to the sinks.
+	Attempts int `json:"-"`
+}
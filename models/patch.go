@@ -0,0 +1,86 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+)
+
+// Media types of the supported asset patch documents.
+const (
+	// RFC 7386 JSON Merge Patch.
+	MergePatchType = "application/merge-patch+json"
+	// RFC 6902 JSON Patch.
+	JSONPatchType = "application/json-patch+json"
+)
+
+var ErrInvalidPatch = errors.New("Invalid patch document")
+var ErrPatchFailed = errors.New("Patch can not be applied to the asset")
+
+// AssetPatch is a partial update of the DataMap of an asset.
+type AssetPatch struct {
+	// MergePatchType or JSONPatchType.
+	Type     string
+	Document []byte
+	// Expected version of the asset. Any version is patched if it is zero.
+	Version int64
+}
+
+// NewAssetPatch validates the patch document. A merge patch has to be a JSON object,
+// because the DataMap of an asset can not be replaced by any other value.
+func NewAssetPatch(patchType string, document []byte, version int64) (*AssetPatch, error) {
+	switch patchType {
+	case MergePatchType:
+		object := make(map[string]interface{})
+		if err := json.Unmarshal(document, &object); err != nil || object == nil {
+			return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+		}
+	case JSONPatchType:
+		if _, err := jsonpatch.DecodePatch(document); err != nil || !bytes.HasPrefix(bytes.TrimSpace(document), []byte("[")) {
+			return nil, fmt.Errorf("%w: JSON patch must be an array of operations", ErrInvalidPatch)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported patch type '%s'", ErrInvalidPatch, patchType)
+	}
+
+	return &AssetPatch{Type: patchType, Document: document, Version: version}, nil
+}
+
+// Apply returns the patched copy of dataMap.
+func (p *AssetPatch) Apply(dataMap DataMap) (DataMap, error) {
+	if dataMap == nil {
+		dataMap = DataMap{}
+	}
+
+	original, err := json.Marshal(dataMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch p.Type {
+	case MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, p.Document)
+	case JSONPatchType:
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(p.Document)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+		}
+		patched, err = operations.Apply(original)
+	default:
+		return nil, fmt.Errorf("%w: unsupported patch type '%s'", ErrInvalidPatch, p.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPatchFailed, err.Error())
+	}
+
+	output := DataMap{}
+	if err := json.Unmarshal(patched, &output); err != nil || output == nil {
+		return nil, fmt.Errorf("%w: the result is not a JSON object", ErrPatchFailed)
+	}
+	return output, nil
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+func TestNewAssetPatch(t *testing.T) {
+	invalid := []struct {
+		patchType string
+		document  string
+	}{
+		{MergePatchType, `["not", "an", "object"]`},
+		{MergePatchType, `null`},
+		{MergePatchType, `{"broken":`},
+		{JSONPatchType, `{"op": "add", "path": "/a", "value": 1}`},
+		{JSONPatchType, `[{"op": "add", "path": "/a"`},
+		{"application/json", `{}`},
+	}
+
+	for _, testCase := range invalid {
+		_, err := NewAssetPatch(testCase.patchType, []byte(testCase.document), 0)
+		if !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("%s %s: expected ErrInvalidPatch, got %v", testCase.patchType, testCase.document, err)
+		}
+	}
+
+	patch, err := NewAssetPatch(MergePatchType, []byte(`{"a": 1}`), 3)
+	tests.CheckResult(patch, &AssetPatch{Type: MergePatchType, Document: []byte(`{"a": 1}`), Version: 3}, err, nil, "valid", t)
+}
+
+func TestAssetPatchApply(t *testing.T) {
+	dataMap := DataMap{
+		"name":  "Product",
+		"tags":  []interface{}{"a", "b"},
+		"theme": map[string]interface{}{"color": "red", "size": float64(12)},
+	}
+
+	merge, err := NewAssetPatch(MergePatchType, []byte(`{"name": "Renamed", "theme": {"color": null, "font": "mono"}}`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := merge.Apply(dataMap)
+	expected := DataMap{
+		"name":  "Renamed",
+		"tags":  []interface{}{"a", "b"},
+		"theme": map[string]interface{}{"size": float64(12), "font": "mono"},
+	}
+	tests.CheckResult(output, expected, err, nil, "merge_patch", t)
+
+	jsonPatch, err := NewAssetPatch(JSONPatchType, []byte(`[
+		{"op": "test", "path": "/name", "value": "Product"},
+		{"op": "add", "path": "/tags/-", "value": "c"},
+		{"op": "move", "from": "/theme/color", "path": "/color"},
+		{"op": "remove", "path": "/theme/size"}
+	]`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err = jsonPatch.Apply(dataMap)
+	expected = DataMap{
+		"name":  "Product",
+		"tags":  []interface{}{"a", "b", "c"},
+		"theme": map[string]interface{}{},
+		"color": "red",
+	}
+	tests.CheckResult(output, expected, err, nil, "json_patch", t)
+
+	// The original DataMap is never modified.
+	tests.CheckResult(dataMap["name"], "Product", nil, nil, "original", t)
+
+	failing, err := NewAssetPatch(JSONPatchType, []byte(`[{"op": "test", "path": "/name", "value": "Other"}]`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := failing.Apply(dataMap); !errors.Is(err, ErrPatchFailed) {
+		t.Errorf("failed test operation: expected ErrPatchFailed, got %v", err)
+	}
+
+	replaceRoot, err := NewAssetPatch(JSONPatchType, []byte(`[{"op": "replace", "path": "", "value": [1]}]`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replaceRoot.Apply(dataMap); !errors.Is(err, ErrPatchFailed) {
+		t.Errorf("replaced root: expected ErrPatchFailed, got %v", err)
+	}
+}
@@ -34,3 +34,13 @@ func (l Privileges) IsPartnerPrivilege(privilege int) bool {
 	}
 	return false
 }
+
+// Name returns the name of the privilege, it is empty for an unknown privilege.
+func (l Privileges) Name(privilege int) string {
+	for _, value := range l {
+		if value.ID == privilege {
+			return value.Name
+		}
+	}
+	return ""
+}
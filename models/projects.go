@@ -40,22 +40,6 @@ type ProjectViewer struct {
 	IsOwner   bool      `json:"is_owner" validate:"required"`
 }
 
-// Viewers describes a set of project data. Each set has a single viewer ID.
-// Whichever user possese this ID can view the data with this ID. The set of project data has a single owner user.
-// This structure contains the owner and the list of users who can view the data.
-type ViewersList []Viewer
-
-type Viewer struct {
-	IsOwner   bool
-	ViewerID  uuid.UUID
-	ProjectID uuid.UUID
-}
-
-type ViewUsers struct {
-	OwnerID   uuid.UUID
-	UsersList []uuid.UUID
-}
-
 func (f *RepoFunctions) NewProject(productID *uuid.UUID, detailsID *uuid.UUID, assetsID *uuid.UUID) (*Project, error) {
 	var p Project
 
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaFileExtension is the extension of the schema files loaded by LoadDirectory.
+const SchemaFileExtension = ".json"
+
+var ErrInvalidSchema = errors.New("Invalid JSON schema")
+var ErrSchemaValidation = errors.New("Asset data does not match its schema")
+var errSchemaReference = errors.New("Schemas can only reference themselves")
+
+// FieldError is a single validation error of an asset.
+type FieldError struct {
+	// JSON pointer of the invalid value in the DataMap, empty for the whole DataMap.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError lists every invalid field of an asset. It matches ErrSchemaValidation with errors.Is.
+type SchemaValidationError struct {
+	AssetType string
+	Fields    []FieldError
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = fmt.Sprintf("'%s' %s", field.Field, field.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", ErrSchemaValidation.Error(), e.AssetType, strings.Join(messages, ", "))
+}
+
+func (e *SchemaValidationError) Unwrap() error {
+	return ErrSchemaValidation
+}
+
+type assetSchema struct {
+	document []byte
+	schema   *jsonschema.Schema
+}
+
+// SchemaRegistry holds the JSON schema of the asset types. Assets of a type without a schema are not validated.
+// The registry is safe for concurrent use, a nil registry validates every asset.
+type SchemaRegistry struct {
+	mutex   sync.RWMutex
+	schemas map[string]*assetSchema
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas: make(map[string]*assetSchema),
+	}
+}
+
+// Register compiles document and replaces the schema of assetType with it.
+// Only self-contained schemas are accepted, references to other files or URLs fail.
+func (r *SchemaRegistry) Register(assetType string, document []byte) error {
+	url := "asset:///" + assetType + SchemaFileExtension
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(string) (io.ReadCloser, error) {
+		return nil, errSchemaReference
+	}
+	if err := compiler.AddResource(url, bytes.NewReader(document)); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSchema, err.Error())
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSchema, err.Error())
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.schemas[assetType] = &assetSchema{
+		document: document,
+		schema:   schema,
+	}
+	return nil
+}
+
+// LoadDirectory registers every '<asset type>.json' file of directory, for example 'user_settings.json'.
+func (r *SchemaRegistry) LoadDirectory(directory string) error {
+	files, err := filepath.Glob(filepath.Join(directory, "*"+SchemaFileExtension))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		document, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		assetType := strings.TrimSuffix(filepath.Base(file), SchemaFileExtension)
+		if err := r.Register(assetType, document); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// Remove deletes the schema of assetType, so its assets are not validated any more.
+func (r *SchemaRegistry) Remove(assetType string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, ok := r.schemas[assetType]
+	delete(r.schemas, assetType)
+	return ok
+}
+
+// Schema returns the registered document of assetType.
+func (r *SchemaRegistry) Schema(assetType string) ([]byte, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	schema, ok := r.schemas[assetType]
+	if !ok {
+		return nil, false
+	}
+	return schema.document, true
+}
+
+// AssetTypes returns the asset types with a schema in order.
+func (r *SchemaRegistry) AssetTypes() []string {
+	assetTypes := make([]string, 0)
+	if r == nil {
+		return assetTypes
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for assetType := range r.schemas {
+		assetTypes = append(assetTypes, assetType)
+	}
+	sort.Strings(assetTypes)
+	return assetTypes
+}
+
+// Validate checks dataMap against the schema of assetType and returns a *SchemaValidationError if it does not match.
+func (r *SchemaRegistry) Validate(assetType string, dataMap DataMap) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.RLock()
+	schema, ok := r.schemas[assetType]
+	r.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	// The validator only accepts the types produced by encoding/json.
+	if dataMap == nil {
+		dataMap = DataMap{}
+	}
+	encoded, err := json.Marshal(dataMap)
+	if err != nil {
+		return err
+	}
+	var instance interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&instance); err != nil {
+		return err
+	}
+
+	err = schema.schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		return err
+	}
+
+	validationErr := &SchemaValidationError{
+		AssetType: assetType,
+		Fields:    make([]FieldError, 0),
+	}
+	collectFieldErrors(validationError, validationErr)
+	sort.SliceStable(validationErr.Fields, func(i, j int) bool {
+		return validationErr.Fields[i].Field < validationErr.Fields[j].Field
+	})
+	return validationErr
+}
+
+// collectFieldErrors adds the innermost errors of the validation tree, the others only summarise their causes.
+func collectFieldErrors(err *jsonschema.ValidationError, output *SchemaValidationError) {
+	if len(err.Causes) == 0 {
+		output.Fields = append(output.Fields, FieldError{
+			Field:   err.InstanceLocation,
+			Message: err.Message,
+		})
+		return
+	}
+
+	for _, cause := range err.Causes {
+		collectFieldErrors(cause, output)
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"price": {"type": "number", "minimum": 0},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["name"]
+}`
+
+func TestSchemaRegistryValidate(t *testing.T) {
+	registry := NewSchemaRegistry()
+	err := registry.Register("product_details", []byte(testSchema))
+	tests.CheckResult(registry.AssetTypes(), []string{"product_details"}, err, nil, "register", t)
+
+	err = registry.Validate("product_details", DataMap{"name": "test", "price": 12, "tags": []string{"a"}})
+	tests.CheckResult(nil, nil, err, nil, "valid", t)
+
+	err = registry.Validate("product_assets", DataMap{"anything": true})
+	tests.CheckResult(nil, nil, err, nil, "no_schema", t)
+
+	err = registry.Validate("product_details", DataMap{"price": -1, "tags": []interface{}{"a", 2}})
+	var validationErr *SchemaValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrSchemaValidation) {
+		t.Fatalf("invalid: expected SchemaValidationError, got %v", err)
+	}
+	fields := make([]string, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		fields[i] = field.Field
+	}
+	tests.CheckResult(fields, []string{"", "/price", "/tags/1"}, nil, nil, "invalid_fields", t)
+
+	var nilRegistry *SchemaRegistry
+	tests.CheckResult(nil, nil, nilRegistry.Validate("product_details", DataMap{}), nil, "nil_registry", t)
+
+	tests.CheckResult(registry.Remove("product_details"), true, nil, nil, "remove", t)
+	err = registry.Validate("product_details", DataMap{})
+	tests.CheckResult(nil, nil, err, nil, "removed", t)
+}
+
+func TestSchemaRegistryRegister(t *testing.T) {
+	registry := NewSchemaRegistry()
+	invalid := []string{
+		`{"type": `,
+		`{"type": "unknown"}`,
+		`{"$ref": "file:///etc/passwd"}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+	}
+	for _, document := range invalid {
+		if err := registry.Register("user_settings", []byte(document)); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%s: expected ErrInvalidSchema, got %v", document, err)
+		}
+	}
+
+	document := []byte(`{"$defs": {"color": {"enum": ["red", "blue"]}}, "properties": {"theme": {"$ref": "#/$defs/color"}}}`)
+	err := registry.Register("user_settings", document)
+	tests.CheckResult(nil, nil, err, nil, "local_reference", t)
+
+	output, ok := registry.Schema("user_settings")
+	tests.CheckResult(output, document, ok, true, "schema", t)
+}
+
+func TestSchemaRegistryLoadDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir("", "schemas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	if err := ioutil.WriteFile(filepath.Join(directory, "project_details.json"), []byte(testSchema), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(directory, "README.md"), []byte("not a schema"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewSchemaRegistry()
+	err = registry.LoadDirectory(directory)
+	tests.CheckResult(registry.AssetTypes(), []string{"project_details"}, err, nil, "load", t)
+
+	if err := ioutil.WriteFile(filepath.Join(directory, "project_assets.json"), []byte(`{"type": `), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.LoadDirectory(directory); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("invalid file: expected ErrInvalidSchema, got %v", err)
+	}
+}
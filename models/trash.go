@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashEntry is a soft deleted user, product or project waiting to be restored or purged.
+type TrashEntry struct {
+	// Name of the table of the entity: users, products or projects.
+	Type      string    `json:"type"`
+	ID        uuid.UUID `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
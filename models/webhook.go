@@ -0,0 +1,148 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// States of a webhook delivery. A pending delivery is retried until it succeeds or runs out of attempts.
+const (
+	WebhookPending   = "pending"
+	WebhookSucceeded = "succeeded"
+	WebhookFailed    = "failed"
+)
+
+// Page sizes of the webhook delivery log.
+const (
+	DefaultWebhookDeliveryLimit = 50
+	MaxWebhookDeliveryLimit     = 500
+)
+
+// Limits of the webhook subscription columns.
+const (
+	MaxWebhookURLLength    = 2048
+	MaxWebhookSecretLength = 255
+)
+
+// Length of the generated secrets in bytes, they are stored hex encoded.
+const webhookSecretBytes = 32
+
+var ErrInvalidWebhookSubscription = errors.New("Invalid webhook subscription")
+
+// WebhookSubscription delivers the domain events of the selected types to URL.
+type WebhookSubscription struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Key of the HMAC signature of the deliveries. It is only returned when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+	// Types of the delivered events, every event is delivered if it is empty.
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is the delivery of an event to a subscription with the result of its last attempt.
+type WebhookDelivery struct {
+	ID             int64       `json:"id"`
+	SubscriptionID uuid.UUID   `json:"subscription_id"`
+	Event          DomainEvent `json:"event"`
+	Status         string      `json:"status"`
+	Attempts       int         `json:"attempts"`
+	NextAttemptAt  time.Time   `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time  `json:"last_attempt_at"`
+	// Response status of the last attempt, zero if no response was received.
+	LastStatusCode int       `json:"last_status_code,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// IsEventType reports whether eventType is one of the published domain event types.
+func IsEventType(eventType string) bool {
+	switch eventType {
+	case EventUserCreated, EventUserDeleted, EventUserTrashed, EventUserRestored,
+		EventProductCreated, EventProductDeleted, EventProductTrashed, EventProductRestored,
+		EventProductOwnerChanged, EventProductUserAdded, EventProductUserRemoved,
+		EventProjectCreated, EventProjectDeleted, EventProjectTrashed, EventProjectRestored, EventProjectOwnerChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewWebhookSubscription creates a subscription of url to the events of eventTypes.
+// A random secret is generated if secret is empty.
+func (f *RepoFunctions) NewWebhookSubscription(url string, secret string, eventTypes []string) (*WebhookSubscription, error) {
+	newID, err := f.UUIDImpl.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == "" {
+		if secret, err = NewWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	if eventTypes == nil {
+		eventTypes = make([]string, 0)
+	}
+
+	return &WebhookSubscription{
+		ID:         newID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// NewWebhookSecret returns a random hex encoded secret.
+func NewWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Validate checks the URL, the secret and the event types of the subscription.
+func (s *WebhookSubscription) Validate() error {
+	if len(s.URL) > MaxWebhookURLLength {
+		return fmt.Errorf("%w: url is longer than %d characters", ErrInvalidWebhookSubscription, MaxWebhookURLLength)
+	}
+
+	target, err := url.Parse(s.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url has to be an absolute http or https URL", ErrInvalidWebhookSubscription)
+	}
+
+	if s.Secret == "" || len(s.Secret) > MaxWebhookSecretLength {
+		return fmt.Errorf("%w: secret has to be between 1 and %d characters", ErrInvalidWebhookSubscription, MaxWebhookSecretLength)
+	}
+
+	for _, eventType := range s.EventTypes {
+		if !IsEventType(eventType) {
+			return fmt.Errorf("%w: unknown event type '%s'", ErrInvalidWebhookSubscription, eventType)
+		}
+	}
+	return nil
+}
+
+// Accepts reports whether the events of eventType are delivered to the subscription.
+func (s *WebhookSubscription) Accepts(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+
+	for _, accepted := range s.EventTypes {
+		if accepted == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+func TestWebhookSubscriptionValidate(t *testing.T) {
+	functions := &RepoFunctions{UUIDImpl: &RepoUUID{}}
+	subscription, err := functions.NewWebhookSubscription("https://billing.example.com/hooks", "", nil)
+	tests.CheckResult(len(subscription.Secret), 2*webhookSecretBytes, err, nil, "generated_secret", t)
+	tests.CheckResult(subscription.EventTypes, []string{}, subscription.Validate(), nil, "valid", t)
+
+	invalid := map[string]WebhookSubscription{
+		"relative_url":   {URL: "/hooks", Secret: "secret"},
+		"unknown_scheme": {URL: "ftp://billing.example.com", Secret: "secret"},
+		"long_url":       {URL: "https://billing.example.com/" + strings.Repeat("a", MaxWebhookURLLength), Secret: "secret"},
+		"missing_secret": {URL: "https://billing.example.com/hooks"},
+		"long_secret":    {URL: "https://billing.example.com/hooks", Secret: strings.Repeat("a", MaxWebhookSecretLength+1)},
+		"unknown_event":  {URL: "https://billing.example.com/hooks", Secret: "secret", EventTypes: []string{"user.renamed"}},
+	}
+	for name, subscription := range invalid {
+		subscription := subscription
+		if err := subscription.Validate(); !errors.Is(err, ErrInvalidWebhookSubscription) {
+			t.Errorf("%s: expected an invalid subscription error, got %v", name, err)
+		}
+	}
+}
+
+func TestWebhookSubscriptionAccepts(t *testing.T) {
+	all := &WebhookSubscription{}
+	tests.CheckResult(all.Accepts(EventProjectDeleted), true, nil, nil, "all_events", t)
+
+	filtered := &WebhookSubscription{EventTypes: []string{EventUserCreated, EventProductUserAdded}}
+	tests.CheckResult(filtered.Accepts(EventProductUserAdded), true, nil, nil, "accepted", t)
+	tests.CheckResult(filtered.Accepts(EventUserDeleted), false, nil, nil, "filtered", t)
+}
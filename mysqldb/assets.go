@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -19,77 +21,101 @@ const (
 	ProjectAssets  = "project_assets"
 )
 
-var ErrAssetMissing = "This %s is missing or old value is the same as new"
+var ErrAssetMissing = errors.New("Asset is missing or old value is the same as new")
+var ErrVersionConflict = errors.New("Asset has been modified since it was read")
+
+// IsAssetType reports whether assetType is the name of an asset table.
+func IsAssetType(assetType string) bool {
+	for _, assetTable := range assetTables {
+		if assetTable == assetType {
+			return true
+		}
+	}
+	return false
+}
 
 var AddAssetQuery = "INSERT INTO %s (id, data) VALUES (UUID_TO_BIN(?), ?)"
 
-func (*MYSQLFunctions) AddAsset(assetType string, asset *models.Asset, tx *sql.Tx) error {
+func (*MYSQLFunctions) AddAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
 	// Prepare data
 	binary, err := json.Marshal(asset.DataMap)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	// Execute transaction
 	query := fmt.Sprintf(AddAssetQuery, assetType)
-	_, err = tx.Exec(query, asset.ID, binary)
+	_, err = tx.ExecContext(ctx, query, asset.ID, binary)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
+	asset.Version = 1
 	return nil
 }
 
-var UpdateAssetQuery = "UPDATE %s set data = ? where id = UUID_TO_BIN(?)"
+var UpdateAssetQuery = "UPDATE %s set data = ?, version = version + 1 where id = UUID_TO_BIN(?)"
+var UpdateAssetVersionQuery = "UPDATE %s set data = ?, version = version + 1 where id = UUID_TO_BIN(?) AND version = ?"
+var GetAssetVersionQuery = "SELECT version FROM %s WHERE id = UUID_TO_BIN(?)"
 
-func (f *MYSQLFunctions) UpdateAsset(assetType string, asset *models.Asset) error {
+// UpdateAsset overwrites the data of the asset and sets asset.Version to the new version.
+// If asset.Version is set, ErrVersionConflict is returned when the stored asset has a different version.
+func (*MYSQLFunctions) UpdateAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
 	binary, err := json.Marshal(asset.DataMap)
 	if err != nil {
 		return err
 	}
 
-	// Execute transaction
-	tx, err := f.DBConnector.ConnectSystem()
-	if err != nil {
+	if err := addAssetHistory(ctx, assetType, &asset.ID, tx); err != nil {
 		return err
 	}
 
-	query := fmt.Sprintf(UpdateAssetQuery, assetType)
-	result, err := tx.Exec(query, binary, asset.ID)
+	var result sql.Result
+	if asset.Version > 0 {
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(UpdateAssetVersionQuery, assetType), binary, asset.ID, asset.Version)
+	} else {
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(UpdateAssetQuery, assetType), binary, asset.ID)
+	}
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
-	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
-		return fmt.Errorf(ErrAssetMissing, assetType)
+	var version int64
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(GetAssetVersionQuery, assetType), asset.ID).Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		return errors.WithMessage(ErrAssetMissing, assetType)
+	case err != nil:
+		return TranslateError(err)
+	case affected == 0:
+		return errors.WithMessagef(ErrVersionConflict, "%s version %d, expected %d", assetType, version, asset.Version)
+	default:
 	}
 
-	return tx.Commit()
+	asset.Version = version
+	return nil
 }
 
-var GetAssetQuery = "SELECT BIN_TO_UUID(id), data FROM %s WHERE id = UUID_TO_BIN(?)"
+var GetAssetQuery = "SELECT BIN_TO_UUID(id), data, version FROM %s WHERE id = UUID_TO_BIN(?)"
 
-func (f *MYSQLFunctions) GetAsset(assetType string, assetID *uuid.UUID) (*models.Asset, error) {
+func (f *MYSQLFunctions) GetAsset(ctx context.Context, assetType string, assetID *uuid.UUID) (*models.Asset, error) {
 	asset := &models.Asset{}
 
-	tx, err := f.DBConnector.ConnectSystem()
+	tx, err := f.DBConnector.ConnectSystem(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	query := fmt.Sprintf(GetAssetQuery, assetType)
-	result := tx.QueryRow(query, assetID)
+	result := tx.QueryRowContext(ctx, query, assetID)
 
 	dataMap := []byte{}
-	err = result.Scan(&asset.ID, &dataMap)
+	err = result.Scan(&asset.ID, &dataMap, &asset.Version)
 	switch {
 	case err == sql.ErrNoRows:
 		if errRb := tx.Commit(); errRb != nil {
@@ -110,39 +136,36 @@ func (f *MYSQLFunctions) GetAsset(assetType string, assetID *uuid.UUID) (*models
 
 var DeleteAssetQuery = "DELETE FROM %s WHERE id=UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteAsset(assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+func (*MYSQLFunctions) DeleteAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
 	query := fmt.Sprintf(DeleteAssetQuery, assetType)
-	result, err := tx.Exec(query, *assetID)
+	result, err := tx.ExecContext(ctx, query, *assetID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
-		return fmt.Errorf(ErrAssetMissing, assetType)
+		return errors.WithMessage(ErrAssetMissing, assetType)
 	}
-	return nil
+	return deleteAssetHistory(ctx, assetType, assetID, tx)
 }
 
-var GetAssetsQuery = "SELECT BIN_TO_UUID(id), data FROM %s WHERE id IN (UUID_TO_BIN(?)"
+var GetAssetsQuery = "SELECT BIN_TO_UUID(id), data, version FROM %s WHERE id IN (UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetAssets(assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
+func (*MYSQLFunctions) GetAssets(ctx context.Context, assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
 	query := GetAssetsQuery + strings.Repeat(",UUID_TO_BIN(?)", len(IDs)-1) + ")"
 	interfaceList := make([]interface{}, len(IDs))
 	for i := range IDs {
 		interfaceList[i] = IDs[i]
 	}
 	query = fmt.Sprintf(query, assetType)
-	rows, err := tx.Query(query, interfaceList...)
+	rows, err := tx.QueryContext(ctx, query, interfaceList...)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -151,18 +174,18 @@ func (*MYSQLFunctions) GetAssets(assetType string, IDs []uuid.UUID, tx *sql.Tx)
 	for rows.Next() {
 		dataMap := []byte{}
 		asset := models.Asset{}
-		err := rows.Scan(&asset.ID, &dataMap)
+		err := rows.Scan(&asset.ID, &dataMap, &asset.Version)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		assets = append(assets, asset)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	if len(assets) == 0 {
@@ -171,3 +194,94 @@ func (*MYSQLFunctions) GetAssets(assetType string, IDs []uuid.UUID, tx *sql.Tx)
 
 	return assets, nil
 }
+
+var MergePatchAssetQuery = "UPDATE %s SET data = JSON_MERGE_PATCH(data, ?), version = version + 1 WHERE id = UUID_TO_BIN(?)"
+var MergePatchAssetVersionQuery = "UPDATE %s SET data = JSON_MERGE_PATCH(data, ?), version = version + 1 WHERE id = UUID_TO_BIN(?) AND version = ?"
+var GetAssetForUpdateQuery = "SELECT BIN_TO_UUID(id), data, version FROM %s WHERE id = UUID_TO_BIN(?) FOR UPDATE"
+
+// PatchAsset applies patch to the data of the asset in tx and returns the patched asset.
+// Merge patches are applied by MySQL, JSON patches are applied to the asset locked by the read.
+// If patch.Version is set, ErrVersionConflict is returned when the stored asset has a different version.
+func (f *MYSQLFunctions) PatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error) {
+	if err := addAssetHistory(ctx, assetType, assetID, tx); err != nil {
+		return nil, err
+	}
+
+	if patch.Type == models.MergePatchType {
+		return f.mergePatchAsset(ctx, assetType, assetID, patch, tx)
+	}
+
+	asset, err := getAssetForUpdate(ctx, assetType, assetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Version > 0 && patch.Version != asset.Version {
+		return nil, errors.WithMessagef(ErrVersionConflict, "%s version %d, expected %d", assetType, asset.Version, patch.Version)
+	}
+
+	dataMap, err := patch.Apply(asset.DataMap)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := json.Marshal(dataMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(UpdateAssetQuery, assetType), binary, assetID); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	asset.DataMap = dataMap
+	asset.Version++
+	return asset, nil
+}
+
+func (f *MYSQLFunctions) mergePatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error) {
+	var result sql.Result
+	var err error
+	if patch.Version > 0 {
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(MergePatchAssetVersionQuery, assetType), patch.Document, assetID, patch.Version)
+	} else {
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(MergePatchAssetQuery, assetType), patch.Document, assetID)
+	}
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	asset, err := getAssetForUpdate(ctx, assetType, assetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 0 {
+		return nil, errors.WithMessagef(ErrVersionConflict, "%s version %d, expected %d", assetType, asset.Version, patch.Version)
+	}
+	return asset, nil
+}
+
+// getAssetForUpdate reads the asset and locks it until the end of tx.
+func getAssetForUpdate(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) (*models.Asset, error) {
+	asset := &models.Asset{}
+	dataMap := []byte{}
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(GetAssetForUpdateQuery, assetType), assetID).Scan(&asset.ID, &dataMap, &asset.Version)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.WithMessage(ErrAssetMissing, assetType)
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -19,6 +20,7 @@ const (
 	DeleteAssetTest
 	UpdateAssetTest
 	GetAssetTest
+	PatchAssetTest
 )
 
 type AssetExpectedData struct {
@@ -28,6 +30,7 @@ type AssetExpectedData struct {
 
 type AssetInputData struct {
 	asset *models.Asset
+	patch *models.AssetPatch
 }
 
 func createAssetTestData(testID int) (*tests.OrderedTests, error) {
@@ -84,7 +87,6 @@ func createAssetTestData(testID int) (*tests.OrderedTests, error) {
 		mock.ExpectBegin()
 		query = fmt.Sprintf(AddAssetQuery, UserAssets)
 		mock.ExpectExec(query).WithArgs(asset.ID, binaryDataMap).WillReturnError(err)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: AssetInputData{
 				asset: asset,
@@ -101,6 +103,7 @@ func createAssetTestData(testID int) (*tests.OrderedTests, error) {
 		mock.ExpectBegin()
 		query := fmt.Sprintf(DeleteAssetQuery, UserAssets)
 		mock.ExpectExec(query).WithArgs(asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(DeleteAssetHistoryQuery).WithArgs(UserAssets, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 2))
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: AssetInputData{
 				asset: asset,
@@ -116,54 +119,96 @@ func createAssetTestData(testID int) (*tests.OrderedTests, error) {
 		mock.ExpectBegin()
 		query = fmt.Sprintf(DeleteAssetQuery, UserAssets)
 		mock.ExpectExec(query).WithArgs(asset.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: AssetInputData{
 				asset: asset,
 			},
 			Mock: nil,
 			Expected: AssetExpectedData{
-				err: fmt.Errorf(ErrAssetMissing, UserAssets),
+				err: errors.WithMessage(ErrAssetMissing, UserAssets),
 			},
 		}
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 	case UpdateAssetTest:
 		testCase := "valid_asset"
-
 		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
 		query := fmt.Sprintf(UpdateAssetQuery, UserAssets)
 		mock.ExpectExec(query).WithArgs(binaryDataMap, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
+		query = fmt.Sprintf(GetAssetVersionQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: AssetInputData{
-				asset: asset,
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap},
 			},
 			Mock: nil,
 			Expected: AssetExpectedData{
-				err: nil,
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 2},
+				err:   nil,
+			},
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "valid_version"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		query = fmt.Sprintf(UpdateAssetVersionQuery, UserAssets)
+		mock.ExpectExec(query).WithArgs(binaryDataMap, &asset.ID, 2).WillReturnResult(sqlmock.NewResult(1, 1))
+		query = fmt.Sprintf(GetAssetVersionQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data: AssetInputData{
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 2},
+			},
+			Mock: nil,
+			Expected: AssetExpectedData{
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 3},
+				err:   nil,
+			},
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "version_conflict"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		query = fmt.Sprintf(UpdateAssetVersionQuery, UserAssets)
+		mock.ExpectExec(query).WithArgs(binaryDataMap, &asset.ID, 2).WillReturnResult(sqlmock.NewResult(1, 0))
+		query = fmt.Sprintf(GetAssetVersionQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data: AssetInputData{
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 2},
+			},
+			Mock: nil,
+			Expected: AssetExpectedData{
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 2},
+				err:   errors.WithMessagef(ErrVersionConflict, "%s version %d, expected %d", UserAssets, 3, 2),
 			},
 		}
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 
 		testCase = "missing_asset"
 		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 0))
 		query = fmt.Sprintf(UpdateAssetQuery, UserAssets)
 		mock.ExpectExec(query).WithArgs(binaryDataMap, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
+		query = fmt.Sprintf(GetAssetVersionQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnError(sql.ErrNoRows)
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: AssetInputData{
-				asset: asset,
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap},
 			},
 			Mock: nil,
 			Expected: AssetExpectedData{
-				err: fmt.Errorf(ErrAssetMissing, UserAssets),
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap},
+				err:   errors.WithMessage(ErrAssetMissing, UserAssets),
 			},
 		}
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 	case GetAssetTest:
 		testCase := "valid_asset"
 
-		rows := sqlmock.NewRows([]string{"id", "data"}).AddRow(binaryID, binaryDataMap)
+		rows := sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(binaryID, binaryDataMap, 1)
 		mock.ExpectBegin()
 		query := fmt.Sprintf(GetAssetQuery, UserAssets)
 		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(rows)
@@ -174,7 +219,7 @@ func createAssetTestData(testID int) (*tests.OrderedTests, error) {
 			},
 			Mock: nil,
 			Expected: AssetExpectedData{
-				asset: asset,
+				asset: &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 1},
 				err:   nil,
 			},
 		}
@@ -196,6 +241,93 @@ func createAssetTestData(testID int) (*tests.OrderedTests, error) {
 			},
 		}
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+	case PatchAssetTest:
+		patched := models.DataMap{"name": "patched"}
+		binaryPatched, err := json.Marshal(patched)
+		if err != nil {
+			return nil, err
+		}
+
+		testCase := "merge_patch"
+		mergePatch := &models.AssetPatch{Type: models.MergePatchType, Document: []byte(`{"name": "patched"}`)}
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		query := fmt.Sprintf(MergePatchAssetQuery, UserAssets)
+		mock.ExpectExec(query).WithArgs(mergePatch.Document, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		query = fmt.Sprintf(GetAssetForUpdateQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(binaryID, binaryPatched, 2))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data: AssetInputData{
+				asset: asset,
+				patch: mergePatch,
+			},
+			Mock: nil,
+			Expected: AssetExpectedData{
+				asset: &models.Asset{ID: asset.ID, DataMap: patched, Version: 2},
+				err:   nil,
+			},
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "merge_patch_version_conflict"
+		versionedPatch := &models.AssetPatch{Type: models.MergePatchType, Document: mergePatch.Document, Version: 1}
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		query = fmt.Sprintf(MergePatchAssetVersionQuery, UserAssets)
+		mock.ExpectExec(query).WithArgs(versionedPatch.Document, &asset.ID, 1).WillReturnResult(sqlmock.NewResult(1, 0))
+		query = fmt.Sprintf(GetAssetForUpdateQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(binaryID, binaryDataMap, 3))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data: AssetInputData{
+				asset: asset,
+				patch: versionedPatch,
+			},
+			Mock: nil,
+			Expected: AssetExpectedData{
+				asset: nil,
+				err:   errors.WithMessagef(ErrVersionConflict, "%s version %d, expected %d", UserAssets, 3, 1),
+			},
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "json_patch"
+		jsonPatch := &models.AssetPatch{Type: models.JSONPatchType, Document: []byte(`[{"op": "add", "path": "/name", "value": "patched"}]`)}
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		query = fmt.Sprintf(GetAssetForUpdateQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(binaryID, binaryDataMap, 1))
+		query = fmt.Sprintf(UpdateAssetQuery, UserAssets)
+		mock.ExpectExec(query).WithArgs(binaryPatched, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data: AssetInputData{
+				asset: asset,
+				patch: jsonPatch,
+			},
+			Mock: nil,
+			Expected: AssetExpectedData{
+				asset: &models.Asset{ID: asset.ID, DataMap: patched, Version: 2},
+				err:   nil,
+			},
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "json_patch_missing_asset"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, UserAssets)).WithArgs(UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 0))
+		query = fmt.Sprintf(GetAssetForUpdateQuery, UserAssets)
+		mock.ExpectQuery(query).WithArgs(&asset.ID).WillReturnError(sql.ErrNoRows)
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data: AssetInputData{
+				asset: asset,
+				patch: jsonPatch,
+			},
+			Mock: nil,
+			Expected: AssetExpectedData{
+				asset: nil,
+				err:   errors.WithMessage(ErrAssetMissing, UserAssets),
+			},
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 	}
 
 	DBFunctions = &MYSQLFunctions{
@@ -231,7 +363,7 @@ func TestAddAsset(t *testing.T) {
 			expectedData := testCase.Expected.(AssetExpectedData)
 			inputData := testCase.Data.(AssetInputData)
 
-			err = DBFunctions.AddAsset(UserAssets, inputData.asset, tx)
+			err = DBFunctions.AddAsset(context.Background(), UserAssets, inputData.asset, tx)
 			if diff := pretty.Diff(err, expectedData.err); len(diff) != 0 {
 				t.Errorf(tests.TestResultString, testCaseString, err, expectedData.err, diff)
 				return
@@ -263,7 +395,7 @@ func TestDeleteAsset(t *testing.T) {
 			expectedData := testCase.Expected.(AssetExpectedData)
 			inputData := testCase.Data.(AssetInputData)
 
-			err = DBFunctions.DeleteAsset(UserAssets, &inputData.asset.ID, tx)
+			err = DBFunctions.DeleteAsset(context.Background(), UserAssets, &inputData.asset.ID, tx)
 			if diff := pretty.Diff(err, expectedData.err); len(diff) != 0 {
 				t.Errorf(tests.TestResultString, testCaseString, err, expectedData.err, diff)
 				return
@@ -286,15 +418,17 @@ func TestUpdateAsset(t *testing.T) {
 	for _, testCaseString := range dataSet.OrderedList {
 		testCaseString := testCaseString
 		t.Run(testCaseString, func(t *testing.T) {
+			tx, err := DBFunctions.DBConnector.(*DBConnectorMock).DB.Begin()
+			if err != nil {
+				t.Errorf("Failed to setup DB transaction: %s", err)
+				return
+			}
 			testCase := dataSet.TestDataSet[testCaseString]
 			expectedData := testCase.Expected.(AssetExpectedData)
 			inputData := testCase.Data.(AssetInputData)
 
-			err = DBFunctions.UpdateAsset(UserAssets, inputData.asset)
-			if diff := pretty.Diff(err, expectedData.err); len(diff) != 0 {
-				t.Errorf(tests.TestResultString, testCaseString, err, expectedData.err, diff)
-				return
-			}
+			err = DBFunctions.UpdateAsset(context.Background(), UserAssets, inputData.asset, tx)
+			tests.CheckResult(inputData.asset, expectedData.asset, err, expectedData.err, testCaseString, t)
 		})
 	}
 }
@@ -317,7 +451,36 @@ func TestGetAsset(t *testing.T) {
 			expectedData := testCase.Expected.(AssetExpectedData)
 			inputData := testCase.Data.(AssetInputData)
 
-			output, err := DBFunctions.GetAsset(UserAssets, &inputData.asset.ID)
+			output, err := DBFunctions.GetAsset(context.Background(), UserAssets, &inputData.asset.ID)
+			tests.CheckResult(output, expectedData.asset, err, expectedData.err, testCaseString, t)
+		})
+	}
+}
+
+func TestPatchAsset(t *testing.T) {
+	// Create test data
+	dataSet, err := createAssetTestData(PatchAssetTest)
+	if err != nil {
+		t.Errorf("Failed to generate test data: %s", err)
+		return
+	}
+
+	defer DBFunctions.DBConnector.(*DBConnectorMock).DB.Close()
+
+	// Run tests
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			tx, err := DBFunctions.DBConnector.(*DBConnectorMock).DB.Begin()
+			if err != nil {
+				t.Errorf("Failed to setup DB transaction: %s", err)
+				return
+			}
+			testCase := dataSet.TestDataSet[testCaseString]
+			expectedData := testCase.Expected.(AssetExpectedData)
+			inputData := testCase.Data.(AssetInputData)
+
+			output, err := DBFunctions.PatchAsset(context.Background(), UserAssets, &inputData.asset.ID, inputData.patch, tx)
 			tests.CheckResult(output, expectedData.asset, err, expectedData.err, testCaseString, t)
 		})
 	}
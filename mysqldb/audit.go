@@ -0,0 +1,163 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Tables of the relations recorded in the audit log besides the entity and asset tables.
+const (
+	AuditEvents    = "audit_events"
+	ProductUsers   = "users_products"
+	ProjectUsers   = "users_projects"
+	ProjectViewers = "users_viewers"
+)
+
+var AddAuditEventQuery = "INSERT INTO audit_events (occurred_at, actor, action, entity_type, entity_id, before_data, after_data) VALUES (?, UUID_TO_BIN(?), ?, ?, UUID_TO_BIN(?), ?, ?)"
+
+// AddAuditEvent records the event in tx, so it is only kept if the change is committed.
+func (*MYSQLFunctions) AddAuditEvent(ctx context.Context, event *models.AuditEvent, tx *sql.Tx) error {
+	before, err := marshalSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := marshalSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+
+	var actor interface{}
+	if event.Actor != nil {
+		actor = event.Actor
+	}
+
+	_, err = tx.ExecContext(ctx, AddAuditEventQuery, event.OccurredAt, actor, event.Action, event.EntityType, event.EntityID, before, after)
+	return TranslateError(err)
+}
+
+var GetAuditEventsQuery = "SELECT id, occurred_at, BIN_TO_UUID(actor), action, entity_type, BIN_TO_UUID(entity_id), before_data, after_data FROM audit_events"
+
+// GetAuditEvents lists the events matching filter, the latest first. sql.ErrNoRows is returned if there are none.
+// The filter has to be validated by the caller.
+func (*MYSQLFunctions) GetAuditEvents(ctx context.Context, filter *models.AuditFilter, tx *sql.Tx) ([]models.AuditEvent, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if filter.Actor != nil {
+		conditions = append(conditions, "actor = UUID_TO_BIN(?)")
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		conditions = append(conditions, "entity_id = UUID_TO_BIN(?)")
+		args = append(args, filter.EntityID)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "occurred_at < ?")
+		args = append(args, filter.To)
+	}
+	if filter.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filter.BeforeID)
+	}
+
+	query := GetAuditEventsQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanAuditEvents(rows)
+}
+
+var GetAuditEventsAfterQuery = GetAuditEventsQuery + " WHERE id > ? ORDER BY id LIMIT ?"
+
+// GetAuditEventsAfter lists at most limit events recorded after the event afterID, the oldest first.
+// sql.ErrNoRows is returned if there are none.
+func (*MYSQLFunctions) GetAuditEventsAfter(ctx context.Context, afterID int64, limit int, tx *sql.Tx) ([]models.AuditEvent, error) {
+	rows, err := tx.QueryContext(ctx, GetAuditEventsAfterQuery, afterID, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanAuditEvents(rows)
+}
+
+// marshalSnapshot returns the JSON of the snapshot or nil if there is none.
+func marshalSnapshot(snapshot models.DataMap) (interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	return json.Marshal(snapshot)
+}
+
+func scanAuditEvent(event *models.AuditEvent, actor sql.NullString, before []byte, after []byte) error {
+	if actor.Valid {
+		actorID, err := uuid.Parse(actor.String)
+		if err != nil {
+			return errors.Wrap(err, "Invalid actor in the audit log")
+		}
+		event.Actor = &actorID
+	}
+
+	if before != nil {
+		if err := json.Unmarshal(before, &event.Before); err != nil {
+			return err
+		}
+	}
+
+	if after != nil {
+		if err := json.Unmarshal(after, &event.After); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanAuditEvents(rows *sql.Rows) ([]models.AuditEvent, error) {
+	defer rows.Close()
+
+	events := make([]models.AuditEvent, 0)
+	for rows.Next() {
+		event := models.AuditEvent{}
+		var actor sql.NullString
+		var before, after []byte
+		if err := rows.Scan(&event.ID, &event.OccurredAt, &actor, &event.Action, &event.EntityType, &event.EntityID, &before, &after); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := scanAuditEvent(&event, actor, before, after); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(events) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return events, nil
+}
@@ -0,0 +1,107 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestAuditEvents(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	actor := uuid.New()
+	productID := uuid.New()
+	occurredAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	event := &models.AuditEvent{
+		OccurredAt: occurredAt,
+		Actor:      &actor,
+		Action:     models.ActionCreate,
+		EntityType: Products,
+		EntityID:   productID,
+		After:      models.DataMap{"name": "testProduct"},
+	}
+	columns := []string{"id", "occurred_at", "actor", "action", "entity_type", "entity_id", "before_data", "after_data"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(AddAuditEventQuery).
+		WithArgs(occurredAt, &actor, models.ActionCreate, Products, productID, nil, []byte(`{"name":"testProduct"}`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(AddAuditEventQuery).
+		WithArgs(occurredAt, nil, models.ActionDelete, Products, productID, []byte(`{"name":"testProduct"}`), nil).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery(GetAuditEventsQuery + " ORDER BY id DESC LIMIT ?").WithArgs(models.DefaultAuditLimit).WillReturnRows(
+		sqlmock.NewRows(columns).
+			AddRow(2, occurredAt, nil, models.ActionDelete, Products, productID.String(), []byte(`{"name":"testProduct"}`), nil).
+			AddRow(1, occurredAt, actor.String(), models.ActionCreate, Products, productID.String(), nil, []byte(`{"name":"testProduct"}`)))
+	mock.ExpectQuery(GetAuditEventsQuery+" WHERE actor = UUID_TO_BIN(?) AND action = ? AND entity_type = ? AND entity_id = UUID_TO_BIN(?) AND occurred_at >= ? AND occurred_at < ? AND id < ? ORDER BY id DESC LIMIT ?").
+		WithArgs(&actor, models.ActionUpdate, Products, &productID, occurredAt, occurredAt.Add(time.Hour), 2, 10).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(GetAuditEventsAfterQuery).WithArgs(1, 10).WillReturnRows(
+		sqlmock.NewRows(columns).
+			AddRow(2, occurredAt, nil, models.ActionDelete, Products, productID.String(), []byte(`{"name":"testProduct"}`), nil))
+	mock.ExpectQuery(GetAuditEventsAfterQuery).WithArgs(2, 10).WillReturnRows(sqlmock.NewRows(columns))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	functions := &MYSQLFunctions{}
+	err = functions.AddAuditEvent(ctx, event, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_create_event", t)
+
+	deleteEvent := &models.AuditEvent{
+		OccurredAt: occurredAt,
+		Action:     models.ActionDelete,
+		EntityType: Products,
+		EntityID:   productID,
+		Before:     models.DataMap{"name": "testProduct"},
+	}
+	err = functions.AddAuditEvent(ctx, deleteEvent, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_delete_event", t)
+
+	filter := &models.AuditFilter{}
+	err = filter.Validate()
+	tests.CheckResult(filter.Limit, models.DefaultAuditLimit, err, nil, "default_limit", t)
+
+	events, err := functions.GetAuditEvents(ctx, filter, tx)
+	event.ID = 1
+	deleteEvent.ID = 2
+	tests.CheckResult(events, []models.AuditEvent{*deleteEvent, *event}, err, nil, "events", t)
+
+	filter = &models.AuditFilter{
+		Actor:      &actor,
+		Action:     models.ActionUpdate,
+		EntityType: Products,
+		EntityID:   &productID,
+		From:       occurredAt,
+		To:         occurredAt.Add(time.Hour),
+		BeforeID:   2,
+		Limit:      10,
+	}
+	_, err = functions.GetAuditEvents(ctx, filter, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "filtered_events", t)
+
+	events, err = functions.GetAuditEventsAfter(ctx, 1, 10, tx)
+	tests.CheckResult(events, []models.AuditEvent{*deleteEvent}, err, nil, "events_after", t)
+
+	_, err = functions.GetAuditEventsAfter(ctx, 2, 10, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_events_after", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,332 @@
+package mysqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Kinds of the backed up columns, they decide the form of the values in the dump and in the queries.
+const (
+	BackupUUID = iota
+	BackupText
+	// Integers stored as int by the memory backend, like the privilege identifiers.
+	BackupInt
+	// Integers stored as int64 by the memory backend, like the asset versions.
+	BackupBigInt
+	BackupJSON
+	BackupTime
+)
+
+// BackupColumn describes a column of a backed up table.
+type BackupColumn struct {
+	Name string
+	Kind int
+	// Table whose key the column references, empty if the column is not a reference.
+	References string
+}
+
+// BackupTable describes the columns of a table included in the dump.
+// Key is the primary key column, it is empty for the tables connecting two entities.
+type BackupTable struct {
+	Name    string
+	Key     string
+	Columns []BackupColumn
+}
+
+// OrderBy returns the columns the rows of the table are dumped in the order of.
+func (t *BackupTable) OrderBy() []string {
+	if t.Key != "" {
+		return []string{t.Key}
+	}
+
+	columns := make([]string, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		columns = append(columns, column.Name)
+	}
+	return columns
+}
+
+// Column returns the column of the table called name.
+func (t *BackupTable) Column(name string) (*BackupColumn, bool) {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i], true
+		}
+	}
+	return nil, false
+}
+
+func assetBackupTable(name string) BackupTable {
+	return BackupTable{
+		Name: name,
+		Key:  "id",
+		Columns: []BackupColumn{
+			{Name: "id", Kind: BackupUUID},
+			{Name: "data", Kind: BackupJSON},
+			{Name: "version", Kind: BackupBigInt},
+		},
+	}
+}
+
+// BackupTables lists the tables of the dump in dependency order, every table references only the ones before it.
+// The timestamps maintained by the DB, the generated columns and the leftover columns of old implementations
+// are not part of the dump. The audit log, the outbox, the asset history and the webhooks are not backed up either.
+var BackupTables = []BackupTable{
+	{
+		Name: "privileges",
+		Key:  "id",
+		Columns: []BackupColumn{
+			{Name: "id", Kind: BackupInt},
+			{Name: "name", Kind: BackupText},
+			{Name: "description", Kind: BackupText},
+		},
+	},
+	assetBackupTable(UserSettings),
+	assetBackupTable(UserAssets),
+	{
+		Name: Users,
+		Key:  "id",
+		Columns: []BackupColumn{
+			{Name: "id", Kind: BackupUUID},
+			{Name: "name", Kind: BackupText},
+			{Name: "email", Kind: BackupText},
+			{Name: "password", Kind: BackupText},
+			{Name: "user_settings_id", Kind: BackupUUID, References: UserSettings},
+			{Name: "user_assets_id", Kind: BackupUUID, References: UserAssets},
+			{Name: "deleted_at", Kind: BackupTime},
+		},
+	},
+	assetBackupTable(ProductDetails),
+	assetBackupTable(ProductAssets),
+	{
+		Name: Products,
+		Key:  "id",
+		Columns: []BackupColumn{
+			{Name: "id", Kind: BackupUUID},
+			{Name: "name", Kind: BackupText},
+			{Name: "product_details_id", Kind: BackupUUID, References: ProductDetails},
+			{Name: "product_assets_id", Kind: BackupUUID, References: ProductAssets},
+			{Name: "deleted_at", Kind: BackupTime},
+		},
+	},
+	{
+		Name: ProductUsers,
+		Columns: []BackupColumn{
+			{Name: "products_id", Kind: BackupUUID, References: Products},
+			{Name: "users_id", Kind: BackupUUID, References: Users},
+			{Name: "privileges_id", Kind: BackupInt, References: "privileges"},
+		},
+	},
+	assetBackupTable(ProjectDetails),
+	assetBackupTable(ProjectAssets),
+	{
+		Name: Projects,
+		Key:  "id",
+		Columns: []BackupColumn{
+			{Name: "id", Kind: BackupUUID},
+			{Name: "products_id", Kind: BackupUUID, References: Products},
+			{Name: "project_details_id", Kind: BackupUUID, References: ProjectDetails},
+			{Name: "project_assets_id", Kind: BackupUUID, References: ProjectAssets},
+			{Name: "deleted_at", Kind: BackupTime},
+		},
+	},
+	{
+		Name: ProjectUsers,
+		Columns: []BackupColumn{
+			{Name: "projects_id", Kind: BackupUUID, References: Projects},
+			{Name: "users_id", Kind: BackupUUID, References: Users},
+			{Name: "privileges_id", Kind: BackupInt, References: "privileges"},
+		},
+	},
+	{
+		Name: "viewers",
+		Key:  "id",
+		Columns: []BackupColumn{
+			{Name: "id", Kind: BackupUUID},
+			{Name: "owner_id", Kind: BackupUUID, References: Users},
+		},
+	},
+	{
+		Name: "users_viewers",
+		Columns: []BackupColumn{
+			{Name: "users_id", Kind: BackupUUID, References: Users},
+			{Name: "viewer_id", Kind: BackupUUID, References: "viewers"},
+			{Name: "projects_id", Kind: BackupUUID, References: Projects},
+		},
+	},
+}
+
+var ErrUnknownBackupTable = errors.New("Table is not part of the backup")
+
+// GetBackupTable returns the description of the backed up table called name.
+func GetBackupTable(name string) (*BackupTable, error) {
+	for i := range BackupTables {
+		if BackupTables[i].Name == name {
+			return &BackupTables[i], nil
+		}
+	}
+	return nil, errors.WithMessage(ErrUnknownBackupTable, name)
+}
+
+// Decode converts the JSON form of the column value to the value stored in a models.BackupRow.
+func (c *BackupColumn) Decode(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	switch c.Kind {
+	case BackupUUID:
+		var ID uuid.UUID
+		err := json.Unmarshal(raw, &ID)
+		return ID, err
+	case BackupText:
+		var text string
+		err := json.Unmarshal(raw, &text)
+		return text, err
+	case BackupInt, BackupBigInt:
+		var number int64
+		err := json.Unmarshal(raw, &number)
+		return number, err
+	case BackupJSON:
+		if !json.Valid(raw) {
+			return nil, errors.New("invalid JSON")
+		}
+		return json.RawMessage(raw), nil
+	case BackupTime:
+		var t time.Time
+		err := json.Unmarshal(raw, &t)
+		return t.UTC(), err
+	default:
+		return nil, fmt.Errorf("Unknown column kind %d", c.Kind)
+	}
+}
+
+// ScanBackupRow reads the current row of rows selecting the columns of table in order.
+func ScanBackupRow(rows *sql.Rows, table *BackupTable) (models.BackupRow, error) {
+	values := make([]interface{}, len(table.Columns))
+	for i, column := range table.Columns {
+		switch column.Kind {
+		case BackupInt, BackupBigInt:
+			values[i] = &sql.NullInt64{}
+		case BackupTime:
+			values[i] = &sql.NullTime{}
+		default:
+			values[i] = &sql.NullString{}
+		}
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return nil, err
+	}
+
+	r := make(models.BackupRow, len(table.Columns))
+	for i, column := range table.Columns {
+		r[column.Name] = nil
+		switch value := values[i].(type) {
+		case *sql.NullInt64:
+			if value.Valid {
+				r[column.Name] = value.Int64
+			}
+		case *sql.NullTime:
+			if value.Valid {
+				r[column.Name] = value.Time.UTC()
+			}
+		case *sql.NullString:
+			if !value.Valid {
+				break
+			}
+
+			switch column.Kind {
+			case BackupUUID:
+				ID, err := uuid.Parse(value.String)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s.%s", table.Name, column.Name)
+				}
+				r[column.Name] = ID
+			case BackupJSON:
+				r[column.Name] = json.RawMessage(value.String)
+			default:
+				r[column.Name] = value.String
+			}
+		}
+	}
+	return r, nil
+}
+
+// BackupArgs returns the query arguments inserting r into table, in the order of the columns.
+// JSON values are passed as strings, the missing columns are NULL.
+func BackupArgs(table *BackupTable, r models.BackupRow) []interface{} {
+	args := make([]interface{}, len(table.Columns))
+	for i, column := range table.Columns {
+		switch value := r[column.Name].(type) {
+		case json.RawMessage:
+			args[i] = string(value)
+		default:
+			args[i] = value
+		}
+	}
+	return args
+}
+
+// dumpTableQuery selects the backed up columns of the table, the UUIDs are converted to their text form.
+func dumpTableQuery(table *BackupTable) string {
+	columns := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		if column.Kind == BackupUUID {
+			columns = append(columns, fmt.Sprintf("BIN_TO_UUID(%s)", column.Name))
+		} else {
+			columns = append(columns, column.Name)
+		}
+	}
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", strings.Join(columns, ", "), table.Name, strings.Join(table.OrderBy(), ", "))
+}
+
+func restoreRowQuery(table *BackupTable) string {
+	columns := make([]string, 0, len(table.Columns))
+	placeholders := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column.Name)
+		if column.Kind == BackupUUID {
+			placeholders = append(placeholders, "UUID_TO_BIN(?)")
+		} else {
+			placeholders = append(placeholders, "?")
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.Name, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+}
+
+// DumpTable calls fn with every row of the table. Iteration stops at the first error returned by fn.
+func (*MYSQLFunctions) DumpTable(ctx context.Context, table *BackupTable, fn func(r models.BackupRow) error, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, dumpTableQuery(table))
+	if err != nil {
+		return TranslateError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r, err := ScanBackupRow(rows, table)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return TranslateError(rows.Err())
+}
+
+// RestoreRow inserts a row of the dump into the table.
+func (*MYSQLFunctions) RestoreRow(ctx context.Context, table *BackupTable, r models.BackupRow, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, restoreRowQuery(table), BackupArgs(table, r)...)
+	return TranslateError(err)
+}
@@ -0,0 +1,118 @@
+package mysqldb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func TestBackup(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	users, err := GetBackupTable(Users)
+	tests.CheckResult(nil, nil, err, nil, "users_table", t)
+	settings, err := GetBackupTable(UserSettings)
+	tests.CheckResult(nil, nil, err, nil, "settings_table", t)
+	productUsers, err := GetBackupTable(ProductUsers)
+	tests.CheckResult(nil, nil, err, nil, "product_users_table", t)
+
+	userID := uuid.New()
+	settingsID := uuid.New()
+	deletedAt := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+	user := models.BackupRow{
+		"id":               userID,
+		"name":             "testName",
+		"email":            "test@test.com",
+		"password":         nil,
+		"user_settings_id": settingsID,
+		"user_assets_id":   nil,
+		"deleted_at":       deletedAt,
+	}
+	setting := models.BackupRow{"id": settingsID, "data": json.RawMessage(`{"theme":"dark"}`), "version": int64(2)}
+
+	dumpUsersQuery := "SELECT BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id), deleted_at FROM users ORDER BY id"
+	restoreSettingsQuery := "INSERT INTO user_settings (id, data, version) VALUES (UUID_TO_BIN(?), ?, ?)"
+	mock.ExpectBegin()
+	mock.ExpectQuery(dumpUsersQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "user_settings_id", "user_assets_id", "deleted_at"}).
+			AddRow(userID.String(), "testName", "test@test.com", nil, settingsID.String(), nil, deletedAt))
+	mock.ExpectQuery("SELECT BIN_TO_UUID(products_id), BIN_TO_UUID(users_id), privileges_id FROM users_products ORDER BY products_id, users_id, privileges_id").
+		WillReturnRows(sqlmock.NewRows([]string{"products_id", "users_id", "privileges_id"}).AddRow(uuid.New().String(), userID.String(), 1))
+	mock.ExpectExec(restoreSettingsQuery).WithArgs(settingsID, `{"theme":"dark"}`, int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO users (id, name, email, password, user_settings_id, user_assets_id, deleted_at) VALUES (UUID_TO_BIN(?), ?, ?, ?, UUID_TO_BIN(?), UUID_TO_BIN(?), ?)").
+		WithArgs(userID, "testName", "test@test.com", nil, settingsID, nil, deletedAt).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	functions := &MYSQLFunctions{}
+	rows := make([]models.BackupRow, 0)
+	err = functions.DumpTable(ctx, users, func(r models.BackupRow) error {
+		rows = append(rows, r)
+		return nil
+	}, tx)
+	tests.CheckResult(rows, []models.BackupRow{user}, err, nil, "dump_users", t)
+
+	errStop := errors.New("stop")
+	err = functions.DumpTable(ctx, productUsers, func(r models.BackupRow) error {
+		if r["privileges_id"] != int64(1) {
+			return errors.Errorf("unexpected privilege %v", r["privileges_id"])
+		}
+		return errStop
+	}, tx)
+	tests.CheckResult(nil, nil, err, errStop, "dump_stopped", t)
+
+	err = functions.RestoreRow(ctx, settings, setting, tx)
+	tests.CheckResult(nil, nil, err, nil, "restore_settings", t)
+
+	err = functions.RestoreRow(ctx, users, user, tx)
+	tests.CheckResult(nil, nil, err, nil, "restore_user", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBackupColumnDecode(t *testing.T) {
+	ID := uuid.New()
+	deletedAt := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+	data := []struct {
+		column   BackupColumn
+		raw      string
+		expected interface{}
+		err      bool
+	}{
+		{BackupColumn{Kind: BackupUUID}, `"` + ID.String() + `"`, ID, false},
+		{BackupColumn{Kind: BackupUUID}, `"not-a-uuid"`, nil, true},
+		{BackupColumn{Kind: BackupText}, `null`, nil, false},
+		{BackupColumn{Kind: BackupInt}, `3`, int64(3), false},
+		{BackupColumn{Kind: BackupBigInt}, `1.5`, nil, true},
+		{BackupColumn{Kind: BackupJSON}, `{"a":[1,2]}`, json.RawMessage(`{"a":[1,2]}`), false},
+		{BackupColumn{Kind: BackupTime}, `"2021-07-15T14:00:00+02:00"`, deletedAt, false},
+	}
+
+	for _, d := range data {
+		value, err := d.column.Decode(json.RawMessage(d.raw))
+		if d.err {
+			tests.CheckResult(err != nil, true, nil, nil, d.raw, t)
+			continue
+		}
+		tests.CheckResult(value, d.expected, err, nil, d.raw, t)
+	}
+}
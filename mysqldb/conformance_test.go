@@ -0,0 +1,70 @@
+package mysqldb_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests/backendtest"
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// The conformance suite needs a MySQL server, it is skipped unless MYSQL_TEST_DSN is set,
+// for example to root:123secure@tcp(localhost:3306)/ for the server of docker-compose.
+// The user must be allowed to create databases, every test runs in a new one that is dropped afterwards.
+const testDSNVariable = "MYSQL_TEST_DSN"
+
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv(testDSNVariable)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNVariable)
+	}
+
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("Invalid %s: %s", testDSNVariable, err)
+	}
+	config.DBName = ""
+	config.ParseTime = true
+
+	server, err := sql.Open("mysql", config.FormatDSN())
+	if err != nil {
+		t.Fatalf("Failed to connect to the test server: %s", err)
+	}
+	defer server.Close()
+
+	backendtest.Run(t, func(t *testing.T) (mysqldb.FunctionsCommon, mysqldb.ConnectorCommon) {
+		name := "test_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+		if _, err := server.Exec(fmt.Sprintf("CREATE DATABASE %s", name)); err != nil {
+			t.Fatalf("Failed to create test DB: %s", err)
+		}
+		t.Cleanup(func() {
+			if _, err := server.Exec(fmt.Sprintf("DROP DATABASE %s", name)); err != nil {
+				t.Errorf("Failed to drop test DB: %s", err)
+			}
+		})
+
+		dbConfig := config.Clone()
+		dbConfig.DBName = name
+		connector := &mysqldb.MYSQLConnector{
+			DBConnection: dbConfig.FormatDSN(),
+			MaxOpenConns: 4,
+			MaxIdleConns: 4,
+		}
+		if err := connector.BootstrapSystem(); err != nil {
+			t.Fatalf("Failed to bootstrap test DB: %s", err)
+		}
+		t.Cleanup(func() {
+			if err := connector.Close(); err != nil {
+				t.Errorf("Failed to close test DB: %s", err)
+			}
+		})
+
+		return &mysqldb.MYSQLFunctions{DBConnector: connector, UUIDImpl: &models.RepoUUID{}}, connector
+	})
+}
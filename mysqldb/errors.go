@@ -0,0 +1,149 @@
+package mysqldb
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+// MySQL server error numbers recognised by TranslateError.
+const (
+	ErDupEntry            = 1062
+	ErBadNull             = 1048
+	ErLockWaitTimeout     = 1205
+	ErLockDeadlock        = 1213
+	ErNoReferencedRow     = 1216
+	ErRowIsReferenced     = 1217
+	ErNoDefaultForField   = 1364
+	ErDataTooLong         = 1406
+	ErRowIsReferenced2    = 1451
+	ErNoReferencedRow2    = 1452
+	ErCheckConstraintFail = 3819
+)
+
+// Sentinel errors of the translation layer. Use errors.Is to check for them,
+// the details of the failure are available through errors.As and *SQLError.
+var ErrDuplicateEntry = errors.New("Duplicate entry")
+var ErrForeignKeyViolation = errors.New("Referenced row does not exist")
+var ErrRowReferenced = errors.New("Row is still referenced by another table")
+var ErrNotNullViolation = errors.New("Column cannot be null")
+var ErrDataTooLong = errors.New("Data too long for column")
+var ErrCheckViolation = errors.New("Check constraint violated")
+var ErrDeadlock = errors.New("Deadlock found when trying to get lock")
+var ErrLockWaitTimeout = errors.New("Lock wait timeout exceeded")
+
+// SQLError is the backend neutral form of a constraint or locking failure reported by the DB server.
+// Table, Constraint, Column and ReferencedTable are filled in when the server message contains them.
+type SQLError struct {
+	Kind            error
+	Number          uint16
+	Table           string
+	Constraint      string
+	Column          string
+	ReferencedTable string
+	Value           string
+	Err             error
+}
+
+func (e *SQLError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SQLError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes the error match its sentinel kind, for example errors.Is(err, ErrDuplicateEntry).
+func (e *SQLError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Duplicate entry 'name' for key 'users.name' (MySQL 8.0.19+ prefixes the key with the table).
+var duplicateEntryRegexp = regexp.MustCompile("Duplicate entry '(.*)' for key '(?:([^'.]+)\\.)?([^']+)'")
+
+// (`db`.`table`, CONSTRAINT `name` FOREIGN KEY (`column`) REFERENCES `parent` (`id`))
+var foreignKeyRegexp = regexp.MustCompile("\\(`[^`]*`\\.`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`\\) REFERENCES `([^`]+)`")
+
+// Column 'name' cannot be null / Data too long for column 'name' at row 1 / Field 'name' doesn't have a default value
+var columnRegexp = regexp.MustCompile("(?:Column|column|Field) '([^']+)'")
+
+// Check constraint 'name' is violated.
+var checkConstraintRegexp = regexp.MustCompile("Check constraint '([^']+)'")
+
+// duplicateKeyColumn returns the column name of a single column unique key.
+// MySQL names such keys after the column and adds a _<n> suffix to repeated ones.
+func duplicateKeyColumn(key string) string {
+	if index := strings.LastIndex(key, "_"); index != -1 {
+		if _, err := strconv.Atoi(key[index+1:]); err == nil {
+			return key[:index]
+		}
+	}
+	return key
+}
+
+// TranslateError converts MySQL server errors to *SQLError. Any other error is returned unchanged.
+func TranslateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	sqlErr := &SQLError{
+		Number: mysqlErr.Number,
+		Err:    err,
+	}
+
+	switch mysqlErr.Number {
+	case ErDupEntry:
+		sqlErr.Kind = ErrDuplicateEntry
+		if match := duplicateEntryRegexp.FindStringSubmatch(mysqlErr.Message); match != nil {
+			sqlErr.Value = match[1]
+			sqlErr.Table = match[2]
+			sqlErr.Constraint = match[3]
+			sqlErr.Column = duplicateKeyColumn(match[3])
+		}
+	case ErNoReferencedRow, ErNoReferencedRow2, ErRowIsReferenced, ErRowIsReferenced2:
+		sqlErr.Kind = ErrForeignKeyViolation
+		if mysqlErr.Number == ErRowIsReferenced || mysqlErr.Number == ErRowIsReferenced2 {
+			sqlErr.Kind = ErrRowReferenced
+		}
+		if match := foreignKeyRegexp.FindStringSubmatch(mysqlErr.Message); match != nil {
+			sqlErr.Table = match[1]
+			sqlErr.Constraint = match[2]
+			sqlErr.Column = match[3]
+			sqlErr.ReferencedTable = match[4]
+		}
+	case ErBadNull, ErNoDefaultForField:
+		sqlErr.Kind = ErrNotNullViolation
+		if match := columnRegexp.FindStringSubmatch(mysqlErr.Message); match != nil {
+			sqlErr.Column = match[1]
+		}
+	case ErDataTooLong:
+		sqlErr.Kind = ErrDataTooLong
+		if match := columnRegexp.FindStringSubmatch(mysqlErr.Message); match != nil {
+			sqlErr.Column = match[1]
+		}
+	case ErCheckConstraintFail:
+		sqlErr.Kind = ErrCheckViolation
+		if match := checkConstraintRegexp.FindStringSubmatch(mysqlErr.Message); match != nil {
+			sqlErr.Constraint = match[1]
+		}
+	case ErLockDeadlock:
+		sqlErr.Kind = ErrDeadlock
+	case ErLockWaitTimeout:
+		sqlErr.Kind = ErrLockWaitTimeout
+	default:
+		return err
+	}
+
+	return sqlErr
+}
+
+// IsColumnError reports whether err is an SQL error of the given kind caused by the given column.
+func IsColumnError(err error, kind error, column string) bool {
+	var sqlErr *SQLError
+	return errors.As(err, &sqlErr) && sqlErr.Kind == kind && sqlErr.Column == column
+}
@@ -0,0 +1,152 @@
+package mysqldb
+
+import (
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+type TranslateErrorExpectedData struct {
+	kind            error
+	table           string
+	constraint      string
+	column          string
+	referencedTable string
+}
+
+func createTranslateErrorTestData() *tests.OrderedTests {
+	dataSet := &tests.OrderedTests{
+		OrderedList: make(tests.OrderedTestList, 0),
+		TestDataSet: make(tests.DataSet),
+	}
+
+	testCase := "duplicate_entry"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &mysql.MySQLError{
+			Number:  ErDupEntry,
+			Message: "Duplicate entry 'test@test.com' for key 'users.email'",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:       ErrDuplicateEntry,
+			table:      "users",
+			constraint: "email",
+			column:     "email",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "duplicate_entry_repeated_key"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &mysql.MySQLError{
+			Number:  ErDupEntry,
+			Message: "Duplicate entry 'test' for key 'name_2'",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:       ErrDuplicateEntry,
+			constraint: "name_2",
+			column:     "name",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "missing_parent_row"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &mysql.MySQLError{
+			Number: ErNoReferencedRow2,
+			Message: "Cannot add or update a child row: a foreign key constraint fails " +
+				"(`custom_db`.`projects`, CONSTRAINT `projects_ibfk_1` FOREIGN KEY (`products_id`) REFERENCES `products` (`id`))",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:            ErrForeignKeyViolation,
+			table:           "projects",
+			constraint:      "projects_ibfk_1",
+			column:          "products_id",
+			referencedTable: "products",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "referenced_parent_row"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &mysql.MySQLError{
+			Number: ErRowIsReferenced2,
+			Message: "Cannot delete or update a parent row: a foreign key constraint fails " +
+				"(`user_database`.`users_products`, CONSTRAINT `users_products_ibfk_2` FOREIGN KEY (`users_id`) REFERENCES `users` (`id`))",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:            ErrRowReferenced,
+			table:           "users_products",
+			constraint:      "users_products_ibfk_2",
+			column:          "users_id",
+			referencedTable: "users",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "deadlock"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &mysql.MySQLError{
+			Number:  ErLockDeadlock,
+			Message: "Deadlock found when trying to get lock; try restarting transaction",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind: ErrDeadlock,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "lock_wait_timeout"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &mysql.MySQLError{
+			Number:  ErLockWaitTimeout,
+			Message: "Lock wait timeout exceeded; try restarting transaction",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind: ErrLockWaitTimeout,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	return dataSet
+}
+
+func TestTranslateError(t *testing.T) {
+	dataSet := createTranslateErrorTestData()
+
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			testCase := dataSet.TestDataSet[testCaseString]
+			expectedData := testCase.Expected.(TranslateErrorExpectedData)
+			input := testCase.Data.(*mysql.MySQLError)
+
+			// Wrapping must not hide the typed error.
+			err := TranslateError(errors.Wrap(input, "wrapped"))
+			if !errors.Is(err, expectedData.kind) {
+				t.Errorf("%s test failed. %v is not %v", testCaseString, err, expectedData.kind)
+				return
+			}
+
+			var sqlErr *SQLError
+			if !errors.As(err, &sqlErr) {
+				t.Errorf("%s test failed. %v is not *SQLError", testCaseString, err)
+				return
+			}
+
+			output := TranslateErrorExpectedData{
+				kind:            sqlErr.Kind,
+				table:           sqlErr.Table,
+				constraint:      sqlErr.Constraint,
+				column:          sqlErr.Column,
+				referencedTable: sqlErr.ReferencedTable,
+			}
+			tests.CheckResult(output, expectedData, nil, nil, testCaseString, t)
+		})
+	}
+
+	plainErr := errors.New("This is a failure test")
+	tests.CheckResult(nil, nil, TranslateError(plainErr), plainErr, "not_mysql_error", t)
+	tests.CheckResult(nil, nil, TranslateError(nil), nil, "nil_error", t)
+}
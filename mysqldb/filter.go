@@ -0,0 +1,74 @@
+package mysqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/pkg/errors"
+)
+
+// detailsColumns lists the details keys indexed through generated columns of the details tables.
+var detailsColumns = map[string][]string{
+	ProductDetails: {models.IsFree, models.Requires3D, models.HasTrial},
+	ProjectDetails: {"name", "visibility"},
+}
+
+// indexedDetailsPredicate returns the condition of a filter on every indexed key of detailsTable.
+func indexedDetailsPredicate(detailsTable string) string {
+	filter := make(models.DetailsFilter)
+	for _, key := range detailsColumns[detailsTable] {
+		filter[key] = ""
+	}
+
+	// The indexed keys are valid, so the filter is too.
+	predicate, _, _ := detailsPredicate(detailsTable, filter)
+	return predicate
+}
+
+// detailsPredicate returns the condition selecting the rows of detailsTable matching filter.
+// The JSON values are compared, so "true" does not match true. Strings and booleans of the indexed keys
+// are compared with the generated columns as well, so their indexes can be used.
+func detailsPredicate(detailsTable string, filter models.DetailsFilter) (string, []interface{}, error) {
+	if len(filter) == 0 {
+		return "", nil, errors.WithMessage(models.ErrInvalidFilter, "empty filter")
+	}
+
+	if err := filter.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	indexed := make(map[string]bool)
+	for _, key := range detailsColumns[detailsTable] {
+		indexed[key] = true
+	}
+
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	for _, key := range filter.Keys() {
+		value := filter[key]
+		if indexed[key] {
+			switch v := value.(type) {
+			case string:
+				conditions = append(conditions, key+" = LEFT(?, 255)")
+				args = append(args, v)
+			case bool:
+				conditions = append(conditions, key+" = ?")
+				args = append(args, strconv.FormatBool(v))
+			default:
+			}
+		}
+
+		binary, err := json.Marshal(value)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// The keys are validated, so they can be part of the JSON path.
+		conditions = append(conditions, fmt.Sprintf(`JSON_EXTRACT(data, '$."%s"') = CAST(? AS JSON)`, key))
+		args = append(args, string(binary))
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
@@ -0,0 +1,70 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestDetailsPredicate(t *testing.T) {
+	filter := models.DetailsFilter{models.IsFree: true, "price": float64(0), "vendor": "test"}
+	predicate, args, err := detailsPredicate(ProductDetails, filter)
+	expected := `is_free = ? AND JSON_EXTRACT(data, '$."is_free"') = CAST(? AS JSON) AND ` +
+		`JSON_EXTRACT(data, '$."price"') = CAST(? AS JSON) AND ` +
+		`JSON_EXTRACT(data, '$."vendor"') = CAST(? AS JSON)`
+	tests.CheckResult(predicate, expected, err, nil, "product_predicate", t)
+	tests.CheckResult(args, []interface{}{"true", "true", "0", `"test"`}, nil, nil, "product_args", t)
+
+	predicate, args, err = detailsPredicate(ProjectDetails, models.DetailsFilter{"name": "test"})
+	expected = `name = LEFT(?, 255) AND JSON_EXTRACT(data, '$."name"') = CAST(? AS JSON)`
+	tests.CheckResult(predicate, expected, err, nil, "project_predicate", t)
+	tests.CheckResult(args, []interface{}{"test", `"test"`}, nil, nil, "project_args", t)
+
+	_, _, err = detailsPredicate(ProductDetails, models.DetailsFilter{})
+	tests.CheckResult(errors.Is(err, models.ErrInvalidFilter), true, nil, nil, "empty_filter", t)
+
+	_, _, err = detailsPredicate(ProductDetails, models.DetailsFilter{"a') OR ('1": "1"})
+	tests.CheckResult(errors.Is(err, models.ErrInvalidFilter), true, nil, nil, "invalid_key", t)
+}
+
+func TestGetProductsByDetails(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	product := models.Product{ID: uuid.New(), Name: "test", DetailsID: uuid.New(), AssetsID: uuid.New()}
+	query := productsByDetailsQuery(`is_free = ? AND JSON_EXTRACT(data, '$."is_free"') = CAST(? AS JSON)`)
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).WithArgs("false", "false").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "product_details_id", "product_assets_id"}).
+			AddRow(product.ID.String(), product.Name, product.DetailsID.String(), product.AssetsID.String()))
+	mock.ExpectQuery(query).WithArgs("false", "false").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "product_details_id", "product_assets_id"}))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	functions := &MYSQLFunctions{}
+	filter := models.DetailsFilter{models.IsFree: false}
+	products, err := functions.GetProductsByDetails(context.Background(), filter, tx)
+	tests.CheckResult(products, []models.Product{product}, err, nil, "matching_product", t)
+
+	products, err = functions.GetProductsByDetails(context.Background(), filter, tx)
+	tests.CheckResult(products == nil, true, err, sql.ErrNoRows, "no_matching_product", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,67 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestAssetHistory(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	assetID := uuid.New()
+	userID := uuid.New()
+	changedAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	ctx := models.WithActingUser(context.Background(), userID)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(fmt.Sprintf(AddAssetHistoryQuery, ProjectAssets)).WithArgs(ProjectAssets, &userID, &assetID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(GetAssetHistoryQuery).WithArgs(ProjectAssets, &assetID).WillReturnRows(
+		sqlmock.NewRows([]string{"asset_id", "version", "changed_by", "changed_at"}).
+			AddRow(assetID.String(), 2, userID.String(), changedAt).
+			AddRow(assetID.String(), 1, nil, changedAt))
+	mock.ExpectQuery(GetAssetRevisionQuery).WithArgs(ProjectAssets, &assetID, 1).WillReturnRows(
+		sqlmock.NewRows([]string{"asset_id", "version", "data", "changed_by", "changed_at"}).
+			AddRow(assetID.String(), 1, []byte(`{"color": "red"}`), nil, changedAt))
+	mock.ExpectQuery(GetAssetRevisionQuery).WithArgs(ProjectAssets, &assetID, 3).WillReturnError(sql.ErrNoRows)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	err = addAssetHistory(ctx, ProjectAssets, &assetID, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_history", t)
+
+	functions := &MYSQLFunctions{}
+	revisions, err := functions.GetAssetHistory(ctx, ProjectAssets, &assetID, tx)
+	expected := []models.AssetRevision{
+		{AssetID: assetID, Version: 2, ChangedAt: changedAt, ChangedBy: &userID},
+		{AssetID: assetID, Version: 1, ChangedAt: changedAt},
+	}
+	tests.CheckResult(revisions, expected, err, nil, "history", t)
+
+	revision, err := functions.GetAssetRevision(ctx, ProjectAssets, &assetID, 1, tx)
+	expectedRevision := &models.AssetRevision{AssetID: assetID, Version: 1, DataMap: models.DataMap{"color": "red"}, ChangedAt: changedAt}
+	tests.CheckResult(revision, expectedRevision, err, nil, "revision", t)
+
+	_, err = functions.GetAssetRevision(ctx, ProjectAssets, &assetID, 3, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "missing_revision", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
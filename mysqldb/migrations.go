@@ -0,0 +1,153 @@
+package mysqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/db/migrations"
+	"github.com/pkg/errors"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// MigrationConnector is implemented by the connectors whose schema is managed by sql-migrate.
+type MigrationConnector interface {
+	ConnectorCommon
+	// Migrator opens the connection pool if needed and returns the migration manager working on it.
+	Migrator() (*Migrator, error)
+}
+
+var ErrUnknownMigration = errors.New("Migration not found")
+
+// Migrator applies and reverts the schema migrations of a SQL backend.
+// Every migration is executed in its own transaction and recorded in the gorp_migrations table.
+type Migrator struct {
+	DB      *sql.DB
+	Dialect string
+	Source  migrate.MigrationSource
+}
+
+// MigrationStatus describes a single migration file and whether it is applied.
+type MigrationStatus struct {
+	ID        string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Status returns the migrations of the source in execution order. Migrations recorded in the DB,
+// but missing from the source, are listed at the end.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, err := m.Source.FindMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := migrate.GetMigrationRecords(m.DB, m.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]time.Time, len(records))
+	for _, record := range records {
+		applied[record.Id] = record.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		appliedAt, ok := applied[migration.Id]
+		statuses = append(statuses, MigrationStatus{ID: migration.Id, Applied: ok, AppliedAt: appliedAt})
+		delete(applied, migration.Id)
+	}
+
+	for _, record := range records {
+		if _, ok := applied[record.Id]; ok {
+			statuses = append(statuses, MigrationStatus{ID: record.Id, Applied: true, AppliedAt: record.AppliedAt})
+		}
+	}
+	return statuses, nil
+}
+
+// Version returns the schema version of the DB, the version of the last applied migration.
+func (m *Migrator) Version() (string, error) {
+	records, err := migrate.GetMigrationRecords(m.DB, m.Dialect)
+	if err != nil {
+		return "", err
+	}
+
+	if len(records) == 0 {
+		return "", errors.New("No migration is applied")
+	}
+	return migrations.Version(records[len(records)-1].Id), nil
+}
+
+// Pending returns the number of migrations of the source that are not applied yet.
+func (m *Migrator) Pending() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations up to and including the one identified by to.
+// All pending migrations are applied if to is empty.
+func (m *Migrator) Up(to string) (int, error) {
+	max := 0
+	if to != "" {
+		planned, _, err := migrate.PlanMigration(m.DB, m.Dialect, m.Source, migrate.Up, 0)
+		if err != nil {
+			return 0, err
+		}
+
+		max = -1
+		for i, migration := range planned {
+			if migration.Id == to {
+				max = i + 1
+				break
+			}
+		}
+
+		if max == -1 {
+			return 0, errors.Wrapf(ErrUnknownMigration, "%s is not pending", to)
+		}
+	}
+
+	return migrate.ExecMax(m.DB, m.Dialect, m.Source, migrate.Up, max)
+}
+
+// Down reverts the last count applied migrations.
+func (m *Migrator) Down(count int) (int, error) {
+	if count <= 0 {
+		return 0, fmt.Errorf("Invalid number of migrations to revert: %d", count)
+	}
+
+	return migrate.ExecMax(m.DB, m.Dialect, m.Source, migrate.Down, count)
+}
+
+// Redo reverts the last applied migration and applies it again.
+func (m *Migrator) Redo() error {
+	planned, _, err := migrate.PlanMigration(m.DB, m.Dialect, m.Source, migrate.Down, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(planned) == 0 {
+		return errors.New("There is no applied migration to redo")
+	}
+
+	if _, err := migrate.ExecMax(m.DB, m.Dialect, m.Source, migrate.Down, 1); err != nil {
+		return err
+	}
+
+	if _, err := migrate.ExecMax(m.DB, m.Dialect, m.Source, migrate.Up, 1); err != nil {
+		return errors.Wrapf(err, "Migration %s is reverted, but failed to apply it again", planned[0].Id)
+	}
+	return nil
+}
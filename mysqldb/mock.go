@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -15,8 +16,8 @@ type DBConnectorMock struct {
 	DB   *sql.DB
 }
 
-func (i *DBConnectorMock) ConnectSystem() (*sql.Tx, error) {
-	tx, err := i.DB.Begin()
+func (i *DBConnectorMock) ConnectSystem(ctx context.Context) (*sql.Tx, error) {
+	tx, err := i.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +36,22 @@ func (DBConnectorMock) Rollback(tx *sql.Tx) error {
 	return nil
 }
 
+func (*DBConnectorMock) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return nil
+}
+
+func (*DBConnectorMock) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return nil
+}
+
+func (*DBConnectorMock) ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	return nil
+}
+
+func (*DBConnectorMock) Close() error {
+	return nil
+}
+
 func createTestProductUsersData() (*models.ProductUserIDs, error) {
 	userID, err := uuid.NewUUID()
 	if err != nil {
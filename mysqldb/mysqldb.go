@@ -1,81 +1,138 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/artofimagination/mysql-user-db-go-interface/db/migrations"
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	// Need to register mysql drivers with database/sql
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
-	migrate "github.com/rubenv/sql-migrate"
 )
 
 // Common interface for DB connection. Needed in order to allow mock and custom DB interface implementation.
 type ConnectorCommon interface {
 	BootstrapSystem() error
-	ConnectSystem() (*sql.Tx, error)
+	ConnectSystem(ctx context.Context) (*sql.Tx, error)
 	Commit(tx *sql.Tx) error
 	Rollback(tx *sql.Tx) error
+	Savepoint(ctx context.Context, tx *sql.Tx, name string) error
+	RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error
+	ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error
+	Close() error
 }
 
+var ErrConnectorNotOpen = errors.New("DB connection pool is not open, BootstrapSystem must be called first")
+
 // MYSQL database connector implementation
+// The connector owns a single pooled DB handle that is opened by BootstrapSystem
+// and shared by every transaction until Close is called.
 type MYSQLConnector struct {
-	DBConnection       string
+	DBConnection string
+	// Directory of the migration files, the migrations embedded in the binary are used if empty.
 	MigrationDirectory string
+	// BootstrapSystem only opens the connection pool, the schema is migrated by the migrate command.
+	SkipMigration bool
+	// How long an instance waits for the schema migration executed by another instance.
+	MigrationLockTimeout time.Duration
+	MaxOpenConns         int
+	MaxIdleConns         int
+	ConnMaxLifetime      time.Duration
+
+	db *sql.DB
 }
 
 // Data handling common function interface. Needed in order to allow mock and custom functionality implementations.
 type FunctionsCommon interface {
-	GetUser(queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error)
-	AddUser(user *models.User, tx *sql.Tx) error
-	DeleteUser(userID *uuid.UUID, tx *sql.Tx) error
-	GetProductUserIDs(productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error)
-	GetUsersByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error)
-
-	AddAsset(assetType string, asset *models.Asset, tx *sql.Tx) error
-	DeleteAsset(assetType string, assetID *uuid.UUID, tx *sql.Tx) error
-	GetAssets(assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error)
-	GetAsset(assetType string, assetID *uuid.UUID) (*models.Asset, error)
-	UpdateAsset(assetType string, asset *models.Asset) error
-
-	UpdateUsersProducts(userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error
-	AddProductUsers(productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error
-	DeleteProductUsersByProductID(productID *uuid.UUID, tx *sql.Tx) error
-	DeleteProductUser(productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error
-	GetUserProductIDs(userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error)
-
-	GetProductByID(ID *uuid.UUID, tx *sql.Tx) (*models.Product, error)
-	GetProductByName(name string, tx *sql.Tx) (*models.Product, error)
-	AddProduct(product *models.Product, tx *sql.Tx) error
-	DeleteProduct(productID *uuid.UUID, tx *sql.Tx) error
-	GetProductsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error)
-
-	AddProject(project *models.Project, tx *sql.Tx) error
-	AddProjectUsers(projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error
-	GetProjectByID(ID *uuid.UUID, tx *sql.Tx) (*models.Project, error)
-	DeleteProjectUsersByProjectID(projectID *uuid.UUID, tx *sql.Tx) error
-	DeleteProject(projectID *uuid.UUID, tx *sql.Tx) error
-	DeleteProjectsByProductID(productID *uuid.UUID, tx *sql.Tx) error
-	GetProjectsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error)
-	GetProductProjects(productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error)
-
-	GetUserProjectIDs(userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error)
-	UpdateUsersProjects(userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error
-	AddProjectViewer(projectViewer *models.ProjectViewer, tx *sql.Tx) error
-	DeleteProjectViewerByUserID(userID *uuid.UUID, tx *sql.Tx) error
-	GetProjectViewersByUserID(userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error)
-	GetProjectViewersByViewerID(viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error)
-	DeleteProjectViewerByViewerID(viewerID *uuid.UUID, tx *sql.Tx) error
-	DeleteProjectViewerByProjectID(projectID *uuid.UUID, tx *sql.Tx) error
-
-	DeleteViewerByOwnerID(userID *uuid.UUID, tx *sql.Tx) error
-
-	GetPrivileges() (models.Privileges, error)
-	GetPrivilege(name string) (*models.Privilege, error)
+	GetUser(ctx context.Context, queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error)
+	AddUser(ctx context.Context, user *models.User, tx *sql.Tx) error
+	DeleteUser(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error
+	GetProductUserIDs(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error)
+	GetUsersByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error)
+
+	AddAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error
+	DeleteAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error
+	GetAssets(ctx context.Context, assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error)
+	GetAsset(ctx context.Context, assetType string, assetID *uuid.UUID) (*models.Asset, error)
+	UpdateAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error
+	PatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error)
+	GetAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) ([]models.AssetRevision, error)
+	GetAssetRevision(ctx context.Context, assetType string, assetID *uuid.UUID, version int64, tx *sql.Tx) (*models.AssetRevision, error)
+
+	TrashEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error
+	RestoreEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error
+	TrashProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error
+	RestoreProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error
+	GetTrash(ctx context.Context, entityType string, deletedBefore time.Time, tx *sql.Tx) ([]models.TrashEntry, error)
+	GetTrashEntry(ctx context.Context, entityType string, ID *uuid.UUID, tx *sql.Tx) (*models.TrashEntry, error)
+
+	AddAuditEvent(ctx context.Context, event *models.AuditEvent, tx *sql.Tx) error
+	GetAuditEvents(ctx context.Context, filter *models.AuditFilter, tx *sql.Tx) ([]models.AuditEvent, error)
+	GetAuditEventsAfter(ctx context.Context, afterID int64, limit int, tx *sql.Tx) ([]models.AuditEvent, error)
+
+	AddOutboxEvent(ctx context.Context, event *models.DomainEvent, tx *sql.Tx) error
+	GetOutboxEvents(ctx context.Context, limit int, tx *sql.Tx) ([]models.DomainEvent, error)
+	DeleteOutboxEvent(ctx context.Context, ID int64, tx *sql.Tx) error
+	RecordOutboxFailure(ctx context.Context, ID int64, message string, tx *sql.Tx) error
+
+	AddWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error
+	UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error
+	DeleteWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error
+	GetWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context, tx *sql.Tx) ([]models.WebhookSubscription, error)
+	AddWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, beforeID int64, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error)
+	GetDueWebhookDeliveries(ctx context.Context, dueAt time.Time, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error
+	DeleteWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, tx *sql.Tx) error
+
+	UpdateUsersProducts(ctx context.Context, userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error
+	AddProductUsers(ctx context.Context, productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error
+	DeleteProductUsersByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error
+	DeleteProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error
+	GetUserProductIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error)
+
+	GetProductByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Product, error)
+	GetProductByName(ctx context.Context, name string, tx *sql.Tx) (*models.Product, error)
+	AddProduct(ctx context.Context, product *models.Product, tx *sql.Tx) error
+	DeleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error
+	GetProductsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error)
+	GetProductsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Product, error)
+
+	AddProject(ctx context.Context, project *models.Project, tx *sql.Tx) error
+	AddProjectUsers(ctx context.Context, projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error
+	GetProjectByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Project, error)
+	DeleteProjectUsersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error
+	DeleteProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error
+	DeleteProject(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error
+	DeleteProjectsByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error
+	GetProjectsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error)
+	GetProjectsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Project, error)
+	GetProductProjects(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error)
+
+	GetUserProjectIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error)
+	UpdateUsersProjects(ctx context.Context, userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error
+	AddProjectViewer(ctx context.Context, projectViewer *models.ProjectViewer, tx *sql.Tx) error
+	DeleteProjectViewerByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error
+	GetProjectViewersByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error)
+	GetProjectViewersByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error)
+	GetProjectViewersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error)
+	DeleteProjectViewerByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) error
+	DeleteProjectViewerByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error
+
+	GetViewerIDsByOwnerID(ctx context.Context, ownerID *uuid.UUID, tx *sql.Tx) ([]uuid.UUID, error)
+	DeleteViewerByOwnerID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error
+
+	GetPrivileges(ctx context.Context) (models.Privileges, error)
+	GetPrivilege(ctx context.Context, name string) (*models.Privilege, error)
+
+	DumpTable(ctx context.Context, table *BackupTable, fn func(r models.BackupRow) error, tx *sql.Tx) error
+	RestoreRow(ctx context.Context, table *BackupTable, r models.BackupRow, tx *sql.Tx) error
 }
 
 // MYSQLFunctions represents the implementation of MYSQL data manipulation functions.
@@ -85,63 +142,204 @@ type MYSQLFunctions struct {
 }
 
 func (*MYSQLConnector) Commit(tx *sql.Tx) error {
-	return tx.Commit()
+	return TranslateError(tx.Commit())
 }
 
 func (*MYSQLConnector) Rollback(tx *sql.Tx) error {
 	return tx.Rollback()
 }
 
-func (c *MYSQLConnector) BootstrapSystem() error {
-	fmt.Printf("Executing MYSQL migration\n")
-	migrations := &migrate.FileMigrationSource{
-		Dir: c.MigrationDirectory,
+// Savepoint marks the current state of the transaction. Changes made after it can be undone
+// by RollbackToSavepoint without aborting the transaction itself.
+func (*MYSQLConnector) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+func (*MYSQLConnector) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+func (*MYSQLConnector) ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+// open creates the connection pool if it does not exist yet.
+func (c *MYSQLConnector) open() error {
+	if c.db != nil {
+		return nil
 	}
-	fmt.Printf("Getting migration files\n")
 
 	db, err := sql.Open("mysql", c.DBConnection)
 	if err != nil {
 		return err
 	}
+
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	c.db = db
+	return nil
+}
+
+func (c *MYSQLConnector) BootstrapSystem() error {
+	if err := c.open(); err != nil {
+		return err
+	}
 	fmt.Printf("DB connection open\n")
 
-	n := 0
+	if c.SkipMigration {
+		fmt.Printf("Automatic migration is disabled\n")
+		return nil
+	}
+
+	var err error
 	for retryCount := 20; retryCount > 0; retryCount-- {
-		n, err = migrate.Exec(db, "mysql", migrations, migrate.Up)
+		err = c.db.Ping()
 		if err == nil {
 			break
 		}
 		time.Sleep(1 * time.Second)
-		log.Printf("Failed to execute migration %s. Retrying...\n", err.Error())
+		log.Printf("Failed to connect to DB %s. Retrying...\n", err.Error())
+	}
+
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "DB is not available after multiple retries.")
+	}
+
+	fmt.Printf("Executing MYSQL migration\n")
+	migrator, err := c.Migrator()
+	if err != nil {
+		return err
 	}
 
+	n, err := migrateLocked(context.Background(), c.db, c.MigrationLockTimeout, migrator.Up, migrator.Pending)
 	if err != nil {
-		return errors.Wrap(errors.WithStack(err), "Migration failed after multiple retries.")
+		return errors.Wrap(errors.WithStack(err), "Migration failed.")
 	}
 	fmt.Printf("Applied %d migrations!\n", n)
 	return nil
 }
 
-func RollbackWithErrorStack(tx *sql.Tx, errorStack error) error {
-	if err := tx.Rollback(); err != nil {
-		errorString := fmt.Sprintf("%s\n%s\n", errorStack.Error(), err.Error())
-		return errors.Wrap(errors.WithStack(errors.New(errorString)), "Failed to rollback changes")
+// Every instance connecting to the same database uses the same lock, instances of other databases
+// on the same server are not blocked.
+const (
+	GetMigrationLockQuery     = "SELECT GET_LOCK(CONCAT('migration.', DATABASE()), 0)"
+	ReleaseMigrationLockQuery = "SELECT RELEASE_LOCK(CONCAT('migration.', DATABASE()))"
+)
+
+var ErrMigrationLockTimeout = errors.New("Timed out waiting for the migration of another instance")
+
+// migrationPollInterval is the delay between the schema version checks of the instances waiting for the migration.
+var migrationPollInterval = time.Second
+
+// migrateLocked serialises the migrations of the instances sharing the database.
+// The instance acquiring the migration lock applies the pending migrations, the others do not
+// migrate themselves, they wait at most timeout until the schema reaches the version of their migration files.
+// The lock belongs to the DB session, so it is held on a dedicated connection and released
+// by the server as well if the instance dies during the migration.
+func migrateLocked(
+	ctx context.Context,
+	db *sql.DB,
+	timeout time.Duration,
+	up func(to string) (int, error),
+	pending func() (int, error)) (int, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, GetMigrationLockQuery).Scan(&acquired); err != nil {
+		return 0, errors.Wrap(err, "Failed to get the migration lock")
+	}
+
+	if !acquired.Valid {
+		return 0, errors.New("Failed to get the migration lock")
+	}
+
+	if acquired.Int64 == 1 {
+		n, err := up("")
+		var released sql.NullInt64
+		if errRelease := conn.QueryRowContext(ctx, ReleaseMigrationLockQuery).Scan(&released); errRelease != nil && err == nil {
+			err = errors.Wrap(errRelease, "Failed to release the migration lock")
+		}
+		return n, err
+	}
+
+	log.Printf("Another instance is migrating the DB, waiting for it to finish\n")
+	deadline := time.Now().Add(timeout)
+	for {
+		count, err := pending()
+		if err != nil {
+			return 0, err
+		}
+
+		if count == 0 {
+			return 0, nil
+		}
+
+		if time.Now().After(deadline) {
+			return 0, errors.Wrapf(ErrMigrationLockTimeout, "%d migrations are still pending after %s", count, timeout)
+		}
+		time.Sleep(migrationPollInterval)
 	}
-	return errorStack
 }
 
-func (c *MYSQLConnector) ConnectSystem() (*sql.Tx, error) {
-	db, err := sql.Open("mysql", c.DBConnection)
+// Migrator opens the connection pool if needed and returns the migration manager working on it.
+func (c *MYSQLConnector) Migrator() (*Migrator, error) {
+	if err := c.open(); err != nil {
+		return nil, err
+	}
+
+	source, err := migrations.Source(migrations.MySQL, c.MigrationDirectory)
 	if err != nil {
 		return nil, err
 	}
 
-	defer db.Close()
+	return &Migrator{
+		DB:      c.db,
+		Dialect: "mysql",
+		Source:  source,
+	}, nil
+}
 
-	tx, err := db.Begin()
+// RollbackWithErrorStack rolls back the transaction and returns the translated form of errorStack.
+// The returned error still matches the sentinel errors of TranslateError if the rollback fails too.
+// Only functions that start the transaction themselves may use it, a transaction received
+// as argument is rolled back by its owner.
+func RollbackWithErrorStack(tx *sql.Tx, errorStack error) error {
+	errorStack = TranslateError(errorStack)
+	if err := tx.Rollback(); err != nil {
+		return errors.Wrapf(errorStack, "Failed to rollback changes: %s", err.Error())
+	}
+	return errorStack
+}
+
+// ConnectSystem starts a new transaction on a connection taken from the pool.
+func (c *MYSQLConnector) ConnectSystem(ctx context.Context) (*sql.Tx, error) {
+	if c.db == nil {
+		return nil, ErrConnectorNotOpen
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	return tx, nil
 }
+
+// Close releases the connection pool. It is safe to call on a connector that has not been bootstrapped.
+func (c *MYSQLConnector) Close() error {
+	if c.db == nil {
+		return nil
+	}
+
+	err := c.db.Close()
+	c.db = nil
+	return err
+}
@@ -0,0 +1,135 @@
+package mysqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+func TestConnectSystem(t *testing.T) {
+	connector := &MYSQLConnector{}
+
+	tx, err := connector.ConnectSystem(context.Background())
+	tests.CheckResult(tx == nil, true, err, ErrConnectorNotOpen, "not_bootstrapped", t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	connector.db = db
+
+	// Every transaction must come from the same pool instead of a new handle.
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	tx, err = connector.ConnectSystem(context.Background())
+	tests.CheckResult(nil, nil, err, nil, "first_transaction", t)
+	tests.CheckResult(nil, nil, connector.Commit(tx), nil, "first_transaction_commit", t)
+
+	tx, err = connector.ConnectSystem(context.Background())
+	tests.CheckResult(nil, nil, err, nil, "second_transaction", t)
+	tests.CheckResult(nil, nil, connector.Rollback(tx), nil, "second_transaction_rollback", t)
+
+	tests.CheckResult(nil, nil, connector.Close(), nil, "close", t)
+	tests.CheckResult(nil, nil, connector.Close(), nil, "close_twice", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSavepoint(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+	connector := &MYSQLConnector{db: db}
+
+	// A failed sub operation is undone without ending the transaction.
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	tx, err := connector.ConnectSystem(ctx)
+	tests.CheckResult(nil, nil, err, nil, "begin", t)
+	tests.CheckResult(nil, nil, connector.Savepoint(ctx, tx, "sp_1"), nil, "savepoint", t)
+	tests.CheckResult(nil, nil, connector.RollbackToSavepoint(ctx, tx, "sp_1"), nil, "rollback_to_savepoint", t)
+	tests.CheckResult(nil, nil, connector.Savepoint(ctx, tx, "sp_2"), nil, "second_savepoint", t)
+	tests.CheckResult(nil, nil, connector.ReleaseSavepoint(ctx, tx, "sp_2"), nil, "release_savepoint", t)
+	tests.CheckResult(nil, nil, connector.Commit(tx), nil, "commit", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrateLocked(t *testing.T) {
+	migrationPollInterval = time.Millisecond
+	defer func() { migrationPollInterval = time.Second }()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	migrated := 0
+	up := func(to string) (int, error) {
+		migrated++
+		return 2, nil
+	}
+
+	pendingCounts := []int{}
+	pending := func() (int, error) {
+		if len(pendingCounts) == 0 {
+			return 0, errors.New("Unexpected schema version check")
+		}
+		count := pendingCounts[0]
+		pendingCounts = pendingCounts[1:]
+		return count, nil
+	}
+
+	ctx := context.Background()
+
+	// The instance holding the lock applies the migrations and releases the lock.
+	mock.ExpectQuery(GetMigrationLockQuery).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectQuery(ReleaseMigrationLockQuery).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	n, err := migrateLocked(ctx, db, time.Minute, up, pending)
+	tests.CheckResult(n, 2, err, nil, "lock_acquired", t)
+	tests.CheckResult(migrated, 1, nil, nil, "lock_acquired_migrated", t)
+
+	// The other instances wait for the schema to be up to date without migrating.
+	mock.ExpectQuery(GetMigrationLockQuery).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+	pendingCounts = []int{2, 1, 0}
+	n, err = migrateLocked(ctx, db, time.Minute, up, pending)
+	tests.CheckResult(n, 0, err, nil, "lock_lost", t)
+	tests.CheckResult(migrated, 1, len(pendingCounts), 0, "lock_lost_not_migrated", t)
+
+	mock.ExpectQuery(GetMigrationLockQuery).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+	pendingCounts = []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	_, err = migrateLocked(ctx, db, 5*time.Millisecond, up, pending)
+	tests.CheckResult(errors.Is(err, ErrMigrationLockTimeout), true, migrated, 1, "lock_lost_timeout", t)
+
+	mock.ExpectQuery(GetMigrationLockQuery).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(nil))
+	_, err = migrateLocked(ctx, db, time.Minute, up, pending)
+	tests.CheckResult(err != nil, true, migrated, 1, "lock_error", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
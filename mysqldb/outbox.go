@@ -0,0 +1,95 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const OutboxEvents = "outbox_events"
+
+// MaxOutboxErrorLength is the length of the last_error column, longer messages have to be cut by the caller.
+const MaxOutboxErrorLength = 1024
+
+var AddOutboxEventQuery = "INSERT INTO outbox_events (event_type, occurred_at, actor, aggregate_id, payload) VALUES (?, ?, UUID_TO_BIN(?), UUID_TO_BIN(?), ?)"
+
+// AddOutboxEvent writes the event in tx, so it is only published if the change is committed.
+func (*MYSQLFunctions) AddOutboxEvent(ctx context.Context, event *models.DomainEvent, tx *sql.Tx) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	var actor interface{}
+	if event.Actor != nil {
+		actor = event.Actor
+	}
+
+	_, err = tx.ExecContext(ctx, AddOutboxEventQuery, event.Type, event.OccurredAt, actor, event.AggregateID, payload)
+	return TranslateError(err)
+}
+
+var GetOutboxEventsQuery = "SELECT id, event_type, occurred_at, BIN_TO_UUID(actor), BIN_TO_UUID(aggregate_id), payload, attempts FROM outbox_events ORDER BY id LIMIT ?"
+
+// GetOutboxEvents lists at most limit undelivered events, the oldest first. sql.ErrNoRows is returned if there are none.
+func (*MYSQLFunctions) GetOutboxEvents(ctx context.Context, limit int, tx *sql.Tx) ([]models.DomainEvent, error) {
+	rows, err := tx.QueryContext(ctx, GetOutboxEventsQuery, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+
+	events := make([]models.DomainEvent, 0)
+	for rows.Next() {
+		event := models.DomainEvent{}
+		var actor sql.NullString
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.Type, &event.OccurredAt, &actor, &event.AggregateID, &payload, &event.Attempts); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := scanDomainEvent(&event, actor, payload); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(events) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return events, nil
+}
+
+var DeleteOutboxEventQuery = "DELETE FROM outbox_events WHERE id = ?"
+
+// DeleteOutboxEvent removes the delivered event. Deleting an event already removed by another dispatcher is not an error.
+func (*MYSQLFunctions) DeleteOutboxEvent(ctx context.Context, ID int64, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, DeleteOutboxEventQuery, ID)
+	return TranslateError(err)
+}
+
+var RecordOutboxFailureQuery = "UPDATE outbox_events SET attempts = attempts + 1, last_error = ? WHERE id = ?"
+
+// RecordOutboxFailure counts a failed delivery of the event and keeps its error.
+func (*MYSQLFunctions) RecordOutboxFailure(ctx context.Context, ID int64, message string, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, RecordOutboxFailureQuery, message, ID)
+	return TranslateError(err)
+}
+
+func scanDomainEvent(event *models.DomainEvent, actor sql.NullString, payload []byte) error {
+	if actor.Valid {
+		actorID, err := uuid.Parse(actor.String)
+		if err != nil {
+			return errors.Wrap(err, "Invalid actor in the outbox")
+		}
+		event.Actor = &actorID
+	}
+	return json.Unmarshal(payload, &event.Payload)
+}
@@ -0,0 +1,74 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestOutboxEvents(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	actor := uuid.New()
+	userID := uuid.New()
+	occurredAt := time.Date(2021, 8, 15, 12, 0, 0, 0, time.UTC)
+	event := &models.DomainEvent{
+		Type:        models.EventUserCreated,
+		OccurredAt:  occurredAt,
+		Actor:       &actor,
+		AggregateID: userID,
+		Payload:     models.DataMap{"name": "testUser"},
+	}
+	columns := []string{"id", "event_type", "occurred_at", "actor", "aggregate_id", "payload", "attempts"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(AddOutboxEventQuery).
+		WithArgs(models.EventUserCreated, occurredAt, &actor, userID, []byte(`{"name":"testUser"}`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(GetOutboxEventsQuery).WithArgs(10).WillReturnRows(
+		sqlmock.NewRows(columns).
+			AddRow(1, models.EventUserCreated, occurredAt, actor.String(), userID.String(), []byte(`{"name":"testUser"}`), 2))
+	mock.ExpectExec(RecordOutboxFailureQuery).WithArgs("connection refused", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteOutboxEventQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(GetOutboxEventsQuery).WithArgs(10).WillReturnRows(sqlmock.NewRows(columns))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	functions := &MYSQLFunctions{}
+	err = functions.AddOutboxEvent(ctx, event, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_event", t)
+
+	events, err := functions.GetOutboxEvents(ctx, 10, tx)
+	event.ID = 1
+	event.Attempts = 2
+	tests.CheckResult(events, []models.DomainEvent{*event}, err, nil, "events", t)
+
+	err = functions.RecordOutboxFailure(ctx, 1, "connection refused", tx)
+	tests.CheckResult(nil, nil, err, nil, "record_failure", t)
+
+	err = functions.DeleteOutboxEvent(ctx, 1, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_event", t)
+
+	_, err = functions.GetOutboxEvents(ctx, 10, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_events", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
@@ -8,13 +9,13 @@ import (
 
 var GetPrivilegesQuery = "SELECT id, name, description from privileges"
 
-func (f *MYSQLFunctions) GetPrivileges() (models.Privileges, error) {
-	tx, err := f.DBConnector.ConnectSystem()
+func (f *MYSQLFunctions) GetPrivileges(ctx context.Context) (models.Privileges, error) {
+	tx, err := f.DBConnector.ConnectSystem(ctx)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, err
 	}
 
-	rows, err := tx.Query(GetPrivilegesQuery)
+	rows, err := tx.QueryContext(ctx, GetPrivilegesQuery)
 	switch {
 	case err == sql.ErrNoRows:
 		if err := tx.Commit(); err != nil {
@@ -47,13 +48,13 @@ func (f *MYSQLFunctions) GetPrivileges() (models.Privileges, error) {
 
 var GetPrivilegeQuery = "SELECT id, name, description from privileges where name = ?"
 
-func (f *MYSQLFunctions) GetPrivilege(name string) (*models.Privilege, error) {
-	tx, err := f.DBConnector.ConnectSystem()
+func (f *MYSQLFunctions) GetPrivilege(ctx context.Context, name string) (*models.Privilege, error) {
+	tx, err := f.DBConnector.ConnectSystem(ctx)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, err
 	}
 
-	rows := tx.QueryRow(GetPrivilegeQuery, name)
+	rows := tx.QueryRowContext(ctx, GetPrivilegeQuery, name)
 	switch {
 	case err == sql.ErrNoRows:
 		if err := tx.Commit(); err != nil {
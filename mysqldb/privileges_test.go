@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -144,7 +145,7 @@ func TestGetPrivileges(t *testing.T) {
 		t.Run(testCaseString, func(t *testing.T) {
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(PrivilegeExpectedData)
 
-			output, err := DBFunctions.GetPrivileges()
+			output, err := DBFunctions.GetPrivileges(context.Background())
 			tests.CheckResult(output, expectedData.privileges, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -167,7 +168,7 @@ func TestGetPrivilege(t *testing.T) {
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(PrivilegeExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(string)
 
-			output, err := DBFunctions.GetPrivilege(inputData)
+			output, err := DBFunctions.GetPrivilege(context.Background(), inputData)
 			tests.CheckResult(output, expectedData.privilege, err, expectedData.err, testCaseString, t)
 		})
 	}
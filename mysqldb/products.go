@@ -1,8 +1,8 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"strings"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
@@ -10,7 +10,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-var ErrSQLDuplicateProductNameEntryString = "Duplicate entry '%s' for key 'products.name'"
+// ErrDuplicateProductNameEntry is the error of a duplicate product name, the controllers return it as ErrProductExists.
 var ErrDuplicateProductNameEntry = errors.New("Product with this name already exists")
 var ErrNoUserWithProduct = errors.New("No user is associated to this product")
 var ErrNoProductUserAdded = errors.New("No product user relation has been added")
@@ -19,22 +19,19 @@ var ErrNoUsersProductUpdate = errors.New("No users product was updated")
 
 var AddProductUsersQuery = "INSERT INTO users_products (users_id, products_id, privileges_id) VALUES (UUID_TO_BIN(?), UUID_TO_BIN(?), ?)"
 
-func (*MYSQLFunctions) AddProductUsers(productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
+func (*MYSQLFunctions) AddProductUsers(ctx context.Context, productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
 	for userID, privilege := range productUsers.UserMap {
-		result, err := tx.Exec(AddProductUsersQuery, userID, productID, privilege)
+		result, err := tx.ExecContext(ctx, AddProductUsersQuery, userID, productID, privilege)
 		if err != nil {
-			return RollbackWithErrorStack(tx, err)
+			return TranslateError(err)
 		}
 
 		affected, err := result.RowsAffected()
 		if err != nil {
-			return RollbackWithErrorStack(tx, err)
+			return TranslateError(err)
 		}
 
 		if affected == 0 {
-			if errRb := tx.Rollback(); errRb != nil {
-				return err
-			}
 			return ErrNoProductUserAdded
 		}
 	}
@@ -43,21 +40,18 @@ func (*MYSQLFunctions) AddProductUsers(productID *uuid.UUID, productUsers *model
 
 var DeleteProductUsersByProductIDQuery = "DELETE FROM users_products where products_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProductUsersByProductID(productID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProductUsersByProductIDQuery, productID)
+func (*MYSQLFunctions) DeleteProductUsersByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductUsersByProductIDQuery, productID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoUserWithProduct
 	}
 
@@ -66,21 +60,18 @@ func (*MYSQLFunctions) DeleteProductUsersByProductID(productID *uuid.UUID, tx *s
 
 var UpdateUsersProductsQuery = "UPDATE users_products set privileges_id = ? where users_id = UUID_TO_BIN(?) AND products_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) UpdateUsersProducts(userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
-	result, err := tx.Exec(UpdateUsersProductsQuery, privilege, userID, productID)
+func (*MYSQLFunctions) UpdateUsersProducts(ctx context.Context, userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, UpdateUsersProductsQuery, privilege, userID, productID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoUsersProductUpdate
 	}
 
@@ -89,54 +80,43 @@ func (*MYSQLFunctions) UpdateUsersProducts(userID *uuid.UUID, productID *uuid.UU
 
 var AddProductQuery = "INSERT INTO products (id, name, product_details_id, product_assets_id) VALUES (UUID_TO_BIN(?), ?, UUID_TO_BIN(?), UUID_TO_BIN(?))"
 
-func (*MYSQLFunctions) AddProduct(product *models.Product, tx *sql.Tx) error {
+func (*MYSQLFunctions) AddProduct(ctx context.Context, product *models.Product, tx *sql.Tx) error {
 	// Execute transaction
-	_, err := tx.Exec(AddProductQuery, product.ID, product.Name, product.DetailsID, product.AssetsID)
-	errDuplicateName := fmt.Errorf(ErrSQLDuplicateProductNameEntryString, product.Name)
+	_, err := tx.ExecContext(ctx, AddProductQuery, product.ID, product.Name, product.DetailsID, product.AssetsID)
 	if err != nil {
-		switch {
-		case err.Error() == errDuplicateName.Error():
-			if errRb := tx.Rollback(); errRb != nil {
-				return err
-			}
-			return errDuplicateName
-		case err != nil:
-			return RollbackWithErrorStack(tx, err)
-		default:
-			return nil
-		}
+		return TranslateError(err)
 	}
 	return nil
 }
 
-var GetProductByIDQuery = "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE id = UUID_TO_BIN(?)"
+var GetProductByIDQuery = "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE id = UUID_TO_BIN(?) AND deleted_at IS NULL"
 
-func (*MYSQLFunctions) GetProductByID(ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
+func (*MYSQLFunctions) GetProductByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
 	product := models.Product{}
-	query := tx.QueryRow(GetProductByIDQuery, ID)
+	query := tx.QueryRowContext(ctx, GetProductByIDQuery, ID)
 	err := query.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, sql.ErrNoRows
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 
 	return &product, nil
 }
 
-var GetProductsByIDsQuery = "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE id IN (UUID_TO_BIN(?)"
+var GetProductsByIDsQuery = "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE deleted_at IS NULL AND id IN (UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetProductsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
+func (*MYSQLFunctions) GetProductsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
 	query := GetProductsByIDsQuery + strings.Repeat(",UUID_TO_BIN(?)", len(IDs)-1) + ")"
 	interfaceList := make([]interface{}, len(IDs))
 	for i := range IDs {
 		interfaceList[i] = IDs[i]
 	}
-	rows, err := tx.Query(query, interfaceList...)
+	rows, err := tx.QueryContext(ctx, query, interfaceList...)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -146,13 +126,53 @@ func (*MYSQLFunctions) GetProductsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.P
 		product := models.Product{}
 		err := rows.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		products = append(products, product)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
+	}
+
+	if len(products) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return products, nil
+}
+
+// productsByDetailsQuery returns the query of the products whose details match predicate.
+func productsByDetailsQuery(predicate string) string {
+	return "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE deleted_at IS NULL AND product_details_id IN (SELECT id FROM product_details WHERE " + predicate + ")"
+}
+
+// GetProductsByDetails returns the products whose details match filter.
+func (*MYSQLFunctions) GetProductsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Product, error) {
+	predicate, args, err := detailsPredicate(ProductDetails, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, productsByDetailsQuery(predicate), args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		product := models.Product{}
+		err := rows.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		products = append(products, product)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
 	}
 
 	if len(products) == 0 {
@@ -164,13 +184,13 @@ func (*MYSQLFunctions) GetProductsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.P
 
 var GetUserProductIDsQuery = "SELECT BIN_TO_UUID(products_id), privileges_id FROM users_products where users_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetUserProductIDs(userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
-	rows, err := tx.Query(GetUserProductIDsQuery, userID)
+func (*MYSQLFunctions) GetUserProductIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetUserProductIDsQuery, userID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, sql.ErrNoRows
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 
@@ -184,31 +204,31 @@ func (*MYSQLFunctions) GetUserProductIDs(userID *uuid.UUID, tx *sql.Tx) (*models
 		privilege := -1
 		err := rows.Scan(&productID, &privilege)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		userProducts.ProductMap[productID] = privilege
 		userProducts.ProductIDArray = append(userProducts.ProductIDArray, productID)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 	return &userProducts, nil
 }
 
-var GetProductByNameQuery = "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE name = ?"
+var GetProductByNameQuery = "SELECT BIN_TO_UUID(id), name, BIN_TO_UUID(product_details_id), BIN_TO_UUID(product_assets_id) FROM products WHERE name = ? AND deleted_at IS NULL"
 
-func (*MYSQLFunctions) GetProductByName(name string, tx *sql.Tx) (*models.Product, error) {
+func (*MYSQLFunctions) GetProductByName(ctx context.Context, name string, tx *sql.Tx) (*models.Product, error) {
 	product := models.Product{}
 
-	query := tx.QueryRow(GetProductByNameQuery, name)
+	query := tx.QueryRowContext(ctx, GetProductByNameQuery, name)
 
 	err := query.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, sql.ErrNoRows
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 
@@ -217,21 +237,18 @@ func (*MYSQLFunctions) GetProductByName(name string, tx *sql.Tx) (*models.Produc
 
 var DeleteProductQuery = "DELETE FROM products where id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProduct(productID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProductQuery, productID)
+func (*MYSQLFunctions) DeleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductQuery, productID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoProductDeleted
 	}
 
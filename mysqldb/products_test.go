@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
@@ -141,7 +143,6 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 		expected := errors.New("This is a failure test")
 		mock.ExpectBegin()
 		mock.ExpectExec(AddProductQuery).WithArgs(product.ID, product.Name, product.DetailsID, product.AssetsID).WillReturnError(expected)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				product: product,
@@ -153,10 +154,13 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 
 		testCase = "duplicate_name"
-		expected = fmt.Errorf(ErrSQLDuplicateProductNameEntryString, product.Name)
+		mysqlErr := &mysql.MySQLError{
+			Number:  ErDupEntry,
+			Message: fmt.Sprintf("Duplicate entry '%s' for key 'products.name'", product.Name),
+		}
+		expected = TranslateError(mysqlErr)
 		mock.ExpectBegin()
-		mock.ExpectExec(AddProductQuery).WithArgs(product.ID, product.Name, product.DetailsID, product.AssetsID).WillReturnError(expected)
-		mock.ExpectRollback()
+		mock.ExpectExec(AddProductQuery).WithArgs(product.ID, product.Name, product.DetailsID, product.AssetsID).WillReturnError(mysqlErr)
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				product: product,
@@ -192,7 +196,6 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 			privilege := productUsers.UserMap[userID]
 			mock.ExpectExec(AddProductUsersQuery).WithArgs(userID, product.ID, privilege).WillReturnError(expected)
 		}
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				product:      product,
@@ -210,7 +213,6 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 			privilege := productUsers.UserMap[userID]
 			mock.ExpectExec(AddProductUsersQuery).WithArgs(userID, product.ID, privilege).WillReturnResult(sqlmock.NewResult(1, 0))
 		}
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				product:      product,
@@ -239,7 +241,6 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 		testCase = "missing_id"
 		mock.ExpectBegin()
 		mock.ExpectExec(DeleteProductUsersByProductIDQuery).WithArgs(product.ID).WillReturnError(ErrNoUserWithProduct)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				product: product,
@@ -363,7 +364,6 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 		testCase = "no_product"
 		mock.ExpectBegin()
 		mock.ExpectExec(DeleteProductQuery).WithArgs(product.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				product: product,
@@ -395,7 +395,6 @@ func createProductsTestData(testID int) (*tests.OrderedTests, error) {
 		privilege = 1
 		mock.ExpectBegin()
 		mock.ExpectExec(UpdateUsersProductsQuery).WithArgs(privilege, userID, product.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: ProductInputData{
 				userID:    &userID,
@@ -444,7 +443,7 @@ func TestAddProduct(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			err = DBFunctions.AddProduct(inputData.product, tx)
+			err = DBFunctions.AddProduct(context.Background(), inputData.product, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -471,7 +470,7 @@ func TestAddProductUsers(t *testing.T) {
 			testCase := dataSet.TestDataSet[testCaseString]
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
-			err = DBFunctions.AddProductUsers(&inputData.product.ID, inputData.productUsers, tx)
+			err = DBFunctions.AddProductUsers(context.Background(), &inputData.product.ID, inputData.productUsers, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -499,7 +498,7 @@ func TestUpdateUsersProducts(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			err = DBFunctions.UpdateUsersProducts(inputData.userID, &inputData.product.ID, inputData.privilege, tx)
+			err = DBFunctions.UpdateUsersProducts(context.Background(), inputData.userID, &inputData.product.ID, inputData.privilege, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -527,7 +526,7 @@ func TestDeleteProductUsersByProductID(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			err = DBFunctions.DeleteProductUsersByProductID(&inputData.product.ID, tx)
+			err = DBFunctions.DeleteProductUsersByProductID(context.Background(), &inputData.product.ID, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -556,7 +555,7 @@ func TestGetProductByID(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			output, err := DBFunctions.GetProductByID(&inputData.product.ID, tx)
+			output, err := DBFunctions.GetProductByID(context.Background(), &inputData.product.ID, tx)
 			tests.CheckResult(output, expectedData.product, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -584,7 +583,7 @@ func TestGetProductByName(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			output, err := DBFunctions.GetProductByName(inputData.product.Name, tx)
+			output, err := DBFunctions.GetProductByName(context.Background(), inputData.product.Name, tx)
 			tests.CheckResult(output, expectedData.product, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -612,7 +611,7 @@ func TestGetUserProductIDs(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			output, err := DBFunctions.GetUserProductIDs(inputData.userID, tx)
+			output, err := DBFunctions.GetUserProductIDs(context.Background(), inputData.userID, tx)
 			tests.CheckResult(output, expectedData.userProducts, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -641,7 +640,7 @@ func TestDeleteProduct(t *testing.T) {
 			expectedData := testCase.Expected.(ProductExpectedData)
 			inputData := testCase.Data.(ProductInputData)
 
-			err = DBFunctions.DeleteProduct(&inputData.product.ID, tx)
+			err = DBFunctions.DeleteProduct(context.Background(), &inputData.product.ID, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
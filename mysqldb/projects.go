@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 
@@ -18,22 +19,19 @@ var ErrNoUsersProjectUpdate = errors.New("No users project was updated")
 
 var AddProjectUsersQuery = "INSERT INTO users_projects (users_id, projects_id, privileges_id) VALUES (UUID_TO_BIN(?), UUID_TO_BIN(?), ?)"
 
-func (*MYSQLFunctions) AddProjectUsers(projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error {
+func (*MYSQLFunctions) AddProjectUsers(ctx context.Context, projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error {
 	for userID, privilege := range projectUsers.UserMap {
-		result, err := tx.Exec(AddProjectUsersQuery, userID, projectID, privilege)
+		result, err := tx.ExecContext(ctx, AddProjectUsersQuery, userID, projectID, privilege)
 		if err != nil {
-			return RollbackWithErrorStack(tx, err)
+			return TranslateError(err)
 		}
 
 		affected, err := result.RowsAffected()
 		if err != nil {
-			return RollbackWithErrorStack(tx, err)
+			return TranslateError(err)
 		}
 
 		if affected == 0 {
-			if errRb := tx.Rollback(); errRb != nil {
-				return err
-			}
 			return ErrNoProjectUserAdded
 		}
 	}
@@ -42,21 +40,38 @@ func (*MYSQLFunctions) AddProjectUsers(projectID *uuid.UUID, projectUsers *model
 
 var DeleteProjectUsersByProjectIDQuery = "DELETE FROM users_projects where projects_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProjectUsersByProjectID(projectID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProjectUsersByProjectIDQuery, projectID)
+func (*MYSQLFunctions) DeleteProjectUsersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectUsersByProjectIDQuery, projectID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return ErrNoUserWithProject
+	}
+
+	return nil
+}
+
+var DeleteProjectUserQuery = "DELETE FROM users_projects where projects_id = UUID_TO_BIN(?) AND users_id = UUID_TO_BIN(?)"
+
+func (*MYSQLFunctions) DeleteProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectUserQuery, projectID, userID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoUserWithProject
 	}
 
@@ -65,21 +80,18 @@ func (*MYSQLFunctions) DeleteProjectUsersByProjectID(projectID *uuid.UUID, tx *s
 
 var UpdateUsersProjectsQuery = "UPDATE users_projects set privileges_id = ? where users_id = UUID_TO_BIN(?) AND projects_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) UpdateUsersProjects(userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error {
-	result, err := tx.Exec(UpdateUsersProjectsQuery, privilege, userID, projectID)
+func (*MYSQLFunctions) UpdateUsersProjects(ctx context.Context, userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, UpdateUsersProjectsQuery, privilege, userID, projectID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoUsersProjectUpdate
 	}
 
@@ -88,42 +100,42 @@ func (*MYSQLFunctions) UpdateUsersProjects(userID *uuid.UUID, projectID *uuid.UU
 
 var AddProjectQuery = "INSERT INTO projects (id, products_id, project_details_id, project_assets_id) VALUES (UUID_TO_BIN(?), UUID_TO_BIN(?), UUID_TO_BIN(?), UUID_TO_BIN(?))"
 
-func (*MYSQLFunctions) AddProject(project *models.Project, tx *sql.Tx) error {
-	_, err := tx.Exec(AddProjectQuery, project.ID, project.ProductID, project.DetailsID, project.AssetsID)
+func (*MYSQLFunctions) AddProject(ctx context.Context, project *models.Project, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, AddProjectQuery, project.ID, project.ProductID, project.DetailsID, project.AssetsID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 	return nil
 }
 
-var GetProjectByIDQuery = "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects WHERE id = UUID_TO_BIN(?)"
+var GetProjectByIDQuery = "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects WHERE id = UUID_TO_BIN(?) AND deleted_at IS NULL"
 
-func (*MYSQLFunctions) GetProjectByID(ID *uuid.UUID, tx *sql.Tx) (*models.Project, error) {
+func (*MYSQLFunctions) GetProjectByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Project, error) {
 	project := &models.Project{}
-	query := tx.QueryRow(GetProjectByIDQuery, ID)
+	query := tx.QueryRowContext(ctx, GetProjectByIDQuery, ID)
 	err := query.Scan(&project.ID, &project.ProductID, &project.DetailsID, &project.AssetsID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, sql.ErrNoRows
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 
 	return project, nil
 }
 
-var GetProjectsByIDsQuery = "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects WHERE id IN (UUID_TO_BIN(?)"
+var GetProjectsByIDsQuery = "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects WHERE deleted_at IS NULL AND id IN (UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetProjectsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+func (*MYSQLFunctions) GetProjectsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
 	query := GetProjectsByIDsQuery + strings.Repeat(",UUID_TO_BIN(?)", len(IDs)-1) + ")"
 	interfaceList := make([]interface{}, len(IDs))
 	for i := range IDs {
 		interfaceList[i] = IDs[i]
 	}
-	rows, err := tx.Query(query, interfaceList...)
+	rows, err := tx.QueryContext(ctx, query, interfaceList...)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -133,13 +145,53 @@ func (*MYSQLFunctions) GetProjectsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.P
 		project := models.Project{}
 		err := rows.Scan(&project.ID, &project.ProductID, &project.DetailsID, &project.AssetsID)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		projects = append(projects, project)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
+	}
+
+	if len(projects) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return projects, nil
+}
+
+// projectsByDetailsQuery returns the query of the projects whose details match predicate.
+func projectsByDetailsQuery(predicate string) string {
+	return "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects WHERE deleted_at IS NULL AND project_details_id IN (SELECT id FROM project_details WHERE " + predicate + ")"
+}
+
+// GetProjectsByDetails returns the projects whose details match filter.
+func (*MYSQLFunctions) GetProjectsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Project, error) {
+	predicate, args, err := detailsPredicate(ProjectDetails, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, projectsByDetailsQuery(predicate), args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	projects := make([]models.Project, 0)
+	for rows.Next() {
+		project := models.Project{}
+		err := rows.Scan(&project.ID, &project.ProductID, &project.DetailsID, &project.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		projects = append(projects, project)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
 	}
 
 	if len(projects) == 0 {
@@ -151,13 +203,13 @@ func (*MYSQLFunctions) GetProjectsByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.P
 
 var GetUserProjectIDsQuery = "SELECT BIN_TO_UUID(projects_id), privileges_id FROM users_projects where users_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetUserProjectIDs(userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error) {
-	rows, err := tx.Query(GetUserProjectIDsQuery, userID)
+func (*MYSQLFunctions) GetUserProjectIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetUserProjectIDsQuery, userID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, sql.ErrNoRows
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 
@@ -171,25 +223,25 @@ func (*MYSQLFunctions) GetUserProjectIDs(userID *uuid.UUID, tx *sql.Tx) (*models
 		privilege := -1
 		err := rows.Scan(&projectID, &privilege)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		userProjects.ProjectMap[projectID] = privilege
 		userProjects.ProjectIDArray = append(userProjects.ProjectIDArray, projectID)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 	return userProjects, nil
 }
 
-var GetProductProjectsQuery = "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects where products_id = UUID_TO_BIN(?)"
+var GetProductProjectsQuery = "SELECT BIN_TO_UUID(id), BIN_TO_UUID(products_id), BIN_TO_UUID(project_details_id), BIN_TO_UUID(project_assets_id) FROM projects where products_id = UUID_TO_BIN(?) AND deleted_at IS NULL"
 
-func (*MYSQLFunctions) GetProductProjects(productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+func (*MYSQLFunctions) GetProductProjects(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
 	projects := make([]models.Project, 0)
-	rows, err := tx.Query(GetProductProjectsQuery, &productID)
+	rows, err := tx.QueryContext(ctx, GetProductProjectsQuery, &productID)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -197,13 +249,13 @@ func (*MYSQLFunctions) GetProductProjects(productID *uuid.UUID, tx *sql.Tx) ([]m
 		project := models.Project{}
 		err := rows.Scan(&project.ID, &project.ProductID, &project.DetailsID, &project.AssetsID)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		projects = append(projects, project)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	if len(projects) == 0 {
@@ -215,21 +267,18 @@ func (*MYSQLFunctions) GetProductProjects(productID *uuid.UUID, tx *sql.Tx) ([]m
 
 var DeleteProjectQuery = "DELETE FROM projects where id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProject(projectID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProjectQuery, projectID)
+func (*MYSQLFunctions) DeleteProject(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectQuery, projectID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoProjectDeleted
 	}
 
@@ -238,15 +287,15 @@ func (*MYSQLFunctions) DeleteProject(projectID *uuid.UUID, tx *sql.Tx) error {
 
 var DeleteProjectsByProductIDQuery = "DELETE FROM projects where products_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProjectsByProductID(productID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProjectsByProductIDQuery, productID)
+func (*MYSQLFunctions) DeleteProjectsByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectsByProductIDQuery, productID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
@@ -258,8 +307,8 @@ func (*MYSQLFunctions) DeleteProjectsByProductID(productID *uuid.UUID, tx *sql.T
 
 var AddViewerQuery = "INSERT INTO viewers (id, owner_id) VALUES (UUID_TO_BIN(?), UUID_TO_BIN(?))"
 
-func addViewer(viewerID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
-	if _, err := tx.Exec(AddViewerQuery, viewerID, userID); err != nil {
+func addViewer(ctx context.Context, viewerID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, AddViewerQuery, viewerID, userID); err != nil {
 		return err
 	}
 	return nil
@@ -267,37 +316,65 @@ func addViewer(viewerID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
 
 var AddProjectViewerQuery = "INSERT INTO users_viewers (users_id, viewer_id, projects_id) VALUES (UUID_TO_BIN(?), UUID_TO_BIN(?), UUID_TO_BIN(?))"
 
-func (*MYSQLFunctions) AddProjectViewer(projectViewer *models.ProjectViewer, tx *sql.Tx) error {
+func (*MYSQLFunctions) AddProjectViewer(ctx context.Context, projectViewer *models.ProjectViewer, tx *sql.Tx) error {
 	if projectViewer.IsOwner {
-		if err := addViewer(&projectViewer.ViewerID, &projectViewer.UserID, tx); err != nil {
-			return RollbackWithErrorStack(tx, err)
+		if err := addViewer(ctx, &projectViewer.ViewerID, &projectViewer.UserID, tx); err != nil {
+			return TranslateError(err)
 		}
 	}
 
-	_, err := tx.Exec(AddProjectViewerQuery, projectViewer.UserID, projectViewer.ViewerID, projectViewer.ProjectID)
+	_, err := tx.ExecContext(ctx, AddProjectViewerQuery, projectViewer.UserID, projectViewer.ViewerID, projectViewer.ProjectID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 	return nil
 }
 
+var GetViewerIDsByOwnerIDQuery = "SELECT BIN_TO_UUID(id) FROM viewers WHERE owner_id = UUID_TO_BIN(?)"
+
+// GetViewerIDsByOwnerID returns the IDs of the viewers owned by the user. sql.ErrNoRows is returned if there are none.
+func (*MYSQLFunctions) GetViewerIDsByOwnerID(ctx context.Context, ownerID *uuid.UUID, tx *sql.Tx) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, GetViewerIDsByOwnerIDQuery, ownerID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	viewerIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var viewerID uuid.UUID
+		if err := rows.Scan(&viewerID); err != nil {
+			return nil, TranslateError(err)
+		}
+		viewerIDs = append(viewerIDs, viewerID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(viewerIDs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return viewerIDs, nil
+}
+
 var DeleteViewerByOwnerQuery = "DELETE FROM viewers where owner_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteViewerByOwnerID(userID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteViewerByOwnerQuery, userID)
+func (*MYSQLFunctions) DeleteViewerByOwnerID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteViewerByOwnerQuery, userID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoViewerDeleted
 	}
 
@@ -306,26 +383,26 @@ func (*MYSQLFunctions) DeleteViewerByOwnerID(userID *uuid.UUID, tx *sql.Tx) erro
 
 var DeleteProjectViewerByUserIDQuery = "DELETE FROM users_viewers where users_id = UUID_TO_BIN(?)"
 
-func (f *MYSQLFunctions) DeleteProjectViewerByUserID(userID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProjectViewerByUserIDQuery, userID)
+func (f *MYSQLFunctions) DeleteProjectViewerByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectViewerByUserIDQuery, userID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
 		return ErrNoProjectViewerDeleted
 	}
 
-	if err := f.DeleteViewerByOwnerID(userID, tx); err != nil {
+	if err := f.DeleteViewerByOwnerID(ctx, userID, tx); err != nil {
 		if err == ErrNoViewerDeleted {
 			return err
 		}
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	return nil
@@ -333,10 +410,10 @@ func (f *MYSQLFunctions) DeleteProjectViewerByUserID(userID *uuid.UUID, tx *sql.
 
 var GetProjectViewerByUserIDQuery = "SELECT BIN_TO_UUID(users_id), BIN_TO_UUID(viewer_id), BIN_TO_UUID(projects_id) FROM users_viewers WHERE users_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetProjectViewersByUserID(userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
-	rows, err := tx.Query(GetProjectViewerByUserIDQuery, userID)
+func (*MYSQLFunctions) GetProjectViewersByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	rows, err := tx.QueryContext(ctx, GetProjectViewerByUserIDQuery, userID)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -346,13 +423,13 @@ func (*MYSQLFunctions) GetProjectViewersByUserID(userID *uuid.UUID, tx *sql.Tx)
 		projectViewer := models.ProjectViewer{}
 		err := rows.Scan(&projectViewer.UserID, &projectViewer.ViewerID, &projectViewer.ProjectID)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		projectViewers = append(projectViewers, projectViewer)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	if len(projectViewers) == 0 {
@@ -364,10 +441,41 @@ func (*MYSQLFunctions) GetProjectViewersByUserID(userID *uuid.UUID, tx *sql.Tx)
 
 var GetProjectViewerByViewerIDQuery = "SELECT BIN_TO_UUID(users_id), BIN_TO_UUID(viewer_id), BIN_TO_UUID(projects_id) FROM users_viewers WHERE viewer_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) GetProjectViewersByViewerID(viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
-	rows, err := tx.Query(GetProjectViewerByViewerIDQuery, viewerID)
+func (*MYSQLFunctions) GetProjectViewersByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	rows, err := tx.QueryContext(ctx, GetProjectViewerByViewerIDQuery, viewerID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	projectViewers := make([]models.ProjectViewer, 0)
+	for rows.Next() {
+		projectViewer := models.ProjectViewer{}
+		err := rows.Scan(&projectViewer.UserID, &projectViewer.ViewerID, &projectViewer.ProjectID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		projectViewers = append(projectViewers, projectViewer)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(projectViewers) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return projectViewers, nil
+}
+
+var GetProjectViewerByProjectIDQuery = "SELECT BIN_TO_UUID(users_id), BIN_TO_UUID(viewer_id), BIN_TO_UUID(projects_id) FROM users_viewers WHERE projects_id = UUID_TO_BIN(?)"
+
+func (*MYSQLFunctions) GetProjectViewersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	rows, err := tx.QueryContext(ctx, GetProjectViewerByProjectIDQuery, projectID)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -377,13 +485,13 @@ func (*MYSQLFunctions) GetProjectViewersByViewerID(viewerID *uuid.UUID, tx *sql.
 		projectViewer := models.ProjectViewer{}
 		err := rows.Scan(&projectViewer.UserID, &projectViewer.ViewerID, &projectViewer.ProjectID)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		projectViewers = append(projectViewers, projectViewer)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	if len(projectViewers) == 0 {
@@ -395,21 +503,18 @@ func (*MYSQLFunctions) GetProjectViewersByViewerID(viewerID *uuid.UUID, tx *sql.
 
 var DeleteProjectViewerByViewerIDQuery = "DELETE FROM users_viewers where viewer_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProjectViewerByViewerID(viewerID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProjectViewerByViewerIDQuery, viewerID)
+func (*MYSQLFunctions) DeleteProjectViewerByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectViewerByViewerIDQuery, viewerID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoProjectViewerDeleted
 	}
 
@@ -418,15 +523,15 @@ func (*MYSQLFunctions) DeleteProjectViewerByViewerID(viewerID *uuid.UUID, tx *sq
 
 var DeleteProjectViewerByProjectIDQuery = "DELETE FROM users_viewers where projects_id = UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteProjectViewerByProjectID(projectID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProjectViewerByProjectIDQuery, projectID)
+func (*MYSQLFunctions) DeleteProjectViewerByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectViewerByProjectIDQuery, projectID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
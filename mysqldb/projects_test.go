@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -23,6 +24,8 @@ const (
 	getProductProjectsTest
 	deleteProjectTest
 	updateUsersProjectsTest
+	deleteProjectUserTest
+	getViewerIDsByOwnerIDTest
 )
 
 func createTestProjectData() (*models.Project, error) {
@@ -77,6 +80,7 @@ type ProjectExpectedData struct {
 	project      *models.Project
 	projects     []models.Project
 	userProjects *models.UserProjectIDs
+	viewerIDs    []uuid.UUID
 	err          error
 }
 
@@ -161,7 +165,6 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec(AddProjectQuery).WithArgs(project.ID, project.ProductID, project.DetailsID, project.AssetsID).WillReturnError(expected)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -198,7 +201,6 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 			privilege := projectUsers.UserMap[userID]
 			mock.ExpectExec(AddProjectUsersQuery).WithArgs(userID, project.ID, privilege).WillReturnError(expected)
 		}
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -214,7 +216,6 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 			privilege := projectUsers.UserMap[userID]
 			mock.ExpectExec(AddProjectUsersQuery).WithArgs(userID, project.ID, privilege).WillReturnResult(sqlmock.NewResult(1, 0))
 		}
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -243,7 +244,6 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec(DeleteProductUsersByProductIDQuery).WithArgs(project.ID).WillReturnError(expectedData.err)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -376,7 +376,6 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec(DeleteProjectQuery).WithArgs(project.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -405,7 +404,6 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec(DeleteProjectsByProductIDQuery).WithArgs(project.ProductID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -436,7 +434,67 @@ func createProjectsTestData(testID int) (*tests.OrderedTests, error) {
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec(UpdateUsersProjectsQuery).WithArgs(inputData.privilege, userID, project.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data:     inputData,
+			Expected: expectedData,
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	case deleteProjectUserTest:
+		testCase := "valid_id"
+		inputData := ProjectInputData{
+			project: project,
+			userID:  &userID,
+		}
+		expectedData := ProjectExpectedData{
+			err: nil,
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(DeleteProjectUserQuery).WithArgs(project.ID, userID).WillReturnResult(sqlmock.NewResult(1, 1))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data:     inputData,
+			Expected: expectedData,
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "missing_project_user"
+		expectedData = ProjectExpectedData{
+			err: ErrNoUserWithProject,
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(DeleteProjectUserQuery).WithArgs(project.ID, userID).WillReturnResult(sqlmock.NewResult(1, 0))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data:     inputData,
+			Expected: expectedData,
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	case getViewerIDsByOwnerIDTest:
+		viewerID := uuid.New()
+		testCase := "valid_id"
+		inputData := ProjectInputData{
+			userID: &userID,
+		}
+		expectedData := ProjectExpectedData{
+			viewerIDs: []uuid.UUID{viewerID},
+			err:       nil,
+		}
+		mock.ExpectBegin()
+		mock.ExpectQuery(GetViewerIDsByOwnerIDQuery).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(viewerID.String()))
+		dataSet.TestDataSet[testCase] = tests.Data{
+			Data:     inputData,
+			Expected: expectedData,
+		}
+		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+		testCase = "no_viewers"
+		expectedData = ProjectExpectedData{
+			err: sql.ErrNoRows,
+		}
+		mock.ExpectBegin()
+		mock.ExpectQuery(GetViewerIDsByOwnerIDQuery).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data:     inputData,
 			Expected: expectedData,
@@ -478,7 +536,7 @@ func TestAddProject(t *testing.T) {
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
 
-			err = DBFunctions.AddProject(inputData.project, tx)
+			err = DBFunctions.AddProject(context.Background(), inputData.project, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -504,7 +562,7 @@ func TestAddProjectUsers(t *testing.T) {
 			}
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-			err = DBFunctions.AddProjectUsers(&inputData.project.ID, inputData.projectUsers, tx)
+			err = DBFunctions.AddProjectUsers(context.Background(), &inputData.project.ID, inputData.projectUsers, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -530,7 +588,7 @@ func TestUpdateUsersProjects(t *testing.T) {
 			}
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-			err = DBFunctions.UpdateUsersProjects(inputData.userID, &inputData.project.ID, inputData.privilege, tx)
+			err = DBFunctions.UpdateUsersProjects(context.Background(), inputData.userID, &inputData.project.ID, inputData.privilege, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -556,12 +614,64 @@ func TestDeleteProjectUsersByProjectID(t *testing.T) {
 			}
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-			err = DBFunctions.DeleteProductUsersByProductID(&inputData.project.ID, tx)
+			err = DBFunctions.DeleteProductUsersByProductID(context.Background(), &inputData.project.ID, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
 }
 
+func TestDeleteProjectUser(t *testing.T) {
+	// Create test data
+	dataSet, err := createProjectsTestData(deleteProjectUserTest)
+	if err != nil {
+		t.Errorf("Failed to generate test data: %s", err)
+		return
+	}
+	defer DBFunctions.DBConnector.(*DBConnectorMock).DB.Close()
+
+	// Run tests
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			tx, err := DBFunctions.DBConnector.(*DBConnectorMock).DB.Begin()
+			if err != nil {
+				t.Errorf("Failed to setup DB transaction %s", err)
+				return
+			}
+			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
+			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
+			err = DBFunctions.DeleteProjectUser(context.Background(), &inputData.project.ID, inputData.userID, tx)
+			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
+		})
+	}
+}
+
+func TestGetViewerIDsByOwnerID(t *testing.T) {
+	// Create test data
+	dataSet, err := createProjectsTestData(getViewerIDsByOwnerIDTest)
+	if err != nil {
+		t.Errorf("Failed to generate test data: %s", err)
+		return
+	}
+	defer DBFunctions.DBConnector.(*DBConnectorMock).DB.Close()
+
+	// Run tests
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			tx, err := DBFunctions.DBConnector.(*DBConnectorMock).DB.Begin()
+			if err != nil {
+				t.Errorf("Failed to setup DB transaction %s", err)
+				return
+			}
+			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
+			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
+			output, err := DBFunctions.GetViewerIDsByOwnerID(context.Background(), inputData.userID, tx)
+			tests.CheckResult(output, expectedData.viewerIDs, err, expectedData.err, testCaseString, t)
+		})
+	}
+}
+
 func TestGetProjectByID(t *testing.T) {
 	// Create test data
 	dataSet, err := createProjectsTestData(getProjectByIDTest)
@@ -583,7 +693,7 @@ func TestGetProjectByID(t *testing.T) {
 
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-			output, err := DBFunctions.GetProjectByID(&inputData.project.ID, tx)
+			output, err := DBFunctions.GetProjectByID(context.Background(), &inputData.project.ID, tx)
 			tests.CheckResult(output, expectedData.project, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -609,7 +719,7 @@ func TestGetUserProjectIDs(t *testing.T) {
 			}
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-			output, err := DBFunctions.GetUserProjectIDs(inputData.userID, tx)
+			output, err := DBFunctions.GetUserProjectIDs(context.Background(), inputData.userID, tx)
 			tests.CheckResult(output, expectedData.userProjects, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -635,7 +745,7 @@ func TestGetProductProjects(t *testing.T) {
 			}
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-			output, err := DBFunctions.GetProductProjects(inputData.productID, tx)
+			output, err := DBFunctions.GetProductProjects(context.Background(), inputData.productID, tx)
 			tests.CheckResult(output, expectedData.projects, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -661,7 +771,7 @@ func TestDeleteProject(t *testing.T) {
 		testCaseString := testCaseString
 		expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 		inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-		err = DBFunctions.DeleteProject(&inputData.project.ID, tx)
+		err = DBFunctions.DeleteProject(context.Background(), &inputData.project.ID, tx)
 		tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 	}
 }
@@ -686,7 +796,7 @@ func TestDeleteProjectByProductID(t *testing.T) {
 		testCaseString := testCaseString
 		expectedData := dataSet.TestDataSet[testCaseString].Expected.(ProjectExpectedData)
 		inputData := dataSet.TestDataSet[testCaseString].Data.(ProjectInputData)
-		err = DBFunctions.DeleteProjectsByProductID(&inputData.project.ProductID, tx)
+		err = DBFunctions.DeleteProjectsByProductID(context.Background(), &inputData.project.ProductID, tx)
 		tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 	}
 }
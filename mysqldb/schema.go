@@ -0,0 +1,714 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Column describes a column the way information_schema reports it.
+// Type is the data type followed by the maximum length for character and binary types, for example varchar(50).
+type Column struct {
+	Type     string
+	Nullable bool
+}
+
+// Index describes an index by its columns, the names generated by MySQL are not compared.
+type Index struct {
+	Columns []string
+	Unique  bool
+}
+
+func (i Index) String() string {
+	kind := "index"
+	if i.Unique {
+		kind = "unique index"
+	}
+	return fmt.Sprintf("%s(%s)", kind, strings.Join(i.Columns, ", "))
+}
+
+type Table struct {
+	Columns map[string]Column
+	Indexes []Index
+}
+
+// Schema maps the table names to their structure.
+type Schema map[string]Table
+
+var (
+	idColumn        = Column{Type: "binary(16)", Nullable: false}
+	uuidColumn      = Column{Type: "binary(16)", Nullable: true}
+	dataColumn      = Column{Type: "json", Nullable: false}
+	timestampColumn = Column{Type: "datetime", Nullable: false}
+	deletedColumn   = Column{Type: "datetime", Nullable: true}
+	privilegeColumn = Column{Type: "tinyint", Nullable: true}
+
+	primaryKey = Index{Columns: []string{"id"}, Unique: true}
+)
+
+// assetTable returns the structure shared by the asset tables.
+func assetTable() Table {
+	return Table{
+		Columns: map[string]Column{
+			"id":         idColumn,
+			"data":       dataColumn,
+			"version":    {Type: "bigint", Nullable: false},
+			"created_at": timestampColumn,
+			"updated_at": timestampColumn,
+		},
+		Indexes: []Index{primaryKey},
+	}
+}
+
+// userSettingsTable has a leftover column of the first user settings implementation.
+func userSettingsTable() Table {
+	table := assetTable()
+	table.Columns["two_steps_verif"] = Column{Type: "tinyint", Nullable: true}
+	return table
+}
+
+// detailsTable is an asset table with generated columns indexing the details used by the filters.
+func detailsTable(keys ...string) Table {
+	table := assetTable()
+	for _, key := range keys {
+		table.Columns[key] = Column{Type: "varchar(255)", Nullable: true}
+		table.Indexes = append(table.Indexes, Index{Columns: []string{key}})
+	}
+	return table
+}
+
+// ExpectedSchema is the schema produced by the migrations in db/migrations/mysql.
+// It has to be updated together with the migrations.
+var ExpectedSchema = Schema{
+	UserSettings:   userSettingsTable(),
+	UserAssets:     assetTable(),
+	ProductDetails: detailsTable(detailsColumns[ProductDetails]...),
+	ProductAssets:  assetTable(),
+	ProjectDetails: detailsTable(detailsColumns[ProjectDetails]...),
+	ProjectAssets:  assetTable(),
+	"users": {
+		Columns: map[string]Column{
+			"id":               idColumn,
+			"name":             {Type: "varchar(50)", Nullable: false},
+			"email":            {Type: "varchar(300)", Nullable: false},
+			"password":         {Type: "varchar(1024)", Nullable: true},
+			"user_settings_id": uuidColumn,
+			"user_assets_id":   uuidColumn,
+			"created_at":       timestampColumn,
+			"updated_at":       timestampColumn,
+			"deleted_at":       deletedColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"name"}, Unique: true},
+			{Columns: []string{"email"}, Unique: true},
+			{Columns: []string{"user_settings_id"}},
+			{Columns: []string{"user_assets_id"}},
+			{Columns: []string{"deleted_at"}},
+		},
+	},
+	"features": {
+		Columns: map[string]Column{
+			"id":         {Type: "bigint", Nullable: false},
+			"name":       {Type: "varchar(128)", Nullable: false},
+			"config":     {Type: "json", Nullable: true},
+			"created_at": timestampColumn,
+			"updated_at": timestampColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"name"}, Unique: true},
+		},
+	},
+	"products": {
+		Columns: map[string]Column{
+			"id":                 idColumn,
+			"name":               {Type: "varchar(255)", Nullable: false},
+			"product_details_id": uuidColumn,
+			"product_assets_id":  uuidColumn,
+			"created_at":         timestampColumn,
+			"updated_at":         timestampColumn,
+			"deleted_at":         deletedColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"name"}, Unique: true},
+			{Columns: []string{"product_details_id"}},
+			{Columns: []string{"product_assets_id"}},
+			{Columns: []string{"deleted_at"}},
+		},
+	},
+	"privileges": {
+		Columns: map[string]Column{
+			"id":          {Type: "tinyint", Nullable: false},
+			"name":        {Type: "varchar(255)", Nullable: false},
+			"description": {Type: "varchar(512)", Nullable: false},
+			"created_at":  timestampColumn,
+			"updated_at":  timestampColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"name"}, Unique: true},
+		},
+	},
+	"users_products": {
+		Columns: map[string]Column{
+			"products_id":   uuidColumn,
+			"users_id":      uuidColumn,
+			"privileges_id": privilegeColumn,
+			"created_at":    timestampColumn,
+			"updated_at":    timestampColumn,
+		},
+		Indexes: []Index{
+			{Columns: []string{"products_id"}},
+			{Columns: []string{"users_id"}},
+		},
+	},
+	"projects": {
+		Columns: map[string]Column{
+			"id":                 idColumn,
+			"products_id":        idColumn,
+			"project_details_id": uuidColumn,
+			"project_assets_id":  uuidColumn,
+			"created_at":         timestampColumn,
+			"updated_at":         timestampColumn,
+			"deleted_at":         deletedColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"products_id"}},
+			{Columns: []string{"project_details_id"}},
+			{Columns: []string{"project_assets_id"}},
+			{Columns: []string{"deleted_at"}},
+		},
+	},
+	"users_projects": {
+		Columns: map[string]Column{
+			"projects_id":   uuidColumn,
+			"users_id":      uuidColumn,
+			"privileges_id": privilegeColumn,
+			"created_at":    timestampColumn,
+			"updated_at":    timestampColumn,
+		},
+		Indexes: []Index{
+			{Columns: []string{"projects_id"}},
+			{Columns: []string{"users_id"}},
+		},
+	},
+	"users_viewers": {
+		Columns: map[string]Column{
+			"users_id":    uuidColumn,
+			"viewer_id":   uuidColumn,
+			"projects_id": uuidColumn,
+			"created_at":  timestampColumn,
+			"updated_at":  timestampColumn,
+		},
+		Indexes: []Index{
+			{Columns: []string{"users_id"}},
+			{Columns: []string{"viewer_id"}},
+			{Columns: []string{"projects_id"}},
+		},
+	},
+	AssetHistory: {
+		Columns: map[string]Column{
+			"asset_type": {Type: "varchar(32)", Nullable: false},
+			"asset_id":   idColumn,
+			"version":    {Type: "bigint", Nullable: false},
+			"data":       dataColumn,
+			"changed_by": uuidColumn,
+			"changed_at": timestampColumn,
+		},
+		Indexes: []Index{
+			{Columns: []string{"asset_type", "asset_id", "version"}, Unique: true},
+		},
+	},
+	AuditEvents: {
+		Columns: map[string]Column{
+			"id":          {Type: "bigint", Nullable: false},
+			"occurred_at": timestampColumn,
+			"actor":       uuidColumn,
+			"action":      {Type: "varchar(32)", Nullable: false},
+			"entity_type": {Type: "varchar(64)", Nullable: false},
+			"entity_id":   idColumn,
+			"before_data": {Type: "json", Nullable: true},
+			"after_data":  {Type: "json", Nullable: true},
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"entity_type", "entity_id"}},
+			{Columns: []string{"actor"}},
+			{Columns: []string{"occurred_at"}},
+		},
+	},
+	OutboxEvents: {
+		Columns: map[string]Column{
+			"id":           {Type: "bigint", Nullable: false},
+			"event_type":   {Type: "varchar(64)", Nullable: false},
+			"occurred_at":  timestampColumn,
+			"actor":        uuidColumn,
+			"aggregate_id": idColumn,
+			"payload":      dataColumn,
+			"attempts":     {Type: "int", Nullable: false},
+			"last_error":   {Type: "varchar(1024)", Nullable: true},
+		},
+		Indexes: []Index{
+			primaryKey,
+		},
+	},
+	WebhookSubscriptions: {
+		Columns: map[string]Column{
+			"id":          idColumn,
+			"url":         {Type: "varchar(2048)", Nullable: false},
+			"secret":      {Type: "varchar(255)", Nullable: false},
+			"event_types": dataColumn,
+			"created_at":  timestampColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+		},
+	},
+	WebhookDeliveries: {
+		Columns: map[string]Column{
+			"id":               {Type: "bigint", Nullable: false},
+			"subscription_id":  idColumn,
+			"event_id":         {Type: "bigint", Nullable: false},
+			"event_type":       {Type: "varchar(64)", Nullable: false},
+			"payload":          dataColumn,
+			"status":           {Type: "varchar(16)", Nullable: false},
+			"attempts":         {Type: "int", Nullable: false},
+			"next_attempt_at":  timestampColumn,
+			"last_attempt_at":  {Type: "datetime", Nullable: true},
+			"last_status_code": {Type: "int", Nullable: false},
+			"last_error":       {Type: "varchar(1024)", Nullable: true},
+			"created_at":       timestampColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"subscription_id", "event_id"}, Unique: true},
+			{Columns: []string{"status", "next_attempt_at"}},
+		},
+	},
+	"viewers": {
+		Columns: map[string]Column{
+			"id":         idColumn,
+			"owner_id":   uuidColumn,
+			"created_at": timestampColumn,
+			"updated_at": timestampColumn,
+		},
+		Indexes: []Index{
+			primaryKey,
+			{Columns: []string{"owner_id"}},
+		},
+	},
+}
+
+// Tables of the database that are not managed by the migrations.
+var ignoredTables = map[string]bool{
+	"gorp_migrations": true,
+}
+
+// Queries lists the query constants of the package by name, so they can be checked against the schema.
+// The table of the asset queries is substituted with every asset table, the tables of the other queries are listed in queryTables.
+var Queries = map[string]string{
+	"AddAssetQuery":                       AddAssetQuery,
+	"UpdateAssetQuery":                    UpdateAssetQuery,
+	"UpdateAssetVersionQuery":             UpdateAssetVersionQuery,
+	"GetAssetVersionQuery":                GetAssetVersionQuery,
+	"GetAssetQuery":                       GetAssetQuery,
+	"DeleteAssetQuery":                    DeleteAssetQuery,
+	"GetAssetsQuery":                      GetAssetsQuery,
+	"MergePatchAssetQuery":                MergePatchAssetQuery,
+	"MergePatchAssetVersionQuery":         MergePatchAssetVersionQuery,
+	"GetAssetForUpdateQuery":              GetAssetForUpdateQuery,
+	"GetMigrationLockQuery":               GetMigrationLockQuery,
+	"ReleaseMigrationLockQuery":           ReleaseMigrationLockQuery,
+	"GetPrivilegesQuery":                  GetPrivilegesQuery,
+	"GetPrivilegeQuery":                   GetPrivilegeQuery,
+	"AddProductUsersQuery":                AddProductUsersQuery,
+	"DeleteProductUsersByProductIDQuery":  DeleteProductUsersByProductIDQuery,
+	"UpdateUsersProductsQuery":            UpdateUsersProductsQuery,
+	"AddProductQuery":                     AddProductQuery,
+	"GetProductByIDQuery":                 GetProductByIDQuery,
+	"GetProductsByIDsQuery":               GetProductsByIDsQuery,
+	"GetUserProductIDsQuery":              GetUserProductIDsQuery,
+	"GetProductByNameQuery":               GetProductByNameQuery,
+	"DeleteProductQuery":                  DeleteProductQuery,
+	"AddProjectUsersQuery":                AddProjectUsersQuery,
+	"DeleteProjectUsersByProjectIDQuery":  DeleteProjectUsersByProjectIDQuery,
+	"DeleteProjectUserQuery":              DeleteProjectUserQuery,
+	"UpdateUsersProjectsQuery":            UpdateUsersProjectsQuery,
+	"AddProjectQuery":                     AddProjectQuery,
+	"GetProjectByIDQuery":                 GetProjectByIDQuery,
+	"GetProjectsByIDsQuery":               GetProjectsByIDsQuery,
+	"GetUserProjectIDsQuery":              GetUserProjectIDsQuery,
+	"GetProductProjectsQuery":             GetProductProjectsQuery,
+	"DeleteProjectQuery":                  DeleteProjectQuery,
+	"DeleteProjectsByProductIDQuery":      DeleteProjectsByProductIDQuery,
+	"AddViewerQuery":                      AddViewerQuery,
+	"AddProjectViewerQuery":               AddProjectViewerQuery,
+	"GetViewerIDsByOwnerIDQuery":          GetViewerIDsByOwnerIDQuery,
+	"DeleteViewerByOwnerQuery":            DeleteViewerByOwnerQuery,
+	"DeleteProjectViewerByUserIDQuery":    DeleteProjectViewerByUserIDQuery,
+	"GetProjectViewerByUserIDQuery":       GetProjectViewerByUserIDQuery,
+	"GetProjectViewerByViewerIDQuery":     GetProjectViewerByViewerIDQuery,
+	"GetProjectViewerByProjectIDQuery":    GetProjectViewerByProjectIDQuery,
+	"DeleteProjectViewerByViewerIDQuery":  DeleteProjectViewerByViewerIDQuery,
+	"DeleteProjectViewerByProjectIDQuery": DeleteProjectViewerByProjectIDQuery,
+	"GetUserByEmailQuery":                 GetUserByEmailQuery,
+	"GetUserByIDQuery":                    GetUserByIDQuery,
+	"GetUsersByIDsQuery":                  GetUsersByIDsQuery,
+	"InsertUserQuery":                     InsertUserQuery,
+	"DeleteUserQuery":                     DeleteUserQuery,
+	"GetProductUserIDsQuery":              GetProductUserIDsQuery,
+	"DeleteProductUserQuery":              DeleteProductUserQuery,
+	"SchemaColumnsQuery":                  SchemaColumnsQuery,
+	"SchemaIndexesQuery":                  SchemaIndexesQuery,
+	"AddAssetHistoryQuery":                AddAssetHistoryQuery,
+	"GetAssetHistoryQuery":                GetAssetHistoryQuery,
+	"GetAssetRevisionQuery":               GetAssetRevisionQuery,
+	"DeleteAssetHistoryQuery":             DeleteAssetHistoryQuery,
+	"TrashEntityQuery":                    TrashEntityQuery,
+	"RestoreEntityQuery":                  RestoreEntityQuery,
+	"TrashProjectsByProductIDQuery":       TrashProjectsByProductIDQuery,
+	"RestoreProjectsByProductIDQuery":     RestoreProjectsByProductIDQuery,
+	"GetTrashQuery":                       GetTrashQuery,
+	"GetTrashEntryQuery":                  GetTrashEntryQuery,
+	"AddAuditEventQuery":                  AddAuditEventQuery,
+	"GetAuditEventsQuery":                 GetAuditEventsQuery,
+	"GetAuditEventsAfterQuery":            GetAuditEventsAfterQuery,
+	"AddOutboxEventQuery":                 AddOutboxEventQuery,
+	"GetOutboxEventsQuery":                GetOutboxEventsQuery,
+	"DeleteOutboxEventQuery":              DeleteOutboxEventQuery,
+	"RecordOutboxFailureQuery":            RecordOutboxFailureQuery,
+	"AddWebhookSubscriptionQuery":         AddWebhookSubscriptionQuery,
+	"UpdateWebhookSubscriptionQuery":      UpdateWebhookSubscriptionQuery,
+	"DeleteWebhookSubscriptionQuery":      DeleteWebhookSubscriptionQuery,
+	"GetWebhookSubscriptionQuery":         GetWebhookSubscriptionQuery,
+	"GetWebhookSubscriptionsQuery":        GetWebhookSubscriptionsQuery,
+	"AddWebhookDeliveryQuery":             AddWebhookDeliveryQuery,
+	"GetWebhookDeliveriesQuery":           GetWebhookDeliveriesQuery,
+	"GetDueWebhookDeliveriesQuery":        GetDueWebhookDeliveriesQuery,
+	"UpdateWebhookDeliveryQuery":          UpdateWebhookDeliveryQuery,
+	"DeleteWebhookDeliveriesQuery":        DeleteWebhookDeliveriesQuery,
+}
+
+// queryTables lists the tables substituted into the queries of Queries that are not executed on the asset tables.
+var queryTables = map[string][]string{
+	"TrashEntityQuery":   trashTables,
+	"RestoreEntityQuery": trashTables,
+	"GetTrashQuery":      trashTables,
+	"GetTrashEntryQuery": trashTables,
+}
+
+var assetTables = []string{UserSettings, UserAssets, ProductDetails, ProductAssets, ProjectDetails, ProjectAssets}
+
+var ErrSchemaDrift = errors.New("DB schema does not match the schema expected by the code")
+
+// SchemaDrift is a single difference between the DB and the code.
+// Query is set if a query constant references a table or column missing from the DB.
+type SchemaDrift struct {
+	Table   string
+	Column  string
+	Query   string
+	Message string
+}
+
+func (d SchemaDrift) String() string {
+	switch {
+	case d.Query != "" && d.Column != "":
+		return fmt.Sprintf("%s: %s.%s %s", d.Query, d.Table, d.Column, d.Message)
+	case d.Query != "":
+		return fmt.Sprintf("%s: %s %s", d.Query, d.Table, d.Message)
+	case d.Column != "":
+		return fmt.Sprintf("%s.%s %s", d.Table, d.Column, d.Message)
+	default:
+		return fmt.Sprintf("%s %s", d.Table, d.Message)
+	}
+}
+
+// SchemaChecker is implemented by the connectors that can compare the live DB with ExpectedSchema.
+type SchemaChecker interface {
+	CheckSchema(ctx context.Context) ([]SchemaDrift, error)
+}
+
+var SchemaColumnsQuery = "SELECT TABLE_NAME, COLUMN_NAME, DATA_TYPE, CHARACTER_MAXIMUM_LENGTH, IS_NULLABLE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE()"
+var SchemaIndexesQuery = "SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, COLUMN_NAME FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX"
+
+// LoadSchema reads the tables, columns and indexes of the current database from information_schema.
+func LoadSchema(ctx context.Context, db *sql.DB) (Schema, error) {
+	schema := make(Schema)
+	table := func(name string) Table {
+		t, ok := schema[name]
+		if !ok {
+			t = Table{Columns: make(map[string]Column)}
+		}
+		return t
+	}
+
+	rows, err := db.QueryContext(ctx, SchemaColumnsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, columnName, dataType, nullable string
+		var length sql.NullInt64
+		if err := rows.Scan(&tableName, &columnName, &dataType, &length, &nullable); err != nil {
+			return nil, err
+		}
+
+		columnType := strings.ToLower(dataType)
+		if length.Valid {
+			columnType = fmt.Sprintf("%s(%d)", columnType, length.Int64)
+		}
+
+		t := table(tableName)
+		t.Columns[columnName] = Column{Type: columnType, Nullable: nullable == "YES"}
+		schema[tableName] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexRows, err := db.QueryContext(ctx, SchemaIndexesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+
+	lastTable, lastIndex := "", ""
+	for indexRows.Next() {
+		var tableName, indexName, columnName string
+		var nonUnique int
+		if err := indexRows.Scan(&tableName, &indexName, &nonUnique, &columnName); err != nil {
+			return nil, err
+		}
+
+		t := table(tableName)
+		if tableName == lastTable && indexName == lastIndex {
+			last := &t.Indexes[len(t.Indexes)-1]
+			last.Columns = append(last.Columns, columnName)
+		} else {
+			t.Indexes = append(t.Indexes, Index{Columns: []string{columnName}, Unique: nonUnique == 0})
+		}
+		schema[tableName] = t
+		lastTable, lastIndex = tableName, indexName
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// CompareSchema returns the tables, columns and indexes of actual that differ from expected.
+// Indexes are compared by their columns and uniqueness, so duplicates and generated names are not reported.
+func CompareSchema(expected Schema, actual Schema) []SchemaDrift {
+	drifts := make([]SchemaDrift, 0)
+	for _, tableName := range sortedTables(expected) {
+		expectedTable := expected[tableName]
+		actualTable, ok := actual[tableName]
+		if !ok {
+			drifts = append(drifts, SchemaDrift{Table: tableName, Message: "table is missing"})
+			continue
+		}
+
+		for _, columnName := range sortedColumns(expectedTable) {
+			expectedColumn := expectedTable.Columns[columnName]
+			actualColumn, ok := actualTable.Columns[columnName]
+			switch {
+			case !ok:
+				drifts = append(drifts, SchemaDrift{Table: tableName, Column: columnName, Message: "column is missing"})
+			case actualColumn.Type != expectedColumn.Type:
+				drifts = append(drifts, SchemaDrift{
+					Table:   tableName,
+					Column:  columnName,
+					Message: fmt.Sprintf("has type %s instead of %s", actualColumn.Type, expectedColumn.Type),
+				})
+			case actualColumn.Nullable != expectedColumn.Nullable:
+				drifts = append(drifts, SchemaDrift{
+					Table:   tableName,
+					Column:  columnName,
+					Message: fmt.Sprintf("nullable is %t instead of %t", actualColumn.Nullable, expectedColumn.Nullable),
+				})
+			}
+		}
+
+		for _, columnName := range sortedColumns(actualTable) {
+			if _, ok := expectedTable.Columns[columnName]; !ok {
+				drifts = append(drifts, SchemaDrift{Table: tableName, Column: columnName, Message: "column is not expected"})
+			}
+		}
+
+		expectedIndexes := indexSet(expectedTable.Indexes)
+		actualIndexes := indexSet(actualTable.Indexes)
+		for _, index := range sortedKeys(expectedIndexes) {
+			if !actualIndexes[index] {
+				drifts = append(drifts, SchemaDrift{Table: tableName, Message: index + " is missing"})
+			}
+		}
+		for _, index := range sortedKeys(actualIndexes) {
+			if !expectedIndexes[index] {
+				drifts = append(drifts, SchemaDrift{Table: tableName, Message: index + " is not expected"})
+			}
+		}
+	}
+
+	for _, tableName := range sortedTables(actual) {
+		if _, ok := expected[tableName]; !ok && !ignoredTables[tableName] {
+			drifts = append(drifts, SchemaDrift{Table: tableName, Message: "table is not expected"})
+		}
+	}
+	return drifts
+}
+
+var (
+	stringLiteralPattern = regexp.MustCompile(`'[^']*'`)
+	tableNamePattern     = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|JOIN)\s+([A-Za-z_][A-Za-z0-9_.]*)`)
+	identifierPattern    = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*`)
+)
+
+// Words of the queries that are not column names.
+var sqlKeywords = map[string]bool{
+	"select": true, "from": true, "where": true, "insert": true, "into": true, "values": true,
+	"update": true, "set": true, "delete": true, "and": true, "or": true, "in": true, "is": true,
+	"not": true, "null": true, "as": true, "on": true, "join": true, "order": true, "by": true,
+	"limit": true, "asc": true, "desc": true, "bin_to_uuid": true, "uuid_to_bin": true,
+	"get_lock": true, "release_lock": true, "concat": true, "database": true, "for": true,
+	"json_merge_patch": true, "json_extract": true, "cast": true, "json": true, "left": true,
+}
+
+// CheckQueries returns the query constants of Queries, the backup queries and the details filter queries referencing tables or columns missing from schema.
+// The column names of a query are resolved against all the tables it references.
+// Queries of tables outside of the database, like information_schema, are not checked.
+func CheckQueries(schema Schema) []SchemaDrift {
+	names := make([]string, 0, len(Queries))
+	for name := range Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	drifts := make([]SchemaDrift, 0)
+	for _, name := range names {
+		query := Queries[name]
+		if !strings.Contains(query, "%s") {
+			drifts = append(drifts, checkQuery(schema, name, query)...)
+			continue
+		}
+
+		tables, ok := queryTables[name]
+		if !ok {
+			tables = assetTables
+		}
+		for _, table := range tables {
+			drifts = append(drifts, checkQuery(schema, name, fmt.Sprintf(query, table))...)
+		}
+	}
+
+	// The backup queries are generated from the table descriptions.
+	for i := range BackupTables {
+		drifts = append(drifts, checkQuery(schema, "DumpTable", dumpTableQuery(&BackupTables[i]))...)
+		drifts = append(drifts, checkQuery(schema, "RestoreRow", restoreRowQuery(&BackupTables[i]))...)
+	}
+
+	// The details filters are generated from the filter, the indexed keys cover the generated columns.
+	drifts = append(drifts, checkQuery(schema, "GetProductsByDetails", productsByDetailsQuery(indexedDetailsPredicate(ProductDetails)))...)
+	drifts = append(drifts, checkQuery(schema, "GetProjectsByDetails", projectsByDetailsQuery(indexedDetailsPredicate(ProjectDetails)))...)
+	return drifts
+}
+
+func checkQuery(schema Schema, name string, query string) []SchemaDrift {
+	query = stringLiteralPattern.ReplaceAllString(query, "")
+
+	tables := make([]string, 0)
+	for _, match := range tableNamePattern.FindAllStringSubmatch(query, -1) {
+		if strings.Contains(match[1], ".") {
+			return nil
+		}
+		tables = append(tables, match[1])
+	}
+
+	drifts := make([]SchemaDrift, 0)
+	known := make(map[string]bool)
+	for _, tableName := range tables {
+		known[tableName] = true
+		if _, ok := schema[tableName]; !ok {
+			drifts = append(drifts, SchemaDrift{Table: tableName, Query: name, Message: "table is missing"})
+		}
+	}
+	if len(drifts) > 0 {
+		return drifts
+	}
+
+	for _, identifier := range identifierPattern.FindAllString(query, -1) {
+		if sqlKeywords[strings.ToLower(identifier)] || known[identifier] {
+			continue
+		}
+
+		found := false
+		for _, tableName := range tables {
+			if _, ok := schema[tableName].Columns[identifier]; ok {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			drifts = append(drifts, SchemaDrift{Table: strings.Join(tables, ", "), Column: identifier, Query: name, Message: "column is missing"})
+		}
+	}
+	return drifts
+}
+
+// CheckSchema compares the live DB with ExpectedSchema and checks the query constants against it.
+func (c *MYSQLConnector) CheckSchema(ctx context.Context) ([]SchemaDrift, error) {
+	if c.db == nil {
+		return nil, ErrConnectorNotOpen
+	}
+
+	schema, err := LoadSchema(ctx, c.db)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the DB schema")
+	}
+	return append(CompareSchema(ExpectedSchema, schema), CheckQueries(schema)...), nil
+}
+
+func sortedTables(schema Schema) []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedColumns(table Table) []string {
+	names := make([]string, 0, len(table.Columns))
+	for name := range table.Columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indexSet(indexes []Index) map[string]bool {
+	set := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		set[index.String()] = true
+	}
+	return set
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package mysqldb
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+)
+
+// copySchema returns a deep copy of schema that can be modified by the test.
+func copySchema(schema Schema) Schema {
+	output := make(Schema, len(schema))
+	for name, table := range schema {
+		columns := make(map[string]Column, len(table.Columns))
+		for columnName, column := range table.Columns {
+			columns[columnName] = column
+		}
+		output[name] = Table{Columns: columns, Indexes: append([]Index{}, table.Indexes...)}
+	}
+	return output
+}
+
+func TestCompareSchema(t *testing.T) {
+	tests.CheckResult(CompareSchema(ExpectedSchema, ExpectedSchema), []SchemaDrift{}, nil, nil, "no_drift", t)
+
+	actual := copySchema(ExpectedSchema)
+	delete(actual, "viewers")
+	delete(actual["products"].Columns, "product_assets_id")
+	actual["users"].Columns["password"] = Column{Type: "binary(60)", Nullable: false}
+	actual["users_viewers"].Columns["is_owner"] = Column{Type: "tinyint", Nullable: true}
+	privileges := actual["privileges"]
+	privileges.Indexes = []Index{primaryKey}
+	actual["privileges"] = privileges
+	actual["gorp_migrations"] = Table{Columns: map[string]Column{"id": {Type: "varchar(255)"}}}
+	actual["sessions"] = Table{Columns: map[string]Column{"id": idColumn}}
+
+	expected := []string{
+		"privileges unique index(name) is missing",
+		"products.product_assets_id column is missing",
+		"users.password has type binary(60) instead of varchar(1024)",
+		"users_viewers.is_owner column is not expected",
+		"viewers table is missing",
+		"sessions table is not expected",
+	}
+
+	output := make([]string, 0)
+	for _, drift := range CompareSchema(ExpectedSchema, actual) {
+		output = append(output, drift.String())
+	}
+	tests.CheckResult(output, expected, nil, nil, "drift", t)
+}
+
+func TestCheckQueries(t *testing.T) {
+	expected := []string{}
+	output := make([]string, 0)
+	for _, drift := range CheckQueries(ExpectedSchema) {
+		output = append(output, drift.String())
+	}
+	tests.CheckResult(output, expected, nil, nil, "expected_schema", t)
+
+	actual := copySchema(ExpectedSchema)
+	delete(actual, "privileges")
+	output = make([]string, 0)
+	for _, drift := range CheckQueries(actual) {
+		if strings.Contains(drift.Query, "Privilege") {
+			output = append(output, drift.String())
+		}
+	}
+	expected = []string{
+		"GetPrivilegeQuery: privileges table is missing",
+		"GetPrivilegesQuery: privileges table is missing",
+	}
+	tests.CheckResult(output, expected, nil, nil, "missing_table", t)
+
+	// The details filters use the generated columns of the indexed keys.
+	delete(actual["product_details"].Columns, "is_free")
+	output = make([]string, 0)
+	for _, drift := range CheckQueries(actual) {
+		if strings.Contains(drift.Query, "ByDetails") {
+			output = append(output, drift.String())
+		}
+	}
+	expected = []string{"GetProductsByDetails: products, product_details.is_free column is missing"}
+	tests.CheckResult(output, expected, nil, nil, "missing_generated_column", t)
+}
+
+// Every query constant of the package has to be listed in Queries to be checked.
+func TestQueriesRegistered(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing := make([]string, 0)
+	fileSet := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(fileSet, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, decl := range parsed.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || (genDecl.Tok != token.VAR && genDecl.Tok != token.CONST) {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if _, ok := Queries[name.Name]; strings.HasSuffix(name.Name, "Query") && !ok {
+						missing = append(missing, name.Name)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(missing)
+	tests.CheckResult(missing, []string{}, nil, nil, "queries_registered", t)
+}
+
+func TestLoadSchema(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	columns := sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "DATA_TYPE", "CHARACTER_MAXIMUM_LENGTH", "IS_NULLABLE"}).
+		AddRow("viewers", "id", "binary", 16, "NO").
+		AddRow("viewers", "owner_id", "binary", 16, "YES").
+		AddRow("viewers", "created_at", "datetime", nil, "NO")
+	indexes := sqlmock.NewRows([]string{"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME"}).
+		AddRow("viewers", "PRIMARY", 0, "id").
+		AddRow("viewers", "owner", 1, "owner_id").
+		AddRow("viewers", "owner", 1, "created_at")
+	mock.ExpectQuery(SchemaColumnsQuery).WillReturnRows(columns)
+	mock.ExpectQuery(SchemaIndexesQuery).WillReturnRows(indexes)
+
+	expected := Schema{
+		"viewers": {
+			Columns: map[string]Column{
+				"id":         idColumn,
+				"owner_id":   uuidColumn,
+				"created_at": timestampColumn,
+			},
+			Indexes: []Index{
+				primaryKey,
+				{Columns: []string{"owner_id", "created_at"}},
+			},
+		},
+	}
+
+	schema, err := LoadSchema(context.Background(), db)
+	tests.CheckResult(schema, expected, err, nil, "load_schema", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
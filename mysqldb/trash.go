@@ -0,0 +1,134 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Tables of the entities that can be soft deleted.
+const (
+	Users    = "users"
+	Products = "products"
+	Projects = "projects"
+)
+
+var trashTables = []string{Users, Products, Projects}
+
+var ErrEntityMissing = errors.New("Entity is missing or its delete state is already the requested one")
+
+// IsTrashType reports whether entityType is the name of a table that can be soft deleted.
+func IsTrashType(entityType string) bool {
+	for _, trashTable := range trashTables {
+		if trashTable == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+var TrashEntityQuery = "UPDATE %s SET deleted_at = ? WHERE id = UUID_TO_BIN(?) AND deleted_at IS NULL"
+
+// TrashEntity marks the entity deleted at deletedAt, so the Get queries do not return it any more.
+// ErrEntityMissing is returned if the entity does not exist or has already been deleted.
+func (*MYSQLFunctions) TrashEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(TrashEntityQuery, entityType), deletedAt, ID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errors.WithMessage(ErrEntityMissing, entityType)
+	}
+	return nil
+}
+
+var RestoreEntityQuery = "UPDATE %s SET deleted_at = NULL WHERE id = UUID_TO_BIN(?) AND deleted_at = ?"
+
+// RestoreEntity clears the delete mark of the entity if it has been deleted at deletedAt.
+// ErrEntityMissing is returned if the entity is not in the trash or has been deleted at another time.
+func (*MYSQLFunctions) RestoreEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(RestoreEntityQuery, entityType), ID, deletedAt)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errors.WithMessage(ErrEntityMissing, entityType)
+	}
+	return nil
+}
+
+var TrashProjectsByProductIDQuery = "UPDATE projects SET deleted_at = ? WHERE products_id = UUID_TO_BIN(?) AND deleted_at IS NULL"
+
+// TrashProjectsByProductID marks the projects of the product deleted at deletedAt.
+func (*MYSQLFunctions) TrashProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, TrashProjectsByProductIDQuery, deletedAt, productID)
+	return TranslateError(err)
+}
+
+var RestoreProjectsByProductIDQuery = "UPDATE projects SET deleted_at = NULL WHERE products_id = UUID_TO_BIN(?) AND deleted_at = ?"
+
+// RestoreProjectsByProductID restores the projects of the product deleted at deletedAt, together with the product.
+func (*MYSQLFunctions) RestoreProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, RestoreProjectsByProductIDQuery, productID, deletedAt)
+	return TranslateError(err)
+}
+
+var GetTrashQuery = "SELECT BIN_TO_UUID(id), deleted_at FROM %s WHERE deleted_at <= ? ORDER BY deleted_at"
+
+// GetTrash lists the entities of entityType deleted at or before deletedBefore, the oldest first.
+func (*MYSQLFunctions) GetTrash(ctx context.Context, entityType string, deletedBefore time.Time, tx *sql.Tx) ([]models.TrashEntry, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(GetTrashQuery, entityType), deletedBefore)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.TrashEntry, 0)
+	for rows.Next() {
+		entry := models.TrashEntry{Type: entityType}
+		if err := rows.Scan(&entry.ID, &entry.DeletedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(entries) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return entries, nil
+}
+
+var GetTrashEntryQuery = "SELECT BIN_TO_UUID(id), deleted_at FROM %s WHERE id = UUID_TO_BIN(?) AND deleted_at IS NOT NULL"
+
+// GetTrashEntry returns the entity if it is in the trash or sql.ErrNoRows.
+func (*MYSQLFunctions) GetTrashEntry(ctx context.Context, entityType string, ID *uuid.UUID, tx *sql.Tx) (*models.TrashEntry, error) {
+	entry := &models.TrashEntry{Type: entityType}
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(GetTrashEntryQuery, entityType), ID).Scan(&entry.ID, &entry.DeletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, TranslateError(err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,76 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func TestTrash(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	productID := uuid.New()
+	deletedAt := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(fmt.Sprintf(TrashEntityQuery, Products)).WithArgs(deletedAt, &productID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(TrashProjectsByProductIDQuery).WithArgs(deletedAt, &productID).WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec(fmt.Sprintf(TrashEntityQuery, Products)).WithArgs(deletedAt, &productID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(fmt.Sprintf(GetTrashQuery, Products)).WithArgs(deletedAt).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(productID.String(), deletedAt))
+	mock.ExpectQuery(fmt.Sprintf(GetTrashEntryQuery, Products)).WithArgs(&productID).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(productID.String(), deletedAt))
+	mock.ExpectExec(fmt.Sprintf(RestoreEntityQuery, Products)).WithArgs(&productID, deletedAt).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(RestoreProjectsByProductIDQuery).WithArgs(&productID, deletedAt).WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectQuery(fmt.Sprintf(GetTrashEntryQuery, Products)).WithArgs(&productID).WillReturnError(sql.ErrNoRows)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	functions := &MYSQLFunctions{}
+	err = functions.TrashEntity(ctx, Products, &productID, deletedAt, tx)
+	tests.CheckResult(nil, nil, err, nil, "trash_product", t)
+
+	err = functions.TrashProjectsByProductID(ctx, &productID, deletedAt, tx)
+	tests.CheckResult(nil, nil, err, nil, "trash_projects", t)
+
+	err = functions.TrashEntity(ctx, Products, &productID, deletedAt, tx)
+	tests.CheckResult(errors.Is(err, ErrEntityMissing), true, nil, nil, "trash_product_twice", t)
+
+	entries, err := functions.GetTrash(ctx, Products, deletedAt, tx)
+	expected := []models.TrashEntry{{Type: Products, ID: productID, DeletedAt: deletedAt}}
+	tests.CheckResult(entries, expected, err, nil, "trash", t)
+
+	entry, err := functions.GetTrashEntry(ctx, Products, &productID, tx)
+	tests.CheckResult(entry, &expected[0], err, nil, "trash_entry", t)
+
+	err = functions.RestoreEntity(ctx, Products, &productID, deletedAt, tx)
+	tests.CheckResult(nil, nil, err, nil, "restore_product", t)
+
+	err = functions.RestoreProjectsByProductID(ctx, &productID, deletedAt, tx)
+	tests.CheckResult(nil, nil, err, nil, "restore_projects", t)
+
+	_, err = functions.GetTrashEntry(ctx, Products, &productID, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "restored_trash_entry", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -1,8 +1,8 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"strings"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
@@ -20,17 +20,16 @@ const (
 
 var ErrNoUserWithEmail = errors.New("There is no user associated with this email")
 
-var ErrSQLDuplicateUserNameEntryString = "Error 1062: Duplicate entry '%s' for key 'users.name'"
-var ErrSQLDuplicateEmailEntryString = "Error 1062: Duplicate entry '%s' for key 'users.email'"
+// ErrDuplicateUserNameEntry is the error of a duplicate user name, the controllers return it as ErrDuplicateNameEntry.
 var ErrDuplicateUserNameEntry = errors.New("User with this name already exists")
 var ErrNoUserDeleted = errors.New("No user was deleted")
 
-var GetUserByEmailQuery = "select BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id) from users where email = ?"
-var GetUserByIDQuery = "select BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id) from users where id = UUID_TO_BIN(?)"
+var GetUserByEmailQuery = "select BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id) from users where email = ? AND deleted_at IS NULL"
+var GetUserByIDQuery = "select BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id) from users where id = UUID_TO_BIN(?) AND deleted_at IS NULL"
 
 // GetUser returns the user defined by the key name and key value.
 // Key name can be either id or email.
-func (*MYSQLFunctions) GetUser(queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error) {
+func (*MYSQLFunctions) GetUser(ctx context.Context, queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error) {
 	queryString := GetUserByIDQuery
 	if queryType == ByEmail {
 		queryString = GetUserByEmailQuery
@@ -38,31 +37,31 @@ func (*MYSQLFunctions) GetUser(queryType int, keyValue interface{}, tx *sql.Tx)
 	}
 
 	var user models.User
-	query := tx.QueryRow(queryString, keyValue)
+	query := tx.QueryRowContext(ctx, queryString, keyValue)
 	password := ""
 	err := query.Scan(&user.ID, &user.Name, &user.Email, &password, &user.SettingsID, &user.AssetsID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, err
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 	user.Password = []byte(password)
 	return &user, nil
 }
 
-var GetUsersByIDsQuery = "select BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id) from users where id IN (UUID_TO_BIN(?)"
+var GetUsersByIDsQuery = "select BIN_TO_UUID(id), name, email, password, BIN_TO_UUID(user_settings_id), BIN_TO_UUID(user_assets_id) from users where deleted_at IS NULL AND id IN (UUID_TO_BIN(?)"
 
-func (MYSQLFunctions) GetUsersByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error) {
+func (MYSQLFunctions) GetUsersByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error) {
 	query := GetUsersByIDsQuery + strings.Repeat(",UUID_TO_BIN(?)", len(IDs)-1) + ")"
 	interfaceList := make([]interface{}, len(IDs))
 	for i := range IDs {
 		interfaceList[i] = IDs[i]
 	}
-	rows, err := tx.Query(query, interfaceList...)
+	rows, err := tx.QueryContext(ctx, query, interfaceList...)
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	defer rows.Close()
@@ -73,14 +72,14 @@ func (MYSQLFunctions) GetUsersByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.User,
 		password := ""
 		err := rows.Scan(&user.ID, &user.Name, &user.Email, &password, &user.SettingsID, &user.AssetsID)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		user.Password = []byte(password)
 		users = append(users, user)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 
 	if len(users) == 0 {
@@ -90,15 +89,15 @@ func (MYSQLFunctions) GetUsersByIDs(IDs []uuid.UUID, tx *sql.Tx) ([]models.User,
 	return users, nil
 }
 
-func (f *MYSQLFunctions) UserExists(username string) (bool, error) {
+func (f *MYSQLFunctions) UserExists(ctx context.Context, username string) (bool, error) {
 	var user models.User
-	tx, err := f.DBConnector.ConnectSystem()
+	tx, err := f.DBConnector.ConnectSystem(ctx)
 	if err != nil {
 		return false, err
 	}
 
 	queryString := "SELECT name FROM users WHERE name = ?"
-	queryUser := tx.QueryRow(queryString, username)
+	queryUser := tx.QueryRowContext(ctx, queryString, username)
 	err = queryUser.Scan(&user.Name)
 	switch {
 	case err == sql.ErrNoRows:
@@ -110,17 +109,17 @@ func (f *MYSQLFunctions) UserExists(username string) (bool, error) {
 	}
 }
 
-func (f *MYSQLFunctions) EmailExists(email string) (bool, error) {
+func (f *MYSQLFunctions) EmailExists(ctx context.Context, email string) (bool, error) {
 	email = strings.ReplaceAll(strings.ToLower(email), " ", "")
 
 	var user models.User
-	queryString := "select email from users where email = ?"
-	tx, err := f.DBConnector.ConnectSystem()
+	queryString := "select email from users where email = ? AND deleted_at IS NULL"
+	tx, err := f.DBConnector.ConnectSystem(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	queryEmail := tx.QueryRow(queryString, email)
+	queryEmail := tx.QueryRowContext(ctx, queryString, email)
 	if err != nil {
 		return false, RollbackWithErrorStack(tx, err)
 	}
@@ -149,48 +148,28 @@ var InsertUserQuery = "INSERT INTO users (id, name, email, password, user_settin
 // AddUser creates a new user entry in the DB.
 // Whitespaces in the email are automatically deleted
 // Email/Name are unique in DB. Duplicates will return error.
-func (*MYSQLFunctions) AddUser(user *models.User, tx *sql.Tx) error {
-	_, err := tx.Exec(InsertUserQuery, user.ID, user.Name, user.Email, string(user.Password), user.SettingsID, user.AssetsID)
-	errDuplicateName := fmt.Errorf(ErrSQLDuplicateUserNameEntryString, user.Name)
-	errDuplicateEmail := fmt.Errorf(ErrSQLDuplicateEmailEntryString, user.Email)
+func (*MYSQLFunctions) AddUser(ctx context.Context, user *models.User, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, InsertUserQuery, user.ID, user.Name, user.Email, string(user.Password), user.SettingsID, user.AssetsID)
 	if err != nil {
-		switch {
-		case err.Error() == errDuplicateName.Error():
-			if errRb := tx.Rollback(); errRb != nil {
-				return err
-			}
-			return errDuplicateName
-		case err.Error() == errDuplicateEmail.Error():
-			if errRb := tx.Rollback(); errRb != nil {
-				return err
-			}
-			return errDuplicateEmail
-		case err != nil:
-			return RollbackWithErrorStack(tx, err)
-		default:
-			return nil
-		}
+		return TranslateError(err)
 	}
 	return nil
 }
 
 var DeleteUserQuery = "DELETE FROM users WHERE id=UUID_TO_BIN(?)"
 
-func (*MYSQLFunctions) DeleteUser(ID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteUserQuery, ID)
+func (*MYSQLFunctions) DeleteUser(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteUserQuery, ID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoUserDeleted
 	}
 
@@ -199,13 +178,13 @@ func (*MYSQLFunctions) DeleteUser(ID *uuid.UUID, tx *sql.Tx) error {
 
 var GetProductUserIDsQuery = "SELECT BIN_TO_UUID(users_id), privileges_id FROM users_products where products_id = UUID_TO_BIN(?)"
 
-func (MYSQLFunctions) GetProductUserIDs(productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error) {
-	rows, err := tx.Query(GetProductUserIDsQuery, productID)
+func (MYSQLFunctions) GetProductUserIDs(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetProductUserIDsQuery, productID)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, sql.ErrNoRows
 	case err != nil:
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	default:
 	}
 
@@ -219,35 +198,32 @@ func (MYSQLFunctions) GetProductUserIDs(productID *uuid.UUID, tx *sql.Tx) (*mode
 		privilege := -1
 		err := rows.Scan(&userID, &privilege)
 		if err != nil {
-			return nil, RollbackWithErrorStack(tx, err)
+			return nil, TranslateError(err)
 		}
 		productUsers.UserMap[userID] = privilege
 		productUsers.UserIDArray = append(productUsers.UserIDArray, userID)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, RollbackWithErrorStack(tx, err)
+		return nil, TranslateError(err)
 	}
 	return &productUsers, nil
 }
 
 var DeleteProductUserQuery = "DELETE FROM users_products where products_id = UUID_TO_BIN(?) AND users_id = UUID_TO_BIN(?)"
 
-func (MYSQLFunctions) DeleteProductUser(productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
-	result, err := tx.Exec(DeleteProductUserQuery, productID, userID)
+func (MYSQLFunctions) DeleteProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductUserQuery, productID, userID)
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	affected, err := result.RowsAffected()
 	if err != nil {
-		return RollbackWithErrorStack(tx, err)
+		return TranslateError(err)
 	}
 
 	if affected == 0 {
-		if errRb := tx.Rollback(); errRb != nil {
-			return err
-		}
 		return ErrNoUserWithProduct
 	}
 
@@ -1,6 +1,7 @@
 package mysqldb
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
@@ -118,7 +120,6 @@ func createUsersTestData(testID int) (*tests.OrderedTests, error) {
 		err := errors.New("This is a failure test")
 		mock.ExpectBegin()
 		mock.ExpectQuery(GetUserByEmailQuery).WithArgs(user.Email).WillReturnError(err)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: UserInputData{
 				queryType: ByEmail,
@@ -167,7 +168,6 @@ func createUsersTestData(testID int) (*tests.OrderedTests, error) {
 		err = errors.New("This is a failure test")
 		mock.ExpectBegin()
 		mock.ExpectQuery(GetUserByIDQuery).WithArgs(user.ID).WillReturnError(err)
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: UserInputData{
 				queryType: ByID,
@@ -211,10 +211,13 @@ func createUsersTestData(testID int) (*tests.OrderedTests, error) {
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 
 		testCase = "duplicate_name"
-		expected := fmt.Errorf(ErrSQLDuplicateUserNameEntryString, user.Name)
+		mysqlErr := &mysql.MySQLError{
+			Number:  ErDupEntry,
+			Message: fmt.Sprintf("Duplicate entry '%s' for key 'users.name'", user.Name),
+		}
+		expected := TranslateError(mysqlErr)
 		mock.ExpectBegin()
-		mock.ExpectExec(InsertUserQuery).WithArgs(user.ID, user.Name, user.Email, password, user.SettingsID, user.AssetsID).WillReturnError(expected)
-		mock.ExpectRollback()
+		mock.ExpectExec(InsertUserQuery).WithArgs(user.ID, user.Name, user.Email, password, user.SettingsID, user.AssetsID).WillReturnError(mysqlErr)
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: UserInputData{
 				user: user,
@@ -226,10 +229,13 @@ func createUsersTestData(testID int) (*tests.OrderedTests, error) {
 		dataSet.OrderedList = append(dataSet.OrderedList, testCase)
 
 		testCase = "duplicate_email"
-		expected = fmt.Errorf(ErrSQLDuplicateEmailEntryString, user.Email)
+		mysqlErr = &mysql.MySQLError{
+			Number:  ErDupEntry,
+			Message: fmt.Sprintf("Duplicate entry '%s' for key 'users.email'", user.Email),
+		}
+		expected = TranslateError(mysqlErr)
 		mock.ExpectBegin()
-		mock.ExpectExec(InsertUserQuery).WithArgs(user.ID, user.Name, user.Email, password, user.SettingsID, user.AssetsID).WillReturnError(expected)
-		mock.ExpectRollback()
+		mock.ExpectExec(InsertUserQuery).WithArgs(user.ID, user.Name, user.Email, password, user.SettingsID, user.AssetsID).WillReturnError(mysqlErr)
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: UserInputData{
 				user: user,
@@ -257,7 +263,6 @@ func createUsersTestData(testID int) (*tests.OrderedTests, error) {
 		testCase = "invalid_user"
 		mock.ExpectBegin()
 		mock.ExpectExec(DeleteUserQuery).WithArgs(user.ID).WillReturnResult(sqlmock.NewResult(1, 0))
-		mock.ExpectRollback()
 		dataSet.TestDataSet[testCase] = tests.Data{
 			Data: UserInputData{
 				user: user,
@@ -356,7 +361,7 @@ func TestGetUser(t *testing.T) {
 			expectedData := testCase.Expected.(UserExpectedData)
 			inputData := testCase.Data.(UserInputData)
 
-			output, err := DBFunctions.GetUser(inputData.queryType, inputData.keyValue, tx)
+			output, err := DBFunctions.GetUser(context.Background(), inputData.queryType, inputData.keyValue, tx)
 			tests.CheckResult(output, expectedData.user, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -384,7 +389,7 @@ func TestAddUser(t *testing.T) {
 			expectedData := testCase.Expected.(UserExpectedData)
 			inputData := testCase.Data.(UserInputData)
 
-			err = DBFunctions.AddUser(inputData.user, tx)
+			err = DBFunctions.AddUser(context.Background(), inputData.user, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -412,7 +417,7 @@ func TestDeleteUser(t *testing.T) {
 			expectedData := testCase.Expected.(UserExpectedData)
 			inputData := testCase.Data.(UserInputData)
 
-			err = DBFunctions.DeleteUser(&inputData.user.ID, tx)
+			err = DBFunctions.DeleteUser(context.Background(), &inputData.user.ID, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -441,7 +446,7 @@ func TestGetProductUserIDs(t *testing.T) {
 			expectedData := testCase.Expected.(UserExpectedData)
 			inputData := testCase.Data.(UserInputData)
 
-			output, err := DBFunctions.GetProductUserIDs(inputData.productID, tx)
+			output, err := DBFunctions.GetProductUserIDs(context.Background(), inputData.productID, tx)
 			tests.CheckResult(output, expectedData.productUsers, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -469,7 +474,7 @@ func TestDeleteProductUser(t *testing.T) {
 			expectedData := dataSet.TestDataSet[testCaseString].Expected.(UserExpectedData)
 			inputData := dataSet.TestDataSet[testCaseString].Data.(UserInputData)
 
-			err = DBFunctions.DeleteProductUser(inputData.productID, &inputData.user.ID, tx)
+			err = DBFunctions.DeleteProductUser(context.Background(), inputData.productID, &inputData.user.ID, tx)
 			tests.CheckResult(nil, nil, err, expectedData.err, testCaseString, t)
 		})
 	}
@@ -0,0 +1,231 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	WebhookSubscriptions = "webhook_subscriptions"
+	WebhookDeliveries    = "webhook_deliveries"
+)
+
+var ErrWebhookSubscriptionMissing = errors.New("Webhook subscription is missing")
+
+var AddWebhookSubscriptionQuery = "INSERT INTO webhook_subscriptions (id, url, secret, event_types, created_at) VALUES (UUID_TO_BIN(?), ?, ?, ?, ?)"
+
+func (*MYSQLFunctions) AddWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, AddWebhookSubscriptionQuery, subscription.ID, subscription.URL, subscription.Secret, eventTypes, subscription.CreatedAt)
+	return TranslateError(err)
+}
+
+var UpdateWebhookSubscriptionQuery = "UPDATE webhook_subscriptions SET url = ?, secret = ?, event_types = ? WHERE id = UUID_TO_BIN(?)"
+
+// UpdateWebhookSubscription replaces the URL, the secret and the event types of the subscription.
+// MySQL does not count the rows updated without change, the caller has to check that the subscription exists.
+func (*MYSQLFunctions) UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, UpdateWebhookSubscriptionQuery, subscription.URL, subscription.Secret, eventTypes, subscription.ID)
+	return TranslateError(err)
+}
+
+var DeleteWebhookSubscriptionQuery = "DELETE FROM webhook_subscriptions WHERE id = UUID_TO_BIN(?)"
+
+// DeleteWebhookSubscription returns ErrWebhookSubscriptionMissing if the subscription does not exist.
+// The deliveries of the subscription have to be deleted first.
+func (*MYSQLFunctions) DeleteWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteWebhookSubscriptionQuery, ID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrWebhookSubscriptionMissing
+	}
+	return nil
+}
+
+var GetWebhookSubscriptionQuery = "SELECT BIN_TO_UUID(id), url, secret, event_types, created_at FROM webhook_subscriptions WHERE id = UUID_TO_BIN(?)"
+
+// GetWebhookSubscription returns sql.ErrNoRows if the subscription does not exist.
+func (*MYSQLFunctions) GetWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+	var eventTypes []byte
+	err := tx.QueryRowContext(ctx, GetWebhookSubscriptionQuery, ID).Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.CreatedAt)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+var GetWebhookSubscriptionsQuery = "SELECT BIN_TO_UUID(id), url, secret, event_types, created_at FROM webhook_subscriptions ORDER BY created_at, id"
+
+// GetWebhookSubscriptions lists the subscriptions, the oldest first. sql.ErrNoRows is returned if there are none.
+func (*MYSQLFunctions) GetWebhookSubscriptions(ctx context.Context, tx *sql.Tx) ([]models.WebhookSubscription, error) {
+	rows, err := tx.QueryContext(ctx, GetWebhookSubscriptionsQuery)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+
+	subscriptions := make([]models.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription := models.WebhookSubscription{}
+		var eventTypes []byte
+		if err := rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.CreatedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return subscriptions, nil
+}
+
+var AddWebhookDeliveryQuery = "INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at) VALUES (UUID_TO_BIN(?), ?, ?, ?, ?, ?, ?)"
+
+// AddWebhookDelivery queues the delivery of the event to the subscription.
+// An event already queued for the subscription fails with ErrDuplicateEntry.
+func (*MYSQLFunctions) AddWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	payload, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, AddWebhookDeliveryQuery, delivery.SubscriptionID, delivery.Event.ID, delivery.Event.Type, payload, delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt)
+	return TranslateError(err)
+}
+
+var webhookDeliveryColumns = "id, BIN_TO_UUID(subscription_id), payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, created_at FROM webhook_deliveries"
+
+var GetWebhookDeliveriesQuery = "SELECT " + webhookDeliveryColumns + " WHERE subscription_id = UUID_TO_BIN(?) AND id < ? ORDER BY id DESC LIMIT ?"
+
+// GetWebhookDeliveries lists the deliveries of the subscription with an ID below beforeID, the latest first.
+// sql.ErrNoRows is returned if there are none.
+func (*MYSQLFunctions) GetWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, beforeID int64, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	rows, err := tx.QueryContext(ctx, GetWebhookDeliveriesQuery, subscriptionID, beforeID, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+var GetDueWebhookDeliveriesQuery = "SELECT " + webhookDeliveryColumns + " WHERE status = ? AND next_attempt_at <= ? ORDER BY id LIMIT ?"
+
+// GetDueWebhookDeliveries lists at most limit pending deliveries due at dueAt, the oldest first.
+// sql.ErrNoRows is returned if there are none.
+func (*MYSQLFunctions) GetDueWebhookDeliveries(ctx context.Context, dueAt time.Time, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	rows, err := tx.QueryContext(ctx, GetDueWebhookDeliveriesQuery, models.WebhookPending, dueAt, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+var UpdateWebhookDeliveryQuery = "UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_attempt_at = ?, last_status_code = ?, last_error = ? WHERE id = ?"
+
+// UpdateWebhookDelivery records the result of a delivery attempt.
+func (*MYSQLFunctions) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	_, err := tx.ExecContext(
+		ctx,
+		UpdateWebhookDeliveryQuery,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.LastStatusCode,
+		nullString(delivery.LastError),
+		delivery.ID)
+	return TranslateError(err)
+}
+
+var DeleteWebhookDeliveriesQuery = "DELETE FROM webhook_deliveries WHERE subscription_id = UUID_TO_BIN(?)"
+
+// DeleteWebhookDeliveries deletes the delivery log of the subscription.
+func (*MYSQLFunctions) DeleteWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, DeleteWebhookDeliveriesQuery, subscriptionID)
+	return TranslateError(err)
+}
+
+// nullString stores the empty string as NULL.
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := models.WebhookDelivery{}
+		var payload []byte
+		var lastAttemptAt sql.NullTime
+		var lastError sql.NullString
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&lastAttemptAt,
+			&delivery.LastStatusCode,
+			&lastError,
+			&delivery.CreatedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := json.Unmarshal(payload, &delivery.Event); err != nil {
+			return nil, err
+		}
+		if lastAttemptAt.Valid {
+			delivery.LastAttemptAt = &lastAttemptAt.Time
+		}
+		delivery.LastError = lastError.String
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(deliveries) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return deliveries, nil
+}
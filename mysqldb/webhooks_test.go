@@ -0,0 +1,167 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	subscription := &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "https://billing.example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []string{models.EventUserCreated},
+		CreatedAt:  createdAt,
+	}
+	columns := []string{"id", "url", "secret", "event_types", "created_at"}
+	eventTypes := []byte(`["user.created"]`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(AddWebhookSubscriptionQuery).
+		WithArgs(subscription.ID, subscription.URL, subscription.Secret, eventTypes, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(GetWebhookSubscriptionQuery).WithArgs(&subscription.ID).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(subscription.ID.String(), subscription.URL, subscription.Secret, eventTypes, createdAt))
+	mock.ExpectQuery(GetWebhookSubscriptionsQuery).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(subscription.ID.String(), subscription.URL, subscription.Secret, eventTypes, createdAt))
+	mock.ExpectExec(UpdateWebhookSubscriptionQuery).
+		WithArgs(subscription.URL, "rotated", eventTypes, subscription.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteWebhookSubscriptionQuery).WithArgs(&subscription.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteWebhookSubscriptionQuery).WithArgs(&subscription.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(GetWebhookSubscriptionsQuery).WillReturnRows(sqlmock.NewRows(columns))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	functions := &MYSQLFunctions{}
+	err = functions.AddWebhookSubscription(ctx, subscription, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_subscription", t)
+
+	found, err := functions.GetWebhookSubscription(ctx, &subscription.ID, tx)
+	tests.CheckResult(found, subscription, err, nil, "get_subscription", t)
+
+	subscriptions, err := functions.GetWebhookSubscriptions(ctx, tx)
+	tests.CheckResult(subscriptions, []models.WebhookSubscription{*subscription}, err, nil, "get_subscriptions", t)
+
+	subscription.Secret = "rotated"
+	err = functions.UpdateWebhookSubscription(ctx, subscription, tx)
+	tests.CheckResult(nil, nil, err, nil, "update_subscription", t)
+
+	err = functions.DeleteWebhookSubscription(ctx, &subscription.ID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_subscription", t)
+
+	err = functions.DeleteWebhookSubscription(ctx, &subscription.ID, tx)
+	tests.CheckResult(nil, nil, err, ErrWebhookSubscriptionMissing, "delete_missing", t)
+
+	_, err = functions.GetWebhookSubscriptions(ctx, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_subscriptions", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	subscriptionID := uuid.New()
+	userID := uuid.New()
+	createdAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	attemptedAt := createdAt.Add(time.Minute)
+	event := models.DomainEvent{
+		ID:          7,
+		Type:        models.EventUserCreated,
+		OccurredAt:  createdAt,
+		AggregateID: userID,
+		Payload:     models.DataMap{"name": "testUser"},
+	}
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		Event:          event,
+		Status:         models.WebhookPending,
+		NextAttemptAt:  createdAt,
+		CreatedAt:      createdAt,
+	}
+	payload := []byte(`{"id":7,"type":"user.created","occurred_at":"2021-09-01T12:00:00Z","actor":null,"aggregate_id":"` +
+		userID.String() + `","payload":{"name":"testUser"}}`)
+	columns := []string{
+		"id", "subscription_id", "payload", "status", "attempts", "next_attempt_at",
+		"last_attempt_at", "last_status_code", "last_error", "created_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(AddWebhookDeliveryQuery).
+		WithArgs(subscriptionID, int64(7), models.EventUserCreated, payload, models.WebhookPending, createdAt, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(GetDueWebhookDeliveriesQuery).WithArgs(models.WebhookPending, createdAt, 10).WillReturnRows(
+		sqlmock.NewRows(columns).
+			AddRow(1, subscriptionID.String(), payload, models.WebhookPending, 0, createdAt, nil, 0, nil, createdAt))
+	mock.ExpectExec(UpdateWebhookDeliveryQuery).
+		WithArgs(models.WebhookPending, 1, attemptedAt, &attemptedAt, 500, "rejected", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(GetWebhookDeliveriesQuery).WithArgs(&subscriptionID, int64(10), 5).WillReturnRows(
+		sqlmock.NewRows(columns).
+			AddRow(1, subscriptionID.String(), payload, models.WebhookPending, 1, attemptedAt, attemptedAt, 500, "rejected", createdAt))
+	mock.ExpectExec(DeleteWebhookDeliveriesQuery).WithArgs(&subscriptionID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(GetWebhookDeliveriesQuery).WithArgs(&subscriptionID, int64(10), 5).WillReturnRows(sqlmock.NewRows(columns))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	functions := &MYSQLFunctions{}
+	err = functions.AddWebhookDelivery(ctx, delivery, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_delivery", t)
+
+	delivery.ID = 1
+	deliveries, err := functions.GetDueWebhookDeliveries(ctx, createdAt, 10, tx)
+	tests.CheckResult(deliveries, []models.WebhookDelivery{*delivery}, err, nil, "due_deliveries", t)
+
+	delivery.Attempts = 1
+	delivery.NextAttemptAt = attemptedAt
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.LastStatusCode = 500
+	delivery.LastError = "rejected"
+	err = functions.UpdateWebhookDelivery(ctx, delivery, tx)
+	tests.CheckResult(nil, nil, err, nil, "update_delivery", t)
+
+	deliveries, err = functions.GetWebhookDeliveries(ctx, &subscriptionID, 10, 5, tx)
+	tests.CheckResult(deliveries, []models.WebhookDelivery{*delivery}, err, nil, "deliveries", t)
+
+	err = functions.DeleteWebhookDeliveries(ctx, &subscriptionID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_deliveries", t)
+
+	_, err = functions.GetWebhookDeliveries(ctx, &subscriptionID, 10, 5, tx)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_deliveries", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Package outbox implements the sinks the domain events of the outbox are delivered to.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+)
+
+// Sink receives the domain events. Deliver must return an error unless the event is accepted,
+// the event is then delivered again later, so a sink may receive the same event more than once.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, event *models.DomainEvent) error
+}
+
+// Default timeout of the HTTP sink requests.
+const DefaultHTTPTimeout = 10 * time.Second
+
+// HTTPSink posts every event as JSON to URL. Any response status other than 2xx is a failed delivery.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		URL:    url,
+		Client: &http.Client{Timeout: DefaultHTTPTimeout},
+	}
+}
+
+func (s *HTTPSink) Name() string {
+	return "http " + s.URL
+}
+
+func (s *HTTPSink) Deliver(ctx context.Context, event *models.DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	// Drained, so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("Event %d rejected by %s with status %d", event.ID, s.URL, response.StatusCode)
+	}
+	return nil
+}
+
+// WriterSink writes every event as a line of JSON to the writer.
+type WriterSink struct {
+	name   string
+	mu     sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+func NewWriterSink(name string, writer io.Writer) *WriterSink {
+	return &WriterSink{name: name, writer: writer}
+}
+
+// NewFileSink appends the events to the file at path, it is created if it does not exist. "-" writes to stdout.
+func NewFileSink(path string) (*WriterSink, error) {
+	if path == "-" {
+		return NewWriterSink("stdout", os.Stdout), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := NewWriterSink("file "+path, file)
+	sink.closer = file
+	return sink, nil
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Deliver(ctx context.Context, event *models.DomainEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file of the sink, stdout and the writers passed to NewWriterSink are left open.
+func (s *WriterSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func testEvent() *models.DomainEvent {
+	return &models.DomainEvent{
+		ID:          1,
+		Type:        models.EventUserCreated,
+		OccurredAt:  time.Date(2021, 8, 15, 12, 0, 0, 0, time.UTC),
+		AggregateID: uuid.New(),
+		Payload:     models.DataMap{"name": "testUser"},
+		Attempts:    3,
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	event := testEvent()
+	status := http.StatusOK
+	var received models.DomainEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tests.CheckResult(r.Header.Get("Content-Type"), "application/json", nil, nil, "content_type", t)
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode the event: %s", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	err := sink.Deliver(context.Background(), event)
+	// The number of attempts is not sent.
+	event.Attempts = 0
+	tests.CheckResult(received, *event, err, nil, "delivered", t)
+
+	status = http.StatusServiceUnavailable
+	err = sink.Deliver(context.Background(), event)
+	tests.CheckResult(err != nil, true, nil, nil, "rejected", t)
+}
+
+func TestWriterSink(t *testing.T) {
+	event := testEvent()
+	var buffer bytes.Buffer
+	sink := NewWriterSink("buffer", &buffer)
+	for i := 0; i < 2; i++ {
+		if err := sink.Deliver(context.Background(), event); err != nil {
+			t.Fatalf("Failed to deliver the event: %s", err)
+		}
+	}
+
+	line, err := json.Marshal(event)
+	tests.CheckResult(buffer.String(), string(line)+"\n"+string(line)+"\n", err, nil, "lines", t)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	event := testEvent()
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("Failed to open the file sink: %s", err)
+		}
+		if err := sink.Deliver(context.Background(), event); err != nil {
+			t.Fatalf("Failed to deliver the event: %s", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Failed to close the file sink: %s", err)
+		}
+	}
+
+	// The file is appended to.
+	content, err := ioutil.ReadFile(path)
+	line, _ := json.Marshal(event)
+	tests.CheckResult(string(content), string(line)+"\n"+string(line)+"\n", err, nil, "appended", t)
+}
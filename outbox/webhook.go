@@ -0,0 +1,86 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+)
+
+// Headers of the webhook requests. The receiver verifies the signature with VerifySignature
+// and can recognize the redelivered requests by the delivery ID.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of a webhook request: the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+// The timestamp is part of the signature, so the receiver can reject replayed requests.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature is the signature of the request made with secret.
+func VerifySignature(secret string, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// PostWebhook posts the event of the delivery as JSON to the URL of the subscription, signed with its secret.
+// It returns the response status, zero if no response was received. Any status other than 2xx is a failed delivery.
+func PostWebhook(ctx context.Context, client *http.Client, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, Sign(subscription.Secret, timestamp, body))
+	request.Header.Set(WebhookEventHeader, delivery.Event.Type)
+	request.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// Drained, so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, &RejectedError{StatusCode: response.StatusCode}
+	}
+	return response.StatusCode, nil
+}
+
+// RejectedError is returned if the receiver answers a delivery with a status other than 2xx.
+type RejectedError struct {
+	StatusCode int
+}
+
+func (e *RejectedError) Error() string {
+	return "Delivery rejected with status " + strconv.Itoa(e.StatusCode)
+}
@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", "1630497600", body)
+	tests.CheckResult(VerifySignature("secret", "1630497600", body, signature), true, nil, nil, "valid", t)
+	tests.CheckResult(VerifySignature("other", "1630497600", body, signature), false, nil, nil, "wrong_secret", t)
+	tests.CheckResult(VerifySignature("secret", "1630497601", body, signature), false, nil, nil, "replayed", t)
+	tests.CheckResult(VerifySignature("secret", "1630497600", []byte(`{"id":2}`), signature), false, nil, nil, "tampered", t)
+}
+
+func TestPostWebhook(t *testing.T) {
+	subscription := &models.WebhookSubscription{ID: uuid.New(), Secret: "secret"}
+	delivery := &models.WebhookDelivery{ID: 3, SubscriptionID: subscription.ID, Event: *testEvent()}
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read the body: %s", err)
+		}
+
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		tests.CheckResult(timestamp, strconv.FormatInt(now.Unix(), 10), nil, nil, "timestamp", t)
+		tests.CheckResult(VerifySignature("secret", timestamp, body, r.Header.Get(WebhookSignatureHeader)), true, nil, nil, "signature", t)
+		tests.CheckResult(r.Header.Get(WebhookEventHeader), models.EventUserCreated, nil, nil, "event_type", t)
+		tests.CheckResult(r.Header.Get(WebhookDeliveryHeader), "3", nil, nil, "delivery_id", t)
+
+		var received models.DomainEvent
+		err = json.Unmarshal(body, &received)
+		delivery.Event.Attempts = 0
+		tests.CheckResult(received, delivery.Event, err, nil, "event", t)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	subscription.URL = server.URL
+
+	code, err := PostWebhook(context.Background(), server.Client(), subscription, delivery, now)
+	tests.CheckResult(code, http.StatusNoContent, err, nil, "delivered", t)
+
+	status = http.StatusInternalServerError
+	code, err = PostWebhook(context.Background(), server.Client(), subscription, delivery, now)
+	var rejected *RejectedError
+	tests.CheckResult(code, http.StatusInternalServerError, errors.As(err, &rejected), true, "rejected", t)
+}
@@ -0,0 +1,215 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var AddAssetQuery = "INSERT INTO %s (id, data) VALUES ($1, $2)"
+
+func (*PGFunctions) AddAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
+	// Prepare data
+	binary, err := json.Marshal(asset.DataMap)
+	if err != nil {
+		return err
+	}
+
+	// jsonb is sent as text, []byte parameters would be sent as bytea.
+	query := fmt.Sprintf(AddAssetQuery, assetType)
+	_, err = tx.ExecContext(ctx, query, asset.ID, string(binary))
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	asset.Version = 1
+	return nil
+}
+
+var UpdateAssetQuery = "UPDATE %s SET data = $1, version = version + 1 WHERE id = $2 RETURNING version"
+var UpdateAssetVersionQuery = "UPDATE %s SET data = $1, version = version + 1 WHERE id = $2 AND version = $3 RETURNING version"
+var GetAssetVersionQuery = "SELECT version FROM %s WHERE id = $1"
+
+// UpdateAsset overwrites the data of the asset and sets asset.Version to the new version.
+// If asset.Version is set, mysqldb.ErrVersionConflict is returned when the stored asset has a different version.
+func (*PGFunctions) UpdateAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
+	binary, err := json.Marshal(asset.DataMap)
+	if err != nil {
+		return err
+	}
+
+	if err := addAssetHistory(ctx, assetType, &asset.ID, tx); err != nil {
+		return err
+	}
+
+	var result *sql.Row
+	if asset.Version > 0 {
+		result = tx.QueryRowContext(ctx, fmt.Sprintf(UpdateAssetVersionQuery, assetType), string(binary), asset.ID, asset.Version)
+	} else {
+		result = tx.QueryRowContext(ctx, fmt.Sprintf(UpdateAssetQuery, assetType), string(binary), asset.ID)
+	}
+
+	var version int64
+	err = result.Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		// Either the asset is missing or its version is different.
+		err = tx.QueryRowContext(ctx, fmt.Sprintf(GetAssetVersionQuery, assetType), asset.ID).Scan(&version)
+		switch {
+		case err == sql.ErrNoRows:
+			return errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+		case err != nil:
+			return TranslateError(err)
+		default:
+			return errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, version, asset.Version)
+		}
+	case err != nil:
+		return TranslateError(err)
+	default:
+	}
+
+	asset.Version = version
+	return nil
+}
+
+var GetAssetQuery = "SELECT id, data, version FROM %s WHERE id = $1"
+
+func (f *PGFunctions) GetAsset(ctx context.Context, assetType string, assetID *uuid.UUID) (*models.Asset, error) {
+	asset := &models.Asset{}
+
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(GetAssetQuery, assetType)
+	result := tx.QueryRowContext(ctx, query, assetID)
+
+	dataMap := []byte{}
+	err = result.Scan(&asset.ID, &dataMap, &asset.Version)
+	switch {
+	case err == sql.ErrNoRows:
+		if errRb := tx.Commit(); errRb != nil {
+			return nil, errRb
+		}
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, RollbackWithErrorStack(tx, err)
+	default:
+	}
+
+	if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	return asset, TranslateError(tx.Commit())
+}
+
+var DeleteAssetQuery = "DELETE FROM %s WHERE id = $1"
+
+func (*PGFunctions) DeleteAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+	query := fmt.Sprintf(DeleteAssetQuery, assetType)
+	result, err := tx.ExecContext(ctx, query, assetID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	}
+	return deleteAssetHistory(ctx, assetType, assetID, tx)
+}
+
+var GetAssetsQuery = "SELECT id, data, version FROM %s WHERE id = ANY($1::uuid[])"
+
+func (*PGFunctions) GetAssets(ctx context.Context, assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
+	query := fmt.Sprintf(GetAssetsQuery, assetType)
+	rows, err := tx.QueryContext(ctx, query, uuidArray(IDs))
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	assets := make([]models.Asset, 0)
+	for rows.Next() {
+		dataMap := []byte{}
+		asset := models.Asset{}
+		err := rows.Scan(&asset.ID, &dataMap, &asset.Version)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(assets) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return assets, nil
+}
+
+var GetAssetForUpdateQuery = "SELECT id, data, version FROM %s WHERE id = $1 FOR UPDATE"
+
+// PatchAsset applies patch to the data of the asset locked by the read in tx and returns the patched asset.
+// If patch.Version is set, mysqldb.ErrVersionConflict is returned when the stored asset has a different version.
+func (*PGFunctions) PatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error) {
+	asset := &models.Asset{}
+	dataMap := []byte{}
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(GetAssetForUpdateQuery, assetType), assetID).Scan(&asset.ID, &dataMap, &asset.Version)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	if patch.Version > 0 && patch.Version != asset.Version {
+		return nil, errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, asset.Version, patch.Version)
+	}
+
+	if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+		return nil, err
+	}
+
+	if err := addAssetHistory(ctx, assetType, assetID, tx); err != nil {
+		return nil, err
+	}
+
+	patched, err := patch.Apply(asset.DataMap)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(UpdateAssetQuery, assetType), string(binary), assetID).Scan(&asset.Version)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	asset.DataMap = patched
+	return asset, nil
+}
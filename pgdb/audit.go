@@ -0,0 +1,159 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var AddAuditEventQuery = "INSERT INTO audit_events (occurred_at, actor, action, entity_type, entity_id, before_data, after_data) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+
+// AddAuditEvent records the event in tx, so it is only kept if the change is committed.
+func (*PGFunctions) AddAuditEvent(ctx context.Context, event *models.AuditEvent, tx *sql.Tx) error {
+	before, err := marshalSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := marshalSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+
+	var actor interface{}
+	if event.Actor != nil {
+		actor = event.Actor
+	}
+
+	_, err = tx.ExecContext(ctx, AddAuditEventQuery, event.OccurredAt, actor, event.Action, event.EntityType, event.EntityID, before, after)
+	return TranslateError(err)
+}
+
+var GetAuditEventsQuery = "SELECT id, occurred_at, actor, action, entity_type, entity_id, before_data, after_data FROM audit_events"
+
+// GetAuditEvents lists the events matching filter, the latest first. sql.ErrNoRows is returned if there are none.
+// The filter has to be validated by the caller.
+func (*PGFunctions) GetAuditEvents(ctx context.Context, filter *models.AuditFilter, tx *sql.Tx) ([]models.AuditEvent, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Actor != nil {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		where("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		where("entity_id = $%d", filter.EntityID)
+	}
+	if !filter.From.IsZero() {
+		where("occurred_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("occurred_at < $%d", filter.To)
+	}
+	if filter.BeforeID > 0 {
+		where("id < $%d", filter.BeforeID)
+	}
+
+	query := GetAuditEventsQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanAuditEvents(rows)
+}
+
+var GetAuditEventsAfterQuery = GetAuditEventsQuery + " WHERE id > $1 ORDER BY id LIMIT $2"
+
+// GetAuditEventsAfter lists at most limit events recorded after the event afterID, the oldest first.
+// sql.ErrNoRows is returned if there are none.
+func (*PGFunctions) GetAuditEventsAfter(ctx context.Context, afterID int64, limit int, tx *sql.Tx) ([]models.AuditEvent, error) {
+	rows, err := tx.QueryContext(ctx, GetAuditEventsAfterQuery, afterID, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanAuditEvents(rows)
+}
+
+// marshalSnapshot returns the JSON of the snapshot as text or nil if there is none.
+// jsonb is sent as text, []byte parameters would be sent as bytea.
+func marshalSnapshot(snapshot models.DataMap) (interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+
+	binary, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return string(binary), nil
+}
+
+func scanAuditEvent(event *models.AuditEvent, actor sql.NullString, before []byte, after []byte) error {
+	if actor.Valid {
+		actorID, err := uuid.Parse(actor.String)
+		if err != nil {
+			return errors.Wrap(err, "Invalid actor in the audit log")
+		}
+		event.Actor = &actorID
+	}
+
+	if before != nil {
+		if err := json.Unmarshal(before, &event.Before); err != nil {
+			return err
+		}
+	}
+
+	if after != nil {
+		if err := json.Unmarshal(after, &event.After); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanAuditEvents(rows *sql.Rows) ([]models.AuditEvent, error) {
+	defer rows.Close()
+
+	events := make([]models.AuditEvent, 0)
+	for rows.Next() {
+		event := models.AuditEvent{}
+		var actor sql.NullString
+		var before, after []byte
+		if err := rows.Scan(&event.ID, &event.OccurredAt, &actor, &event.Action, &event.EntityType, &event.EntityID, &before, &after); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := scanAuditEvent(&event, actor, before, after); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(events) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return events, nil
+}
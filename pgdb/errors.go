@@ -0,0 +1,79 @@
+package pgdb
+
+import (
+	"regexp"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// PostgreSQL error codes recognised by TranslateError.
+const (
+	CodeStringDataRightTruncation = "22001"
+	CodeNotNullViolation          = "23502"
+	CodeForeignKeyViolation       = "23503"
+	CodeUniqueViolation           = "23505"
+	CodeCheckViolation            = "23514"
+	CodeSerializationFailure      = "40001"
+	CodeDeadlockDetected          = "40P01"
+	CodeLockNotAvailable          = "55P03"
+)
+
+// Key (name)=(value) already exists. / Key (column)=(value) is not present in table "parent".
+var keyDetailRegexp = regexp.MustCompile(`^Key \(([^)]+)\)=\((.*)\) (?:already exists|is not present in table "([^"]+)"|is still referenced from table "([^"]+)")`)
+
+// update or delete on table "parent" violates foreign key constraint ...
+var referencedTableRegexp = regexp.MustCompile(`^update or delete on table "([^"]+)"`)
+
+// TranslateError converts PostgreSQL server errors to *mysqldb.SQLError, so that they match
+// the same sentinel errors as the MySQL ones. Any other error is returned unchanged.
+func TranslateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	sqlErr := &mysqldb.SQLError{
+		Table:      pqErr.Table,
+		Constraint: pqErr.Constraint,
+		Column:     pqErr.Column,
+		Err:        err,
+	}
+
+	match := keyDetailRegexp.FindStringSubmatch(pqErr.Detail)
+	switch pqErr.Code {
+	case CodeUniqueViolation:
+		sqlErr.Kind = mysqldb.ErrDuplicateEntry
+		if match != nil {
+			sqlErr.Column = match[1]
+			sqlErr.Value = match[2]
+		}
+	case CodeForeignKeyViolation:
+		sqlErr.Kind = mysqldb.ErrForeignKeyViolation
+		if match != nil && match[3] != "" {
+			sqlErr.Column = match[1]
+			sqlErr.Value = match[2]
+			sqlErr.ReferencedTable = match[3]
+		}
+		// The table of the error is the referencing one in both directions.
+		if match := referencedTableRegexp.FindStringSubmatch(pqErr.Message); match != nil {
+			sqlErr.Kind = mysqldb.ErrRowReferenced
+			sqlErr.ReferencedTable = match[1]
+		}
+	case CodeNotNullViolation:
+		sqlErr.Kind = mysqldb.ErrNotNullViolation
+	case CodeStringDataRightTruncation:
+		sqlErr.Kind = mysqldb.ErrDataTooLong
+	case CodeCheckViolation:
+		sqlErr.Kind = mysqldb.ErrCheckViolation
+	case CodeDeadlockDetected, CodeSerializationFailure:
+		sqlErr.Kind = mysqldb.ErrDeadlock
+	case CodeLockNotAvailable:
+		sqlErr.Kind = mysqldb.ErrLockWaitTimeout
+	default:
+		return err
+	}
+
+	return sqlErr
+}
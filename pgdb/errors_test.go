@@ -0,0 +1,185 @@
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type TranslateErrorExpectedData struct {
+	kind            error
+	table           string
+	constraint      string
+	column          string
+	referencedTable string
+}
+
+func createTranslateErrorTestData() *tests.OrderedTests {
+	dataSet := &tests.OrderedTests{
+		OrderedList: make(tests.OrderedTestList, 0),
+		TestDataSet: make(tests.DataSet),
+	}
+
+	testCase := "duplicate_entry"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:       CodeUniqueViolation,
+			Message:    "duplicate key value violates unique constraint \"users_email_key\"",
+			Detail:     "Key (email)=(test@test.com) already exists.",
+			Table:      "users",
+			Constraint: "users_email_key",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:       mysqldb.ErrDuplicateEntry,
+			table:      "users",
+			constraint: "users_email_key",
+			column:     "email",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "missing_parent_row"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:       CodeForeignKeyViolation,
+			Message:    "insert or update on table \"projects\" violates foreign key constraint \"projects_products_id_fkey\"",
+			Detail:     "Key (products_id)=(c34a7368-344a-11eb-adc1-0242ac120002) is not present in table \"products\".",
+			Table:      "projects",
+			Constraint: "projects_products_id_fkey",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:            mysqldb.ErrForeignKeyViolation,
+			table:           "projects",
+			constraint:      "projects_products_id_fkey",
+			column:          "products_id",
+			referencedTable: "products",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "referenced_parent_row"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:       CodeForeignKeyViolation,
+			Message:    "update or delete on table \"users\" violates foreign key constraint \"users_products_users_id_fkey\" on table \"users_products\"",
+			Detail:     "Key (id)=(c34a7368-344a-11eb-adc1-0242ac120002) is still referenced from table \"users_products\".",
+			Table:      "users_products",
+			Constraint: "users_products_users_id_fkey",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:            mysqldb.ErrRowReferenced,
+			table:           "users_products",
+			constraint:      "users_products_users_id_fkey",
+			referencedTable: "users",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "not_null"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:    CodeNotNullViolation,
+			Message: "null value in column \"name\" violates not-null constraint",
+			Table:   "users",
+			Column:  "name",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind:   mysqldb.ErrNotNullViolation,
+			table:  "users",
+			column: "name",
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "data_too_long"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:    CodeStringDataRightTruncation,
+			Message: "value too long for type character varying(50)",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind: mysqldb.ErrDataTooLong,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "deadlock"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:    CodeDeadlockDetected,
+			Message: "deadlock detected",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind: mysqldb.ErrDeadlock,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "serialization_failure"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:    CodeSerializationFailure,
+			Message: "could not serialize access due to concurrent update",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind: mysqldb.ErrDeadlock,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "lock_not_available"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data: &pq.Error{
+			Code:    CodeLockNotAvailable,
+			Message: "canceling statement due to lock timeout",
+		},
+		Expected: TranslateErrorExpectedData{
+			kind: mysqldb.ErrLockWaitTimeout,
+		},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	return dataSet
+}
+
+func TestTranslateError(t *testing.T) {
+	dataSet := createTranslateErrorTestData()
+
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			testCase := dataSet.TestDataSet[testCaseString]
+			expectedData := testCase.Expected.(TranslateErrorExpectedData)
+			input := testCase.Data.(*pq.Error)
+
+			// Wrapping must not hide the typed error.
+			err := TranslateError(errors.Wrap(input, "wrapped"))
+			if !errors.Is(err, expectedData.kind) {
+				t.Errorf("%s test failed. %v is not %v", testCaseString, err, expectedData.kind)
+				return
+			}
+
+			var sqlErr *mysqldb.SQLError
+			if !errors.As(err, &sqlErr) {
+				t.Errorf("%s test failed. %v is not *mysqldb.SQLError", testCaseString, err)
+				return
+			}
+
+			output := TranslateErrorExpectedData{
+				kind:            sqlErr.Kind,
+				table:           sqlErr.Table,
+				constraint:      sqlErr.Constraint,
+				column:          sqlErr.Column,
+				referencedTable: sqlErr.ReferencedTable,
+			}
+			tests.CheckResult(output, expectedData, nil, nil, testCaseString, t)
+		})
+	}
+
+	plainErr := errors.New("This is a failure test")
+	tests.CheckResult(nil, nil, TranslateError(plainErr), plainErr, "not_postgres_error", t)
+	tests.CheckResult(nil, nil, TranslateError(nil), nil, "nil_error", t)
+}
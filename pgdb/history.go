@@ -0,0 +1,104 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var AddAssetHistoryQuery = "INSERT INTO asset_history (asset_type, asset_id, version, data, changed_by) SELECT $1, id, version, data, $2 FROM %s WHERE id = $3 FOR UPDATE"
+
+// addAssetHistory copies the current version of the asset into the history before it is overwritten in tx.
+// The asset is locked until the end of tx, so the copied version is the one replaced by the update.
+func addAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+	var changedBy interface{}
+	if userID := models.ActingUser(ctx); userID != nil {
+		changedBy = userID
+	}
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(AddAssetHistoryQuery, assetType), assetType, changedBy, assetID)
+	return TranslateError(err)
+}
+
+var GetAssetHistoryQuery = "SELECT asset_id, version, changed_by, changed_at FROM asset_history WHERE asset_type = $1 AND asset_id = $2 ORDER BY version DESC"
+
+// GetAssetHistory lists the replaced versions of the asset without their data, the latest first.
+func (*PGFunctions) GetAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) ([]models.AssetRevision, error) {
+	rows, err := tx.QueryContext(ctx, GetAssetHistoryQuery, assetType, assetID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.AssetRevision, 0)
+	for rows.Next() {
+		revision := models.AssetRevision{}
+		var changedBy sql.NullString
+		if err := rows.Scan(&revision.AssetID, &revision.Version, &changedBy, &revision.ChangedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if revision.ChangedBy, err = parseChangedBy(changedBy); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return revisions, nil
+}
+
+var GetAssetRevisionQuery = "SELECT asset_id, version, data, changed_by, changed_at FROM asset_history WHERE asset_type = $1 AND asset_id = $2 AND version = $3"
+
+// GetAssetRevision returns a replaced version of the asset with its data or sql.ErrNoRows.
+func (*PGFunctions) GetAssetRevision(ctx context.Context, assetType string, assetID *uuid.UUID, version int64, tx *sql.Tx) (*models.AssetRevision, error) {
+	revision := &models.AssetRevision{}
+	dataMap := []byte{}
+	var changedBy sql.NullString
+	err := tx.QueryRowContext(ctx, GetAssetRevisionQuery, assetType, assetID, version).Scan(&revision.AssetID, &revision.Version, &dataMap, &changedBy, &revision.ChangedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, TranslateError(err)
+	}
+
+	if err := json.Unmarshal(dataMap, &revision.DataMap); err != nil {
+		return nil, err
+	}
+
+	if revision.ChangedBy, err = parseChangedBy(changedBy); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+var DeleteAssetHistoryQuery = "DELETE FROM asset_history WHERE asset_type = $1 AND asset_id = $2"
+
+// deleteAssetHistory removes the replaced versions of a deleted asset.
+func deleteAssetHistory(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, DeleteAssetHistoryQuery, assetType, assetID)
+	return TranslateError(err)
+}
+
+func parseChangedBy(changedBy sql.NullString) (*uuid.UUID, error) {
+	if !changedBy.Valid {
+		return nil, nil
+	}
+
+	userID, err := uuid.Parse(changedBy.String)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid user in the asset history")
+	}
+	return &userID, nil
+}
@@ -0,0 +1,178 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/db/migrations"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// PostgreSQL database connector implementation
+// The connector owns a single pooled DB handle that is opened by BootstrapSystem
+// and shared by every transaction until Close is called.
+type PGConnector struct {
+	DBConnection string
+	// Directory of the migration files, the migrations embedded in the binary are used if empty.
+	MigrationDirectory string
+	// BootstrapSystem only opens the connection pool, the schema is migrated by the migrate command.
+	SkipMigration   bool
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	db *sql.DB
+}
+
+// PGFunctions represents the implementation of PostgreSQL data manipulation functions.
+// Errors are reported with the sentinel errors of mysqldb, so the callers do not depend on the backend.
+type PGFunctions struct {
+	DBConnector mysqldb.ConnectorCommon
+	UUIDImpl    models.UUIDCommon
+}
+
+func (*PGConnector) Commit(tx *sql.Tx) error {
+	return TranslateError(tx.Commit())
+}
+
+func (*PGConnector) Rollback(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+// Savepoint marks the current state of the transaction. Changes made after it can be undone
+// by RollbackToSavepoint without aborting the transaction itself.
+func (*PGConnector) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+func (*PGConnector) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+func (*PGConnector) ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+// open creates the connection pool if it does not exist yet.
+func (c *PGConnector) open() error {
+	if c.db != nil {
+		return nil
+	}
+
+	db, err := sql.Open("postgres", c.DBConnection)
+	if err != nil {
+		return err
+	}
+
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	c.db = db
+	return nil
+}
+
+func (c *PGConnector) BootstrapSystem() error {
+	if err := c.open(); err != nil {
+		return err
+	}
+	fmt.Printf("DB connection open\n")
+
+	if c.SkipMigration {
+		fmt.Printf("Automatic migration is disabled\n")
+		return nil
+	}
+
+	fmt.Printf("Executing PostgreSQL migration\n")
+	migrator, err := c.Migrator()
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for retryCount := 20; retryCount > 0; retryCount-- {
+		n, err = migrator.Up("")
+		if err == nil {
+			break
+		}
+		time.Sleep(1 * time.Second)
+		log.Printf("Failed to execute migration %s. Retrying...\n", err.Error())
+	}
+
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "Migration failed after multiple retries.")
+	}
+	fmt.Printf("Applied %d migrations!\n", n)
+	return nil
+}
+
+// Migrator opens the connection pool if needed and returns the migration manager working on it.
+func (c *PGConnector) Migrator() (*mysqldb.Migrator, error) {
+	if err := c.open(); err != nil {
+		return nil, err
+	}
+
+	source, err := migrations.Source(migrations.Postgres, c.MigrationDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mysqldb.Migrator{
+		DB:      c.db,
+		Dialect: "postgres",
+		Source:  source,
+	}, nil
+}
+
+// RollbackWithErrorStack rolls back the transaction and returns the translated form of errorStack.
+// Only functions that start the transaction themselves may use it.
+func RollbackWithErrorStack(tx *sql.Tx, errorStack error) error {
+	errorStack = TranslateError(errorStack)
+	if err := tx.Rollback(); err != nil {
+		return errors.Wrapf(errorStack, "Failed to rollback changes: %s", err.Error())
+	}
+	return errorStack
+}
+
+// ConnectSystem starts a new transaction on a connection taken from the pool.
+func (c *PGConnector) ConnectSystem(ctx context.Context) (*sql.Tx, error) {
+	if c.db == nil {
+		return nil, mysqldb.ErrConnectorNotOpen
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// Close releases the connection pool. It is safe to call on a connector that has not been bootstrapped.
+func (c *PGConnector) Close() error {
+	if c.db == nil {
+		return nil
+	}
+
+	err := c.db.Close()
+	c.db = nil
+	return err
+}
+
+// uuidArray converts the IDs to a parameter usable with "= ANY($1)".
+func uuidArray(IDs []uuid.UUID) interface{} {
+	list := make(pq.StringArray, len(IDs))
+	for i := range IDs {
+		list[i] = IDs[i].String()
+	}
+	return list
+}
@@ -0,0 +1,851 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func TestConnectSystem(t *testing.T) {
+	connector := &PGConnector{}
+
+	tx, err := connector.ConnectSystem(context.Background())
+	tests.CheckResult(tx == nil, true, err, mysqldb.ErrConnectorNotOpen, "not_bootstrapped", t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	connector.db = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	ctx := context.Background()
+	tx, err = connector.ConnectSystem(ctx)
+	tests.CheckResult(nil, nil, err, nil, "begin", t)
+	tests.CheckResult(nil, nil, connector.Savepoint(ctx, tx, "sp_1"), nil, "savepoint", t)
+	tests.CheckResult(nil, nil, connector.RollbackToSavepoint(ctx, tx, "sp_1"), nil, "rollback_to_savepoint", t)
+	tests.CheckResult(nil, nil, connector.Commit(tx), nil, "commit", t)
+	tests.CheckResult(nil, nil, connector.Close(), nil, "close", t)
+	tests.CheckResult(nil, nil, connector.Close(), nil, "close_twice", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+// createTestFunctions returns data functions working on a connector backed by sqlmock.
+func createTestFunctions() (*PGFunctions, *sql.DB, sqlmock.Sqlmock, error) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &PGFunctions{DBConnector: &PGConnector{db: db}}, db, mock, nil
+}
+
+func TestUsers(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	user := &models.User{
+		ID:         uuid.New(),
+		Name:       "testName",
+		Email:      "test@test.com",
+		Password:   []byte("testPass"),
+		SettingsID: uuid.New(),
+		AssetsID:   uuid.New(),
+	}
+	columns := []string{"id", "name", "email", "password", "user_settings_id", "user_assets_id"}
+	duplicateErr := &pq.Error{Code: CodeUniqueViolation, Detail: "Key (name)=(testName) already exists."}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(InsertUserQuery).
+		WithArgs(user.ID, user.Name, user.Email, string(user.Password), user.SettingsID, user.AssetsID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(InsertUserQuery).
+		WithArgs(user.ID, user.Name, user.Email, string(user.Password), user.SettingsID, user.AssetsID).
+		WillReturnError(duplicateErr)
+	// Native uuid columns are returned in their text form.
+	mock.ExpectQuery(GetUserByEmailQuery).WithArgs(user.Email).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(user.ID.String(), user.Name, user.Email, string(user.Password), user.SettingsID.String(), user.AssetsID.String()))
+	mock.ExpectQuery(GetUsersByIDsQuery).WithArgs(pq.StringArray{user.ID.String()}).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectExec(DeleteUserQuery).WithArgs(user.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	tx, err := functions.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction %s", err)
+		return
+	}
+
+	tests.CheckResult(nil, nil, functions.AddUser(ctx, user, tx), nil, "add_user", t)
+
+	err = functions.AddUser(ctx, user, tx)
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "name"), true, nil, nil, "add_user_duplicate", t)
+
+	output, err := functions.GetUser(ctx, mysqldb.ByEmail, " Test@test.com", tx)
+	tests.CheckResult(output, user, err, nil, "get_user_by_email", t)
+
+	users, err := functions.GetUsersByIDs(ctx, []uuid.UUID{user.ID}, tx)
+	tests.CheckResult(users == nil, true, err, sql.ErrNoRows, "get_users_missing", t)
+
+	err = functions.DeleteUser(ctx, &user.ID, tx)
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUserDeleted, "delete_missing_user", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestProducts(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	product := &models.Product{ID: uuid.New(), Name: "testProduct", DetailsID: uuid.New(), AssetsID: uuid.New()}
+	userID := uuid.New()
+	columns := []string{"id", "name", "product_details_id", "product_assets_id"}
+	duplicateErr := &pq.Error{Code: CodeUniqueViolation, Detail: "Key (name)=(testProduct) already exists."}
+	missingUserErr := &pq.Error{
+		Code:   CodeForeignKeyViolation,
+		Table:  "users_products",
+		Detail: "Key (users_id)=(" + userID.String() + `) is not present in table "users".`,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(AddProductQuery).WithArgs(product.ID, product.Name, product.DetailsID, product.AssetsID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(AddProductQuery).WithArgs(product.ID, product.Name, product.DetailsID, product.AssetsID).
+		WillReturnError(duplicateErr)
+	mock.ExpectExec(AddProductUsersQuery).WithArgs(userID, product.ID, 1).WillReturnError(missingUserErr)
+	mock.ExpectQuery(GetProductByNameQuery).WithArgs(product.Name).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(product.ID.String(), product.Name, product.DetailsID.String(), product.AssetsID.String()))
+	// The IDs are sent as a text array cast to uuid[].
+	mock.ExpectQuery(GetProductsByIDsQuery).WithArgs(pq.StringArray{product.ID.String()}).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(product.ID.String(), product.Name, product.DetailsID.String(), product.AssetsID.String()))
+	mock.ExpectQuery(GetUserProductIDsQuery).WithArgs(&userID).
+		WillReturnRows(sqlmock.NewRows([]string{"products_id", "privileges_id"}).AddRow(product.ID.String(), 1))
+	mock.ExpectExec(UpdateUsersProductsQuery).WithArgs(2, &userID, &product.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(DeleteProductUsersByProductIDQuery).WithArgs(&product.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteProductQuery).WithArgs(&product.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(GetProductByIDQuery).WithArgs(&product.ID).WillReturnError(sql.ErrNoRows)
+
+	ctx := context.Background()
+	tx, err := functions.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction %s", err)
+		return
+	}
+
+	tests.CheckResult(nil, nil, functions.AddProduct(ctx, product, tx), nil, "add_product", t)
+
+	err = functions.AddProduct(ctx, product, tx)
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "name"), true, nil, nil, "add_product_duplicate", t)
+
+	err = functions.AddProductUsers(ctx, &product.ID, &models.ProductUserIDs{UserMap: map[uuid.UUID]int{userID: 1}}, tx)
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "users_id"), true, nil, nil, "add_missing_user", t)
+
+	output, err := functions.GetProductByName(ctx, product.Name, tx)
+	tests.CheckResult(output, product, err, nil, "get_product_by_name", t)
+
+	products, err := functions.GetProductsByIDs(ctx, []uuid.UUID{product.ID}, tx)
+	tests.CheckResult(products, []models.Product{*product}, err, nil, "get_products_by_ids", t)
+
+	userProducts, err := functions.GetUserProductIDs(ctx, &userID, tx)
+	expected := &models.UserProductIDs{
+		ProductMap:     map[uuid.UUID]int{product.ID: 1},
+		ProductIDArray: []uuid.UUID{product.ID},
+	}
+	tests.CheckResult(userProducts, expected, err, nil, "get_user_product_ids", t)
+
+	err = functions.UpdateUsersProducts(ctx, &userID, &product.ID, 2, tx)
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUsersProductUpdate, "update_missing_product_user", t)
+
+	err = functions.DeleteProductUsersByProductID(ctx, &product.ID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_product_users", t)
+
+	err = functions.DeleteProduct(ctx, &product.ID, tx)
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoProductDeleted, "delete_missing_product", t)
+
+	output, err = functions.GetProductByID(ctx, &product.ID, tx)
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "get_missing_product", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestProjects(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	project := &models.Project{ID: uuid.New(), ProductID: uuid.New(), DetailsID: uuid.New(), AssetsID: uuid.New()}
+	userID := uuid.New()
+	columns := []string{"id", "products_id", "project_details_id", "project_assets_id"}
+	missingProductErr := &pq.Error{
+		Code:   CodeForeignKeyViolation,
+		Table:  "projects",
+		Detail: "Key (products_id)=(" + project.ProductID.String() + `) is not present in table "products".`,
+	}
+	referencedErr := &pq.Error{
+		Code:    CodeForeignKeyViolation,
+		Table:   "users_projects",
+		Message: `update or delete on table "projects" violates foreign key constraint "users_projects_projects_id_fkey" on table "users_projects"`,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(AddProjectQuery).WithArgs(project.ID, project.ProductID, project.DetailsID, project.AssetsID).
+		WillReturnError(missingProductErr)
+	mock.ExpectExec(AddProjectQuery).WithArgs(project.ID, project.ProductID, project.DetailsID, project.AssetsID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(AddProjectUsersQuery).WithArgs(userID, &project.ID, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(GetProjectByIDQuery).WithArgs(&project.ID).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(project.ID.String(), project.ProductID.String(), project.DetailsID.String(), project.AssetsID.String()))
+	mock.ExpectQuery(GetProjectsByIDsQuery).WithArgs(pq.StringArray{project.ID.String()}).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(GetProductProjectsQuery).WithArgs(&project.ProductID).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(project.ID.String(), project.ProductID.String(), project.DetailsID.String(), project.AssetsID.String()))
+	mock.ExpectQuery(GetUserProjectIDsQuery).WithArgs(&userID).
+		WillReturnRows(sqlmock.NewRows([]string{"projects_id", "privileges_id"}).AddRow(project.ID.String(), 1))
+	mock.ExpectExec(UpdateUsersProjectsQuery).WithArgs(2, &userID, &project.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteProjectQuery).WithArgs(&project.ID).WillReturnError(referencedErr)
+	mock.ExpectExec(DeleteProjectUserQuery).WithArgs(&project.ID, &userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteProjectUsersByProjectIDQuery).WithArgs(&project.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(DeleteProjectsByProductIDQuery).WithArgs(&project.ProductID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	tx, err := functions.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction %s", err)
+		return
+	}
+
+	err = functions.AddProject(ctx, project, tx)
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "products_id"), true, nil, nil, "add_project_missing_product", t)
+
+	tests.CheckResult(nil, nil, functions.AddProject(ctx, project, tx), nil, "add_project", t)
+
+	err = functions.AddProjectUsers(ctx, &project.ID, &models.ProjectUserIDs{UserMap: map[uuid.UUID]int{userID: 1}}, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_project_users", t)
+
+	output, err := functions.GetProjectByID(ctx, &project.ID, tx)
+	tests.CheckResult(output, project, err, nil, "get_project", t)
+
+	projects, err := functions.GetProjectsByIDs(ctx, []uuid.UUID{project.ID}, tx)
+	tests.CheckResult(projects == nil, true, err, sql.ErrNoRows, "get_projects_missing", t)
+
+	projects, err = functions.GetProductProjects(ctx, &project.ProductID, tx)
+	tests.CheckResult(projects, []models.Project{*project}, err, nil, "get_product_projects", t)
+
+	userProjects, err := functions.GetUserProjectIDs(ctx, &userID, tx)
+	expected := &models.UserProjectIDs{
+		ProjectMap:     map[uuid.UUID]int{project.ID: 1},
+		ProjectIDArray: []uuid.UUID{project.ID},
+	}
+	tests.CheckResult(userProjects, expected, err, nil, "get_user_project_ids", t)
+
+	err = functions.UpdateUsersProjects(ctx, &userID, &project.ID, 2, tx)
+	tests.CheckResult(nil, nil, err, nil, "update_project_user", t)
+
+	err = functions.DeleteProject(ctx, &project.ID, tx)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrRowReferenced), true, nil, nil, "delete_referenced_project", t)
+
+	err = functions.DeleteProjectUser(ctx, &project.ID, &userID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_project_user", t)
+
+	err = functions.DeleteProjectUsersByProjectID(ctx, &project.ID, tx)
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUserWithProject, "delete_missing_project_users", t)
+
+	err = functions.DeleteProjectsByProductID(ctx, &project.ProductID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_product_projects", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestProjectViewers(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	viewer := &models.ProjectViewer{UserID: uuid.New(), ViewerID: uuid.New(), ProjectID: uuid.New(), IsOwner: true}
+	columns := []string{"users_id", "viewer_id", "projects_id"}
+
+	mock.ExpectBegin()
+	// The owner of the viewer is recorded in the viewers table.
+	mock.ExpectExec(AddViewerQuery).WithArgs(viewer.ViewerID, viewer.UserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(AddProjectViewerQuery).WithArgs(viewer.UserID, viewer.ViewerID, viewer.ProjectID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(GetProjectViewerByViewerIDQuery).WithArgs(&viewer.ViewerID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(viewer.UserID.String(), viewer.ViewerID.String(), viewer.ProjectID.String()))
+	mock.ExpectQuery(GetViewerIDsByOwnerIDQuery).WithArgs(&viewer.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(viewer.ViewerID.String()))
+	mock.ExpectQuery(GetProjectViewerByProjectIDQuery).WithArgs(&viewer.ProjectID).WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectExec(DeleteProjectViewerByUserIDQuery).WithArgs(&viewer.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteViewerByOwnerQuery).WithArgs(&viewer.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(DeleteProjectViewerByProjectIDQuery).WithArgs(&viewer.ProjectID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	tx, err := functions.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction %s", err)
+		return
+	}
+
+	tests.CheckResult(nil, nil, functions.AddProjectViewer(ctx, viewer, tx), nil, "add_project_viewer", t)
+
+	// IsOwner is not stored in users_viewers.
+	viewers, err := functions.GetProjectViewersByViewerID(ctx, &viewer.ViewerID, tx)
+	expected := []models.ProjectViewer{{UserID: viewer.UserID, ViewerID: viewer.ViewerID, ProjectID: viewer.ProjectID}}
+	tests.CheckResult(viewers, expected, err, nil, "get_by_viewer_id", t)
+
+	viewerIDs, err := functions.GetViewerIDsByOwnerID(ctx, &viewer.UserID, tx)
+	tests.CheckResult(viewerIDs, []uuid.UUID{viewer.ViewerID}, err, nil, "get_owned_viewer_ids", t)
+
+	viewers, err = functions.GetProjectViewersByProjectID(ctx, &viewer.ProjectID, tx)
+	tests.CheckResult(viewers == nil, true, err, sql.ErrNoRows, "get_by_project_missing", t)
+
+	err = functions.DeleteProjectViewerByUserID(ctx, &viewer.UserID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_by_user_id", t)
+
+	err = functions.DeleteProjectViewerByProjectID(ctx, &viewer.ProjectID, tx)
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoProjectViewerDeleted, "delete_by_missing_project", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPrivileges(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	columns := []string{"id", "name", "description"}
+	owner := &models.Privilege{ID: 1, Name: "Owner", Description: "Can do anything"}
+
+	// The privilege functions run in their own transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery(GetPrivilegesQuery).WillReturnRows(sqlmock.NewRows(columns).AddRow(owner.ID, owner.Name, owner.Description))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(GetPrivilegeQuery).WithArgs("Owner").WillReturnRows(sqlmock.NewRows(columns).AddRow(owner.ID, owner.Name, owner.Description))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(GetPrivilegeQuery).WithArgs("Missing").WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(GetPrivilegesQuery).WillReturnError(&pq.Error{Code: CodeSerializationFailure})
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	privileges, err := functions.GetPrivileges(ctx)
+	tests.CheckResult(privileges, models.Privileges{owner}, err, nil, "get_privileges", t)
+
+	privilege, err := functions.GetPrivilege(ctx, "Owner")
+	tests.CheckResult(privilege, owner, err, nil, "get_privilege", t)
+
+	privilege, err = functions.GetPrivilege(ctx, "Missing")
+	tests.CheckResult(privilege == nil, true, err, sql.ErrNoRows, "get_missing_privilege", t)
+
+	_, err = functions.GetPrivileges(ctx)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrDeadlock), true, nil, nil, "serialization_failure", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	asset := &models.Asset{
+		ID:      uuid.New(),
+		DataMap: models.DataMap{"test": "data"},
+	}
+	data := `{"test":"data"}`
+	query := "UPDATE user_assets SET data = $1, version = version + 1 WHERE id = $2 RETURNING version"
+	versionQuery := "UPDATE user_assets SET data = $1, version = version + 1 WHERE id = $2 AND version = $3 RETURNING version"
+	historyQuery := "INSERT INTO asset_history (asset_type, asset_id, version, data, changed_by) SELECT $1, id, version, data, $2 FROM user_assets WHERE id = $3 FOR UPDATE"
+
+	// jsonb is sent as text.
+	mock.ExpectBegin()
+	mock.ExpectExec(historyQuery).WithArgs(mysqldb.UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(query).WithArgs(data, asset.ID).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectExec(historyQuery).WithArgs(mysqldb.UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(versionQuery).WithArgs(data, asset.ID, 1).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT version FROM user_assets WHERE id = $1").WithArgs(asset.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectExec(historyQuery).WithArgs(mysqldb.UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 0))
+	mock.ExpectQuery(query).WithArgs(data, asset.ID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT version FROM user_assets WHERE id = $1").WithArgs(asset.ID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, data, version FROM user_assets WHERE id = $1").WithArgs(&asset.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(asset.ID.String(), []byte(data), 2))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, data, version FROM user_assets WHERE id = $1 FOR UPDATE").WithArgs(&asset.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(asset.ID.String(), []byte(data), 2))
+	mock.ExpectExec(historyQuery).WithArgs(mysqldb.UserAssets, nil, &asset.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(query).WithArgs(`{"patched":true,"test":"data"}`, &asset.ID).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectQuery("SELECT id, data, version FROM user_assets WHERE id = $1 FOR UPDATE").WithArgs(&asset.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).AddRow(asset.ID.String(), []byte(data), 3))
+
+	ctx := context.Background()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	err = functions.UpdateAsset(ctx, mysqldb.UserAssets, asset, tx)
+	tests.CheckResult(asset.Version, int64(2), err, nil, "update_asset", t)
+
+	err = functions.UpdateAsset(ctx, mysqldb.UserAssets, &models.Asset{ID: asset.ID, DataMap: asset.DataMap, Version: 1}, tx)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrVersionConflict), true, nil, nil, "update_stale_version", t)
+
+	err = functions.UpdateAsset(ctx, mysqldb.UserAssets, &models.Asset{ID: asset.ID, DataMap: asset.DataMap}, tx)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrAssetMissing), true, nil, nil, "update_missing_asset", t)
+
+	err = tx.Commit()
+	tests.CheckResult(nil, nil, err, nil, "commit_updates", t)
+
+	output, err := functions.GetAsset(ctx, mysqldb.UserAssets, &asset.ID)
+	tests.CheckResult(output, asset, err, nil, "get_asset", t)
+
+	tx, err = db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	patch := &models.AssetPatch{Type: models.MergePatchType, Document: []byte(`{"patched": true}`), Version: 2}
+	output, err = functions.PatchAsset(ctx, mysqldb.UserAssets, &asset.ID, patch, tx)
+	expected := &models.Asset{ID: asset.ID, DataMap: models.DataMap{"test": "data", "patched": true}, Version: 3}
+	tests.CheckResult(output, expected, err, nil, "patch_asset", t)
+
+	_, err = functions.PatchAsset(ctx, mysqldb.UserAssets, &asset.ID, patch, tx)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrVersionConflict), true, nil, nil, "patch_stale_version", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetByDetails(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	product := models.Product{ID: uuid.New(), Name: "test", DetailsID: uuid.New(), AssetsID: uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(GetProductsByDetailsQuery).WithArgs(`{"is_free":true,"price":0}`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "product_details_id", "product_assets_id"}).
+			AddRow(product.ID.String(), product.Name, product.DetailsID.String(), product.AssetsID.String()))
+	mock.ExpectQuery(GetProjectsByDetailsQuery).WithArgs(`{"visibility":"Public"}`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "products_id", "project_details_id", "project_assets_id"}))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	products, err := functions.GetProductsByDetails(ctx, models.DetailsFilter{models.IsFree: true, "price": float64(0)}, tx)
+	tests.CheckResult(products, []models.Product{product}, err, nil, "get_products_by_details", t)
+
+	projects, err := functions.GetProjectsByDetails(ctx, models.DetailsFilter{"visibility": models.Public}, tx)
+	tests.CheckResult(projects == nil, true, err, sql.ErrNoRows, "no_matching_project", t)
+
+	_, err = functions.GetProjectsByDetails(ctx, models.DetailsFilter{}, tx)
+	tests.CheckResult(errors.Is(err, models.ErrInvalidFilter), true, nil, nil, "empty_filter", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAssetHistory(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	assetID := uuid.New()
+	userID := uuid.New()
+	changedAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT asset_id, version, changed_by, changed_at FROM asset_history WHERE asset_type = $1 AND asset_id = $2 ORDER BY version DESC").
+		WithArgs(mysqldb.ProjectAssets, &assetID).
+		WillReturnRows(sqlmock.NewRows([]string{"asset_id", "version", "changed_by", "changed_at"}).
+			AddRow(assetID.String(), 2, userID.String(), changedAt).
+			AddRow(assetID.String(), 1, nil, changedAt))
+	mock.ExpectQuery("SELECT asset_id, version, data, changed_by, changed_at FROM asset_history WHERE asset_type = $1 AND asset_id = $2 AND version = $3").
+		WithArgs(mysqldb.ProjectAssets, &assetID, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"asset_id", "version", "data", "changed_by", "changed_at"}).
+			AddRow(assetID.String(), 2, []byte(`{"color":"red"}`), userID.String(), changedAt))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	revisions, err := functions.GetAssetHistory(ctx, mysqldb.ProjectAssets, &assetID, tx)
+	expected := []models.AssetRevision{
+		{AssetID: assetID, Version: 2, ChangedAt: changedAt, ChangedBy: &userID},
+		{AssetID: assetID, Version: 1, ChangedAt: changedAt},
+	}
+	tests.CheckResult(revisions, expected, err, nil, "history", t)
+
+	revision, err := functions.GetAssetRevision(ctx, mysqldb.ProjectAssets, &assetID, 2, tx)
+	expectedRevision := &models.AssetRevision{AssetID: assetID, Version: 2, DataMap: models.DataMap{"color": "red"}, ChangedAt: changedAt, ChangedBy: &userID}
+	tests.CheckResult(revision, expectedRevision, err, nil, "revision", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	userID := uuid.New()
+	deletedAt := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL").
+		WithArgs(deletedAt, &userID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, deleted_at FROM users WHERE deleted_at <= $1 ORDER BY deleted_at").
+		WithArgs(deletedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(userID.String(), deletedAt))
+	mock.ExpectExec("UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at = $2").
+		WithArgs(&userID, deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	err = functions.TrashEntity(ctx, mysqldb.Users, &userID, deletedAt, tx)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrEntityMissing), true, nil, nil, "trash_missing_user", t)
+
+	entries, err := functions.GetTrash(ctx, mysqldb.Users, deletedAt, tx)
+	tests.CheckResult(entries, []models.TrashEntry{{Type: mysqldb.Users, ID: userID, DeletedAt: deletedAt}}, err, nil, "trash", t)
+
+	err = functions.RestoreEntity(ctx, mysqldb.Users, &userID, deletedAt, tx)
+	tests.CheckResult(nil, nil, err, nil, "restore_user", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAuditEvents(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	actor := uuid.New()
+	productID := uuid.New()
+	occurredAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	event := &models.AuditEvent{
+		OccurredAt: occurredAt,
+		Actor:      &actor,
+		Action:     models.ActionCreate,
+		EntityType: mysqldb.Products,
+		EntityID:   productID,
+		After:      models.DataMap{"name": "testProduct"},
+	}
+
+	// jsonb is sent as text.
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO audit_events (occurred_at, actor, action, entity_type, entity_id, before_data, after_data) VALUES ($1, $2, $3, $4, $5, $6, $7)").
+		WithArgs(occurredAt, &actor, models.ActionCreate, mysqldb.Products, productID, nil, `{"name":"testProduct"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, occurred_at, actor, action, entity_type, entity_id, before_data, after_data FROM audit_events WHERE entity_type = $1 AND id < $2 ORDER BY id DESC LIMIT $3").
+		WithArgs(mysqldb.Products, 2, models.DefaultAuditLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "actor", "action", "entity_type", "entity_id", "before_data", "after_data"}).
+			AddRow(1, occurredAt, actor.String(), models.ActionCreate, mysqldb.Products, productID.String(), nil, []byte(`{"name":"testProduct"}`)))
+	mock.ExpectQuery("SELECT id, occurred_at, actor, action, entity_type, entity_id, before_data, after_data FROM audit_events WHERE id > $1 ORDER BY id LIMIT $2").
+		WithArgs(0, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "actor", "action", "entity_type", "entity_id", "before_data", "after_data"}).
+			AddRow(1, occurredAt, actor.String(), models.ActionCreate, mysqldb.Products, productID.String(), nil, []byte(`{"name":"testProduct"}`)))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	err = functions.AddAuditEvent(ctx, event, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_event", t)
+
+	events, err := functions.GetAuditEvents(ctx, &models.AuditFilter{EntityType: mysqldb.Products, BeforeID: 2, Limit: models.DefaultAuditLimit}, tx)
+	event.ID = 1
+	tests.CheckResult(events, []models.AuditEvent{*event}, err, nil, "events", t)
+
+	events, err = functions.GetAuditEventsAfter(ctx, 0, 10, tx)
+	tests.CheckResult(events, []models.AuditEvent{*event}, err, nil, "events_after", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestOutboxEvents(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	actor := uuid.New()
+	userID := uuid.New()
+	occurredAt := time.Date(2021, 8, 15, 12, 0, 0, 0, time.UTC)
+	event := &models.DomainEvent{
+		Type:        models.EventUserCreated,
+		OccurredAt:  occurredAt,
+		Actor:       &actor,
+		AggregateID: userID,
+		Payload:     models.DataMap{"name": "testUser"},
+	}
+
+	// jsonb is sent as text.
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO outbox_events (event_type, occurred_at, actor, aggregate_id, payload) VALUES ($1, $2, $3, $4, $5)").
+		WithArgs(models.EventUserCreated, occurredAt, &actor, userID, `{"name":"testUser"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, event_type, occurred_at, actor, aggregate_id, payload, attempts FROM outbox_events ORDER BY id LIMIT $1").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "occurred_at", "actor", "aggregate_id", "payload", "attempts"}).
+			AddRow(1, models.EventUserCreated, occurredAt, actor.String(), userID.String(), []byte(`{"name":"testUser"}`), 0))
+	mock.ExpectExec("UPDATE outbox_events SET attempts = attempts + 1, last_error = $1 WHERE id = $2").
+		WithArgs("connection refused", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM outbox_events WHERE id = $1").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	err = functions.AddOutboxEvent(ctx, event, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_event", t)
+
+	events, err := functions.GetOutboxEvents(ctx, 10, tx)
+	event.ID = 1
+	tests.CheckResult(events, []models.DomainEvent{*event}, err, nil, "events", t)
+
+	err = functions.RecordOutboxFailure(ctx, 1, "connection refused", tx)
+	tests.CheckResult(nil, nil, err, nil, "record_failure", t)
+
+	err = functions.DeleteOutboxEvent(ctx, 1, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_event", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	subscription := &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "https://billing.example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []string{models.EventUserCreated},
+		CreatedAt:  createdAt,
+	}
+	userID := uuid.New()
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		Event: models.DomainEvent{
+			ID:          7,
+			Type:        models.EventUserCreated,
+			OccurredAt:  createdAt,
+			AggregateID: userID,
+			Payload:     models.DataMap{},
+		},
+		Status:        models.WebhookPending,
+		NextAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+	}
+	payload := `{"id":7,"type":"user.created","occurred_at":"2021-09-01T12:00:00Z","actor":null,"aggregate_id":"` +
+		userID.String() + `","payload":{}}`
+
+	// jsonb is sent as text.
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO webhook_subscriptions (id, url, secret, event_types, created_at) VALUES ($1, $2, $3, $4, $5)").
+		WithArgs(subscription.ID, subscription.URL, subscription.Secret, `["user.created"]`, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, url, secret, event_types, created_at FROM webhook_subscriptions WHERE id = $1").
+		WithArgs(&subscription.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event_types", "created_at"}).
+			AddRow(subscription.ID.String(), subscription.URL, subscription.Secret, []byte(`["user.created"]`), createdAt))
+	mock.ExpectExec("INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)").
+		WithArgs(subscription.ID, int64(7), models.EventUserCreated, payload, models.WebhookPending, createdAt, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, subscription_id, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, created_at FROM webhook_deliveries WHERE status = $1 AND next_attempt_at <= $2 ORDER BY id LIMIT $3").
+		WithArgs(models.WebhookPending, createdAt, 10).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "subscription_id", "payload", "status", "attempts", "next_attempt_at",
+			"last_attempt_at", "last_status_code", "last_error", "created_at"}).
+			AddRow(1, subscription.ID.String(), []byte(payload), models.WebhookPending, 0, createdAt, nil, 0, nil, createdAt))
+	mock.ExpectExec("DELETE FROM webhook_deliveries WHERE subscription_id = $1").
+		WithArgs(&subscription.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM webhook_subscriptions WHERE id = $1").
+		WithArgs(&subscription.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	err = functions.AddWebhookSubscription(ctx, subscription, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_subscription", t)
+
+	found, err := functions.GetWebhookSubscription(ctx, &subscription.ID, tx)
+	tests.CheckResult(found, subscription, err, nil, "get_subscription", t)
+
+	err = functions.AddWebhookDelivery(ctx, delivery, tx)
+	tests.CheckResult(nil, nil, err, nil, "add_delivery", t)
+
+	deliveries, err := functions.GetDueWebhookDeliveries(ctx, createdAt, 10, tx)
+	delivery.ID = 1
+	tests.CheckResult(deliveries, []models.WebhookDelivery{*delivery}, err, nil, "due_deliveries", t)
+
+	err = functions.DeleteWebhookDeliveries(ctx, &subscription.ID, tx)
+	tests.CheckResult(nil, nil, err, nil, "delete_deliveries", t)
+
+	err = functions.DeleteWebhookSubscription(ctx, &subscription.ID, tx)
+	tests.CheckResult(nil, nil, err, mysqldb.ErrWebhookSubscriptionMissing, "delete_missing", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBackup(t *testing.T) {
+	functions, db, mock, err := createTestFunctions()
+	if err != nil {
+		t.Errorf("Failed to create test data %s", err)
+		return
+	}
+	defer db.Close()
+
+	viewers, err := mysqldb.GetBackupTable("viewers")
+	tests.CheckResult(nil, nil, err, nil, "viewers_table", t)
+
+	viewerID := uuid.New()
+	ownerID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, owner_id FROM viewers ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id"}).AddRow(viewerID.String(), ownerID.String()))
+	mock.ExpectExec("INSERT INTO viewers (id, owner_id) VALUES ($1, $2)").
+		WithArgs(viewerID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("Failed to setup DB transaction: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+	rows := make([]models.BackupRow, 0)
+	err = functions.DumpTable(ctx, viewers, func(r models.BackupRow) error {
+		rows = append(rows, r)
+		return nil
+	}, tx)
+	viewer := models.BackupRow{"id": viewerID, "owner_id": ownerID}
+	tests.CheckResult(rows, []models.BackupRow{viewer}, err, nil, "dump_viewers", t)
+
+	err = functions.RestoreRow(ctx, viewers, viewer, tx)
+	tests.CheckResult(nil, nil, err, nil, "restore_viewer", t)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
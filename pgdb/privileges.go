@@ -0,0 +1,64 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+)
+
+var GetPrivilegesQuery = "SELECT id, name, description FROM privileges"
+
+func (f *PGFunctions) GetPrivileges(ctx context.Context) (models.Privileges, error) {
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, GetPrivilegesQuery)
+	if err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	defer rows.Close()
+
+	privileges := make(models.Privileges, 0)
+	for rows.Next() {
+		privilege := &models.Privilege{}
+		err := rows.Scan(&privilege.ID, &privilege.Name, &privilege.Description)
+		if err != nil {
+			return nil, RollbackWithErrorStack(tx, err)
+		}
+		privileges = append(privileges, privilege)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	return privileges, TranslateError(tx.Commit())
+}
+
+var GetPrivilegeQuery = "SELECT id, name, description FROM privileges WHERE name = $1"
+
+func (f *PGFunctions) GetPrivilege(ctx context.Context, name string) (*models.Privilege, error) {
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	privilege := models.Privilege{}
+	err = tx.QueryRowContext(ctx, GetPrivilegeQuery, name).Scan(&privilege.ID, &privilege.Name, &privilege.Description)
+	switch {
+	case err == sql.ErrNoRows:
+		if err := tx.Commit(); err != nil {
+			return nil, TranslateError(err)
+		}
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, RollbackWithErrorStack(tx, err)
+	default:
+	}
+
+	return &privilege, TranslateError(tx.Commit())
+}
@@ -0,0 +1,255 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var AddProductUsersQuery = "INSERT INTO users_products (users_id, products_id, privileges_id) VALUES ($1, $2, $3)"
+
+func (*PGFunctions) AddProductUsers(ctx context.Context, productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
+	for userID, privilege := range productUsers.UserMap {
+		result, err := tx.ExecContext(ctx, AddProductUsersQuery, userID, productID, privilege)
+		if err != nil {
+			return TranslateError(err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return TranslateError(err)
+		}
+
+		if affected == 0 {
+			return mysqldb.ErrNoProductUserAdded
+		}
+	}
+	return nil
+}
+
+var DeleteProductUsersByProductIDQuery = "DELETE FROM users_products WHERE products_id = $1"
+
+func (*PGFunctions) DeleteProductUsersByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductUsersByProductIDQuery, productID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUserWithProduct
+	}
+
+	return nil
+}
+
+var UpdateUsersProductsQuery = "UPDATE users_products SET privileges_id = $1 WHERE users_id = $2 AND products_id = $3"
+
+func (*PGFunctions) UpdateUsersProducts(ctx context.Context, userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, UpdateUsersProductsQuery, privilege, userID, productID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUsersProductUpdate
+	}
+
+	return nil
+}
+
+var AddProductQuery = "INSERT INTO products (id, name, product_details_id, product_assets_id) VALUES ($1, $2, $3, $4)"
+
+func (*PGFunctions) AddProduct(ctx context.Context, product *models.Product, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, AddProductQuery, product.ID, product.Name, product.DetailsID, product.AssetsID)
+	if err != nil {
+		return TranslateError(err)
+	}
+	return nil
+}
+
+var GetProductByIDQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE id = $1 AND deleted_at IS NULL"
+
+func (*PGFunctions) GetProductByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
+	product := models.Product{}
+	query := tx.QueryRowContext(ctx, GetProductByIDQuery, ID)
+	err := query.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	return &product, nil
+}
+
+var GetProductsByIDsQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL"
+
+func (*PGFunctions) GetProductsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
+	rows, err := tx.QueryContext(ctx, GetProductsByIDsQuery, uuidArray(IDs))
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		product := models.Product{}
+		err := rows.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		products = append(products, product)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(products) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return products, nil
+}
+
+var GetProductsByDetailsQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE deleted_at IS NULL AND product_details_id IN (SELECT id FROM product_details WHERE data @> $1::jsonb)"
+
+// GetProductsByDetails returns the products whose details contain the filter, the containment uses the GIN index of the details.
+func (*PGFunctions) GetProductsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Product, error) {
+	document, err := detailsDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, GetProductsByDetailsQuery, document)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		product := models.Product{}
+		err := rows.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		products = append(products, product)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(products) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return products, nil
+}
+
+// detailsDocument returns the jsonb document the details matching filter contain.
+// The values of the filter are scalars, so containment is the same as equality.
+func detailsDocument(filter models.DetailsFilter) (string, error) {
+	if len(filter) == 0 {
+		return "", errors.WithMessage(models.ErrInvalidFilter, "empty filter")
+	}
+
+	if err := filter.Validate(); err != nil {
+		return "", err
+	}
+
+	binary, err := json.Marshal(filter)
+	if err != nil {
+		return "", err
+	}
+	return string(binary), nil
+}
+
+var GetUserProductIDsQuery = "SELECT products_id, privileges_id FROM users_products WHERE users_id = $1"
+
+func (*PGFunctions) GetUserProductIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetUserProductIDsQuery, userID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+	userProducts := models.UserProductIDs{
+		ProductMap:     make(map[uuid.UUID]int),
+		ProductIDArray: make([]uuid.UUID, 0),
+	}
+	for rows.Next() {
+		productID := uuid.UUID{}
+		privilege := -1
+		err := rows.Scan(&productID, &privilege)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		userProducts.ProductMap[productID] = privilege
+		userProducts.ProductIDArray = append(userProducts.ProductIDArray, productID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return &userProducts, nil
+}
+
+var GetProductByNameQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE name = $1 AND deleted_at IS NULL"
+
+func (*PGFunctions) GetProductByName(ctx context.Context, name string, tx *sql.Tx) (*models.Product, error) {
+	product := models.Product{}
+
+	query := tx.QueryRowContext(ctx, GetProductByNameQuery, name)
+
+	err := query.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	return &product, nil
+}
+
+var DeleteProductQuery = "DELETE FROM products WHERE id = $1"
+
+func (*PGFunctions) DeleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductQuery, productID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoProductDeleted
+	}
+
+	return nil
+}
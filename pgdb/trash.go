@@ -0,0 +1,114 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var TrashEntityQuery = "UPDATE %s SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL"
+
+// TrashEntity marks the entity deleted at deletedAt, so the Get queries do not return it any more.
+// mysqldb.ErrEntityMissing is returned if the entity does not exist or has already been deleted.
+func (*PGFunctions) TrashEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(TrashEntityQuery, entityType), deletedAt, ID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errors.WithMessage(mysqldb.ErrEntityMissing, entityType)
+	}
+	return nil
+}
+
+var RestoreEntityQuery = "UPDATE %s SET deleted_at = NULL WHERE id = $1 AND deleted_at = $2"
+
+// RestoreEntity clears the delete mark of the entity if it has been deleted at deletedAt.
+// mysqldb.ErrEntityMissing is returned if the entity is not in the trash or has been deleted at another time.
+func (*PGFunctions) RestoreEntity(ctx context.Context, entityType string, ID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(RestoreEntityQuery, entityType), ID, deletedAt)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errors.WithMessage(mysqldb.ErrEntityMissing, entityType)
+	}
+	return nil
+}
+
+var TrashProjectsByProductIDQuery = "UPDATE projects SET deleted_at = $1 WHERE products_id = $2 AND deleted_at IS NULL"
+
+// TrashProjectsByProductID marks the projects of the product deleted at deletedAt.
+func (*PGFunctions) TrashProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, TrashProjectsByProductIDQuery, deletedAt, productID)
+	return TranslateError(err)
+}
+
+var RestoreProjectsByProductIDQuery = "UPDATE projects SET deleted_at = NULL WHERE products_id = $1 AND deleted_at = $2"
+
+// RestoreProjectsByProductID restores the projects of the product deleted at deletedAt, together with the product.
+func (*PGFunctions) RestoreProjectsByProductID(ctx context.Context, productID *uuid.UUID, deletedAt time.Time, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, RestoreProjectsByProductIDQuery, productID, deletedAt)
+	return TranslateError(err)
+}
+
+var GetTrashQuery = "SELECT id, deleted_at FROM %s WHERE deleted_at <= $1 ORDER BY deleted_at"
+
+// GetTrash lists the entities of entityType deleted at or before deletedBefore, the oldest first.
+func (*PGFunctions) GetTrash(ctx context.Context, entityType string, deletedBefore time.Time, tx *sql.Tx) ([]models.TrashEntry, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(GetTrashQuery, entityType), deletedBefore)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.TrashEntry, 0)
+	for rows.Next() {
+		entry := models.TrashEntry{Type: entityType}
+		if err := rows.Scan(&entry.ID, &entry.DeletedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(entries) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return entries, nil
+}
+
+var GetTrashEntryQuery = "SELECT id, deleted_at FROM %s WHERE id = $1 AND deleted_at IS NOT NULL"
+
+// GetTrashEntry returns the entity if it is in the trash or sql.ErrNoRows.
+func (*PGFunctions) GetTrashEntry(ctx context.Context, entityType string, ID *uuid.UUID, tx *sql.Tx) (*models.TrashEntry, error) {
+	entry := &models.TrashEntry{Type: entityType}
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(GetTrashEntryQuery, entityType), ID).Scan(&entry.ID, &entry.DeletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, TranslateError(err)
+	}
+	return entry, nil
+}
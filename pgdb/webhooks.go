@@ -0,0 +1,225 @@
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+var AddWebhookSubscriptionQuery = "INSERT INTO webhook_subscriptions (id, url, secret, event_types, created_at) VALUES ($1, $2, $3, $4, $5)"
+
+func (*PGFunctions) AddWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	// jsonb is sent as text, []byte parameters would be sent as bytea.
+	_, err = tx.ExecContext(ctx, AddWebhookSubscriptionQuery, subscription.ID, subscription.URL, subscription.Secret, string(eventTypes), subscription.CreatedAt)
+	return TranslateError(err)
+}
+
+var UpdateWebhookSubscriptionQuery = "UPDATE webhook_subscriptions SET url = $1, secret = $2, event_types = $3 WHERE id = $4"
+
+// UpdateWebhookSubscription replaces the URL, the secret and the event types of the subscription.
+// The caller has to check that the subscription exists, like with the MySQL backend.
+func (*PGFunctions) UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription, tx *sql.Tx) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, UpdateWebhookSubscriptionQuery, subscription.URL, subscription.Secret, string(eventTypes), subscription.ID)
+	return TranslateError(err)
+}
+
+var DeleteWebhookSubscriptionQuery = "DELETE FROM webhook_subscriptions WHERE id = $1"
+
+// DeleteWebhookSubscription returns ErrWebhookSubscriptionMissing if the subscription does not exist.
+// The deliveries of the subscription have to be deleted first.
+func (*PGFunctions) DeleteWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteWebhookSubscriptionQuery, ID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrWebhookSubscriptionMissing
+	}
+	return nil
+}
+
+var GetWebhookSubscriptionQuery = "SELECT id, url, secret, event_types, created_at FROM webhook_subscriptions WHERE id = $1"
+
+// GetWebhookSubscription returns sql.ErrNoRows if the subscription does not exist.
+func (*PGFunctions) GetWebhookSubscription(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+	var eventTypes []byte
+	err := tx.QueryRowContext(ctx, GetWebhookSubscriptionQuery, ID).Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.CreatedAt)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+var GetWebhookSubscriptionsQuery = "SELECT id, url, secret, event_types, created_at FROM webhook_subscriptions ORDER BY created_at, id"
+
+// GetWebhookSubscriptions lists the subscriptions, the oldest first. sql.ErrNoRows is returned if there are none.
+func (*PGFunctions) GetWebhookSubscriptions(ctx context.Context, tx *sql.Tx) ([]models.WebhookSubscription, error) {
+	rows, err := tx.QueryContext(ctx, GetWebhookSubscriptionsQuery)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+
+	subscriptions := make([]models.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription := models.WebhookSubscription{}
+		var eventTypes []byte
+		if err := rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.CreatedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return subscriptions, nil
+}
+
+var AddWebhookDeliveryQuery = "INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+
+// AddWebhookDelivery queues the delivery of the event to the subscription.
+// An event already queued for the subscription fails with ErrDuplicateEntry.
+func (*PGFunctions) AddWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	payload, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, AddWebhookDeliveryQuery, delivery.SubscriptionID, delivery.Event.ID, delivery.Event.Type, string(payload), delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt)
+	return TranslateError(err)
+}
+
+var webhookDeliveryColumns = "id, subscription_id, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, created_at FROM webhook_deliveries"
+
+var GetWebhookDeliveriesQuery = "SELECT " + webhookDeliveryColumns + " WHERE subscription_id = $1 AND id < $2 ORDER BY id DESC LIMIT $3"
+
+// GetWebhookDeliveries lists the deliveries of the subscription with an ID below beforeID, the latest first.
+// sql.ErrNoRows is returned if there are none.
+func (*PGFunctions) GetWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, beforeID int64, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	rows, err := tx.QueryContext(ctx, GetWebhookDeliveriesQuery, subscriptionID, beforeID, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+var GetDueWebhookDeliveriesQuery = "SELECT " + webhookDeliveryColumns + " WHERE status = $1 AND next_attempt_at <= $2 ORDER BY id LIMIT $3"
+
+// GetDueWebhookDeliveries lists at most limit pending deliveries due at dueAt, the oldest first.
+// sql.ErrNoRows is returned if there are none.
+func (*PGFunctions) GetDueWebhookDeliveries(ctx context.Context, dueAt time.Time, limit int, tx *sql.Tx) ([]models.WebhookDelivery, error) {
+	rows, err := tx.QueryContext(ctx, GetDueWebhookDeliveriesQuery, models.WebhookPending, dueAt, limit)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+var UpdateWebhookDeliveryQuery = "UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4, last_status_code = $5, last_error = $6 WHERE id = $7"
+
+// UpdateWebhookDelivery records the result of a delivery attempt.
+func (*PGFunctions) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, tx *sql.Tx) error {
+	_, err := tx.ExecContext(
+		ctx,
+		UpdateWebhookDeliveryQuery,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.LastStatusCode,
+		nullString(delivery.LastError),
+		delivery.ID)
+	return TranslateError(err)
+}
+
+var DeleteWebhookDeliveriesQuery = "DELETE FROM webhook_deliveries WHERE subscription_id = $1"
+
+// DeleteWebhookDeliveries deletes the delivery log of the subscription.
+func (*PGFunctions) DeleteWebhookDeliveries(ctx context.Context, subscriptionID *uuid.UUID, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, DeleteWebhookDeliveriesQuery, subscriptionID)
+	return TranslateError(err)
+}
+
+// nullString stores the empty string as NULL.
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := models.WebhookDelivery{}
+		var payload []byte
+		var lastAttemptAt sql.NullTime
+		var lastError sql.NullString
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&lastAttemptAt,
+			&delivery.LastStatusCode,
+			&lastError,
+			&delivery.CreatedAt); err != nil {
+			return nil, TranslateError(err)
+		}
+
+		if err := json.Unmarshal(payload, &delivery.Event); err != nil {
+			return nil, err
+		}
+		if lastAttemptAt.Valid {
+			delivery.LastAttemptAt = &lastAttemptAt.Time
+		}
+		delivery.LastError = lastError.String
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(deliveries) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return deliveries, nil
+}
@@ -1,10 +1,14 @@
 package restcontrollers
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 func (c *RESTController) updateUserSettings(w ResponseWriter, r *Request) {
@@ -21,9 +25,21 @@ func (c *RESTController) updateUserSettings(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.UpdateUserSettings(userData)
+	if err := applyIfMatch(r, userData.Settings); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.DBController.UpdateUserSettings(r.Context(), userData)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoUserSetttingsUpdate.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrNoUserSetttingsUpdate) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -31,6 +47,7 @@ func (c *RESTController) updateUserSettings(w ResponseWriter, r *Request) {
 		return
 	}
 
+	w.setETag(userData.Settings)
 	w.writeData(DataOK, http.StatusOK)
 }
 
@@ -45,11 +62,24 @@ func (c *RESTController) updateUserAssets(w ResponseWriter, r *Request) {
 	userData, err := parseUserDataAssets(data)
 	if err != nil {
 		w.writeError(err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	err = c.DBController.UpdateUserAssets(userData)
+	if err := applyIfMatch(r, userData.Assets); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.DBController.UpdateUserAssets(r.Context(), userData)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoUserAssetsUpdate.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrNoUserAssetsUpdate) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -57,6 +87,7 @@ func (c *RESTController) updateUserAssets(w ResponseWriter, r *Request) {
 		return
 	}
 
+	w.setETag(userData.Assets)
 	w.writeData(DataOK, http.StatusOK)
 }
 
@@ -74,9 +105,21 @@ func (c *RESTController) updateProductDetails(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.UpdateProductDetails(productData)
+	if err := applyIfMatch(r, productData.Details); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.DBController.UpdateProductDetails(r.Context(), productData)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoProductDetailUpdate.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrNoProductDetailUpdate) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -84,12 +127,13 @@ func (c *RESTController) updateProductDetails(w ResponseWriter, r *Request) {
 		return
 	}
 
-	statusCode, err := c.validateProduct(productData)
+	statusCode, err := c.validateProduct(r.Context(), productData)
 	if err != nil {
 		w.writeError(err.Error(), statusCode)
 		return
 	}
 
+	w.setETag(productData.Details)
 	w.writeData(DataOK, statusCode)
 }
 
@@ -107,9 +151,21 @@ func (c *RESTController) updateProductAssets(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.UpdateProductAssets(productData)
+	if err := applyIfMatch(r, productData.Assets); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.DBController.UpdateProductAssets(r.Context(), productData)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoProductAssetUpdate.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrNoProductAssetUpdate) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -117,12 +173,13 @@ func (c *RESTController) updateProductAssets(w ResponseWriter, r *Request) {
 		return
 	}
 
-	statusCode, err := c.validateProduct(productData)
+	statusCode, err := c.validateProduct(r.Context(), productData)
 	if err != nil {
 		w.writeError(err.Error(), statusCode)
 		return
 	}
 
+	w.setETag(productData.Assets)
 	w.writeData(DataOK, statusCode)
 }
 
@@ -140,9 +197,21 @@ func (c *RESTController) updateProjectDetails(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.UpdateProjectDetails(projectData)
+	if err := applyIfMatch(r, projectData.Details); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.DBController.UpdateProjectDetails(r.Context(), projectData)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoProjectDetailsUpdate.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrNoProjectDetailsUpdate) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -150,12 +219,13 @@ func (c *RESTController) updateProjectDetails(w ResponseWriter, r *Request) {
 		return
 	}
 
-	statusCode, err := c.validateProject(projectData)
+	statusCode, err := c.validateProject(r.Context(), projectData)
 	if err != nil {
 		w.writeError(err.Error(), statusCode)
 		return
 	}
 
+	w.setETag(projectData.Details)
 	w.writeData(DataOK, statusCode)
 }
 
@@ -170,11 +240,24 @@ func (c *RESTController) updateProjectAssets(w ResponseWriter, r *Request) {
 	projectData, err := parseProjectData(data)
 	if err != nil {
 		w.writeError(err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	err = c.DBController.UpdateProjectAssets(projectData)
+	if err := applyIfMatch(r, projectData.Assets); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.DBController.UpdateProjectAssets(r.Context(), projectData)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoProjectAssetsUpdate.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrNoProjectAssetsUpdate) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -182,11 +265,86 @@ func (c *RESTController) updateProjectAssets(w ResponseWriter, r *Request) {
 		return
 	}
 
-	statusCode, err := c.validateProject(projectData)
+	statusCode, err := c.validateProject(r.Context(), projectData)
 	if err != nil {
 		w.writeError(err.Error(), statusCode)
 		return
 	}
 
+	w.setETag(projectData.Assets)
 	w.writeData(DataOK, statusCode)
 }
+
+// patchAsset applies the patch document of the request with patch and returns the patched asset.
+// notFound lists the errors of patch returned if the entity or its asset does not exist.
+func (c *RESTController) patchAsset(
+	w ResponseWriter,
+	r *Request,
+	patch func(ctx context.Context, ID *uuid.UUID, patch *models.AssetPatch) (*models.Asset, error),
+	notFound ...error) {
+	ID, assetPatch, err := decodePatch(w, r)
+	if err != nil {
+		if errors.Is(err, errUnsupportedPatchType) {
+			w.writeError(err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	asset, err := patch(r.Context(), ID, assetPatch)
+	if err != nil {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, models.ErrPatchFailed) {
+			w.writeError(err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		for _, notFoundErr := range notFound {
+			if errors.Is(err, notFoundErr) {
+				w.writeError(err.Error(), http.StatusAccepted)
+				return
+			}
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.setETag(asset)
+	w.writeData(asset, http.StatusOK)
+}
+
+func (c *RESTController) patchUserSettings(w ResponseWriter, r *Request) {
+	log.Println("Patch user settings")
+	c.patchAsset(w, r, c.DBController.PatchUserSettings, dbcontrollers.ErrUserNotFound, dbcontrollers.ErrNoUserSetttingsUpdate)
+}
+
+func (c *RESTController) patchUserAssets(w ResponseWriter, r *Request) {
+	log.Println("Patch user assets")
+	c.patchAsset(w, r, c.DBController.PatchUserAssets, dbcontrollers.ErrUserNotFound, dbcontrollers.ErrNoUserAssetsUpdate)
+}
+
+func (c *RESTController) patchProductDetails(w ResponseWriter, r *Request) {
+	log.Println("Patch product details")
+	c.patchAsset(w, r, c.DBController.PatchProductDetails, dbcontrollers.ErrProductNotFound, dbcontrollers.ErrNoProductDetailUpdate)
+}
+
+func (c *RESTController) patchProductAssets(w ResponseWriter, r *Request) {
+	log.Println("Patch product assets")
+	c.patchAsset(w, r, c.DBController.PatchProductAssets, dbcontrollers.ErrProductNotFound, dbcontrollers.ErrNoProductAssetUpdate)
+}
+
+func (c *RESTController) patchProjectDetails(w ResponseWriter, r *Request) {
+	log.Println("Patch project details")
+	c.patchAsset(w, r, c.DBController.PatchProjectDetails, dbcontrollers.ErrProjectNotFound, dbcontrollers.ErrNoProjectDetailsUpdate)
+}
+
+func (c *RESTController) patchProjectAssets(w ResponseWriter, r *Request) {
+	log.Println("Patch project assets")
+	c.patchAsset(w, r, c.DBController.PatchProjectAssets, dbcontrollers.ErrProjectNotFound, dbcontrollers.ErrNoProjectAssetsUpdate)
+}
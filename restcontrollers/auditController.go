@@ -0,0 +1,121 @@
+package restcontrollers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// AuditEventsPage is a page of the audit log. NextBeforeID is the 'before_id' of the next page, zero on the last page.
+type AuditEventsPage struct {
+	Events       []models.AuditEvent `json:"events"`
+	NextBeforeID int64               `json:"next_before_id,omitempty"`
+}
+
+// parseAuditFilter reads the audit filter from the URL parameters. Times are expected in RFC 3339 format.
+func parseAuditFilter(r *Request) (*models.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := &models.AuditFilter{
+		Action:     query.Get("action"),
+		EntityType: query.Get("entity_type"),
+	}
+
+	parseID := func(key string) (*uuid.UUID, error) {
+		value := query.Get(key)
+		if value == "" {
+			return nil, nil
+		}
+
+		ID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errors.Errorf("Invalid '%s'", key)
+		}
+		return &ID, nil
+	}
+
+	parseTime := func(key string) (time.Time, error) {
+		value := query.Get(key)
+		if value == "" {
+			return time.Time{}, nil
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, errors.Errorf("Invalid '%s'", key)
+		}
+		return t.UTC(), nil
+	}
+
+	parseInt := func(key string) (int64, error) {
+		value := query.Get(key)
+		if value == "" {
+			return 0, nil
+		}
+
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("Invalid '%s'", key)
+		}
+		return number, nil
+	}
+
+	var err error
+	if filter.Actor, err = parseID("actor"); err != nil {
+		return nil, err
+	}
+	if filter.EntityID, err = parseID("entity_id"); err != nil {
+		return nil, err
+	}
+	if filter.From, err = parseTime("from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTime("to"); err != nil {
+		return nil, err
+	}
+	if filter.BeforeID, err = parseInt("before_id"); err != nil {
+		return nil, err
+	}
+
+	limit, err := parseInt("limit")
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = int(limit)
+	return filter, nil
+}
+
+func (c *RESTController) getAuditEvents(w ResponseWriter, r *Request) {
+	log.Println("Getting audit events")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := c.DBController.GetAuditEvents(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidAuditFilter) {
+			w.writeError(err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := AuditEventsPage{Events: events}
+	// A full page may be followed by more events.
+	if len(events) == filter.Limit {
+		page.NextBeforeID = events[len(events)-1].ID
+	}
+	w.writeData(page, http.StatusOK)
+}
@@ -0,0 +1,143 @@
+package restcontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// A change stream ends before the write timeout of the server, the clients reconnect after the retry delay
+// and resume from the ID of the last event received. A keep-alive is sent when there are no changes for a while.
+const (
+	changeStreamDuration  = 25 * time.Second
+	changeStreamKeepAlive = 10 * time.Second
+	changeStreamRetry     = time.Second
+)
+
+// LastEventIDHeader is sent by the clients reconnecting to a change stream.
+const LastEventIDHeader = "Last-Event-ID"
+
+// parseChangeFilter reads the 'entity_id' and 'user_id' filters of the change stream from the URL parameters.
+func parseChangeFilter(r *Request) (models.ChangeFilter, error) {
+	filter := models.ChangeFilter{}
+	query := r.URL.Query()
+	for key, target := range map[string]**uuid.UUID{"entity_id": &filter.EntityID, "user_id": &filter.UserID} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+
+		ID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errors.Errorf("Invalid '%s'", key)
+		}
+		*target = &ID
+	}
+	return filter, nil
+}
+
+// parseLastEventID returns the ID the stream resumes after. It is read from the Last-Event-ID header or,
+// for the first connection of a browser, from the 'last_event_id' URL parameter. Without them the stream starts now.
+func (c *RESTController) parseLastEventID(r *Request) (int64, error) {
+	value := r.Header.Get(LastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+
+	if value == "" {
+		return c.DBController.LatestChangeID(r.Context())
+	}
+
+	ID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ID < 0 {
+		return 0, errors.Errorf("Invalid '%s'", LastEventIDHeader)
+	}
+	return ID, nil
+}
+
+// writeChange writes an audit event as a server-sent event, its ID is the ID the stream resumes after.
+func writeChange(w ResponseWriter, change *models.AuditEvent) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: change\ndata: %s\n\n", change.ID, data)
+	return err
+}
+
+func (c *RESTController) streamChanges(w ResponseWriter, r *Request) {
+	log.Println("Streaming changes")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		w.writeError("Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseChangeFilter(r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	afterID, err := c.parseLastEventID(r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disables the response buffering of nginx.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// An event without data sets the ID the client resumes after, without notifying it.
+	if _, err := fmt.Fprintf(w, "retry: %d\nid: %d\n\n", changeStreamRetry.Milliseconds(), afterID); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	ctx, cancel := context.WithTimeout(r.Context(), changeStreamDuration)
+	defer cancel()
+
+	lastWrite := time.Now()
+	err = c.DBController.StreamChanges(ctx, filter, afterID, func(changes []models.AuditEvent, lastID int64) error {
+		if len(changes) == 0 {
+			if time.Since(lastWrite) < changeStreamKeepAlive {
+				return nil
+			}
+
+			// The events skipped by the filter are not read again after a reconnect.
+			if _, err := fmt.Fprintf(w, ": keep-alive\nid: %d\n\n", lastID); err != nil {
+				return err
+			}
+		}
+
+		for i := range changes {
+			if err := writeChange(w, &changes[i]); err != nil {
+				return err
+			}
+		}
+
+		flusher.Flush()
+		lastWrite = time.Now()
+		return nil
+	})
+	if err != nil {
+		log.Printf("Change stream failed: %s\n", err.Error())
+	}
+}
@@ -3,9 +3,14 @@ package restcontrollers
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -22,10 +27,13 @@ const (
 	UserPathGetMultiple       = "/get-users"
 	UserPathUpdateSettings    = "/update-user-settings"
 	UserPathUpdateAssets      = "/update-user-assets"
+	UserPathPatchSettings     = "/patch-user-settings"
+	UserPathPatchAssets       = "/patch-user-assets"
 	UserPathDeleteByID        = "/delete-user"
 	UserPathAuthenticate      = "/authenticate"
 	UserPathAddProductUser    = "/add-product-user"
 	UserPathDeleteProductUser = "/delete-product-user"
+	UserPathExport            = "/export-user"
 )
 
 const (
@@ -34,6 +42,8 @@ const (
 	ProductPathGetMultiple   = "/get-products"
 	ProductPathUpdateDetails = "/update-product-details"
 	ProductPathUpdateAssets  = "/update-product-assets"
+	ProductPathPatchDetails  = "/patch-product-details"
+	ProductPathPatchAssets   = "/patch-product-assets"
 	ProductPathDeleteByID    = "/delete-product"
 )
 
@@ -43,6 +53,8 @@ const (
 	ProjectPathGetMultiple          = "/get-projects"
 	ProjectPathUpdateDetails        = "/update-project-details"
 	ProjectPathUpdateAssets         = "/update-project-assets"
+	ProjectPathPatchDetails         = "/patch-project-details"
+	ProjectPathPatchAssets          = "/patch-project-assets"
 	ProjectPathGetProductProject    = "/get-product-projects"
 	ProjectPathDelete               = "/delete-project"
 	ProjectPathAddViewer            = "/add-project-viewer"
@@ -53,8 +65,45 @@ const (
 )
 
 const (
-	POST = "POST"
-	GET  = "GET"
+	SchemaPathGet = "/get-asset-schema"
+)
+
+const (
+	AssetPathGetHistory     = "/get-asset-history"
+	AssetPathGetVersion     = "/get-asset-version"
+	AssetPathGetDiff        = "/get-asset-diff"
+	AssetPathRestoreVersion = "/restore-asset-version"
+)
+
+const (
+	TrashPathGet     = "/get-trash"
+	TrashPathRestore = "/restore-from-trash"
+)
+
+const (
+	AuditPathGetEvents = "/get-audit-events"
+)
+
+const (
+	WebhookPathAdd           = "/add-webhook-subscription"
+	WebhookPathGetMultiple   = "/get-webhook-subscriptions"
+	WebhookPathGet           = "/get-webhook-subscription"
+	WebhookPathUpdate        = "/update-webhook-subscription"
+	WebhookPathDelete        = "/delete-webhook-subscription"
+	WebhookPathGetDeliveries = "/get-webhook-deliveries"
+)
+
+const (
+	ChangePathStream = "/stream-changes"
+)
+
+// UserIDHeader identifies the user on whose behalf the request is made. It is recorded in the asset history and the audit log.
+const UserIDHeader = "X-User-ID"
+
+const (
+	POST  = "POST"
+	GET   = "GET"
+	PATCH = "PATCH"
 )
 
 var DataOK = "OK"
@@ -86,6 +135,22 @@ func (w ResponseWriter) writeData(data interface{}, statusCode int) {
 	w.writeResponse(response, statusCode)
 }
 
+// writeValidationError writes the invalid fields of an asset failing its schema validation.
+// It returns false if err is not a validation error.
+func (w ResponseWriter) writeValidationError(err error) bool {
+	var validationErr *models.SchemaValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	response := &ResponseData{
+		Error: validationErr.Error(),
+		Data:  validationErr.Fields,
+	}
+	w.writeResponse(response, http.StatusUnprocessableEntity)
+	return true
+}
+
 func (w ResponseWriter) writeResponse(response *ResponseData, statusCode int) {
 	b, err := json.Marshal(response)
 	if err != nil {
@@ -139,24 +204,98 @@ func parseIDList(r *Request) ([]uuid.UUID, error) {
 	return idList, nil
 }
 
+// applyIfMatch sets the version of the If-Match header as the expected version of the asset update.
+// The ETag of an asset is its quoted version, "*" and a missing header update any version unless
+// the request data contains it.
+func applyIfMatch(r *Request, asset *models.Asset) error {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return errors.New("Invalid 'If-Match' header")
+	}
+
+	if asset == nil {
+		return errors.New("Missing asset")
+	}
+	asset.Version = version
+	return nil
+}
+
+var errUnsupportedPatchType = errors.New("Unsupported patch type, use application/merge-patch+json or application/json-patch+json")
+
+// decodePatch reads the patch document of a PATCH request of the entity selected by the 'id' URL parameter.
+// The format of the patch is selected by the Content-Type header and the expected version of the asset by If-Match.
+func decodePatch(w ResponseWriter, r *Request) (*uuid.UUID, *models.AssetPatch, error) {
+	if err := checkRequestType(PATCH, w, r); err != nil {
+		return nil, nil, err
+	}
+
+	ids, ok := r.URL.Query()["id"]
+	if !ok || len(ids[0]) < 1 {
+		return nil, nil, errors.New("Url Param 'id' is missing")
+	}
+
+	id, err := uuid.Parse(ids[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patchType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (patchType != models.MergePatchType && patchType != models.JSONPatchType) {
+		return nil, nil, errUnsupportedPatchType
+	}
+
+	document, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, errors.Wrap(errors.WithStack(err), "Failed to read request body")
+	}
+
+	// The version is only read from If-Match, the patch document can not contain it.
+	asset := &models.Asset{}
+	if err := applyIfMatch(r, asset); err != nil {
+		return nil, nil, err
+	}
+
+	patch, err := models.NewAssetPatch(patchType, document, asset.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &id, patch, nil
+}
+
+// setETag returns the version of the updated asset, so the client can send it in the If-Match header of the next update.
+func (w ResponseWriter) setETag(asset *models.Asset) {
+	if asset != nil && asset.Version > 0 {
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, asset.Version))
+	}
+}
+
 func sayHello(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "Hi! I am a user database server!")
 }
 
 func makeHandler(fn func(ResponseWriter, *Request)) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		r := &Request{request}
 		w := ResponseWriter{writer}
+		if userID := request.Header.Get(UserIDHeader); userID != "" {
+			ID, err := uuid.Parse(userID)
+			if err != nil {
+				w.writeError(fmt.Sprintf("Invalid '%s' header", UserIDHeader), http.StatusBadRequest)
+				return
+			}
+			request = request.WithContext(models.WithActingUser(request.Context(), ID))
+		}
+
+		r := &Request{request}
 		fn(w, r)
 	}
 }
 
-func NewRESTController() (*mux.Router, error) {
-	dbController, err := dbcontrollers.NewDBController()
-	if err != nil {
-		return nil, err
-	}
-
+func NewRESTController(dbController *dbcontrollers.MYSQLController) *mux.Router {
 	restController := &RESTController{
 		DBController: dbController,
 	}
@@ -168,17 +307,22 @@ func NewRESTController() (*mux.Router, error) {
 	r.HandleFunc(UserPathGetMultiple, makeHandler(restController.getUsers))
 	r.HandleFunc(UserPathUpdateSettings, makeHandler(restController.updateUserSettings))
 	r.HandleFunc(UserPathUpdateAssets, makeHandler(restController.updateUserAssets))
+	r.HandleFunc(UserPathPatchSettings, makeHandler(restController.patchUserSettings))
+	r.HandleFunc(UserPathPatchAssets, makeHandler(restController.patchUserAssets))
 	r.HandleFunc(UserPathDeleteByID, makeHandler(restController.deleteUser))
 	r.HandleFunc(UserPathAuthenticate, makeHandler(restController.authenticate))
 
 	r.HandleFunc(UserPathAddProductUser, makeHandler(restController.addProductUser))
 	r.HandleFunc(UserPathDeleteProductUser, makeHandler(restController.deleteProductUser))
+	r.HandleFunc(UserPathExport, makeHandler(restController.exportUser))
 
 	r.HandleFunc(ProductPathAdd, makeHandler(restController.addProduct))
 	r.HandleFunc(ProductPathGetByID, makeHandler(restController.getProduct))
 	r.HandleFunc(ProductPathGetMultiple, makeHandler(restController.getProducts))
 	r.HandleFunc(ProductPathUpdateDetails, makeHandler(restController.updateProductDetails))
 	r.HandleFunc(ProductPathUpdateAssets, makeHandler(restController.updateProductAssets))
+	r.HandleFunc(ProductPathPatchDetails, makeHandler(restController.patchProductDetails))
+	r.HandleFunc(ProductPathPatchAssets, makeHandler(restController.patchProductAssets))
 	r.HandleFunc(ProductPathDeleteByID, makeHandler(restController.deleteProduct))
 
 	r.HandleFunc(ProjectPathAdd, makeHandler(restController.addProject))
@@ -186,6 +330,8 @@ func NewRESTController() (*mux.Router, error) {
 	r.HandleFunc(ProjectPathGetMultiple, makeHandler(restController.getProjects))
 	r.HandleFunc(ProjectPathUpdateDetails, makeHandler(restController.updateProjectDetails))
 	r.HandleFunc(ProjectPathUpdateAssets, makeHandler(restController.updateProjectAssets))
+	r.HandleFunc(ProjectPathPatchDetails, makeHandler(restController.patchProjectDetails))
+	r.HandleFunc(ProjectPathPatchAssets, makeHandler(restController.patchProjectAssets))
 	r.HandleFunc(ProjectPathGetProductProject, makeHandler(restController.getProductProjects))
 	r.HandleFunc(ProjectPathDelete, makeHandler(restController.deleteProject))
 	r.HandleFunc(ProjectPathAddViewer, makeHandler(restController.addProjectViewer))
@@ -194,5 +340,26 @@ func NewRESTController() (*mux.Router, error) {
 	r.HandleFunc(ProjectPathDeleteViewerByUser, makeHandler(restController.deleteProjectViewerByUserID))
 	r.HandleFunc(ProjectPathDeleteViewerByViewer, makeHandler(restController.deleteProjectViewerByViewerID))
 
-	return r, nil
+	r.HandleFunc(SchemaPathGet, makeHandler(restController.getAssetSchema))
+
+	r.HandleFunc(AssetPathGetHistory, makeHandler(restController.getAssetHistory))
+	r.HandleFunc(AssetPathGetVersion, makeHandler(restController.getAssetVersion))
+	r.HandleFunc(AssetPathGetDiff, makeHandler(restController.getAssetDiff))
+	r.HandleFunc(AssetPathRestoreVersion, makeHandler(restController.restoreAssetVersion))
+
+	r.HandleFunc(TrashPathGet, makeHandler(restController.getTrash))
+	r.HandleFunc(TrashPathRestore, makeHandler(restController.restoreFromTrash))
+
+	r.HandleFunc(AuditPathGetEvents, makeHandler(restController.getAuditEvents))
+
+	r.HandleFunc(WebhookPathAdd, makeHandler(restController.addWebhookSubscription))
+	r.HandleFunc(WebhookPathGetMultiple, makeHandler(restController.getWebhookSubscriptions))
+	r.HandleFunc(WebhookPathGet, makeHandler(restController.getWebhookSubscription))
+	r.HandleFunc(WebhookPathUpdate, makeHandler(restController.updateWebhookSubscription))
+	r.HandleFunc(WebhookPathDelete, makeHandler(restController.deleteWebhookSubscription))
+	r.HandleFunc(WebhookPathGetDeliveries, makeHandler(restController.getWebhookDeliveries))
+
+	r.HandleFunc(ChangePathStream, makeHandler(restController.streamChanges))
+
+	return r
 }
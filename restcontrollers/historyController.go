@@ -0,0 +1,197 @@
+package restcontrollers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// parseAssetQuery reads the asset type and ID of a GET request from the 'type' and 'id' URL parameters.
+func parseAssetQuery(w ResponseWriter, r *Request) (string, *uuid.UUID, error) {
+	if err := checkRequestType(GET, w, r); err != nil {
+		return "", nil, err
+	}
+
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		return "", nil, errors.New("Url Param 'type' is missing")
+	}
+
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		return "", nil, errors.New("Url Param 'id' is missing")
+	}
+
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return "", nil, err
+	}
+	return assetType, &id, nil
+}
+
+// parseVersionQuery reads a positive asset version from the URL parameter key.
+func parseVersionQuery(r *Request, key string) (int64, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0, errors.Errorf("Url Param '%s' is missing", key)
+	}
+
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errors.Errorf("Invalid '%s'", key)
+	}
+	return version, nil
+}
+
+// writeHistoryError writes the errors shared by the asset history requests and returns false for any other error.
+func (w ResponseWriter) writeHistoryError(err error) bool {
+	switch {
+	case errors.Is(err, dbcontrollers.ErrUnknownAssetType):
+		w.writeError(err.Error(), http.StatusBadRequest)
+	case errors.Is(err, dbcontrollers.ErrAssetNotFound),
+		errors.Is(err, dbcontrollers.ErrAssetVersionNotFound):
+		w.writeError(err.Error(), http.StatusAccepted)
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *RESTController) getAssetHistory(w ResponseWriter, r *Request) {
+	log.Println("Getting asset history")
+	assetType, assetID, err := parseAssetQuery(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := c.DBController.GetAssetHistory(r.Context(), assetType, assetID)
+	if err != nil {
+		if w.writeHistoryError(err) {
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(revisions, http.StatusOK)
+}
+
+func (c *RESTController) getAssetVersion(w ResponseWriter, r *Request) {
+	log.Println("Getting asset version")
+	assetType, assetID, err := parseAssetQuery(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseVersionQuery(r, "version")
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revision, err := c.DBController.GetAssetVersion(r.Context(), assetType, assetID, version)
+	if err != nil {
+		if w.writeHistoryError(err) {
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(revision, http.StatusOK)
+}
+
+func (c *RESTController) getAssetDiff(w ResponseWriter, r *Request) {
+	log.Println("Getting asset diff")
+	assetType, assetID, err := parseAssetQuery(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseVersionQuery(r, "from")
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	to, err := parseVersionQuery(r, "to")
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	changes, err := c.DBController.DiffAssetVersions(r.Context(), assetType, assetID, from, to)
+	if err != nil {
+		if w.writeHistoryError(err) {
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(changes, http.StatusOK)
+}
+
+func (c *RESTController) restoreAssetVersion(w ResponseWriter, r *Request) {
+	log.Println("Restoring asset version")
+	data, err := decodePostData(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	assetType, ok := data["type"].(string)
+	if !ok {
+		w.writeError("Missing 'type' element", http.StatusBadRequest)
+		return
+	}
+
+	idString, ok := data["id"].(string)
+	if !ok {
+		w.writeError("Missing 'id' element", http.StatusBadRequest)
+		return
+	}
+
+	assetID, err := uuid.Parse(idString)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, ok := data["version"].(float64)
+	if !ok || version <= 0 || version != float64(int64(version)) {
+		w.writeError("Missing 'version' element", http.StatusBadRequest)
+		return
+	}
+
+	// The version the client has seen is only read from If-Match.
+	expected := &models.Asset{}
+	if err := applyIfMatch(r, expected); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	asset, err := c.DBController.RestoreAssetVersion(r.Context(), assetType, &assetID, int64(version), expected.Version)
+	if err != nil {
+		if w.writeHistoryError(err) || w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetVersionConflict) {
+			w.writeError(err.Error(), http.StatusConflict)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.setETag(asset)
+	w.writeData(asset, http.StatusOK)
+}
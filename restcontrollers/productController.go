@@ -1,8 +1,8 @@
 package restcontrollers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 
@@ -32,12 +32,20 @@ func parseProductData(data map[string]interface{}) (*models.ProductData, error)
 	return productData, nil
 }
 
-func (c *RESTController) validateProduct(expected *models.ProductData) (int, error) {
-	product, err := c.DBController.GetProduct(&expected.ID)
+func (c *RESTController) validateProduct(ctx context.Context, expected *models.ProductData) (int, error) {
+	product, err := c.DBController.GetProduct(ctx, &expected.ID)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
 
+	// The versions are checked by the update itself, the request may omit them.
+	if expected.Details != nil && product.Details != nil {
+		expected.Details.Version = product.Details.Version
+	}
+	if expected.Assets != nil && product.Assets != nil {
+		expected.Assets.Version = product.Assets.Version
+	}
+
 	if diff := pretty.Diff(product, expected); len(diff) != 0 {
 		return http.StatusAccepted, errors.New("Failed to update product details")
 	}
@@ -78,10 +86,14 @@ func (c *RESTController) addProduct(w ResponseWriter, r *Request) {
 		return
 	}
 
-	product, err := c.DBController.CreateProduct(name, &userID)
+	product, err := c.DBController.CreateProduct(r.Context(), name, &userID)
 	if err != nil {
-		duplicateProduct := fmt.Errorf(dbcontrollers.ErrProductExistsString, name)
-		if err.Error() == duplicateProduct.Error() || err.Error() == dbcontrollers.ErrEmptyUsersList.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrProductExists) ||
+			errors.Is(err, dbcontrollers.ErrEmptyUsersList) ||
+			errors.Is(err, dbcontrollers.ErrUserNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -112,9 +124,9 @@ func (c *RESTController) getProduct(w ResponseWriter, r *Request) {
 		return
 	}
 
-	productData, err := c.DBController.GetProduct(&id)
+	productData, err := c.DBController.GetProduct(r.Context(), &id)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProductNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProductNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -133,15 +145,32 @@ func (c *RESTController) getProducts(w ResponseWriter, r *Request) {
 		return
 	}
 
+	filter, err := models.ParseDetailsFilter(r.URL.Query())
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(filter) > 0 {
+		productData, err := c.DBController.GetProductsByDetails(r.Context(), filter)
+		if err != nil {
+			w.writeError(err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.writeData(productData, http.StatusOK)
+		return
+	}
+
 	idList, err := parseIDList(r)
 	if err != nil {
 		w.writeError(err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	productData, err := c.DBController.GetProducts(idList)
+	productData, err := c.DBController.GetProducts(r.Context(), idList)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProductNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProductNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -172,9 +201,9 @@ func (c *RESTController) deleteProduct(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.DeleteProduct(&productID)
+	err = c.DBController.DeleteProduct(r.Context(), &productID)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProductNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProductNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -182,8 +211,8 @@ func (c *RESTController) deleteProduct(w ResponseWriter, r *Request) {
 		return
 	}
 
-	_, err = c.DBController.GetProduct(&productID)
-	if err != nil && err.Error() != dbcontrollers.ErrProductNotFound.Error() {
+	_, err = c.DBController.GetProduct(r.Context(), &productID)
+	if err != nil && !errors.Is(err, dbcontrollers.ErrProductNotFound) {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
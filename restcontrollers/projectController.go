@@ -1,8 +1,8 @@
 package restcontrollers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -33,12 +33,20 @@ func parseProjectData(data map[string]interface{}) (*models.ProjectData, error)
 	return projectData, nil
 }
 
-func (c *RESTController) validateProject(expected *models.ProjectData) (int, error) {
-	project, err := c.DBController.GetProject(&expected.ID)
+func (c *RESTController) validateProject(ctx context.Context, expected *models.ProjectData) (int, error) {
+	project, err := c.DBController.GetProject(ctx, &expected.ID)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
 
+	// The versions are checked by the update itself, the request may omit them.
+	if expected.Details != nil && project.Details != nil {
+		expected.Details.Version = project.Details.Version
+	}
+	if expected.Assets != nil && project.Assets != nil {
+		expected.Assets.Version = project.Assets.Version
+	}
+
 	if diff := pretty.Diff(project, expected); len(diff) != 0 {
 		return http.StatusAccepted, errors.New("Failed to update project")
 	}
@@ -96,10 +104,14 @@ func (c *RESTController) addProject(w ResponseWriter, r *Request) {
 		return
 	}
 
-	project, err := c.DBController.CreateProject(name, visibility, &userID, &productID)
+	project, err := c.DBController.CreateProject(r.Context(), name, visibility, &userID, &productID)
 	if err != nil {
-		duplicateProject := fmt.Errorf(dbcontrollers.ErrProjectExistsString, name)
-		if err.Error() == duplicateProject.Error() || err.Error() == dbcontrollers.ErrEmptyUsersList.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrProjectExists) ||
+			errors.Is(err, dbcontrollers.ErrEmptyUsersList) ||
+			errors.Is(err, dbcontrollers.ErrProductNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -129,9 +141,9 @@ func (c *RESTController) getProject(w ResponseWriter, r *Request) {
 		w.writeError(err.Error(), http.StatusBadRequest)
 	}
 
-	projectData, err := c.DBController.GetProject(&id)
+	projectData, err := c.DBController.GetProject(r.Context(), &id)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProjectNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProjectNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -161,9 +173,9 @@ func (c *RESTController) getProductProjects(w ResponseWriter, r *Request) {
 		w.writeError(err.Error(), http.StatusBadRequest)
 	}
 
-	projectList, err := c.DBController.GetProjectsByProductID(&id)
+	projectList, err := c.DBController.GetProjectsByProductID(r.Context(), &id)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrNoProjectForProduct.Error() {
+		if errors.Is(err, dbcontrollers.ErrNoProjectForProduct) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -181,15 +193,32 @@ func (c *RESTController) getProjects(w ResponseWriter, r *Request) {
 		return
 	}
 
+	filter, err := models.ParseDetailsFilter(r.URL.Query())
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(filter) > 0 {
+		projectData, err := c.DBController.GetProjectsByDetails(r.Context(), filter)
+		if err != nil {
+			w.writeError(err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.writeData(projectData, http.StatusOK)
+		return
+	}
+
 	idList, err := parseIDList(r)
 	if err != nil {
 		w.writeError(err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	projectData, err := c.DBController.GetProjects(idList)
+	projectData, err := c.DBController.GetProjects(r.Context(), idList)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProjectNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProjectNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -221,9 +250,9 @@ func (c *RESTController) deleteProject(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.DeleteProject(&projectID)
+	err = c.DBController.DeleteProject(r.Context(), &projectID)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProjectNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProjectNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -231,8 +260,8 @@ func (c *RESTController) deleteProject(w ResponseWriter, r *Request) {
 		return
 	}
 
-	_, err = c.DBController.GetProject(&projectID)
-	if err != nil && err.Error() != dbcontrollers.ErrProjectNotFound.Error() {
+	_, err = c.DBController.GetProject(r.Context(), &projectID)
+	if err != nil && !errors.Is(err, dbcontrollers.ErrProjectNotFound) {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -258,7 +287,12 @@ func (c *RESTController) addProjectViewer(w ResponseWriter, r *Request) {
 		return
 	}
 
-	if err := c.DBController.CreateProjectViewer(projectViewer); err != nil {
+	if err := c.DBController.CreateProjectViewer(r.Context(), projectViewer); err != nil {
+		if errors.Is(err, dbcontrollers.ErrProjectNotFound) ||
+			errors.Is(err, dbcontrollers.ErrViewerAlreadyExists) {
+			w.writeError(err.Error(), http.StatusAccepted)
+			return
+		}
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -286,14 +320,14 @@ func (c *RESTController) deleteProjectViewerByUserID(w ResponseWriter, r *Reques
 		return
 	}
 
-	err = c.DBController.DeleteProjectViewerByUserID(&userID)
+	err = c.DBController.DeleteProjectViewerByUserID(r.Context(), &userID)
 	if err != nil {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	_, err = c.DBController.GetProjectViewersByUserID(&userID)
-	if err != nil && err.Error() != dbcontrollers.ErrUserIsNotConnectedToAnyViewer.Error() {
+	_, err = c.DBController.GetProjectViewersByUserID(r.Context(), &userID)
+	if err != nil && !errors.Is(err, dbcontrollers.ErrUserIsNotConnectedToAnyViewer) {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -321,14 +355,14 @@ func (c *RESTController) deleteProjectViewerByViewerID(w ResponseWriter, r *Requ
 		return
 	}
 
-	err = c.DBController.DeleteProjectViewerByViewerID(&viewerID)
+	err = c.DBController.DeleteProjectViewerByViewerID(r.Context(), &viewerID)
 	if err != nil {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	_, err = c.DBController.GetProjectViewersByViewerID(&viewerID)
-	if err != nil && err != dbcontrollers.ErrProjectViewerNotFound {
+	_, err = c.DBController.GetProjectViewersByViewerID(r.Context(), &viewerID)
+	if err != nil && !errors.Is(err, dbcontrollers.ErrProjectViewerNotFound) {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -354,9 +388,9 @@ func (c *RESTController) getProjectViewersByUserID(w ResponseWriter, r *Request)
 		w.writeError(err.Error(), http.StatusBadRequest)
 	}
 
-	projectViewers, err := c.DBController.GetProjectViewersByUserID(&id)
+	projectViewers, err := c.DBController.GetProjectViewersByUserID(r.Context(), &id)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrUserIsNotConnectedToAnyViewer.Error() {
+		if errors.Is(err, dbcontrollers.ErrUserIsNotConnectedToAnyViewer) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -386,9 +420,9 @@ func (c *RESTController) getProjectViewersByViewerID(w ResponseWriter, r *Reques
 		w.writeError(err.Error(), http.StatusBadRequest)
 	}
 
-	projectViewers, err := c.DBController.GetProjectViewersByViewerID(&id)
+	projectViewers, err := c.DBController.GetProjectViewersByViewerID(r.Context(), &id)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrProjectViewerNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrProjectViewerNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -0,0 +1,40 @@
+package restcontrollers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/pkg/errors"
+)
+
+func (c *RESTController) getAssetSchema(w ResponseWriter, r *Request) {
+	log.Println("Getting asset schema")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		w.writeError("Url Param 'type' is missing", http.StatusBadRequest)
+		return
+	}
+
+	document, err := c.DBController.GetAssetSchema(assetType)
+	if err != nil {
+		if errors.Is(err, dbcontrollers.ErrUnknownAssetType) {
+			w.writeError(err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrAssetSchemaNotFound) {
+			w.writeError(err.Error(), http.StatusAccepted)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(json.RawMessage(document), http.StatusOK)
+}
@@ -0,0 +1,74 @@
+package restcontrollers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func (c *RESTController) getTrash(w ResponseWriter, r *Request) {
+	log.Println("Getting trash")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Without the 'type' parameter the trashed users, products and projects are all listed.
+	entries, err := c.DBController.GetTrash(r.Context(), r.URL.Query().Get("type"))
+	if err != nil {
+		if errors.Is(err, dbcontrollers.ErrUnknownTrashType) {
+			w.writeError(err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(entries, http.StatusOK)
+}
+
+func (c *RESTController) restoreFromTrash(w ResponseWriter, r *Request) {
+	log.Println("Restoring from trash")
+	data, err := decodePostData(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entityType, ok := data["type"].(string)
+	if !ok {
+		w.writeError("Missing 'type' element", http.StatusBadRequest)
+		return
+	}
+
+	idString, ok := data["id"].(string)
+	if !ok {
+		w.writeError("Missing 'id' element", http.StatusBadRequest)
+		return
+	}
+
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.DBController.RestoreFromTrash(r.Context(), entityType, &id); err != nil {
+		switch {
+		case errors.Is(err, dbcontrollers.ErrUnknownTrashType):
+			w.writeError(err.Error(), http.StatusBadRequest)
+		case errors.Is(err, dbcontrollers.ErrNotInTrash):
+			w.writeError(err.Error(), http.StatusAccepted)
+		case errors.Is(err, dbcontrollers.ErrProductInTrash):
+			w.writeError(err.Error(), http.StatusConflict)
+		default:
+			w.writeError(err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.writeData(DataOK, http.StatusOK)
+}
@@ -3,6 +3,7 @@ package restcontrollers
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/pkg/errors"
 )
 
+var errInvalidPassword = errors.New("Invalid password")
+
 func parseUserDataSettings(data map[string]interface{}) (*models.UserData, error) {
 	userData := models.UserData{}
 	userID, ok := data["user-id"]
@@ -110,10 +113,13 @@ func (c *RESTController) addUser(w ResponseWriter, r *Request) {
 	}
 
 	// Execute function
-	user, err := c.DBController.CreateUser(name, email, pwd)
+	user, err := c.DBController.CreateUser(r.Context(), name, email, pwd)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrDuplicateEmailEntry.Error() ||
-			err.Error() == dbcontrollers.ErrDuplicateNameEntry.Error() {
+		if w.writeValidationError(err) {
+			return
+		}
+		if errors.Is(err, dbcontrollers.ErrDuplicateEmailEntry) ||
+			errors.Is(err, dbcontrollers.ErrDuplicateNameEntry) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -144,9 +150,9 @@ func (c *RESTController) getUser(w ResponseWriter, r *Request) {
 		return
 	}
 
-	userData, err := c.DBController.GetUser(&id)
+	userData, err := c.DBController.GetUser(r.Context(), &id)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrUserNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrUserNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -157,6 +163,50 @@ func (c *RESTController) getUser(w ResponseWriter, r *Request) {
 	w.writeData(userData, http.StatusOK)
 }
 
+// exportUser sends everything stored about the user as a JSON archive to download.
+func (c *RESTController) exportUser(w ResponseWriter, r *Request) {
+	log.Println("Exporting user")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ids, ok := r.URL.Query()["id"]
+	if !ok || len(ids[0]) < 1 {
+		w.writeError("Url Param 'id' is missing", http.StatusBadRequest)
+		return
+	}
+
+	id, err := uuid.Parse(ids[0])
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	export, err := c.DBController.ExportUser(r.Context(), &id)
+	if err != nil {
+		if errors.Is(err, dbcontrollers.ErrUserNotFound) {
+			w.writeError(err.Error(), http.StatusAccepted)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	archive, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s.json"`, id))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(archive); err != nil {
+		log.Printf("Failed to send the export of user %s: %s\n", id, err.Error())
+	}
+}
+
 func (c *RESTController) getUserByEmail(w ResponseWriter, r *Request) {
 	log.Println("Getting user by email")
 	if err := checkRequestType(GET, w, r); err != nil {
@@ -170,9 +220,9 @@ func (c *RESTController) getUserByEmail(w ResponseWriter, r *Request) {
 		return
 	}
 
-	userData, err := c.DBController.GetUserByEmail(emails[0])
+	userData, err := c.DBController.GetUserByEmail(r.Context(), emails[0])
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrUserNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrUserNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -195,9 +245,9 @@ func (c *RESTController) getUsers(w ResponseWriter, r *Request) {
 		return
 	}
 
-	userData, err := c.DBController.GetUsers(idList)
+	userData, err := c.DBController.GetUsers(r.Context(), idList)
 	if err != nil {
-		if err.Error() == dbcontrollers.ErrUserNotFound.Error() {
+		if errors.Is(err, dbcontrollers.ErrUserNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -236,8 +286,9 @@ func (c *RESTController) deleteUser(w ResponseWriter, r *Request) {
 		return
 	}
 
-	for productIDString, nomineeIDString := range nomineesMap.(map[string]interface{}) {
-		productID, err := uuid.Parse(productIDString)
+	// The nominees take over the owned products and projects.
+	for ownedIDString, nomineeIDString := range nomineesMap.(map[string]interface{}) {
+		ownedID, err := uuid.Parse(ownedIDString)
 		if err != nil {
 			w.writeError(err.Error(), http.StatusBadRequest)
 			return
@@ -247,26 +298,45 @@ func (c *RESTController) deleteUser(w ResponseWriter, r *Request) {
 			w.writeError(err.Error(), http.StatusBadRequest)
 			return
 		}
-		nominees[productID] = nomineeID
+		nominees[ownedID] = nomineeID
+	}
+
+	// Erasing bypasses the trash, the user is deleted permanently even with soft delete enabled.
+	erase := false
+	if eraseValue, ok := data["erase"]; ok {
+		erase, ok = eraseValue.(bool)
+		if !ok {
+			w.writeError("Invalid 'erase' element, expected a boolean", http.StatusBadRequest)
+			return
+		}
 	}
 
-	if err = c.DBController.DeleteUser(&id, nominees); err != nil {
-		if err.Error() == dbcontrollers.ErrUserNotFound.Error() {
+	deleteUser := c.DBController.DeleteUser
+	if erase {
+		deleteUser = c.DBController.EraseUser
+	}
+
+	if err = deleteUser(r.Context(), &id, nominees); err != nil {
+		if errors.Is(err, dbcontrollers.ErrUserNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
+		if errors.Is(err, dbcontrollers.ErrOwnershipTransferFailed) {
+			w.writeError(err.Error(), http.StatusBadRequest)
+			return
+		}
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	_, err = c.DBController.GetUser(&id)
-	if err != nil && err.Error() != dbcontrollers.ErrUserNotFound.Error() {
+	_, err = c.DBController.GetUser(r.Context(), &id)
+	if err != nil && !errors.Is(err, dbcontrollers.ErrUserNotFound) {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	_, err = c.DBController.GetProduct(&id)
-	if err != nil && err.Error() != dbcontrollers.ErrProductNotFound.Error() {
+	_, err = c.DBController.GetProduct(r.Context(), &id)
+	if err != nil && !errors.Is(err, dbcontrollers.ErrProductNotFound) {
 		w.writeError(err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -305,15 +375,15 @@ func (c *RESTController) authenticate(w ResponseWriter, r *Request) {
 		return
 	}
 
-	err = c.DBController.Authenticate(&id, emails[0], passwords[0],
+	err = c.DBController.Authenticate(r.Context(), &id, emails[0], passwords[0],
 		func(string, pass string, user *models.User) error {
 			if diff := pretty.Diff([]byte(pass), user.Password); len(diff) != 0 {
-				return errors.New("Invalid password")
+				return errInvalidPassword
 			}
 			return nil
 		})
 	if err != nil {
-		if err.Error() == "Invalid password" || err.Error() == dbcontrollers.ErrUserNotFound.Error() {
+		if errors.Is(err, errInvalidPassword) || errors.Is(err, dbcontrollers.ErrUserNotFound) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -348,9 +418,9 @@ func (c *RESTController) addProductUser(w ResponseWriter, r *Request) {
 
 		privilege := userData["privilege"].(float64)
 
-		if err := c.DBController.AddProductUser(&productID, &userID, int(privilege)); err != nil {
-			if err.Error() == dbcontrollers.ErrProductNotFound.Error() ||
-				err.Error() == dbcontrollers.ErrProductUserNotAssociated.Error() {
+		if err := c.DBController.AddProductUser(r.Context(), &productID, &userID, int(privilege)); err != nil {
+			if errors.Is(err, dbcontrollers.ErrProductNotFound) ||
+				errors.Is(err, dbcontrollers.ErrProductUserNotAssociated) {
 				w.writeError(err.Error(), http.StatusAccepted)
 				return
 			}
@@ -381,8 +451,8 @@ func (c *RESTController) deleteProductUser(w ResponseWriter, r *Request) {
 		return
 	}
 
-	if err := c.DBController.DeleteProductUser(&productID, &userID); err != nil {
-		if err.Error() == dbcontrollers.ErrProductUserNotAssociated.Error() {
+	if err := c.DBController.DeleteProductUser(r.Context(), &productID, &userID); err != nil {
+		if errors.Is(err, dbcontrollers.ErrProductUserNotAssociated) {
 			w.writeError(err.Error(), http.StatusAccepted)
 			return
 		}
@@ -0,0 +1,271 @@
+package restcontrollers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/dbcontrollers"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// WebhookDeliveriesPage is a page of the delivery log of a subscription.
+// NextBeforeID is the 'before_id' of the next page, zero on the last page.
+type WebhookDeliveriesPage struct {
+	Deliveries   []models.WebhookDelivery `json:"deliveries"`
+	NextBeforeID int64                    `json:"next_before_id,omitempty"`
+}
+
+// parseEventTypes reads the optional 'event_types' list of the request data.
+func parseEventTypes(data map[string]interface{}) ([]string, error) {
+	eventTypes := make([]string, 0)
+	value, ok := data["event_types"]
+	if !ok || value == nil {
+		return eventTypes, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.New("Invalid 'event_types' element")
+	}
+
+	for _, element := range list {
+		eventType, ok := element.(string)
+		if !ok {
+			return nil, errors.New("Invalid 'event_types' element")
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+	return eventTypes, nil
+}
+
+func parseSubscriptionID(idString string) (*uuid.UUID, error) {
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (c *RESTController) addWebhookSubscription(w ResponseWriter, r *Request) {
+	log.Println("Adding webhook subscription")
+	data, err := decodePostData(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url, ok := data["url"].(string)
+	if !ok {
+		w.writeError("Missing 'url' element", http.StatusBadRequest)
+		return
+	}
+
+	// A secret is generated if it is not set.
+	secret, _ := data["secret"].(string)
+	eventTypes, err := parseEventTypes(data)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := c.DBController.CreateWebhookSubscription(r.Context(), url, secret, eventTypes)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidWebhookSubscription) {
+			w.writeError(err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(subscription, http.StatusCreated)
+}
+
+func (c *RESTController) getWebhookSubscriptions(w ResponseWriter, r *Request) {
+	log.Println("Getting webhook subscriptions")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	subscriptions, err := c.DBController.GetWebhookSubscriptions(r.Context())
+	if err != nil {
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(subscriptions, http.StatusOK)
+}
+
+func (c *RESTController) getWebhookSubscription(w ResponseWriter, r *Request) {
+	log.Println("Getting webhook subscription")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ids, ok := r.URL.Query()["id"]
+	if !ok || len(ids[0]) < 1 {
+		w.writeError("Url Param 'id' is missing", http.StatusBadRequest)
+		return
+	}
+
+	id, err := parseSubscriptionID(ids[0])
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := c.DBController.GetWebhookSubscription(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, dbcontrollers.ErrWebhookSubscriptionNotFound) {
+			w.writeError(err.Error(), http.StatusAccepted)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(subscription, http.StatusOK)
+}
+
+func (c *RESTController) updateWebhookSubscription(w ResponseWriter, r *Request) {
+	log.Println("Updating webhook subscription")
+	data, err := decodePostData(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	idString, ok := data["id"].(string)
+	if !ok {
+		w.writeError("Missing 'id' element", http.StatusBadRequest)
+		return
+	}
+
+	id, err := parseSubscriptionID(idString)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url, ok := data["url"].(string)
+	if !ok {
+		w.writeError("Missing 'url' element", http.StatusBadRequest)
+		return
+	}
+
+	eventTypes, err := parseEventTypes(data)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The subscription keeps its secret if it is not set.
+	secret, _ := data["secret"].(string)
+	subscription := &models.WebhookSubscription{
+		ID:         *id,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	}
+	if err := c.DBController.UpdateWebhookSubscription(r.Context(), subscription); err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidWebhookSubscription):
+			w.writeError(err.Error(), http.StatusBadRequest)
+		case errors.Is(err, dbcontrollers.ErrWebhookSubscriptionNotFound):
+			w.writeError(err.Error(), http.StatusAccepted)
+		default:
+			w.writeError(err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.writeData(DataOK, http.StatusOK)
+}
+
+func (c *RESTController) deleteWebhookSubscription(w ResponseWriter, r *Request) {
+	log.Println("Deleting webhook subscription")
+	data, err := decodePostData(w, r)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	idString, ok := data["id"].(string)
+	if !ok {
+		w.writeError("Missing 'id' element", http.StatusBadRequest)
+		return
+	}
+
+	id, err := parseSubscriptionID(idString)
+	if err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.DBController.DeleteWebhookSubscription(r.Context(), id); err != nil {
+		if errors.Is(err, dbcontrollers.ErrWebhookSubscriptionNotFound) {
+			w.writeError(err.Error(), http.StatusAccepted)
+			return
+		}
+		w.writeError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.writeData(DataOK, http.StatusOK)
+}
+
+func (c *RESTController) getWebhookDeliveries(w ResponseWriter, r *Request) {
+	log.Println("Getting webhook deliveries")
+	if err := checkRequestType(GET, w, r); err != nil {
+		w.writeError(err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	id, err := parseSubscriptionID(query.Get("id"))
+	if err != nil {
+		w.writeError("Invalid 'id'", http.StatusBadRequest)
+		return
+	}
+
+	var beforeID int64
+	if value := query.Get("before_id"); value != "" {
+		if beforeID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			w.writeError("Invalid 'before_id'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := models.DefaultWebhookDeliveryLimit
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			w.writeError("Invalid 'limit'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveries, err := c.DBController.GetWebhookDeliveries(r.Context(), id, beforeID, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, dbcontrollers.ErrInvalidWebhookDeliveryPage):
+			w.writeError(err.Error(), http.StatusBadRequest)
+		case errors.Is(err, dbcontrollers.ErrWebhookSubscriptionNotFound):
+			w.writeError(err.Error(), http.StatusAccepted)
+		default:
+			w.writeError(err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	page := WebhookDeliveriesPage{Deliveries: deliveries}
+	// A full page may be followed by more deliveries.
+	if limit > 0 && len(deliveries) == limit {
+		page.NextBeforeID = deliveries[len(deliveries)-1].ID
+	}
+	w.writeData(page, http.StatusOK)
+}
@@ -0,0 +1,257 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var AddAssetQuery = "INSERT INTO %s (id, data) VALUES (?, ?)"
+
+func (*SQLiteFunctions) AddAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
+	// Prepare data
+	binary, err := json.Marshal(asset.DataMap)
+	if err != nil {
+		return err
+	}
+
+	// Execute transaction
+	query := fmt.Sprintf(AddAssetQuery, assetType)
+	_, err = tx.ExecContext(ctx, query, asset.ID, string(binary))
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	asset.Version = 1
+	return nil
+}
+
+var UpdateAssetQuery = "UPDATE %s SET data = ?, version = version + 1 WHERE id = ?"
+var UpdateAssetVersionQuery = "UPDATE %s SET data = ?, version = version + 1 WHERE id = ? AND version = ?"
+var GetAssetVersionQuery = "SELECT version FROM %s WHERE id = ?"
+
+// UpdateAsset overwrites the data of the asset and sets asset.Version to the new version.
+// If asset.Version is set, mysqldb.ErrVersionConflict is returned when the stored asset has a different version.
+func (*SQLiteFunctions) UpdateAsset(ctx context.Context, assetType string, asset *models.Asset, tx *sql.Tx) error {
+	binary, err := json.Marshal(asset.DataMap)
+	if err != nil {
+		return err
+	}
+
+	if err := addAssetHistory(ctx, assetType, &asset.ID, tx); err != nil {
+		return err
+	}
+
+	var result sql.Result
+	if asset.Version > 0 {
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(UpdateAssetVersionQuery, assetType), string(binary), asset.ID, asset.Version)
+	} else {
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(UpdateAssetQuery, assetType), string(binary), asset.ID)
+	}
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	var version int64
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(GetAssetVersionQuery, assetType), asset.ID).Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		return errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	case err != nil:
+		return TranslateError(err)
+	case affected == 0:
+		return errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, version, asset.Version)
+	default:
+	}
+
+	asset.Version = version
+	return nil
+}
+
+var GetAssetQuery = "SELECT id, data, version FROM %s WHERE id = ?"
+
+func (f *SQLiteFunctions) GetAsset(ctx context.Context, assetType string, assetID *uuid.UUID) (*models.Asset, error) {
+	asset := &models.Asset{}
+
+	tx, err := f.DBConnector.ConnectSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(GetAssetQuery, assetType)
+	result := tx.QueryRowContext(ctx, query, assetID)
+
+	dataMap := []byte{}
+	err = result.Scan(&asset.ID, &dataMap, &asset.Version)
+	switch {
+	case err == sql.ErrNoRows:
+		if errRb := tx.Commit(); errRb != nil {
+			return nil, errRb
+		}
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, RollbackWithErrorStack(tx, err)
+	default:
+	}
+
+	if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+		return nil, RollbackWithErrorStack(tx, err)
+	}
+
+	return asset, TranslateError(tx.Commit())
+}
+
+var DeleteAssetQuery = "DELETE FROM %s WHERE id = ?"
+
+func (*SQLiteFunctions) DeleteAsset(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) error {
+	query := fmt.Sprintf(DeleteAssetQuery, assetType)
+	result, err := tx.ExecContext(ctx, query, assetID)
+	if err != nil {
+		return translateDeleteError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	}
+	return deleteAssetHistory(ctx, assetType, assetID, tx)
+}
+
+var GetAssetsQuery = "SELECT id, data, version FROM %s WHERE id IN "
+
+func (*SQLiteFunctions) GetAssets(ctx context.Context, assetType string, IDs []uuid.UUID, tx *sql.Tx) ([]models.Asset, error) {
+	placeholders, interfaceList := inList(IDs)
+	query := fmt.Sprintf(GetAssetsQuery, assetType) + placeholders
+	rows, err := tx.QueryContext(ctx, query, interfaceList...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	assets := make([]models.Asset, 0)
+	for rows.Next() {
+		dataMap := []byte{}
+		asset := models.Asset{}
+		err := rows.Scan(&asset.ID, &dataMap, &asset.Version)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(assets) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return assets, nil
+}
+
+var MergePatchAssetQuery = "UPDATE %s SET data = json_patch(data, ?), version = version + 1 WHERE id = ?"
+var MergePatchAssetVersionQuery = "UPDATE %s SET data = json_patch(data, ?), version = version + 1 WHERE id = ? AND version = ?"
+
+// PatchAsset applies patch to the data of the asset in tx and returns the patched asset.
+// Merge patches are applied by the json_patch function of SQLite, JSON patches are applied to the asset read in tx.
+// SQLite has a single writer, so the asset can not change between the read and the update of a JSON patch.
+// If patch.Version is set, mysqldb.ErrVersionConflict is returned when the stored asset has a different version.
+func (*SQLiteFunctions) PatchAsset(ctx context.Context, assetType string, assetID *uuid.UUID, patch *models.AssetPatch, tx *sql.Tx) (*models.Asset, error) {
+	if err := addAssetHistory(ctx, assetType, assetID, tx); err != nil {
+		return nil, err
+	}
+
+	if patch.Type == models.MergePatchType {
+		var result sql.Result
+		var err error
+		if patch.Version > 0 {
+			result, err = tx.ExecContext(ctx, fmt.Sprintf(MergePatchAssetVersionQuery, assetType), string(patch.Document), assetID, patch.Version)
+		} else {
+			result, err = tx.ExecContext(ctx, fmt.Sprintf(MergePatchAssetQuery, assetType), string(patch.Document), assetID)
+		}
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+
+		asset, err := getAssetInTx(ctx, assetType, assetID, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		if affected == 0 {
+			return nil, errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, asset.Version, patch.Version)
+		}
+		return asset, nil
+	}
+
+	asset, err := getAssetInTx(ctx, assetType, assetID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Version > 0 && patch.Version != asset.Version {
+		return nil, errors.WithMessagef(mysqldb.ErrVersionConflict, "%s version %d, expected %d", assetType, asset.Version, patch.Version)
+	}
+
+	patched, err := patch.Apply(asset.DataMap)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(UpdateAssetQuery, assetType), string(binary), assetID); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	asset.DataMap = patched
+	asset.Version++
+	return asset, nil
+}
+
+// getAssetInTx reads the asset in tx, mysqldb.ErrAssetMissing is returned if it does not exist.
+func getAssetInTx(ctx context.Context, assetType string, assetID *uuid.UUID, tx *sql.Tx) (*models.Asset, error) {
+	asset := &models.Asset{}
+	dataMap := []byte{}
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(GetAssetQuery, assetType), assetID).Scan(&asset.ID, &dataMap, &asset.Version)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.WithMessage(mysqldb.ErrAssetMissing, assetType)
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	if err := json.Unmarshal(dataMap, &asset.DataMap); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
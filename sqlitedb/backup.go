@@ -0,0 +1,56 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+)
+
+func dumpTableQuery(table *mysqldb.BackupTable) string {
+	columns := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column.Name)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", strings.Join(columns, ", "), table.Name, strings.Join(table.OrderBy(), ", "))
+}
+
+func restoreRowQuery(table *mysqldb.BackupTable) string {
+	columns := make([]string, 0, len(table.Columns))
+	placeholders := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column.Name)
+		placeholders = append(placeholders, "?")
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.Name, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+}
+
+// DumpTable calls fn with every row of the table. Iteration stops at the first error returned by fn.
+func (*SQLiteFunctions) DumpTable(ctx context.Context, table *mysqldb.BackupTable, fn func(r models.BackupRow) error, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, dumpTableQuery(table))
+	if err != nil {
+		return TranslateError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r, err := mysqldb.ScanBackupRow(rows, table)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return TranslateError(rows.Err())
+}
+
+// RestoreRow inserts a row of the dump into the table.
+func (*SQLiteFunctions) RestoreRow(ctx context.Context, table *mysqldb.BackupTable, r models.BackupRow, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, restoreRowQuery(table), mysqldb.BackupArgs(table, r)...)
+	return TranslateError(err)
+}
@@ -0,0 +1,133 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+)
+
+// SQLite extended result codes recognised by TranslateError.
+const (
+	SQLiteBusy                 = 5
+	SQLiteLocked               = 6
+	SQLiteLockedSharedCache    = 262
+	SQLiteBusyRecovery         = 261
+	SQLiteBusySnapshot         = 517
+	SQLiteConstraintCheck      = 275
+	SQLiteConstraintForeignKey = 787
+	SQLiteConstraintNotNull    = 1299
+	SQLiteConstraintPrimaryKey = 1555
+	SQLiteConstraintUnique     = 2067
+)
+
+// Suffix of the check constraints emulating the length limit of the varchar columns.
+// The constraints are named <column>_length.
+const lengthConstraintSuffix = "_length"
+
+// UNIQUE constraint failed: users.name / NOT NULL constraint failed: users.name
+var columnRegexp = regexp.MustCompile(`constraint failed: ([^.\s]+)\.([^\s,]+)`)
+
+// CHECK constraint failed: name_length
+var checkConstraintRegexp = regexp.MustCompile(`CHECK constraint failed: ([^\s(]+)`)
+
+// TranslateError converts SQLite errors to *mysqldb.SQLError, so that they match
+// the same sentinel errors as the MySQL ones. Any other error is returned unchanged.
+//
+// SQLite does not report the column of a failed foreign key, see resolveForeignKey.
+func TranslateError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	sqlErr := &mysqldb.SQLError{
+		Number: uint16(sqliteErr.Code()),
+		Err:    err,
+	}
+
+	message := sqliteErr.Error()
+	switch sqliteErr.Code() {
+	case SQLiteConstraintUnique, SQLiteConstraintPrimaryKey:
+		sqlErr.Kind = mysqldb.ErrDuplicateEntry
+		if match := columnRegexp.FindStringSubmatch(message); match != nil {
+			sqlErr.Table = match[1]
+			sqlErr.Column = match[2]
+		}
+	case SQLiteConstraintNotNull:
+		sqlErr.Kind = mysqldb.ErrNotNullViolation
+		if match := columnRegexp.FindStringSubmatch(message); match != nil {
+			sqlErr.Table = match[1]
+			sqlErr.Column = match[2]
+		}
+	case SQLiteConstraintCheck:
+		sqlErr.Kind = mysqldb.ErrCheckViolation
+		if match := checkConstraintRegexp.FindStringSubmatch(message); match != nil {
+			sqlErr.Constraint = match[1]
+			if strings.HasSuffix(match[1], lengthConstraintSuffix) {
+				sqlErr.Kind = mysqldb.ErrDataTooLong
+				sqlErr.Column = strings.TrimSuffix(match[1], lengthConstraintSuffix)
+			}
+		}
+	case SQLiteConstraintForeignKey:
+		sqlErr.Kind = mysqldb.ErrForeignKeyViolation
+	case SQLiteBusySnapshot, SQLiteLocked, SQLiteLockedSharedCache:
+		// The snapshot of the transaction is stale, only a new transaction can succeed.
+		sqlErr.Kind = mysqldb.ErrDeadlock
+	case SQLiteBusy, SQLiteBusyRecovery:
+		sqlErr.Kind = mysqldb.ErrLockWaitTimeout
+	default:
+		return err
+	}
+
+	return sqlErr
+}
+
+// reference is a foreign key column of an inserted row and the value it refers to.
+type reference struct {
+	column string
+	table  string
+	value  interface{}
+}
+
+// resolveForeignKey translates the error of an insert into table. SQLite reports a failed
+// foreign key without naming it, so the referenced rows are looked up and the first missing one
+// is set as the column of the error, the same way as the MySQL server reports it.
+func resolveForeignKey(ctx context.Context, tx *sql.Tx, err error, table string, references ...reference) error {
+	err = TranslateError(err)
+	var sqlErr *mysqldb.SQLError
+	if !errors.As(err, &sqlErr) || sqlErr.Kind != mysqldb.ErrForeignKeyViolation {
+		return err
+	}
+
+	sqlErr.Table = table
+	for _, reference := range references {
+		count := 0
+		query := "SELECT COUNT(*) FROM " + reference.table + " WHERE id = ?"
+		if errQuery := tx.QueryRowContext(ctx, query, reference.value).Scan(&count); errQuery != nil {
+			return err
+		}
+
+		if count == 0 {
+			sqlErr.Column = reference.column
+			sqlErr.ReferencedTable = reference.table
+			return err
+		}
+	}
+	return err
+}
+
+// translateDeleteError translates the error of a delete. A failed foreign key means
+// that the deleted row is still referenced.
+func translateDeleteError(err error) error {
+	err = TranslateError(err)
+	var sqlErr *mysqldb.SQLError
+	if errors.As(err, &sqlErr) && sqlErr.Kind == mysqldb.ErrForeignKeyViolation {
+		sqlErr.Kind = mysqldb.ErrRowReferenced
+	}
+	return err
+}
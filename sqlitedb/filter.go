@@ -0,0 +1,49 @@
+package sqlitedb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/pkg/errors"
+)
+
+// detailsPredicate returns the condition selecting the details matching filter.
+// json_extract returns booleans as integers, so the JSON type is compared as well to find exact matches.
+// The expressions are the same as the ones of the details indexes, so the indexes can be used.
+func detailsPredicate(filter models.DetailsFilter) (string, []interface{}, error) {
+	if len(filter) == 0 {
+		return "", nil, errors.WithMessage(models.ErrInvalidFilter, "empty filter")
+	}
+
+	if err := filter.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	for _, key := range filter.Keys() {
+		// The keys are validated, so they can be part of the JSON path.
+		extract := fmt.Sprintf("json_extract(data, '$.%s')", key)
+		jsonType := fmt.Sprintf("json_type(data, '$.%s')", key)
+		switch value := filter[key].(type) {
+		case nil:
+			conditions = append(conditions, jsonType+" = 'null'")
+		case bool:
+			if value {
+				conditions = append(conditions, extract+" = ? AND "+jsonType+" = 'true'")
+				args = append(args, 1)
+			} else {
+				conditions = append(conditions, extract+" = ? AND "+jsonType+" = 'false'")
+				args = append(args, 0)
+			}
+		case string:
+			conditions = append(conditions, extract+" = ? AND "+jsonType+" = 'text'")
+			args = append(args, value)
+		default:
+			conditions = append(conditions, extract+" = ? AND "+jsonType+" IN ('integer', 'real')")
+			args = append(args, value)
+		}
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
@@ -0,0 +1,243 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+var AddProductUsersQuery = "INSERT INTO users_products (users_id, products_id, privileges_id) VALUES (?, ?, ?)"
+
+func (*SQLiteFunctions) AddProductUsers(ctx context.Context, productID *uuid.UUID, productUsers *models.ProductUserIDs, tx *sql.Tx) error {
+	for userID, privilege := range productUsers.UserMap {
+		result, err := tx.ExecContext(ctx, AddProductUsersQuery, userID, productID, privilege)
+		if err != nil {
+			return resolveForeignKey(ctx, tx, err, "users_products",
+				reference{column: "users_id", table: "users", value: userID},
+				reference{column: "products_id", table: "products", value: productID})
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return TranslateError(err)
+		}
+
+		if affected == 0 {
+			return mysqldb.ErrNoProductUserAdded
+		}
+	}
+	return nil
+}
+
+var DeleteProductUsersByProductIDQuery = "DELETE FROM users_products WHERE products_id = ?"
+
+func (*SQLiteFunctions) DeleteProductUsersByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductUsersByProductIDQuery, productID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUserWithProduct
+	}
+
+	return nil
+}
+
+var UpdateUsersProductsQuery = "UPDATE users_products SET privileges_id = ? WHERE users_id = ? AND products_id = ?"
+
+func (*SQLiteFunctions) UpdateUsersProducts(ctx context.Context, userID *uuid.UUID, productID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, UpdateUsersProductsQuery, privilege, userID, productID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUsersProductUpdate
+	}
+
+	return nil
+}
+
+var AddProductQuery = "INSERT INTO products (id, name, product_details_id, product_assets_id) VALUES (?, ?, ?, ?)"
+
+func (*SQLiteFunctions) AddProduct(ctx context.Context, product *models.Product, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, AddProductQuery, product.ID, product.Name, product.DetailsID, product.AssetsID)
+	if err != nil {
+		return resolveForeignKey(ctx, tx, err, "products",
+			reference{column: "product_details_id", table: mysqldb.ProductDetails, value: product.DetailsID},
+			reference{column: "product_assets_id", table: mysqldb.ProductAssets, value: product.AssetsID})
+	}
+	return nil
+}
+
+var GetProductByIDQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE id = ? AND deleted_at IS NULL"
+
+func (*SQLiteFunctions) GetProductByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Product, error) {
+	product := models.Product{}
+	query := tx.QueryRowContext(ctx, GetProductByIDQuery, ID)
+	err := query.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	return &product, nil
+}
+
+var GetProductsByIDsQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE deleted_at IS NULL AND id IN "
+
+func (*SQLiteFunctions) GetProductsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Product, error) {
+	placeholders, interfaceList := inList(IDs)
+	rows, err := tx.QueryContext(ctx, GetProductsByIDsQuery+placeholders, interfaceList...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		product := models.Product{}
+		err := rows.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		products = append(products, product)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(products) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return products, nil
+}
+
+// productsByDetailsQuery returns the query of the products whose details match predicate.
+func productsByDetailsQuery(predicate string) string {
+	return "SELECT id, name, product_details_id, product_assets_id FROM products WHERE deleted_at IS NULL AND product_details_id IN (SELECT id FROM product_details WHERE " + predicate + ")"
+}
+
+// GetProductsByDetails returns the products whose details match filter.
+func (*SQLiteFunctions) GetProductsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Product, error) {
+	predicate, args, err := detailsPredicate(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, productsByDetailsQuery(predicate), args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		product := models.Product{}
+		err := rows.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		products = append(products, product)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(products) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return products, nil
+}
+
+var GetUserProductIDsQuery = "SELECT products_id, privileges_id FROM users_products WHERE users_id = ?"
+
+func (*SQLiteFunctions) GetUserProductIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProductIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetUserProductIDsQuery, userID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+	userProducts := models.UserProductIDs{
+		ProductMap:     make(map[uuid.UUID]int),
+		ProductIDArray: make([]uuid.UUID, 0),
+	}
+	for rows.Next() {
+		productID := uuid.UUID{}
+		privilege := -1
+		err := rows.Scan(&productID, &privilege)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		userProducts.ProductMap[productID] = privilege
+		userProducts.ProductIDArray = append(userProducts.ProductIDArray, productID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return &userProducts, nil
+}
+
+var GetProductByNameQuery = "SELECT id, name, product_details_id, product_assets_id FROM products WHERE name = ? AND deleted_at IS NULL"
+
+func (*SQLiteFunctions) GetProductByName(ctx context.Context, name string, tx *sql.Tx) (*models.Product, error) {
+	product := models.Product{}
+
+	query := tx.QueryRowContext(ctx, GetProductByNameQuery, name)
+
+	err := query.Scan(&product.ID, &product.Name, &product.DetailsID, &product.AssetsID)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	return &product, nil
+}
+
+var DeleteProductQuery = "DELETE FROM products WHERE id = ?"
+
+func (*SQLiteFunctions) DeleteProduct(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductQuery, productID)
+	if err != nil {
+		return translateDeleteError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoProductDeleted
+	}
+
+	return nil
+}
@@ -0,0 +1,391 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+var AddProjectUsersQuery = "INSERT INTO users_projects (users_id, projects_id, privileges_id) VALUES (?, ?, ?)"
+
+func (*SQLiteFunctions) AddProjectUsers(ctx context.Context, projectID *uuid.UUID, projectUsers *models.ProjectUserIDs, tx *sql.Tx) error {
+	for userID, privilege := range projectUsers.UserMap {
+		result, err := tx.ExecContext(ctx, AddProjectUsersQuery, userID, projectID, privilege)
+		if err != nil {
+			return resolveForeignKey(ctx, tx, err, "users_projects",
+				reference{column: "users_id", table: "users", value: userID},
+				reference{column: "projects_id", table: "projects", value: projectID})
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return TranslateError(err)
+		}
+
+		if affected == 0 {
+			return mysqldb.ErrNoProjectUserAdded
+		}
+	}
+	return nil
+}
+
+var DeleteProjectUsersByProjectIDQuery = "DELETE FROM users_projects WHERE projects_id = ?"
+
+func (*SQLiteFunctions) DeleteProjectUsersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectUsersByProjectIDQuery, projectID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUserWithProject
+	}
+
+	return nil
+}
+
+var DeleteProjectUserQuery = "DELETE FROM users_projects WHERE projects_id = ? AND users_id = ?"
+
+func (*SQLiteFunctions) DeleteProjectUser(ctx context.Context, projectID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProjectUserQuery, projectID, userID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUserWithProject
+	}
+
+	return nil
+}
+
+var UpdateUsersProjectsQuery = "UPDATE users_projects SET privileges_id = ? WHERE users_id = ? AND projects_id = ?"
+
+func (*SQLiteFunctions) UpdateUsersProjects(ctx context.Context, userID *uuid.UUID, projectID *uuid.UUID, privilege int, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, UpdateUsersProjectsQuery, privilege, userID, projectID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUsersProjectUpdate
+	}
+
+	return nil
+}
+
+var AddProjectQuery = "INSERT INTO projects (id, products_id, project_details_id, project_assets_id) VALUES (?, ?, ?, ?)"
+
+func (*SQLiteFunctions) AddProject(ctx context.Context, project *models.Project, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, AddProjectQuery, project.ID, project.ProductID, project.DetailsID, project.AssetsID)
+	if err != nil {
+		return resolveForeignKey(ctx, tx, err, "projects",
+			reference{column: "products_id", table: "products", value: project.ProductID},
+			reference{column: "project_details_id", table: mysqldb.ProjectDetails, value: project.DetailsID},
+			reference{column: "project_assets_id", table: mysqldb.ProjectAssets, value: project.AssetsID})
+	}
+	return nil
+}
+
+var GetProjectByIDQuery = "SELECT id, products_id, project_details_id, project_assets_id FROM projects WHERE id = ? AND deleted_at IS NULL"
+
+func (*SQLiteFunctions) GetProjectByID(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) (*models.Project, error) {
+	project := &models.Project{}
+	query := tx.QueryRowContext(ctx, GetProjectByIDQuery, ID)
+	err := query.Scan(&project.ID, &project.ProductID, &project.DetailsID, &project.AssetsID)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, sql.ErrNoRows
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+
+	return project, nil
+}
+
+var GetProjectsByIDsQuery = "SELECT id, products_id, project_details_id, project_assets_id FROM projects WHERE deleted_at IS NULL AND id IN "
+
+func (*SQLiteFunctions) GetProjectsByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+	placeholders, interfaceList := inList(IDs)
+	rows, err := tx.QueryContext(ctx, GetProjectsByIDsQuery+placeholders, interfaceList...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return scanProjects(rows)
+}
+
+// projectsByDetailsQuery returns the query of the projects whose details match predicate.
+func projectsByDetailsQuery(predicate string) string {
+	return "SELECT id, products_id, project_details_id, project_assets_id FROM projects WHERE deleted_at IS NULL AND project_details_id IN (SELECT id FROM project_details WHERE " + predicate + ")"
+}
+
+// GetProjectsByDetails returns the projects whose details match filter.
+func (*SQLiteFunctions) GetProjectsByDetails(ctx context.Context, filter models.DetailsFilter, tx *sql.Tx) ([]models.Project, error) {
+	predicate, args, err := detailsPredicate(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, projectsByDetailsQuery(predicate), args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return scanProjects(rows)
+}
+
+var GetUserProjectIDsQuery = "SELECT projects_id, privileges_id FROM users_projects WHERE users_id = ?"
+
+func (*SQLiteFunctions) GetUserProjectIDs(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) (*models.UserProjectIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetUserProjectIDsQuery, userID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+	userProjects := &models.UserProjectIDs{
+		ProjectMap:     make(map[uuid.UUID]int),
+		ProjectIDArray: make([]uuid.UUID, 0),
+	}
+	for rows.Next() {
+		projectID := uuid.UUID{}
+		privilege := -1
+		err := rows.Scan(&projectID, &privilege)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		userProjects.ProjectMap[projectID] = privilege
+		userProjects.ProjectIDArray = append(userProjects.ProjectIDArray, projectID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return userProjects, nil
+}
+
+var GetProductProjectsQuery = "SELECT id, products_id, project_details_id, project_assets_id FROM projects WHERE products_id = ? AND deleted_at IS NULL"
+
+func (*SQLiteFunctions) GetProductProjects(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) ([]models.Project, error) {
+	rows, err := tx.QueryContext(ctx, GetProductProjectsQuery, productID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return scanProjects(rows)
+}
+
+// scanProjects reads and closes the project rows. sql.ErrNoRows is returned if there are none.
+func scanProjects(rows *sql.Rows) ([]models.Project, error) {
+	defer rows.Close()
+
+	projects := make([]models.Project, 0)
+	for rows.Next() {
+		project := models.Project{}
+		err := rows.Scan(&project.ID, &project.ProductID, &project.DetailsID, &project.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		projects = append(projects, project)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(projects) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return projects, nil
+}
+
+var DeleteProjectQuery = "DELETE FROM projects WHERE id = ?"
+
+func (*SQLiteFunctions) DeleteProject(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	return execDelete(ctx, tx, mysqldb.ErrNoProjectDeleted, DeleteProjectQuery, projectID)
+}
+
+var DeleteProjectsByProductIDQuery = "DELETE FROM projects WHERE products_id = ?"
+
+func (*SQLiteFunctions) DeleteProjectsByProductID(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) error {
+	return execDelete(ctx, tx, mysqldb.ErrNoProjectDeleted, DeleteProjectsByProductIDQuery, productID)
+}
+
+var AddViewerQuery = "INSERT INTO viewers (id, owner_id) VALUES (?, ?)"
+
+var AddProjectViewerQuery = "INSERT INTO users_viewers (users_id, viewer_id, projects_id) VALUES (?, ?, ?)"
+
+func (*SQLiteFunctions) AddProjectViewer(ctx context.Context, projectViewer *models.ProjectViewer, tx *sql.Tx) error {
+	if projectViewer.IsOwner {
+		if _, err := tx.ExecContext(ctx, AddViewerQuery, projectViewer.ViewerID, projectViewer.UserID); err != nil {
+			return resolveForeignKey(ctx, tx, err, "viewers",
+				reference{column: "owner_id", table: "users", value: projectViewer.UserID})
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, AddProjectViewerQuery, projectViewer.UserID, projectViewer.ViewerID, projectViewer.ProjectID)
+	if err != nil {
+		return resolveForeignKey(ctx, tx, err, "users_viewers",
+			reference{column: "users_id", table: "users", value: projectViewer.UserID},
+			reference{column: "viewer_id", table: "viewers", value: projectViewer.ViewerID},
+			reference{column: "projects_id", table: "projects", value: projectViewer.ProjectID})
+	}
+	return nil
+}
+
+var GetViewerIDsByOwnerIDQuery = "SELECT id FROM viewers WHERE owner_id = ?"
+
+// GetViewerIDsByOwnerID returns the IDs of the viewers owned by the user. sql.ErrNoRows is returned if there are none.
+func (*SQLiteFunctions) GetViewerIDsByOwnerID(ctx context.Context, ownerID *uuid.UUID, tx *sql.Tx) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, GetViewerIDsByOwnerIDQuery, ownerID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	viewerIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var viewerID uuid.UUID
+		if err := rows.Scan(&viewerID); err != nil {
+			return nil, TranslateError(err)
+		}
+		viewerIDs = append(viewerIDs, viewerID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(viewerIDs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return viewerIDs, nil
+}
+
+var DeleteViewerByOwnerQuery = "DELETE FROM viewers WHERE owner_id = ?"
+
+func (*SQLiteFunctions) DeleteViewerByOwnerID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	return execDelete(ctx, tx, mysqldb.ErrNoViewerDeleted, DeleteViewerByOwnerQuery, userID)
+}
+
+var DeleteProjectViewerByUserIDQuery = "DELETE FROM users_viewers WHERE users_id = ?"
+
+func (f *SQLiteFunctions) DeleteProjectViewerByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) error {
+	if err := execDelete(ctx, tx, mysqldb.ErrNoProjectViewerDeleted, DeleteProjectViewerByUserIDQuery, userID); err != nil {
+		return err
+	}
+
+	return f.DeleteViewerByOwnerID(ctx, userID, tx)
+}
+
+var GetProjectViewerByUserIDQuery = "SELECT users_id, viewer_id, projects_id FROM users_viewers WHERE users_id = ?"
+
+func (*SQLiteFunctions) GetProjectViewersByUserID(ctx context.Context, userID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	rows, err := tx.QueryContext(ctx, GetProjectViewerByUserIDQuery, userID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return scanProjectViewers(rows)
+}
+
+var GetProjectViewerByViewerIDQuery = "SELECT users_id, viewer_id, projects_id FROM users_viewers WHERE viewer_id = ?"
+
+func (*SQLiteFunctions) GetProjectViewersByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	rows, err := tx.QueryContext(ctx, GetProjectViewerByViewerIDQuery, viewerID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return scanProjectViewers(rows)
+}
+
+var GetProjectViewerByProjectIDQuery = "SELECT users_id, viewer_id, projects_id FROM users_viewers WHERE projects_id = ?"
+
+func (*SQLiteFunctions) GetProjectViewersByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) ([]models.ProjectViewer, error) {
+	rows, err := tx.QueryContext(ctx, GetProjectViewerByProjectIDQuery, projectID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return scanProjectViewers(rows)
+}
+
+// scanProjectViewers reads and closes the project viewer rows. sql.ErrNoRows is returned if there are none.
+func scanProjectViewers(rows *sql.Rows) ([]models.ProjectViewer, error) {
+	defer rows.Close()
+
+	projectViewers := make([]models.ProjectViewer, 0)
+	for rows.Next() {
+		projectViewer := models.ProjectViewer{}
+		err := rows.Scan(&projectViewer.UserID, &projectViewer.ViewerID, &projectViewer.ProjectID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		projectViewers = append(projectViewers, projectViewer)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(projectViewers) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return projectViewers, nil
+}
+
+var DeleteProjectViewerByViewerIDQuery = "DELETE FROM users_viewers WHERE viewer_id = ?"
+
+func (*SQLiteFunctions) DeleteProjectViewerByViewerID(ctx context.Context, viewerID *uuid.UUID, tx *sql.Tx) error {
+	return execDelete(ctx, tx, mysqldb.ErrNoProjectViewerDeleted, DeleteProjectViewerByViewerIDQuery, viewerID)
+}
+
+var DeleteProjectViewerByProjectIDQuery = "DELETE FROM users_viewers WHERE projects_id = ?"
+
+func (*SQLiteFunctions) DeleteProjectViewerByProjectID(ctx context.Context, projectID *uuid.UUID, tx *sql.Tx) error {
+	return execDelete(ctx, tx, mysqldb.ErrNoProjectViewerDeleted, DeleteProjectViewerByProjectIDQuery, projectID)
+}
+
+// execDelete executes the delete query and returns errNoneDeleted if no rows were affected.
+func execDelete(ctx context.Context, tx *sql.Tx, errNoneDeleted error, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return translateDeleteError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return errNoneDeleted
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/db/migrations"
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+)
+
+// SQLite database connector implementation
+// The connector owns a single pooled DB handle that is opened by BootstrapSystem
+// and shared by every transaction until Close is called.
+// DBConnection is the path of the database file, it is created if it does not exist.
+type SQLiteConnector struct {
+	DBConnection string
+	// Directory of the migration files, the migrations embedded in the binary are used if empty.
+	MigrationDirectory string
+	// BootstrapSystem only opens the connection pool, the schema is migrated by the migrate command.
+	SkipMigration   bool
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// Time a statement waits for the lock of another connection before failing with ErrLockWaitTimeout.
+	BusyTimeout time.Duration
+
+	db *sql.DB
+}
+
+// SQLiteFunctions represents the implementation of SQLite data manipulation functions.
+// Errors are reported with the sentinel errors of mysqldb, so the callers do not depend on the backend.
+type SQLiteFunctions struct {
+	DBConnector mysqldb.ConnectorCommon
+	UUIDImpl    models.UUIDCommon
+}
+
+// connector opens the SQLite connections of the pool. Foreign keys and the busy timeout
+// are connection level settings in SQLite, so they are applied to every new connection.
+type connector struct {
+	name    string
+	pragmas []string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pragma := range c.pragmas {
+		if _, err := conn.(driver.Execer).Exec(pragma, nil); err != nil {
+			if errClose := conn.Close(); errClose != nil {
+				return nil, errors.Wrapf(err, "Failed to close connection: %s", errClose.Error())
+			}
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (*connector) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
+
+func (*SQLiteConnector) Commit(tx *sql.Tx) error {
+	return TranslateError(tx.Commit())
+}
+
+func (*SQLiteConnector) Rollback(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+// Savepoint marks the current state of the transaction. Changes made after it can be undone
+// by RollbackToSavepoint without aborting the transaction itself.
+func (*SQLiteConnector) Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+func (*SQLiteConnector) RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+func (*SQLiteConnector) ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return TranslateError(err)
+}
+
+// open creates the connection pool if it does not exist yet.
+func (c *SQLiteConnector) open() error {
+	if c.db != nil {
+		return nil
+	}
+
+	db := sql.OpenDB(&connector{
+		name: c.DBConnection,
+		pragmas: []string{
+			"PRAGMA foreign_keys = ON",
+			fmt.Sprintf("PRAGMA busy_timeout = %d", c.BusyTimeout.Milliseconds()),
+		},
+	})
+
+	// Write ahead logging lets the readers of the pool work next to the single writer.
+	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		if errClose := db.Close(); errClose != nil {
+			return errors.Wrapf(err, "Failed to close DB: %s", errClose.Error())
+		}
+		return err
+	}
+
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	c.db = db
+	return nil
+}
+
+func (c *SQLiteConnector) BootstrapSystem() error {
+	if err := c.open(); err != nil {
+		return err
+	}
+	fmt.Printf("DB connection open\n")
+
+	if c.SkipMigration {
+		fmt.Printf("Automatic migration is disabled\n")
+		return nil
+	}
+
+	fmt.Printf("Executing SQLite migration\n")
+	migrator, err := c.Migrator()
+	if err != nil {
+		return err
+	}
+
+	// The database is a local file, there is no server to wait for.
+	n, err := migrator.Up("")
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "Migration failed.")
+	}
+	fmt.Printf("Applied %d migrations!\n", n)
+	return nil
+}
+
+// Migrator opens the connection pool if needed and returns the migration manager working on it.
+func (c *SQLiteConnector) Migrator() (*mysqldb.Migrator, error) {
+	if err := c.open(); err != nil {
+		return nil, err
+	}
+
+	source, err := migrations.Source(migrations.SQLite, c.MigrationDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mysqldb.Migrator{
+		DB:      c.db,
+		Dialect: "sqlite3",
+		Source:  source,
+	}, nil
+}
+
+// RollbackWithErrorStack rolls back the transaction and returns the translated form of errorStack.
+// Only functions that start the transaction themselves may use it.
+func RollbackWithErrorStack(tx *sql.Tx, errorStack error) error {
+	errorStack = TranslateError(errorStack)
+	if err := tx.Rollback(); err != nil {
+		return errors.Wrapf(errorStack, "Failed to rollback changes: %s", err.Error())
+	}
+	return errorStack
+}
+
+// ConnectSystem starts a new transaction on a connection taken from the pool.
+func (c *SQLiteConnector) ConnectSystem(ctx context.Context) (*sql.Tx, error) {
+	if c.db == nil {
+		return nil, mysqldb.ErrConnectorNotOpen
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return tx, nil
+}
+
+// Close releases the connection pool. It is safe to call on a connector that has not been bootstrapped.
+func (c *SQLiteConnector) Close() error {
+	if c.db == nil {
+		return nil
+	}
+
+	err := c.db.Close()
+	c.db = nil
+	return err
+}
+
+// inList returns the placeholders and the arguments of an IN (...) condition.
+func inList(IDs []uuid.UUID) (string, []interface{}) {
+	interfaceList := make([]interface{}, len(IDs))
+	for i := range IDs {
+		interfaceList[i] = IDs[i]
+	}
+	return "(" + strings.TrimSuffix(strings.Repeat("?,", len(IDs)), ",") + ")", interfaceList
+}
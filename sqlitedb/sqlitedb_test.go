@@ -0,0 +1,138 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests/backendtest"
+	"github.com/pkg/errors"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// newTestBackend returns data functions working on a new, migrated database file.
+// Unlike the sqlmock based tests of mysqldb, the queries are executed by a real SQLite engine.
+func newTestBackend(t *testing.T) (mysqldb.FunctionsCommon, mysqldb.ConnectorCommon) {
+	connector := &SQLiteConnector{
+		DBConnection: filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 4,
+		MaxIdleConns: 4,
+		BusyTimeout:  time.Second,
+	}
+	if err := connector.BootstrapSystem(); err != nil {
+		t.Fatalf("Failed to bootstrap test DB: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := connector.Close(); err != nil {
+			t.Errorf("Failed to close test DB: %s", err)
+		}
+	})
+
+	return &SQLiteFunctions{DBConnector: connector}, connector
+}
+
+func TestConformance(t *testing.T) {
+	backendtest.Run(t, newTestBackend)
+}
+
+func TestConnectSystem(t *testing.T) {
+	connector := &SQLiteConnector{}
+
+	tx, err := connector.ConnectSystem(context.Background())
+	tests.CheckResult(tx == nil, true, err, mysqldb.ErrConnectorNotOpen, "not_bootstrapped", t)
+	tests.CheckResult(nil, nil, connector.Close(), nil, "close_not_bootstrapped", t)
+}
+
+func TestForeignKeysEnabled(t *testing.T) {
+	_, common := newTestBackend(t)
+	connector := common.(*SQLiteConnector)
+
+	// Every connection of the pool must enforce the foreign keys, not only the first one.
+	transactions := make([]*sql.Tx, 0)
+	for i := 0; i < connector.MaxOpenConns; i++ {
+		tx, err := connector.ConnectSystem(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to setup DB transaction %s", err)
+		}
+		transactions = append(transactions, tx)
+
+		enabled := false
+		err = tx.QueryRow("PRAGMA foreign_keys").Scan(&enabled)
+		tests.CheckResult(enabled, true, err, nil, "foreign_keys_enabled", t)
+	}
+
+	for _, tx := range transactions {
+		tests.CheckResult(nil, nil, connector.Rollback(tx), nil, "rollback", t)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	connector := &SQLiteConnector{
+		DBConnection:  filepath.Join(t.TempDir(), "test.db"),
+		SkipMigration: true,
+	}
+	if err := connector.BootstrapSystem(); err != nil {
+		t.Fatalf("Failed to bootstrap test DB: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := connector.Close(); err != nil {
+			t.Errorf("Failed to close test DB: %s", err)
+		}
+	})
+
+	migrator, err := connector.Migrator()
+	if err != nil {
+		t.Fatalf("Failed to create migrator: %s", err)
+	}
+	migrator.Source = &migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{
+			{Id: "1_first", Up: []string{"CREATE TABLE first(id TEXT)"}, Down: []string{"DROP TABLE first"}},
+			{Id: "2_second", Up: []string{"CREATE TABLE second(id TEXT)"}, Down: []string{"DROP TABLE second"}},
+			{Id: "3_third", Up: []string{"CREATE TABLE third(id TEXT)"}, Down: []string{"DROP TABLE third"}},
+		},
+	}
+
+	applied := func() []string {
+		statuses, err := migrator.Status()
+		if err != nil {
+			t.Fatalf("Failed to get migration status: %s", err)
+		}
+
+		IDs := make([]string, 0)
+		for _, status := range statuses {
+			if status.Applied {
+				IDs = append(IDs, status.ID)
+			}
+		}
+		return IDs
+	}
+
+	tests.CheckResult(applied(), []string{}, nil, nil, "skipped_migration", t)
+
+	n, err := migrator.Up("2_second")
+	tests.CheckResult(n, 2, err, nil, "up_to", t)
+	tests.CheckResult(applied(), []string{"1_first", "2_second"}, nil, nil, "applied_up_to", t)
+
+	pending, err := migrator.Pending()
+	tests.CheckResult(pending, 1, err, nil, "pending_up_to", t)
+
+	_, err = migrator.Up("1_first")
+	tests.CheckResult(errors.Is(err, mysqldb.ErrUnknownMigration), true, nil, nil, "up_to_applied", t)
+
+	tests.CheckResult(nil, nil, migrator.Redo(), nil, "redo", t)
+	tests.CheckResult(applied(), []string{"1_first", "2_second"}, nil, nil, "applied_redo", t)
+
+	n, err = migrator.Up("")
+	tests.CheckResult(n, 1, err, nil, "up_all", t)
+
+	n, err = migrator.Down(2)
+	tests.CheckResult(n, 2, err, nil, "down", t)
+	tests.CheckResult(applied(), []string{"1_first"}, nil, nil, "applied_down", t)
+
+	_, err = migrator.Down(0)
+	tests.CheckResult(err != nil, true, nil, nil, "down_zero", t)
+}
@@ -0,0 +1,156 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/google/uuid"
+)
+
+var GetUserByEmailQuery = "SELECT id, name, email, password, user_settings_id, user_assets_id FROM users WHERE email = ? AND deleted_at IS NULL"
+var GetUserByIDQuery = "SELECT id, name, email, password, user_settings_id, user_assets_id FROM users WHERE id = ? AND deleted_at IS NULL"
+
+// GetUser returns the user defined by the key name and key value.
+// Key name can be either id or email.
+func (*SQLiteFunctions) GetUser(ctx context.Context, queryType int, keyValue interface{}, tx *sql.Tx) (*models.User, error) {
+	queryString := GetUserByIDQuery
+	if queryType == mysqldb.ByEmail {
+		queryString = GetUserByEmailQuery
+		keyValue = strings.ReplaceAll(strings.ToLower(keyValue.(string)), " ", "")
+	}
+
+	var user models.User
+	query := tx.QueryRowContext(ctx, queryString, keyValue)
+	password := sql.NullString{}
+	err := query.Scan(&user.ID, &user.Name, &user.Email, &password, &user.SettingsID, &user.AssetsID)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, err
+	case err != nil:
+		return nil, TranslateError(err)
+	default:
+	}
+	user.Password = []byte(password.String)
+	return &user, nil
+}
+
+var GetUsersByIDsQuery = "SELECT id, name, email, password, user_settings_id, user_assets_id FROM users WHERE deleted_at IS NULL AND id IN "
+
+func (*SQLiteFunctions) GetUsersByIDs(ctx context.Context, IDs []uuid.UUID, tx *sql.Tx) ([]models.User, error) {
+	placeholders, interfaceList := inList(IDs)
+	rows, err := tx.QueryContext(ctx, GetUsersByIDsQuery+placeholders, interfaceList...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		user := models.User{}
+		password := sql.NullString{}
+		err := rows.Scan(&user.ID, &user.Name, &user.Email, &password, &user.SettingsID, &user.AssetsID)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		user.Password = []byte(password.String)
+		users = append(users, user)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	if len(users) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return users, nil
+}
+
+var InsertUserQuery = "INSERT INTO users (id, name, email, password, user_settings_id, user_assets_id) VALUES (?, ?, ?, ?, ?, ?)"
+
+// AddUser creates a new user entry in the DB.
+// Email/Name are unique in DB. Duplicates will return error.
+func (*SQLiteFunctions) AddUser(ctx context.Context, user *models.User, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, InsertUserQuery, user.ID, user.Name, user.Email, string(user.Password), user.SettingsID, user.AssetsID)
+	if err != nil {
+		return resolveForeignKey(ctx, tx, err, "users",
+			reference{column: "user_settings_id", table: mysqldb.UserSettings, value: user.SettingsID},
+			reference{column: "user_assets_id", table: mysqldb.UserAssets, value: user.AssetsID})
+	}
+	return nil
+}
+
+var DeleteUserQuery = "DELETE FROM users WHERE id = ?"
+
+func (*SQLiteFunctions) DeleteUser(ctx context.Context, ID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteUserQuery, ID)
+	if err != nil {
+		return translateDeleteError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUserDeleted
+	}
+
+	return nil
+}
+
+var GetProductUserIDsQuery = "SELECT users_id, privileges_id FROM users_products WHERE products_id = ?"
+
+func (*SQLiteFunctions) GetProductUserIDs(ctx context.Context, productID *uuid.UUID, tx *sql.Tx) (*models.ProductUserIDs, error) {
+	rows, err := tx.QueryContext(ctx, GetProductUserIDsQuery, productID)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+
+	defer rows.Close()
+	productUsers := models.ProductUserIDs{
+		UserMap:     make(map[uuid.UUID]int),
+		UserIDArray: make([]uuid.UUID, 0),
+	}
+	for rows.Next() {
+		userID := uuid.UUID{}
+		privilege := -1
+		err := rows.Scan(&userID, &privilege)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		productUsers.UserMap[userID] = privilege
+		productUsers.UserIDArray = append(productUsers.UserIDArray, userID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return &productUsers, nil
+}
+
+var DeleteProductUserQuery = "DELETE FROM users_products WHERE products_id = ? AND users_id = ?"
+
+func (*SQLiteFunctions) DeleteProductUser(ctx context.Context, productID *uuid.UUID, userID *uuid.UUID, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, DeleteProductUserQuery, productID, userID)
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	if affected == 0 {
+		return mysqldb.ErrNoUserWithProduct
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func testAssets(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	asset := &models.Asset{
+		ID:      uuid.New(),
+		DataMap: models.DataMap{"test": "data"},
+	}
+	missingID := uuid.New()
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddAsset(ctx, mysqldb.UserAssets, asset, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_asset", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddAsset(ctx, mysqldb.UserAssets, asset, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrDuplicateEntry), true, nil, nil, "add_duplicate_asset", t)
+
+	output, err := functions.GetAsset(ctx, mysqldb.UserAssets, &asset.ID)
+	tests.CheckResult(output, asset, err, nil, "get_asset", t)
+
+	output, err = functions.GetAsset(ctx, mysqldb.UserAssets, &missingID)
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "get_missing_asset", t)
+
+	asset.DataMap["test"] = "updated"
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateAsset(ctx, mysqldb.UserAssets, asset, tx)
+	})
+	tests.CheckResult(asset.Version, int64(2), err, nil, "update_asset", t)
+
+	// A writer holding the old version must not overwrite the update.
+	stale := &models.Asset{ID: asset.ID, DataMap: models.DataMap{"test": "stale"}, Version: 1}
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateAsset(ctx, mysqldb.UserAssets, stale, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrVersionConflict), true, stale.Version, int64(1), "update_stale_version", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateAsset(ctx, mysqldb.UserAssets, asset, tx)
+	})
+	tests.CheckResult(asset.Version, int64(3), err, nil, "update_current_version", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateAsset(ctx, mysqldb.UserAssets, &models.Asset{ID: missingID, DataMap: models.DataMap{}}, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrAssetMissing), true, nil, nil, "update_missing_asset", t)
+
+	var assets []models.Asset
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		assets, err = functions.GetAssets(ctx, mysqldb.UserAssets, []uuid.UUID{asset.ID, missingID}, tx)
+		return err
+	})
+	tests.CheckResult(assets, []models.Asset{*asset}, err, nil, "get_assets", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteAsset(ctx, mysqldb.UserAssets, &asset.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "delete_asset", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteAsset(ctx, mysqldb.UserAssets, &asset.ID, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrAssetMissing), true, nil, nil, "delete_missing_asset", t)
+}
+
+func testRollbackIsolation(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	asset := &models.Asset{
+		ID:      uuid.New(),
+		DataMap: models.DataMap{},
+	}
+
+	// Changes of a rolled back transaction must not be visible to others.
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddAsset(ctx, mysqldb.UserAssets, asset, tx); err != nil {
+			return err
+		}
+		return errors.New("This is a failure test")
+	})
+	tests.CheckResult(err != nil, true, nil, nil, "failed_transaction", t)
+
+	output, err := functions.GetAsset(ctx, mysqldb.UserAssets, &asset.ID)
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "asset_rolled_back", t)
+}
+
+func testPatchAsset(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	asset := &models.Asset{
+		ID:      uuid.New(),
+		DataMap: models.DataMap{"name": "test", "theme": map[string]interface{}{"color": "red", "size": float64(12)}},
+	}
+	missingID := uuid.New()
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddAsset(ctx, mysqldb.UserAssets, asset, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_asset", t)
+
+	patch := func(assetID *uuid.UUID, patchType string, document string, version int64) (output *models.Asset, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) error {
+			output, err = functions.PatchAsset(ctx, mysqldb.UserAssets, assetID, &models.AssetPatch{Type: patchType, Document: []byte(document), Version: version}, tx)
+			return err
+		})
+		return output, err
+	}
+
+	output, err := patch(&asset.ID, models.MergePatchType, `{"theme": {"color": null, "font": "mono"}}`, 1)
+	expected := &models.Asset{ID: asset.ID, DataMap: models.DataMap{"name": "test", "theme": map[string]interface{}{"size": float64(12), "font": "mono"}}, Version: 2}
+	tests.CheckResult(output, expected, err, nil, "merge_patch", t)
+
+	_, err = patch(&asset.ID, models.MergePatchType, `{"name": "stale"}`, 1)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrVersionConflict), true, nil, nil, "merge_patch_stale_version", t)
+
+	output, err = patch(&asset.ID, models.JSONPatchType, `[{"op": "replace", "path": "/name", "value": "patched"}]`, 2)
+	expected = &models.Asset{ID: asset.ID, DataMap: models.DataMap{"name": "patched", "theme": map[string]interface{}{"size": float64(12), "font": "mono"}}, Version: 3}
+	tests.CheckResult(output, expected, err, nil, "json_patch", t)
+
+	// A failing test operation leaves the asset unchanged.
+	_, err = patch(&asset.ID, models.JSONPatchType, `[{"op": "add", "path": "/name", "value": "other"}, {"op": "test", "path": "/name", "value": "patched"}]`, 0)
+	tests.CheckResult(errors.Is(err, models.ErrPatchFailed), true, nil, nil, "json_patch_failed_test", t)
+
+	output, err = functions.GetAsset(ctx, mysqldb.UserAssets, &asset.ID)
+	tests.CheckResult(output, expected, err, nil, "get_patched_asset", t)
+
+	_, err = patch(&missingID, models.MergePatchType, `{"name": "missing"}`, 0)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrAssetMissing), true, nil, nil, "merge_patch_missing_asset", t)
+
+	_, err = patch(&missingID, models.JSONPatchType, `[{"op": "remove", "path": "/name"}]`, 0)
+	tests.CheckResult(errors.Is(err, mysqldb.ErrAssetMissing), true, nil, nil, "json_patch_missing_asset", t)
+}
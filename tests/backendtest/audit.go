@@ -0,0 +1,112 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func testAuditEvents(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	actor := uuid.New()
+	productID := uuid.New()
+	occurredAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	created := models.AuditEvent{
+		OccurredAt: occurredAt,
+		Actor:      &actor,
+		Action:     models.ActionCreate,
+		EntityType: mysqldb.Products,
+		EntityID:   productID,
+		After:      models.DataMap{"name": "testProduct"},
+	}
+	deleted := models.AuditEvent{
+		OccurredAt: occurredAt.Add(time.Hour),
+		Action:     models.ActionDelete,
+		EntityType: mysqldb.Products,
+		EntityID:   productID,
+		Before:     models.DataMap{"name": "testProduct"},
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddAuditEvent(ctx, &created, tx); err != nil {
+			return err
+		}
+		return functions.AddAuditEvent(ctx, &deleted, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_events", t)
+
+	// The events of a rolled back change are not kept.
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddAuditEvent(ctx, &models.AuditEvent{OccurredAt: occurredAt, Action: models.ActionUpdate, EntityType: mysqldb.Users, EntityID: actor}, tx); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	tests.CheckResult(nil, nil, err.Error(), "rollback", "rollback", t)
+
+	getEvents := func(filter models.AuditFilter) (events []models.AuditEvent, err error) {
+		if err := filter.Validate(); err != nil {
+			return nil, err
+		}
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			events, err = functions.GetAuditEvents(ctx, &filter, tx)
+			return err
+		})
+		return events, err
+	}
+
+	events, err := getEvents(models.AuditFilter{})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %v", len(events), err)
+	}
+	created.ID = events[1].ID
+	deleted.ID = events[0].ID
+	tests.CheckResult(events, []models.AuditEvent{deleted, created}, err, nil, "latest_first", t)
+
+	events, err = getEvents(models.AuditFilter{Limit: 1})
+	tests.CheckResult(events, []models.AuditEvent{deleted}, err, nil, "first_page", t)
+
+	events, err = getEvents(models.AuditFilter{Limit: 1, BeforeID: deleted.ID})
+	tests.CheckResult(events, []models.AuditEvent{created}, err, nil, "second_page", t)
+
+	events, err = getEvents(models.AuditFilter{Actor: &actor})
+	tests.CheckResult(events, []models.AuditEvent{created}, err, nil, "by_actor", t)
+
+	events, err = getEvents(models.AuditFilter{Action: models.ActionDelete, EntityType: mysqldb.Products, EntityID: &productID})
+	tests.CheckResult(events, []models.AuditEvent{deleted}, err, nil, "by_entity", t)
+
+	events, err = getEvents(models.AuditFilter{From: occurredAt, To: occurredAt.Add(time.Hour)})
+	tests.CheckResult(events, []models.AuditEvent{created}, err, nil, "by_time", t)
+
+	_, err = getEvents(models.AuditFilter{EntityType: mysqldb.Users})
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_events", t)
+
+	getEventsAfter := func(afterID int64, limit int) (events []models.AuditEvent, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			events, err = functions.GetAuditEventsAfter(ctx, afterID, limit, tx)
+			return err
+		})
+		return events, err
+	}
+
+	events, err = getEventsAfter(0, 10)
+	tests.CheckResult(events, []models.AuditEvent{created, deleted}, err, nil, "after_oldest_first", t)
+
+	events, err = getEventsAfter(0, 1)
+	tests.CheckResult(events, []models.AuditEvent{created}, err, nil, "after_limit", t)
+
+	events, err = getEventsAfter(created.ID, 10)
+	tests.CheckResult(events, []models.AuditEvent{deleted}, err, nil, "after_id", t)
+
+	_, err = getEventsAfter(deleted.ID, 10)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_events_after", t)
+}
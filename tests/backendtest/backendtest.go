@@ -0,0 +1,161 @@
+// Package backendtest is the conformance suite of the storage backends.
+// Every backend runs the same tests against a real database of its own, so that the backends are checked
+// to behave the same way, not only to execute the expected queries.
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+// Factory returns the data functions and the connector of a new, migrated and empty database.
+// The database is released by the cleanup functions registered on t.
+type Factory func(t *testing.T) (mysqldb.FunctionsCommon, mysqldb.ConnectorCommon)
+
+// Run executes the conformance suite against the databases created by newBackend, every test gets a new one.
+func Run(t *testing.T, newBackend Factory) {
+	suite := []struct {
+		name string
+		run  func(t *testing.T, newBackend Factory)
+	}{
+		{"GetUser", testGetUser},
+		{"AddUser", testAddUser},
+		{"DeleteUser", testDeleteUser},
+		{"GetUsersByIDs", testGetUsersByIDs},
+		{"Assets", testAssets},
+		{"RollbackIsolation", testRollbackIsolation},
+		{"PatchAsset", testPatchAsset},
+		{"AssetHistory", testAssetHistory},
+		{"AddProduct", testAddProduct},
+		{"GetProduct", testGetProduct},
+		{"DeleteProduct", testDeleteProduct},
+		{"GetProductsByDetails", testGetProductsByDetails},
+		{"Projects", testProjects},
+		{"ProjectViewers", testProjectViewers},
+		{"Trash", testTrash},
+		{"AuditEvents", testAuditEvents},
+		{"OutboxEvents", testOutboxEvents},
+		{"WebhookSubscriptions", testWebhookSubscriptions},
+		{"WebhookDeliveries", testWebhookDeliveries},
+		{"Backup", testBackup},
+		{"Savepoint", testSavepoint},
+		{"Privileges", testPrivileges},
+	}
+
+	for _, test := range suite {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newBackend)
+		})
+	}
+}
+
+// RunInTx executes unitOfWork in a transaction that is committed if it returns no error.
+func RunInTx(t *testing.T, connector mysqldb.ConnectorCommon, unitOfWork func(tx *sql.Tx) error) error {
+	tx, err := connector.ConnectSystem(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to setup DB transaction %s", err)
+	}
+
+	if err := unitOfWork(tx); err != nil {
+		if errRb := connector.Rollback(tx); errRb != nil {
+			t.Errorf("Failed to rollback DB transaction %s", errRb)
+		}
+		return err
+	}
+	return connector.Commit(tx)
+}
+
+// CreateUser inserts a user with its settings and assets.
+func CreateUser(t *testing.T, functions mysqldb.FunctionsCommon, connector mysqldb.ConnectorCommon, name string) *models.User {
+	ctx := context.Background()
+	user := &models.User{
+		ID:         uuid.New(),
+		Name:       name,
+		Email:      strings.ToLower(name) + "@test.com",
+		Password:   []byte("testPass"),
+		SettingsID: uuid.New(),
+		AssetsID:   uuid.New(),
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		settings := &models.Asset{ID: user.SettingsID, DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.UserSettings, settings, tx); err != nil {
+			return err
+		}
+
+		assets := &models.Asset{ID: user.AssetsID, DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.UserAssets, assets, tx); err != nil {
+			return err
+		}
+		return functions.AddUser(ctx, user, tx)
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test user: %s", err)
+	}
+	return user
+}
+
+func testSavepoint(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	user := CreateUser(t, functions, connector, "testName")
+	other := CreateUser(t, functions, connector, "otherName")
+
+	// A failed sub operation is undone without ending the transaction.
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.DeleteUser(ctx, &other.ID, tx); err != nil {
+			return err
+		}
+		if err := connector.Savepoint(ctx, tx, "sp_1"); err != nil {
+			return err
+		}
+		if err := functions.DeleteUser(ctx, &user.ID, tx); err != nil {
+			return err
+		}
+		if err := connector.RollbackToSavepoint(ctx, tx, "sp_1"); err != nil {
+			return err
+		}
+		return connector.ReleaseSavepoint(ctx, tx, "sp_1")
+	})
+	tests.CheckResult(nil, nil, err, nil, "rollback_to_savepoint", t)
+
+	var output *models.User
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetUser(ctx, mysqldb.ByID, user.ID, tx)
+		return err
+	})
+	tests.CheckResult(output, user, err, nil, "user_kept", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetUser(ctx, mysqldb.ByID, other.ID, tx)
+		return err
+	})
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "delete_before_savepoint_kept", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return connector.RollbackToSavepoint(ctx, tx, "sp_missing")
+	})
+	tests.CheckResult(err != nil, true, nil, nil, "missing_savepoint", t)
+}
+
+func testPrivileges(t *testing.T, newBackend Factory) {
+	functions, _ := newBackend(t)
+	ctx := context.Background()
+
+	privileges, err := functions.GetPrivileges(ctx)
+	tests.CheckResult(len(privileges), 3, err, nil, "get_privileges", t)
+
+	privilege, err := functions.GetPrivilege(ctx, "Owner")
+	tests.CheckResult(privilege.ID, 1, err, nil, "get_privilege", t)
+
+	privilege, err = functions.GetPrivilege(ctx, "Missing")
+	tests.CheckResult(privilege == nil, true, err, sql.ErrNoRows, "get_missing_privilege", t)
+}
@@ -0,0 +1,92 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/pkg/errors"
+)
+
+// dumpTables returns the rows of every backed up table.
+func dumpTables(t *testing.T, functions mysqldb.FunctionsCommon, connector mysqldb.ConnectorCommon) map[string][]models.BackupRow {
+	dump := make(map[string][]models.BackupRow)
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		for i := range mysqldb.BackupTables {
+			table := &mysqldb.BackupTables[i]
+			err := functions.DumpTable(context.Background(), table, func(r models.BackupRow) error {
+				dump[table.Name] = append(dump[table.Name], r)
+				return nil
+			}, tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to dump the tables: %s", err)
+	}
+	return dump
+}
+
+func testBackup(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+	CreateProject(t, functions, connector, &product.ID, &owner.ID)
+	deletedAt := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.TrashEntity(ctx, mysqldb.Products, &product.ID, deletedAt, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "trash_product", t)
+
+	dump := dumpTables(t, functions, connector)
+	expectedUser := models.BackupRow{
+		"id":               owner.ID,
+		"name":             owner.Name,
+		"email":            owner.Email,
+		"password":         "testPass",
+		"user_settings_id": owner.SettingsID,
+		"user_assets_id":   owner.AssetsID,
+		"deleted_at":       nil,
+	}
+	tests.CheckResult(dump[mysqldb.Users], []models.BackupRow{expectedUser}, nil, nil, "users", t)
+	tests.CheckResult(dump[mysqldb.Products][0]["deleted_at"], deletedAt, nil, nil, "deleted_product", t)
+	tests.CheckResult(dump[mysqldb.ProductUsers][0]["privileges_id"], int64(1), nil, nil, "privilege", t)
+	tests.CheckResult(len(dump["privileges"]), 3, nil, nil, "privileges", t)
+	tests.CheckResult(len(dump[mysqldb.ProjectUsers]), 1, nil, nil, "project_users", t)
+	tests.CheckResult(dump[mysqldb.UserSettings][0]["version"], int64(1), nil, nil, "asset_version", t)
+
+	// The rows restored into an empty DB are dumped the same way.
+	restored, restoredConnector := newBackend(t)
+	err = RunInTx(t, restoredConnector, func(tx *sql.Tx) error {
+		for i := range mysqldb.BackupTables {
+			table := &mysqldb.BackupTables[i]
+			if table.Name == "privileges" {
+				continue
+			}
+			for _, r := range dump[table.Name] {
+				if err := restored.RestoreRow(ctx, table, r, tx); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	tests.CheckResult(nil, nil, err, nil, "restore", t)
+	tests.CheckResult(dumpTables(t, restored, restoredConnector), dump, nil, nil, "restored_dump", t)
+
+	table, err := mysqldb.GetBackupTable(mysqldb.Users)
+	tests.CheckResult(nil, nil, err, nil, "users_table", t)
+	err = RunInTx(t, restoredConnector, func(tx *sql.Tx) error {
+		return restored.RestoreRow(ctx, table, expectedUser, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrDuplicateEntry), true, nil, nil, "restore_twice", t)
+}
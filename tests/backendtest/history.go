@@ -0,0 +1,77 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+func testAssetHistory(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	userID := uuid.New()
+	ctx := models.WithActingUser(context.Background(), userID)
+	asset := &models.Asset{
+		ID:      uuid.New(),
+		DataMap: models.DataMap{"color": "red"},
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddAsset(ctx, mysqldb.ProjectAssets, asset, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_asset", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		_, err := functions.GetAssetHistory(ctx, mysqldb.ProjectAssets, &asset.ID, tx)
+		return err
+	})
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_history", t)
+
+	asset.DataMap = models.DataMap{"color": "blue"}
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateAsset(ctx, mysqldb.ProjectAssets, asset, tx)
+	})
+	tests.CheckResult(asset.Version, int64(2), err, nil, "update_asset", t)
+
+	patch := &models.AssetPatch{Type: models.MergePatchType, Document: []byte(`{"color": "green"}`)}
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		_, err := functions.PatchAsset(context.Background(), mysqldb.ProjectAssets, &asset.ID, patch, tx)
+		return err
+	})
+	tests.CheckResult(nil, nil, err, nil, "patch_asset", t)
+
+	var revisions []models.AssetRevision
+	var revision *models.AssetRevision
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		revisions, err = functions.GetAssetHistory(ctx, mysqldb.ProjectAssets, &asset.ID, tx)
+		if err != nil {
+			return err
+		}
+		revision, err = functions.GetAssetRevision(ctx, mysqldb.ProjectAssets, &asset.ID, 1, tx)
+		return err
+	})
+	tests.CheckResult(len(revisions), 2, err, nil, "history", t)
+	tests.CheckResult(revisions[0].Version, int64(2), nil, nil, "history_order", t)
+	tests.CheckResult(revisions[0].ChangedBy == nil, true, nil, nil, "history_without_user", t)
+	tests.CheckResult(revisions[1].ChangedBy, &userID, nil, nil, "history_user", t)
+	tests.CheckResult(revision.DataMap, models.DataMap{"color": "red"}, nil, nil, "revision", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		_, err := functions.GetAssetRevision(ctx, mysqldb.ProjectAssets, &asset.ID, 3, tx)
+		return err
+	})
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "missing_revision", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.DeleteAsset(ctx, mysqldb.ProjectAssets, &asset.ID, tx); err != nil {
+			return err
+		}
+		_, err := functions.GetAssetHistory(ctx, mysqldb.ProjectAssets, &asset.ID, tx)
+		return err
+	})
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "deleted_history", t)
+}
@@ -0,0 +1,96 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func testOutboxEvents(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	actor := uuid.New()
+	userID := uuid.New()
+	occurredAt := time.Date(2021, 8, 15, 12, 0, 0, 0, time.UTC)
+
+	created := models.DomainEvent{
+		Type:        models.EventUserCreated,
+		OccurredAt:  occurredAt,
+		Actor:       &actor,
+		AggregateID: userID,
+		Payload:     models.DataMap{"name": "testUser"},
+	}
+	deleted := models.DomainEvent{
+		Type:        models.EventUserDeleted,
+		OccurredAt:  occurredAt.Add(time.Hour),
+		AggregateID: userID,
+		Payload:     models.DataMap{"name": "testUser"},
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddOutboxEvent(ctx, &created, tx); err != nil {
+			return err
+		}
+		return functions.AddOutboxEvent(ctx, &deleted, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_events", t)
+
+	// The events of a rolled back change are not published.
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddOutboxEvent(ctx, &models.DomainEvent{Type: models.EventUserCreated, OccurredAt: occurredAt, AggregateID: actor}, tx); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	tests.CheckResult(nil, nil, err.Error(), "rollback", "rollback", t)
+
+	getEvents := func(limit int) (events []models.DomainEvent, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			events, err = functions.GetOutboxEvents(ctx, limit, tx)
+			return err
+		})
+		return events, err
+	}
+
+	events, err := getEvents(10)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %v", len(events), err)
+	}
+	created.ID = events[0].ID
+	deleted.ID = events[1].ID
+	tests.CheckResult(events, []models.DomainEvent{created, deleted}, err, nil, "oldest_first", t)
+
+	events, err = getEvents(1)
+	tests.CheckResult(events, []models.DomainEvent{created}, err, nil, "limit", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.RecordOutboxFailure(ctx, created.ID, "connection refused", tx)
+	})
+	created.Attempts = 1
+	events, errGet := getEvents(1)
+	tests.CheckResult(events, []models.DomainEvent{created}, err, errGet, "failure", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.DeleteOutboxEvent(ctx, created.ID, tx); err != nil {
+			return err
+		}
+		// Already delivered by another dispatcher.
+		return functions.DeleteOutboxEvent(ctx, created.ID, tx)
+	})
+	events, errGet = getEvents(10)
+	tests.CheckResult(events, []models.DomainEvent{deleted}, err, errGet, "delete", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteOutboxEvent(ctx, deleted.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "delete_last", t)
+
+	_, err = getEvents(10)
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "no_events", t)
+}
@@ -0,0 +1,204 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// CreateProduct inserts a product with its details and assets, owned by owner.
+func CreateProduct(t *testing.T, functions mysqldb.FunctionsCommon, connector mysqldb.ConnectorCommon, name string, owner *uuid.UUID) *models.Product {
+	ctx := context.Background()
+	product := &models.Product{
+		ID:        uuid.New(),
+		Name:      name,
+		DetailsID: uuid.New(),
+		AssetsID:  uuid.New(),
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		details := &models.Asset{ID: product.DetailsID, DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.ProductDetails, details, tx); err != nil {
+			return err
+		}
+
+		assets := &models.Asset{ID: product.AssetsID, DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.ProductAssets, assets, tx); err != nil {
+			return err
+		}
+
+		if err := functions.AddProduct(ctx, product, tx); err != nil {
+			return err
+		}
+
+		owners := &models.ProductUserIDs{
+			UserMap:     map[uuid.UUID]int{*owner: 1},
+			UserIDArray: []uuid.UUID{*owner},
+		}
+		return functions.AddProductUsers(ctx, &product.ID, owners, tx)
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test product: %s", err)
+	}
+	return product
+}
+
+func testAddProduct(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+
+	duplicate := *product
+	duplicate.ID = uuid.New()
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddProduct(ctx, &duplicate, tx)
+	})
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrDuplicateEntry, "name"), true, nil, nil, "duplicate_name", t)
+
+	missingUser := uuid.New()
+	productUsers := &models.ProductUserIDs{
+		UserMap:     map[uuid.UUID]int{missingUser: 2},
+		UserIDArray: []uuid.UUID{missingUser},
+	}
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddProductUsers(ctx, &product.ID, productUsers, tx)
+	})
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "users_id"), true, nil, nil, "missing_user", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateUsersProducts(ctx, &owner.ID, &product.ID, 2, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "update_users_products", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateUsersProducts(ctx, &missingUser, &product.ID, 2, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUsersProductUpdate, "update_missing_users_products", t)
+
+	var userProducts *models.UserProductIDs
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		userProducts, err = functions.GetUserProductIDs(ctx, &owner.ID, tx)
+		return err
+	})
+	expected := &models.UserProductIDs{
+		ProductMap:     map[uuid.UUID]int{product.ID: 2},
+		ProductIDArray: []uuid.UUID{product.ID},
+	}
+	tests.CheckResult(userProducts, expected, err, nil, "user_products", t)
+}
+
+func testGetProduct(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+	missingID := uuid.New()
+
+	var output *models.Product
+	err := RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetProductByID(ctx, &product.ID, tx)
+		return err
+	})
+	tests.CheckResult(output, product, err, nil, "valid_id", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetProductByID(ctx, &missingID, tx)
+		return err
+	})
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "missing_id", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetProductByName(ctx, product.Name, tx)
+		return err
+	})
+	tests.CheckResult(output, product, err, nil, "valid_name", t)
+
+	var products []models.Product
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		products, err = functions.GetProductsByIDs(ctx, []uuid.UUID{product.ID, missingID}, tx)
+		return err
+	})
+	tests.CheckResult(products, []models.Product{*product}, err, nil, "valid_ids", t)
+}
+
+func testDeleteProduct(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProduct(ctx, &product.ID, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrRowReferenced), true, nil, nil, "referenced_product", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.DeleteProductUsersByProductID(ctx, &product.ID, tx); err != nil {
+			return err
+		}
+		return functions.DeleteProduct(ctx, &product.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "valid_product", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProduct(ctx, &product.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoProductDeleted, "missing_product", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProductUsersByProductID(ctx, &product.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUserWithProduct, "missing_product_users", t)
+}
+
+func testGetProductsByDetails(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	free := CreateProduct(t, functions, connector, "freeProduct", &owner.ID)
+	paid := CreateProduct(t, functions, connector, "paidProduct", &owner.ID)
+
+	details := map[uuid.UUID]models.DataMap{
+		free.DetailsID: {models.IsFree: true, models.Requires3D: false, "price": float64(0), "vendor": "test"},
+		paid.DetailsID: {models.IsFree: "true", models.Requires3D: false, "price": float64(10), "vendor": nil},
+	}
+	for detailsID, dataMap := range details {
+		asset := &models.Asset{ID: detailsID, DataMap: dataMap}
+		err := RunInTx(t, connector, func(tx *sql.Tx) error {
+			return functions.UpdateAsset(ctx, mysqldb.ProductDetails, asset, tx)
+		})
+		if err != nil {
+			t.Fatalf("Failed to update product details: %s", err)
+		}
+	}
+
+	getProducts := func(filter models.DetailsFilter) (products []models.Product, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			products, err = functions.GetProductsByDetails(ctx, filter, tx)
+			return err
+		})
+		return products, err
+	}
+
+	products, err := getProducts(models.DetailsFilter{models.IsFree: true})
+	tests.CheckResult(products, []models.Product{*free}, err, nil, "boolean", t)
+
+	products, err = getProducts(models.DetailsFilter{models.IsFree: "true"})
+	tests.CheckResult(products, []models.Product{*paid}, err, nil, "string", t)
+
+	products, err = getProducts(models.DetailsFilter{models.Requires3D: false, "price": float64(10)})
+	tests.CheckResult(products, []models.Product{*paid}, err, nil, "number", t)
+
+	products, err = getProducts(models.DetailsFilter{"vendor": nil})
+	tests.CheckResult(products, []models.Product{*paid}, err, nil, "null", t)
+
+	products, err = getProducts(models.DetailsFilter{models.IsFree: true, "price": float64(10)})
+	tests.CheckResult(products == nil, true, err, sql.ErrNoRows, "no_match", t)
+}
@@ -0,0 +1,197 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+)
+
+// CreateProject inserts a project of product with its details and assets, owned by owner.
+func CreateProject(t *testing.T, functions mysqldb.FunctionsCommon, connector mysqldb.ConnectorCommon, productID *uuid.UUID, owner *uuid.UUID) *models.Project {
+	ctx := context.Background()
+	project := &models.Project{
+		ID:        uuid.New(),
+		ProductID: *productID,
+		DetailsID: uuid.New(),
+		AssetsID:  uuid.New(),
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		details := &models.Asset{ID: project.DetailsID, DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.ProjectDetails, details, tx); err != nil {
+			return err
+		}
+
+		assets := &models.Asset{ID: project.AssetsID, DataMap: models.DataMap{}}
+		if err := functions.AddAsset(ctx, mysqldb.ProjectAssets, assets, tx); err != nil {
+			return err
+		}
+
+		if err := functions.AddProject(ctx, project, tx); err != nil {
+			return err
+		}
+
+		owners := &models.ProjectUserIDs{
+			UserMap:     map[uuid.UUID]int{*owner: 1},
+			UserIDArray: []uuid.UUID{*owner},
+		}
+		return functions.AddProjectUsers(ctx, &project.ID, owners, tx)
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test project: %s", err)
+	}
+	return project
+}
+
+func testProjects(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+	project := CreateProject(t, functions, connector, &product.ID, &owner.ID)
+	missingID := uuid.New()
+
+	orphan := *project
+	orphan.ID = uuid.New()
+	orphan.ProductID = missingID
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddProject(ctx, &orphan, tx)
+	})
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "products_id"), true, nil, nil, "missing_product", t)
+
+	var output *models.Project
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetProjectByID(ctx, &project.ID, tx)
+		return err
+	})
+	tests.CheckResult(output, project, err, nil, "valid_id", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		output, err = functions.GetProjectByID(ctx, &missingID, tx)
+		return err
+	})
+	tests.CheckResult(output == nil, true, err, sql.ErrNoRows, "missing_id", t)
+
+	var projects []models.Project
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		projects, err = functions.GetProjectsByIDs(ctx, []uuid.UUID{project.ID}, tx)
+		return err
+	})
+	tests.CheckResult(projects, []models.Project{*project}, err, nil, "valid_ids", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		projects, err = functions.GetProductProjects(ctx, &product.ID, tx)
+		return err
+	})
+	tests.CheckResult(projects, []models.Project{*project}, err, nil, "product_projects", t)
+
+	var userProjects *models.UserProjectIDs
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		userProjects, err = functions.GetUserProjectIDs(ctx, &owner.ID, tx)
+		return err
+	})
+	expected := &models.UserProjectIDs{
+		ProjectMap:     map[uuid.UUID]int{project.ID: 1},
+		ProjectIDArray: []uuid.UUID{project.ID},
+	}
+	tests.CheckResult(userProjects, expected, err, nil, "user_projects", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.UpdateUsersProjects(ctx, &missingID, &project.ID, 2, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUsersProjectUpdate, "update_missing_users_projects", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProjectUser(ctx, &project.ID, &missingID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUserWithProject, "delete_missing_project_user", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.DeleteProjectUsersByProjectID(ctx, &project.ID, tx); err != nil {
+			return err
+		}
+		return functions.DeleteProjectsByProductID(ctx, &product.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "delete_product_projects", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProject(ctx, &project.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoProjectDeleted, "delete_missing_project", t)
+}
+
+func testProjectViewers(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+	project := CreateProject(t, functions, connector, &product.ID, &owner.ID)
+
+	viewer := models.ProjectViewer{
+		ViewerID:  uuid.New(),
+		ProjectID: project.ID,
+		UserID:    owner.ID,
+		IsOwner:   true,
+	}
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddProjectViewer(ctx, &viewer, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_project_viewer", t)
+
+	missingViewer := viewer
+	missingViewer.ViewerID = uuid.New()
+	missingViewer.IsOwner = false
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.AddProjectViewer(ctx, &missingViewer, tx)
+	})
+	tests.CheckResult(mysqldb.IsColumnError(err, mysqldb.ErrForeignKeyViolation, "viewer_id"), true, nil, nil, "missing_viewer", t)
+
+	// IsOwner is not stored in users_viewers.
+	viewer.IsOwner = false
+	var viewers []models.ProjectViewer
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		viewers, err = functions.GetProjectViewersByViewerID(ctx, &viewer.ViewerID, tx)
+		return err
+	})
+	tests.CheckResult(viewers, []models.ProjectViewer{viewer}, err, nil, "get_by_viewer_id", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		viewers, err = functions.GetProjectViewersByUserID(ctx, &owner.ID, tx)
+		return err
+	})
+	tests.CheckResult(viewers, []models.ProjectViewer{viewer}, err, nil, "get_by_user_id", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		viewers, err = functions.GetProjectViewersByProjectID(ctx, &project.ID, tx)
+		return err
+	})
+	tests.CheckResult(viewers, []models.ProjectViewer{viewer}, err, nil, "get_by_project_id", t)
+
+	var viewerIDs []uuid.UUID
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		viewerIDs, err = functions.GetViewerIDsByOwnerID(ctx, &owner.ID, tx)
+		return err
+	})
+	tests.CheckResult(viewerIDs, []uuid.UUID{viewer.ViewerID}, err, nil, "get_owned_viewer_ids", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProjectViewerByUserID(ctx, &owner.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "delete_by_user_id", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		viewerIDs, err = functions.GetViewerIDsByOwnerID(ctx, &owner.ID, tx)
+		return err
+	})
+	tests.CheckResult(viewerIDs == nil, true, err, sql.ErrNoRows, "get_missing_owned_viewer_ids", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProjectViewerByProjectID(ctx, &project.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoProjectViewerDeleted, "delete_missing_by_project_id", t)
+}
@@ -0,0 +1,83 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func testTrash(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+	project := CreateProject(t, functions, connector, &product.ID, &owner.ID)
+	deletedAt := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.TrashEntity(ctx, mysqldb.Products, &product.ID, deletedAt, tx); err != nil {
+			return err
+		}
+		return functions.TrashProjectsByProductID(ctx, &product.ID, deletedAt, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "trash_product", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.TrashEntity(ctx, mysqldb.Products, &product.ID, deletedAt, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrEntityMissing), true, nil, nil, "trash_product_twice", t)
+
+	// The trashed entities are hidden from every Get.
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if _, err := functions.GetProductByName(ctx, product.Name, tx); err != sql.ErrNoRows {
+			return errors.Errorf("trashed product by name: %v", err)
+		}
+		if _, err := functions.GetProductProjects(ctx, &product.ID, tx); err != sql.ErrNoRows {
+			return errors.Errorf("trashed product projects: %v", err)
+		}
+		_, err := functions.GetProjectsByIDs(ctx, []uuid.UUID{project.ID}, tx)
+		return err
+	})
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "hidden", t)
+
+	var entries []models.TrashEntry
+	var entry *models.TrashEntry
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		if _, err := functions.GetTrash(ctx, mysqldb.Projects, deletedAt.Add(-time.Second), tx); err != sql.ErrNoRows {
+			return errors.Errorf("trash before the delete: %v", err)
+		}
+		entries, err = functions.GetTrash(ctx, mysqldb.Projects, deletedAt, tx)
+		if err != nil {
+			return err
+		}
+		entry, err = functions.GetTrashEntry(ctx, mysqldb.Products, &product.ID, tx)
+		return err
+	})
+	tests.CheckResult(entries, []models.TrashEntry{{Type: mysqldb.Projects, ID: project.ID, DeletedAt: deletedAt}}, err, nil, "trash", t)
+	tests.CheckResult(entry, &models.TrashEntry{Type: mysqldb.Products, ID: product.ID, DeletedAt: deletedAt}, nil, nil, "trash_entry", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.RestoreEntity(ctx, mysqldb.Products, &product.ID, deletedAt.Add(time.Second), tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrEntityMissing), true, nil, nil, "restore_other_delete", t)
+
+	var projects []models.Project
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		if err := functions.RestoreEntity(ctx, mysqldb.Products, &product.ID, deletedAt, tx); err != nil {
+			return err
+		}
+		if err := functions.RestoreProjectsByProductID(ctx, &product.ID, deletedAt, tx); err != nil {
+			return err
+		}
+		projects, err = functions.GetProductProjects(ctx, &product.ID, tx)
+		return err
+	})
+	tests.CheckResult(projects, []models.Project{*project}, err, nil, "restore_product", t)
+}
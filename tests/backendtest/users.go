@@ -0,0 +1,224 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type userExpectedData struct {
+	user *models.User
+	err  error
+}
+
+type userInputData struct {
+	queryType int
+	keyValue  interface{}
+}
+
+func testGetUser(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	user := CreateUser(t, functions, connector, "testName")
+
+	dataSet := &tests.OrderedTests{
+		OrderedList: make(tests.OrderedTestList, 0),
+		TestDataSet: make(tests.DataSet),
+	}
+
+	testCase := "valid_email"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     userInputData{queryType: mysqldb.ByEmail, keyValue: " TestName@test.com"},
+		Expected: userExpectedData{user: user},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "invalid_email"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     userInputData{queryType: mysqldb.ByEmail, keyValue: "missing@test.com"},
+		Expected: userExpectedData{err: sql.ErrNoRows},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "valid_ID"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     userInputData{queryType: mysqldb.ByID, keyValue: user.ID},
+		Expected: userExpectedData{user: user},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "invalid_ID"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     userInputData{queryType: mysqldb.ByID, keyValue: uuid.New()},
+		Expected: userExpectedData{err: sql.ErrNoRows},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			testCase := dataSet.TestDataSet[testCaseString]
+			expectedData := testCase.Expected.(userExpectedData)
+			inputData := testCase.Data.(userInputData)
+
+			var output *models.User
+			err := RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+				output, err = functions.GetUser(context.Background(), inputData.queryType, inputData.keyValue, tx)
+				return err
+			})
+			tests.CheckResult(output, expectedData.user, err, expectedData.err, testCaseString, t)
+		})
+	}
+}
+
+func testAddUser(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	existing := CreateUser(t, functions, connector, "testName")
+
+	newUser := func(name string, email string) *models.User {
+		return &models.User{
+			ID:         uuid.New(),
+			Name:       name,
+			Email:      email,
+			SettingsID: existing.SettingsID,
+			AssetsID:   existing.AssetsID,
+		}
+	}
+
+	dataSet := &tests.OrderedTests{
+		OrderedList: make(tests.OrderedTestList, 0),
+		TestDataSet: make(tests.DataSet),
+	}
+
+	missingSettings := newUser("missingSettings", "missingSettings@test.com")
+	missingSettings.SettingsID = uuid.New()
+
+	type expected struct {
+		kind   error
+		column string
+	}
+
+	testCase := "valid_user"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     newUser("otherName", "other@test.com"),
+		Expected: expected{},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "duplicate_name"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     newUser(existing.Name, "unique@test.com"),
+		Expected: expected{kind: mysqldb.ErrDuplicateEntry, column: "name"},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "duplicate_email"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     newUser("uniqueName", existing.Email),
+		Expected: expected{kind: mysqldb.ErrDuplicateEntry, column: "email"},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "missing_settings"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     missingSettings,
+		Expected: expected{kind: mysqldb.ErrForeignKeyViolation, column: "user_settings_id"},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	testCase = "name_too_long"
+	dataSet.TestDataSet[testCase] = tests.Data{
+		Data:     newUser(strings.Repeat("a", 51), "long@test.com"),
+		Expected: expected{kind: mysqldb.ErrDataTooLong, column: "name"},
+	}
+	dataSet.OrderedList = append(dataSet.OrderedList, testCase)
+
+	for _, testCaseString := range dataSet.OrderedList {
+		testCaseString := testCaseString
+		t.Run(testCaseString, func(t *testing.T) {
+			testCase := dataSet.TestDataSet[testCaseString]
+			expectedData := testCase.Expected.(expected)
+			inputData := testCase.Data.(*models.User)
+
+			err := RunInTx(t, connector, func(tx *sql.Tx) error {
+				return functions.AddUser(context.Background(), inputData, tx)
+			})
+			if expectedData.kind == nil {
+				tests.CheckResult(nil, nil, err, nil, testCaseString, t)
+				return
+			}
+			tests.CheckResult(mysqldb.IsColumnError(err, expectedData.kind, expectedData.column), true, nil, nil, testCaseString, t)
+		})
+	}
+}
+
+func testDeleteUser(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	user := CreateUser(t, functions, connector, "testName")
+	owner := CreateUser(t, functions, connector, "ownerName")
+	product := CreateProduct(t, functions, connector, "testProduct", &owner.ID)
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteUser(ctx, &user.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "valid_user", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteUser(ctx, &user.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUserDeleted, "missing_user", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteUser(ctx, &owner.ID, tx)
+	})
+	tests.CheckResult(errors.Is(err, mysqldb.ErrRowReferenced), true, nil, nil, "referenced_user", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProductUser(ctx, &product.ID, &user.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrNoUserWithProduct, "missing_product_user", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteProductUser(ctx, &product.ID, &owner.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "valid_product_user", t)
+}
+
+func testGetUsersByIDs(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	first := CreateUser(t, functions, connector, "firstName")
+	second := CreateUser(t, functions, connector, "secondName")
+	product := CreateProduct(t, functions, connector, "testProduct", &first.ID)
+
+	var users []models.User
+	err := RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		users, err = functions.GetUsersByIDs(ctx, []uuid.UUID{first.ID, second.ID}, tx)
+		return err
+	})
+	tests.CheckResult(len(users), 2, err, nil, "valid_ids", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		users, err = functions.GetUsersByIDs(ctx, []uuid.UUID{uuid.New()}, tx)
+		return err
+	})
+	tests.CheckResult(users == nil, true, err, sql.ErrNoRows, "missing_ids", t)
+
+	var productUsers *models.ProductUserIDs
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		productUsers, err = functions.GetProductUserIDs(ctx, &product.ID, tx)
+		return err
+	})
+	expected := &models.ProductUserIDs{
+		UserMap:     map[uuid.UUID]int{first.ID: 1},
+		UserIDArray: []uuid.UUID{first.ID},
+	}
+	tests.CheckResult(productUsers, expected, err, nil, "product_users", t)
+}
@@ -0,0 +1,204 @@
+package backendtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/artofimagination/mysql-user-db-go-interface/models"
+	"github.com/artofimagination/mysql-user-db-go-interface/mysqldb"
+	"github.com/artofimagination/mysql-user-db-go-interface/tests"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func testWebhookSubscriptions(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	createdAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	billing := models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "https://billing.example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []string{models.EventUserCreated, models.EventUserDeleted},
+		CreatedAt:  createdAt,
+	}
+	notifications := models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "http://localhost:8080/events",
+		Secret:     "other",
+		EventTypes: []string{},
+		CreatedAt:  createdAt.Add(time.Hour),
+	}
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddWebhookSubscription(ctx, &notifications, tx); err != nil {
+			return err
+		}
+		return functions.AddWebhookSubscription(ctx, &billing, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_subscriptions", t)
+
+	getSubscriptions := func() (subscriptions []models.WebhookSubscription, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			subscriptions, err = functions.GetWebhookSubscriptions(ctx, tx)
+			return err
+		})
+		return subscriptions, err
+	}
+
+	subscriptions, err := getSubscriptions()
+	tests.CheckResult(subscriptions, []models.WebhookSubscription{billing, notifications}, err, nil, "oldest_first", t)
+
+	billing.URL = "https://billing.example.com/v2/hooks"
+	billing.Secret = "rotated"
+	billing.EventTypes = []string{models.EventProductCreated}
+	var found *models.WebhookSubscription
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		if err := functions.UpdateWebhookSubscription(ctx, &billing, tx); err != nil {
+			return err
+		}
+		found, err = functions.GetWebhookSubscription(ctx, &billing.ID, tx)
+		return err
+	})
+	tests.CheckResult(found, &billing, err, nil, "update", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteWebhookSubscription(ctx, &billing.ID, tx)
+	})
+	subscriptions, errGet := getSubscriptions()
+	tests.CheckResult(subscriptions, []models.WebhookSubscription{notifications}, err, errGet, "delete", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteWebhookSubscription(ctx, &billing.ID, tx)
+	})
+	tests.CheckResult(nil, nil, err, mysqldb.ErrWebhookSubscriptionMissing, "delete_missing", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+		_, err = functions.GetWebhookSubscription(ctx, &billing.ID, tx)
+		return err
+	})
+	tests.CheckResult(nil, nil, err, sql.ErrNoRows, "get_missing", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteWebhookSubscription(ctx, &notifications.ID, tx)
+	})
+	_, errGet = getSubscriptions()
+	tests.CheckResult(nil, nil, err, nil, "delete_last", t)
+	tests.CheckResult(nil, nil, errGet, sql.ErrNoRows, "no_subscriptions", t)
+}
+
+func testWebhookDeliveries(t *testing.T, newBackend Factory) {
+	functions, connector := newBackend(t)
+	ctx := context.Background()
+	createdAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	subscription := models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "https://billing.example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []string{},
+		CreatedAt:  createdAt,
+	}
+
+	newDelivery := func(eventID int64, nextAttemptAt time.Time) models.WebhookDelivery {
+		return models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			Event: models.DomainEvent{
+				ID:          eventID,
+				Type:        models.EventUserCreated,
+				OccurredAt:  createdAt,
+				AggregateID: uuid.New(),
+				Payload:     models.DataMap{"name": "testUser"},
+			},
+			Status:        models.WebhookPending,
+			NextAttemptAt: nextAttemptAt,
+			CreatedAt:     createdAt,
+		}
+	}
+	first := newDelivery(1, createdAt)
+	second := newDelivery(2, createdAt.Add(time.Hour))
+
+	err := RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.AddWebhookSubscription(ctx, &subscription, tx); err != nil {
+			return err
+		}
+		if err := functions.AddWebhookDelivery(ctx, &first, tx); err != nil {
+			return err
+		}
+		return functions.AddWebhookDelivery(ctx, &second, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "add_deliveries", t)
+
+	// An event is queued once for a subscription.
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		duplicate := newDelivery(1, createdAt)
+		return functions.AddWebhookDelivery(ctx, &duplicate, tx)
+	})
+	if !errors.Is(err, mysqldb.ErrDuplicateEntry) {
+		t.Errorf("Expected a duplicate entry error, got %v", err)
+	}
+
+	getDeliveries := func(beforeID int64, limit int) (deliveries []models.WebhookDelivery, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			deliveries, err = functions.GetWebhookDeliveries(ctx, &subscription.ID, beforeID, limit, tx)
+			return err
+		})
+		return deliveries, err
+	}
+
+	deliveries, err := getDeliveries(100, 10)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d: %v", len(deliveries), err)
+	}
+	first.ID = deliveries[1].ID
+	second.ID = deliveries[0].ID
+	tests.CheckResult(deliveries, []models.WebhookDelivery{second, first}, err, nil, "latest_first", t)
+
+	deliveries, err = getDeliveries(second.ID, 10)
+	tests.CheckResult(deliveries, []models.WebhookDelivery{first}, err, nil, "before_id", t)
+
+	getDue := func(dueAt time.Time) (deliveries []models.WebhookDelivery, err error) {
+		err = RunInTx(t, connector, func(tx *sql.Tx) (err error) {
+			deliveries, err = functions.GetDueWebhookDeliveries(ctx, dueAt, 10, tx)
+			return err
+		})
+		return deliveries, err
+	}
+
+	deliveries, err = getDue(createdAt)
+	tests.CheckResult(deliveries, []models.WebhookDelivery{first}, err, nil, "due", t)
+
+	attemptedAt := createdAt.Add(time.Minute)
+	retriedAt := attemptedAt
+	first.Status = models.WebhookSucceeded
+	first.Attempts = 1
+	first.LastAttemptAt = &attemptedAt
+	first.LastStatusCode = 204
+	second.Attempts = 1
+	second.LastAttemptAt = &retriedAt
+	second.LastStatusCode = 500
+	second.LastError = "rejected"
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		if err := functions.UpdateWebhookDelivery(ctx, &first, tx); err != nil {
+			return err
+		}
+		return functions.UpdateWebhookDelivery(ctx, &second, tx)
+	})
+	tests.CheckResult(nil, nil, err, nil, "update", t)
+
+	// Only the pending deliveries are due.
+	deliveries, err = getDue(createdAt.Add(2 * time.Hour))
+	tests.CheckResult(deliveries, []models.WebhookDelivery{second}, err, nil, "due_pending", t)
+
+	deliveries, err = getDeliveries(100, 10)
+	tests.CheckResult(deliveries, []models.WebhookDelivery{second, first}, err, nil, "log", t)
+
+	err = RunInTx(t, connector, func(tx *sql.Tx) error {
+		return functions.DeleteWebhookDeliveries(ctx, &subscription.ID, tx)
+	})
+	_, errGet := getDeliveries(100, 10)
+	tests.CheckResult(nil, nil, err, nil, "delete", t)
+	tests.CheckResult(nil, nil, errGet, sql.ErrNoRows, "no_deliveries", t)
+}